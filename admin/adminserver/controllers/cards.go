@@ -24,7 +24,8 @@ var (
 
 // CardTemplates holds all cards related templates.
 type CardTemplates struct {
-	List *template.Template
+	List    *template.Template
+	History *template.Template
 }
 
 // Cards is a mvc controller that handles all cards related views.
@@ -113,7 +114,7 @@ func (controller *Cards) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if _, err := controller.cards.Create(ctx, userID, percentageQualities, cards.TypeWon); err != nil {
+	if _, err := controller.cards.Create(ctx, userID, percentageQualities, cards.TypeWon, cards.Event{Cause: cards.CauseAdmin}); err != nil {
 		controller.log.Error("could not create card", ErrCards.Wrap(err))
 		http.Error(w, "could not create card", http.StatusInternalServerError)
 		return
@@ -141,3 +142,97 @@ func (controller *Cards) Delete(w http.ResponseWriter, r *http.Request) {
 
 	Redirect(w, r, "/cards", "GET")
 }
+
+// History is an endpoint that will provide a web page with the history of cards.
+// Allows to narrow the history to a single user to investigate disputes and fraud.
+func (controller *Cards) History(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var (
+		err         error
+		limit, page int
+		historyPage cards.HistoryPage
+	)
+	urlQuery := r.URL.Query()
+	limitQuery := urlQuery.Get("limit")
+	pageQuery := urlQuery.Get("page")
+	userIDQuery := urlQuery.Get("userId")
+
+	if limitQuery != "" {
+		if limit, err = strconv.Atoi(limitQuery); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if pageQuery != "" {
+		if page, err = strconv.Atoi(pageQuery); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	cursor := pagination.Cursor{
+		Limit: limit,
+		Page:  page,
+	}
+
+	if userIDQuery != "" {
+		userID, err := uuid.Parse(userIDQuery)
+		if err != nil {
+			http.Error(w, "could not parse user id", http.StatusBadRequest)
+			return
+		}
+
+		historyPage, err = controller.cards.ListHistoryByUserID(ctx, userID, cursor)
+	} else {
+		historyPage, err = controller.cards.ListHistory(ctx, cursor)
+	}
+	if err != nil {
+		controller.log.Error("could not get cards history", ErrCards.Wrap(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = controller.templates.History.Execute(w, historyPage)
+	if err != nil {
+		controller.log.Error("can not execute cards history template", ErrCards.Wrap(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// CardHistory is an endpoint that will provide a web page with the full provenance of the card.
+func (controller *Cards) CardHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	id, err := uuid.Parse(vars["id"])
+	if err != nil {
+		http.Error(w, "could not parse card id", http.StatusBadRequest)
+		return
+	}
+
+	history, err := controller.cards.ListHistoryByCardID(ctx, id)
+	if err != nil {
+		controller.log.Error("could not get card history", ErrCards.Wrap(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	historyPage := cards.HistoryPage{
+		History: history,
+		Page: pagination.Page{
+			Limit:       len(history),
+			CurrentPage: 1,
+			PageCount:   1,
+			TotalCount:  len(history),
+		},
+	}
+
+	err = controller.templates.History.Execute(w, historyPage)
+	if err != nil {
+		controller.log.Error("can not execute card history template", ErrCards.Wrap(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -112,7 +112,7 @@ func (controller *Clubs) CreateSquad(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if _, err = controller.clubs.CreateSquad(ctx, id); err != nil {
+	if _, err = controller.clubs.CreateSquad(ctx, id, ""); err != nil {
 		controller.log.Error("could not create squad", ErrClubs.Wrap(err))
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -8,14 +8,17 @@ import (
 	"math/big"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/zeebo/errs"
 
 	"ultimatedivision/cards"
+	"ultimatedivision/finances"
 	"ultimatedivision/internal/logger"
 	"ultimatedivision/marketplace"
+	"ultimatedivision/marketplace/bids"
 	"ultimatedivision/pkg/pagination"
 	"ultimatedivision/users"
 )
@@ -27,10 +30,11 @@ var (
 
 // MarketplaceTemplates holds all marketplace related templates.
 type MarketplaceTemplates struct {
-	List   *template.Template
-	Get    *template.Template
-	Create *template.Template
-	Bet    *template.Template
+	List    *template.Template
+	Get     *template.Template
+	Create  *template.Template
+	Bet     *template.Template
+	Economy *template.Template
 }
 
 // Marketplace is a mvc controller that handles all marketplace related views.
@@ -38,6 +42,7 @@ type Marketplace struct {
 	log logger.Logger
 
 	marketplace *marketplace.Service
+	bids        *bids.Service
 	cards       *cards.Service
 	users       *users.Service
 
@@ -45,10 +50,11 @@ type Marketplace struct {
 }
 
 // NewMarketplace is a constructor for marketplace controller.
-func NewMarketplace(log logger.Logger, marketplace *marketplace.Service, cards *cards.Service, users *users.Service, templates MarketplaceTemplates) *Marketplace {
+func NewMarketplace(log logger.Logger, marketplace *marketplace.Service, bids *bids.Service, cards *cards.Service, users *users.Service, templates MarketplaceTemplates) *Marketplace {
 	marketplaceController := &Marketplace{
 		log:         log,
 		marketplace: marketplace,
+		bids:        bids,
 		cards:       cards,
 		users:       users,
 		templates:   templates,
@@ -87,7 +93,7 @@ func (controller *Marketplace) ListActiveLots(w http.ResponseWriter, r *http.Req
 		Limit: limit,
 		Page:  page,
 	}
-	lotsPage, err = controller.marketplace.ListActiveLots(ctx, cursor)
+	lotsPage, err = controller.marketplace.ListActiveLots(ctx, marketplace.SaleMode(urlQuery.Get("saleMode")), cursor)
 	if err != nil {
 		controller.log.Error("could not list lots", ErrMarketplace.Wrap(err))
 		switch {
@@ -129,13 +135,63 @@ func (controller *Marketplace) GetLotByID(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err = controller.templates.Get.Execute(w, lot); err != nil {
+	fees, err := controller.marketplace.LotFees(ctx, lot.Type, lot.CardID, lot.CurrentPrice)
+	if err != nil {
+		controller.log.Error("could not get fees of lot", ErrMarketplace.Wrap(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	responseGetLot := marketplace.ResponseGetLot{
+		Lot:  lot,
+		Fees: fees,
+	}
+
+	if err = controller.templates.Get.Execute(w, &responseGetLot); err != nil {
 		controller.log.Error("can not execute get lot template", ErrMarketplace.Wrap(err))
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
 
+// Economy is an endpoint that will provide a web page with price stats and volume of the sales.
+func (controller *Marketplace) Economy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	stats, err := controller.marketplace.ListPriceStats(ctx, marketplace.SalesFilter{})
+	if err != nil {
+		controller.log.Error("could not list price stats", ErrMarketplace.Wrap(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	volumes, err := controller.marketplace.ListVolumes(ctx, marketplace.SalesFilter{}, 24*time.Hour)
+	if err != nil {
+		controller.log.Error("could not list volumes", ErrMarketplace.Wrap(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	revenue, err := controller.marketplace.GetRevenue(ctx)
+	if err != nil {
+		controller.log.Error("could not get revenue", ErrMarketplace.Wrap(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	responseEconomy := marketplace.ResponseEconomy{
+		Stats:   stats,
+		Volumes: volumes,
+		Revenue: revenue,
+	}
+
+	if err = controller.templates.Economy.Execute(w, &responseEconomy); err != nil {
+		controller.log.Error("can not execute economy template", ErrMarketplace.Wrap(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 // CreateLot is an endpoint that will add lot to database.
 func (controller *Marketplace) CreateLot(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -192,9 +248,18 @@ func (controller *Marketplace) CreateLot(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
+		suggestedPrices, err := controller.marketplace.SuggestedPrices(ctx, cardsListPage.Cards)
+		if err != nil {
+			controller.log.Error("could not get suggested prices", ErrMarketplace.Wrap(err))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
 		responseCreateLot := marketplace.ResponseCreateLot{
-			Cards: cardsListPage,
-			Users: usersList,
+			Cards:           cardsListPage,
+			Users:           usersList,
+			Fees:            controller.marketplace.GetFees(),
+			SuggestedPrices: suggestedPrices,
 		}
 
 		if err = controller.templates.Create.Execute(w, responseCreateLot); err != nil {
@@ -206,6 +271,7 @@ func (controller *Marketplace) CreateLot(w http.ResponseWriter, r *http.Request)
 		var (
 			startPrice big.Int
 			maxPrice   big.Int
+			floorPrice big.Int
 		)
 
 		err := r.ParseForm()
@@ -214,11 +280,14 @@ func (controller *Marketplace) CreateLot(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
-		itemIDForm := r.FormValue("itemId")
-		itemID, err := uuid.Parse(itemIDForm)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
+		var itemIDs []uuid.UUID
+		for _, itemIDForm := range r.Form["itemId"] {
+			itemID, err := uuid.Parse(itemIDForm)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			itemIDs = append(itemIDs, itemID)
 		}
 
 		userIDForm := r.FormValue("userId")
@@ -238,6 +307,13 @@ func (controller *Marketplace) CreateLot(w http.ResponseWriter, r *http.Request)
 			http.Error(w, "could not scan max price into big int", http.StatusBadRequest)
 		}
 
+		if floorPriceForm := r.FormValue("floorPrice"); floorPriceForm != "" {
+			if _, ok := floorPrice.SetString(floorPriceForm, 10); !ok {
+				http.Error(w, "could not scan floor price into big int", http.StatusBadRequest)
+				return
+			}
+		}
+
 		periodForm := r.FormValue("period")
 		period, err := strconv.Atoi(periodForm)
 		if err != nil {
@@ -245,10 +321,12 @@ func (controller *Marketplace) CreateLot(w http.ResponseWriter, r *http.Request)
 		}
 
 		createLot := marketplace.CreateLot{
-			CardID:     itemID,
+			CardIDs:    itemIDs,
+			SaleMode:   marketplace.SaleMode(r.FormValue("saleMode")),
 			UserID:     userID,
 			StartPrice: startPrice,
 			MaxPrice:   maxPrice,
+			FloorPrice: floorPrice,
 			Period:     marketplace.Period(period),
 		}
 
@@ -334,22 +412,24 @@ func (controller *Marketplace) PlaceBetLot(w http.ResponseWriter, r *http.Reques
 
 		betAmountForm := r.FormValue("betAmount")
 		if _, ok := betAmount.SetString(betAmountForm, 10); !ok {
-			http.Error(w, "could not scan start price into big int", http.StatusBadRequest)
-		}
-
-		betLot := marketplace.BetLot{
-			CardID:    id,
-			UserID:    userID,
-			BetAmount: betAmount,
+			http.Error(w, "could not scan bet amount into big int", http.StatusBadRequest)
+			return
 		}
 
-		if err := betLot.ValidateBetLot(); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		bid := bids.Bid{
+			LotID:  id,
+			UserID: userID,
+			Amount: betAmount,
 		}
 
-		if err := controller.marketplace.PlaceBetLot(ctx, betLot); err != nil {
+		if err := controller.bids.Create(ctx, bid); err != nil {
 			controller.log.Error("could not place bet lot", ErrMarketplace.Wrap(err))
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			switch {
+			case errs.Is(err, bids.ErrSmallAmountOfBid), finances.ErrInsufficientFunds.Has(err):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			default:
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
 			return
 		}
 
@@ -172,11 +172,16 @@ func (controller *Matches) ListMatches(w http.ResponseWriter, r *http.Request) {
 	cursor := pagination.Cursor{
 		Limit: limit,
 		Page:  page,
+		Token: urlQuery.Get("token"),
 	}
 
 	matchesPage, err := controller.matches.List(ctx, cursor)
 	if err != nil {
 		controller.log.Error("could not list matches", ErrMatches.Wrap(err))
+		if pagination.ErrInvalidToken.Has(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -28,6 +28,7 @@ import (
 	"ultimatedivision/internal/metrics"
 	"ultimatedivision/internal/templatefuncs"
 	"ultimatedivision/marketplace"
+	"ultimatedivision/marketplace/bids"
 	"ultimatedivision/pkg/auth"
 	"ultimatedivision/seasons"
 	"ultimatedivision/store"
@@ -85,7 +86,7 @@ type Server struct {
 // NewServer is a constructor for admin web server.
 func NewServer(config Config, log logger.Logger, listener net.Listener, authService *adminauth.Service,
 	admins *admins.Service, users *users.Service, cards *cards.Service, percentageQualities cards.PercentageQualities,
-	avatars *avatars.Service, marketplace *marketplace.Service, lootboxes *lootboxes.Service, clubs *clubs.Service,
+	avatars *avatars.Service, marketplace *marketplace.Service, bids *bids.Service, lootboxes *lootboxes.Service, clubs *clubs.Service,
 	queue *queue.Service, divisions *divisions.Service, matches *matches.Service, seasons *seasons.Service, store *store.Service, metric *metrics.Metric) (*Server, error) {
 	server := &Server{
 		log:    log,
@@ -129,6 +130,8 @@ func NewServer(config Config, log logger.Logger, listener net.Listener, authServ
 	cardsRouter.HandleFunc("", cardsController.List).Methods(http.MethodGet)
 	cardsRouter.HandleFunc("/create/{userId}", cardsController.Create).Methods(http.MethodGet)
 	cardsRouter.HandleFunc("/delete/{id}", cardsController.Delete).Methods(http.MethodGet)
+	cardsRouter.HandleFunc("/history", cardsController.History).Methods(http.MethodGet)
+	cardsRouter.HandleFunc("/history/{id}", cardsController.CardHistory).Methods(http.MethodGet)
 
 	avatarsRouter := router.PathPrefix("/avatars").Subrouter()
 	avatarsRouter.Use(server.withAuth)
@@ -137,9 +140,10 @@ func NewServer(config Config, log logger.Logger, listener net.Listener, authServ
 
 	marketplaceRouter := router.PathPrefix("/marketplace").Subrouter()
 	marketplaceRouter.Use(server.withAuth)
-	marketplaceController := controllers.NewMarketplace(log, marketplace, cards, users, server.templates.marketplace)
+	marketplaceController := controllers.NewMarketplace(log, marketplace, bids, cards, users, server.templates.marketplace)
 	marketplaceRouter.HandleFunc("", marketplaceController.ListActiveLots).Methods(http.MethodGet)
 	marketplaceRouter.HandleFunc("/get/{id}", marketplaceController.GetLotByID).Methods(http.MethodGet)
+	marketplaceRouter.HandleFunc("/economy", marketplaceController.Economy).Methods(http.MethodGet)
 	marketplaceRouter.HandleFunc("/create", marketplaceController.CreateLot).Methods(http.MethodGet, http.MethodPost)
 	marketplaceRouter.HandleFunc("/bet/{id}", marketplaceController.PlaceBetLot).Methods(http.MethodGet, http.MethodPost)
 
@@ -265,6 +269,14 @@ func (server *Server) initializeTemplates() (err error) {
 		return err
 	}
 
+	server.templates.card.History, err = template.New("history.html").Funcs(template.FuncMap{
+		"Inc": templatefuncs.Inc,
+		"Dec": templatefuncs.Dec,
+	}).ParseFiles(filepath.Join(server.config.StaticDir, "cards", "history.html"))
+	if err != nil {
+		return err
+	}
+
 	server.templates.avatar.Get, err = template.ParseFiles(filepath.Join(server.config.StaticDir, "avatars", "get.html"))
 	if err != nil {
 		return err
@@ -296,6 +308,11 @@ func (server *Server) initializeTemplates() (err error) {
 		return err
 	}
 
+	server.templates.marketplace.Economy, err = template.ParseFiles(filepath.Join(server.config.StaticDir, "marketplace", "economy.html"))
+	if err != nil {
+		return err
+	}
+
 	server.templates.auth.Login, err = template.ParseFiles(filepath.Join(server.config.StaticDir, "auth", "login.html"))
 	if err != nil {
 		return err
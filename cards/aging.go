@@ -0,0 +1,122 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package cards
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"ultimatedivision/pkg/pagination"
+)
+
+// MaxSkill defines the max value of the card skill.
+const MaxSkill = 100
+
+// physicalSkills returns pointers to the physical skills of the card.
+func (card *Card) physicalSkills() []*int {
+	return []*int{
+		&card.Physique,
+		&card.Acceleration,
+		&card.RunningSpeed,
+		&card.ReactionSpeed,
+		&card.Agility,
+		&card.Stamina,
+		&card.Strength,
+		&card.Jumping,
+		&card.Balance,
+	}
+}
+
+// changePhysicalSkills adds delta to the physical skills of the card, keeping them in range of 1 to MaxSkill.
+func (card *Card) changePhysicalSkills(delta int) {
+	for _, skill := range card.physicalSkills() {
+		*skill += delta
+		if *skill < 1 {
+			*skill = 1
+		} else if *skill > MaxSkill {
+			*skill = MaxSkill
+		}
+	}
+}
+
+// ageCard returns card which is one season older.
+// Physical skills grow until the peak age, but the rating never exceeds the potential, and decline after the decline age,
+// each next season faster than the previous one.
+func (service *Service) ageCard(card Card) Card {
+	aging := service.config.Aging
+	card.Age++
+
+	switch {
+	case card.Age <= aging.PeakAge:
+		// physique is one of the main skills, so its growth by RatingSkillsCount raises the rating by one.
+		growth := int(math.Floor((float64(card.Potential) - card.Rating()) * RatingSkillsCount))
+		if growth > aging.Growth {
+			growth = aging.Growth
+		}
+		if growth > 0 {
+			card.changePhysicalSkills(growth)
+		}
+	case card.Age >= aging.DeclineAge:
+		card.changePhysicalSkills(-aging.Decline * (card.Age - aging.DeclineAge + 1))
+	}
+
+	return card
+}
+
+// Aging describes changes of the cards at the end of the season, they are applied in one transaction.
+type Aging struct {
+	SeasonID int
+	// Cards are the cards one season older, History holds the records of their age change.
+	Cards   []Card
+	History []History
+	// Retirements are the records of the status change of the active cards which reached the retirement age.
+	// Cards are retired only if they are still active, retired cards are removed from the squads.
+	Retirements []History
+}
+
+// AgeAll makes all not retired cards one season older and retires active cards which reached the retirement age.
+// Cards are aged only once in the season, returns ids of the retired cards.
+func (service *Service) AgeAll(ctx context.Context, seasonID int) ([]uuid.UUID, error) {
+	aging := Aging{SeasonID: seasonID}
+
+	// the cards are listed by id from the start, so the listing does not shift while the cards are created or sold.
+	cursor := pagination.Cursor{Limit: service.config.Cursor.Limit, Token: pagination.EncodeToken(uuid.Nil.String())}
+	for {
+		page, err := service.cards.List(ctx, cursor)
+		if err != nil {
+			return nil, ErrCards.Wrap(err)
+		}
+
+		for _, card := range page.Cards {
+			if card.Status == StatusRetired {
+				continue
+			}
+
+			aged := service.ageCard(card)
+			event := Event{Cause: CauseSeason}
+			aging.Cards = append(aging.Cards, aged)
+			aging.History = append(aging.History, NewHistory(card, HistoryKindAge, strconv.Itoa(card.Age), strconv.Itoa(aged.Age), event))
+
+			// cards on sale retire after the lot is closed.
+			if aged.Age >= service.config.Aging.RetirementAge && card.Status == StatusActive {
+				aging.Retirements = append(aging.Retirements,
+					NewHistory(card, HistoryKindStatus, strconv.Itoa(int(card.Status)), strconv.Itoa(int(StatusRetired)), event))
+			}
+		}
+
+		if page.Page.NextToken == "" {
+			break
+		}
+		cursor.Token = page.Page.NextToken
+	}
+
+	retired, err := service.cards.ApplyAging(ctx, aging)
+	if ErrSeasonAged.Has(err) {
+		return nil, nil
+	}
+	return retired, ErrCards.Wrap(err)
+}
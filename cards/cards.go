@@ -5,6 +5,7 @@ package cards
 
 import (
 	"context"
+	"math/big"
 
 	"github.com/google/uuid"
 	"github.com/zeebo/errs"
@@ -15,19 +16,23 @@ import (
 // ErrNoCard indicated that card does not exist.
 var ErrNoCard = errs.Class("card does not exist")
 
+// ErrSeasonAged indicated that cards are already aged in the season.
+var ErrSeasonAged = errs.Class("cards are already aged in the season")
+
 // DB is exposing access to cards db.
 //
 // architecture: DB
 type DB interface {
-	// Create adds card in the data base.
-	Create(ctx context.Context, card Card) error
+	// Create adds card in the data base with the records of its history.
+	Create(ctx context.Context, card Card, history ...History) error
 	// Get returns card by id from the data base.
 	Get(ctx context.Context, id uuid.UUID) (Card, error)
 	// GetStatus returns card status by id from the data base.
 	GetStatus(ctx context.Context, id uuid.UUID) (int, error)
 	// GetByPlayerName returns card by player name from DB.
 	GetByPlayerName(ctx context.Context, playerName string) (Card, error)
-	// List returns all cards from the data base.
+	// List returns all cards from the data base, only the listing with the continuation token is ordered by id
+	// and returns the next token.
 	List(ctx context.Context, cursor pagination.Cursor) (Page, error)
 	// ListByUserID returns cards by user id from the database.
 	ListByUserID(ctx context.Context, id uuid.UUID, cursor pagination.Cursor) (Page, error)
@@ -35,6 +40,8 @@ type DB interface {
 	ListByTypeUnordered(ctx context.Context) ([]Card, error)
 	// ListWithFilters returns cards with filters from the database.
 	ListWithFilters(ctx context.Context, filters []Filters, cursor pagination.Cursor) (Page, error)
+	// ListWithQuery returns cards matching the query from the database.
+	ListWithQuery(ctx context.Context, query Query, cursor pagination.Cursor) (Page, error)
 	// ListCardIDsWithFiltersWhereActiveLot returns card ids where active lots from DB, taking the necessary filters.
 	ListCardIDsWithFiltersWhereActiveLot(ctx context.Context, filters []Filters) ([]uuid.UUID, error)
 	// ListByUserIDAndPlayerName returns cards from DB by user id and player name.
@@ -43,16 +50,35 @@ type DB interface {
 	ListCardIDsByPlayerNameWhereActiveLot(ctx context.Context, filter Filters) ([]uuid.UUID, error)
 	// GetSquadCards returns all card with characteristics from the squad from the database.
 	GetSquadCards(ctx context.Context, id uuid.UUID) ([]Card, error)
-	// UpdateStatus updates status card in the database.
-	UpdateStatus(ctx context.Context, id uuid.UUID, status Status) error
-	// UpdateMintedStatus updates minted status of card in database.
-	UpdateMintedStatus(ctx context.Context, id uuid.UUID, status int) error
-	// UpdateType updates type of card in the database.
-	UpdateType(ctx context.Context, id uuid.UUID, typeCard Type) error
-	// UpdateUserID updates user id card in the database.
-	UpdateUserID(ctx context.Context, cardID, userID uuid.UUID) error
+	// UpdateStatus updates status card in the database with the records of the card history.
+	UpdateStatus(ctx context.Context, id uuid.UUID, status Status, history ...History) error
+	// UpdateMintedStatus updates minted status of card in database with the records of the card history.
+	UpdateMintedStatus(ctx context.Context, id uuid.UUID, status int, history ...History) error
+	// UpdateType updates type of card in the database with the records of the card history.
+	UpdateType(ctx context.Context, id uuid.UUID, typeCard Type, history ...History) error
+	// UpdateAging updates age and physical skills of the card in the database with the records of the card history.
+	UpdateAging(ctx context.Context, card Card, history ...History) error
+	// ApplyAging applies aging of the cards at the end of the season in one transaction, returns ids of the retired cards.
+	// Returns ErrSeasonAged if the cards are already aged in the season.
+	ApplyAging(ctx context.Context, aging Aging) ([]uuid.UUID, error)
+	// UpdateUserID updates user id card in the database with the records of the card history.
+	UpdateUserID(ctx context.Context, cardID, userID uuid.UUID, history ...History) error
 	// Delete deletes card record in the data base.
 	Delete(ctx context.Context, id uuid.UUID) error
+	// CreateHistory adds record of the card history in the data base.
+	CreateHistory(ctx context.Context, history History) error
+	// GetScoutingReport returns scouting report of the card from the data base.
+	GetScoutingReport(ctx context.Context, cardID uuid.UUID) (ScoutingReport, error)
+	// ListScoutingReports returns scouting reports of the cards which were scouted from the data base.
+	ListScoutingReports(ctx context.Context, cardIDs []uuid.UUID) ([]ScoutingReport, error)
+	// UpsertScoutingReport adds or updates scouting report of the card in the data base with the records of the card history.
+	UpsertScoutingReport(ctx context.Context, report ScoutingReport, history ...History) error
+	// ListHistoryByCardID returns history of the card from the data base ordered by time.
+	ListHistoryByCardID(ctx context.Context, cardID uuid.UUID) ([]History, error)
+	// ListHistory returns history of all cards from the data base.
+	ListHistory(ctx context.Context, cursor pagination.Cursor) (HistoryPage, error)
+	// ListHistoryByUserID returns history of the cards where user was owner or counterparty from the data base.
+	ListHistoryByUserID(ctx context.Context, userID uuid.UUID, cursor pagination.Cursor) (HistoryPage, error)
 }
 
 // Card describes card entity.
@@ -117,6 +143,17 @@ type Card struct {
 	Sweeping         int          `json:"sweeping"`
 	Throwing         int          `json:"throwing"`
 	IsMinted         int          `json:"isMinted"`
+	Age              int          `json:"age"`
+	Potential        int          `json:"-"`
+	Nationality      string       `json:"nationality"`
+}
+
+// RatingSkillsCount defines the number of the main skills the card rating is calculated from.
+const RatingSkillsCount = 6
+
+// Rating returns overall rating of the card, that is the average of its main skills.
+func (card Card) Rating() float64 {
+	return float64(card.Tactics+card.Physique+card.Technique+card.Offence+card.Defence+card.Goalkeeping) / RatingSkillsCount
 }
 
 // Quality defines the list of possible card qualities.
@@ -164,6 +201,8 @@ const (
 	StatusActive Status = 0
 	// StatusSale indicates that the card is sold and can't used by the team.
 	StatusSale Status = 1
+	// StatusRetired indicates that the footballer is retired and the card can't be used by the team or sold.
+	StatusRetired Status = 2
 	// NotMinted indicates that the card is not minted yet.
 	NotMinted int = 0
 	// Minted indicates that the card already minted.
@@ -256,6 +295,25 @@ type Config struct {
 		Diamond int `json:"diamond"`
 	} `json:"tattoos"`
 
+	// Aging defines ages of the footballer and how its physical skills change each season.
+	Aging struct {
+		MinAge        int `json:"minAge"`
+		MaxAge        int `json:"maxAge"`
+		PeakAge       int `json:"peakAge"`
+		DeclineAge    int `json:"declineAge"`
+		RetirementAge int `json:"retirementAge"`
+		Growth        int `json:"growth"`
+		Decline       int `json:"decline"`
+	} `json:"aging"`
+
+	// Scouting defines price of the scouting and how the estimated range of the potential narrows.
+	Scouting struct {
+		Price              big.Int `json:"price"`
+		InitialUncertainty int     `json:"initialUncertainty"`
+		UncertaintyStep    int     `json:"uncertaintyStep"`
+		MinUncertainty     int     `json:"minUncertainty"`
+	} `json:"scouting"`
+
 	pagination.Cursor `json:"cursor"`
 
 	// CardEfficiencyParameters coefficients for calculating the efficiency of the card.
@@ -317,7 +375,8 @@ type Config struct {
 			Offence   float64 `json:"offence"`
 		} `json:"st"`
 	} `json:"cardEfficiencyParameters"`
-	PathToNamesDataset string `json:"pathToNamesDataset"`
+	PathToNamesDataset         string `json:"pathToNamesDataset"`
+	PathToNationalitiesDataset string `json:"pathToNationalitiesDataset"`
 }
 
 // PercentageQualities entity for probabilities generate cards.
@@ -18,9 +18,9 @@ var ErrInvalidFilter = errs.Class("invalid filter")
 
 // Filters entity for using filter cards.
 type Filters struct {
-	Name           Filter
-	Value          string
-	SearchOperator sqlsearchoperators.SearchOperator
+	Name           Filter                            `json:"name"`
+	Value          string                            `json:"value"`
+	SearchOperator sqlsearchoperators.SearchOperator `json:"operator"`
 }
 
 // Filter defines the list of possible filters.
@@ -139,6 +139,10 @@ const (
 	FilterPrice Filter = "price"
 	// FilterPlayerName indicates filtering by card player name.
 	FilterPlayerName Filter = "player_name"
+	// FilterRating indicates filtering by card rating, see Card.Rating.
+	FilterRating Filter = "rating"
+	// FilterUserID indicates filtering by card owner, it is used only internally and never comes from the request.
+	FilterUserID Filter = "user_id"
 )
 
 // SliceFilters entity for slice filters.
@@ -152,12 +156,14 @@ const (
 	LimitPagination Pagination = "limit"
 	// PagePagination indicates to the current output page for cards.
 	PagePagination Pagination = "page"
+	// TokenPagination indicates the continuation token of the keyset listing of cards.
+	TokenPagination Pagination = "token"
 )
 
 // DecodingURLParameters decodes url parameters to filters entity.
 func (filters *SliceFilters) DecodingURLParameters(urlQuery url.Values) error {
 	for key, value := range urlQuery {
-		if key == string(LimitPagination) || key == string(PagePagination) {
+		if key == string(LimitPagination) || key == string(PagePagination) || key == string(TokenPagination) || key == URLParameterQuery || key == URLParameterSort {
 			continue
 		}
 
@@ -191,80 +197,109 @@ func (filters *SliceFilters) DecodingURLParameters(urlQuery url.Values) error {
 	return nil
 }
 
+// Values returns values of the filter, IN and BETWEEN operators take comma separated values.
+func (f Filters) Values() []string {
+	if f.SearchOperator != sqlsearchoperators.IN && f.SearchOperator != sqlsearchoperators.BETWEEN {
+		return []string{f.Value}
+	}
+
+	values := strings.Split(f.Value, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
+
 // Validate check of valid UTF-8 bytes and type.
 func (f Filters) Validate() error {
-	if f.Name == FilterTactics || f.Name == FilterPositioning || f.Name == FilterComposure || f.Name == FilterAggression ||
-		f.Name == FilterVision || f.Name == FilterAwareness || f.Name == FilterCrosses || f.Name == FilterPhysique ||
-		f.Name == FilterAcceleration || f.Name == FilterRunningSpeed || f.Name == FilterReactionSpeed || f.Name == FilterAgility ||
-		f.Name == FilterStamina || f.Name == FilterStrength || f.Name == FilterJumping || f.Name == FilterBalance ||
-		f.Name == FilterTechnique || f.Name == FilterDribbling || f.Name == FilterBallControl || f.Name == FilterWeakFoot ||
-		f.Name == FilterSkillMoves || f.Name == FilterFinesse || f.Name == FilterCurve || f.Name == FilterVolleys ||
-		f.Name == FilterShortPassing || f.Name == FilterLongPassing || f.Name == FilterForwardPass || f.Name == FilterOffense ||
-		f.Name == FilterFinishingAbility || f.Name == FilterShotPower || f.Name == FilterAccuracy || f.Name == FilterDistance ||
-		f.Name == FilterPenalty || f.Name == FilterFreeKicks || f.Name == FilterCorners || f.Name == FilterHeadingAccuracy ||
-		f.Name == FilterDefence || f.Name == FilterOffsideTrap || f.Name == FilterSliding || f.Name == FilterTackles ||
-		f.Name == FilterBallFocus || f.Name == FilterInterceptions || f.Name == FilterVigilance || f.Name == FilterGoalkeeping ||
-		f.Name == FilterReflexes || f.Name == FilterDiving || f.Name == FilterHandling || f.Name == FilterSweeping || f.Name == FilterThrowing {
-		strings.ToValidUTF8(f.Value, "")
-
-		_, err := strconv.Atoi(f.Value)
+	if !f.SearchOperator.IsValid() || f.SearchOperator == sqlsearchoperators.LIKE {
+		return ErrInvalidFilter.New("'%s' not suitable for %s", f.SearchOperator, f.Name)
+	}
+
+	values := f.Values()
+	if f.SearchOperator == sqlsearchoperators.BETWEEN && len(values) != 2 {
+		return ErrInvalidFilter.New("%s requires exactly two values for %s", f.SearchOperator, f.Name)
+	}
+
+	for _, value := range values {
+		if err := f.validateValue(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isSkill returns true if filter is one of the numeric card skills.
+func (f Filter) isSkill() bool {
+	return f == FilterTactics || f == FilterPositioning || f == FilterComposure || f == FilterAggression ||
+		f == FilterVision || f == FilterAwareness || f == FilterCrosses || f == FilterPhysique ||
+		f == FilterAcceleration || f == FilterRunningSpeed || f == FilterReactionSpeed || f == FilterAgility ||
+		f == FilterStamina || f == FilterStrength || f == FilterJumping || f == FilterBalance ||
+		f == FilterTechnique || f == FilterDribbling || f == FilterBallControl || f == FilterWeakFoot ||
+		f == FilterSkillMoves || f == FilterFinesse || f == FilterCurve || f == FilterVolleys ||
+		f == FilterShortPassing || f == FilterLongPassing || f == FilterForwardPass || f == FilterOffense ||
+		f == FilterFinishingAbility || f == FilterShotPower || f == FilterAccuracy || f == FilterDistance ||
+		f == FilterPenalty || f == FilterFreeKicks || f == FilterCorners || f == FilterHeadingAccuracy ||
+		f == FilterDefence || f == FilterOffsideTrap || f == FilterSliding || f == FilterTackles ||
+		f == FilterBallFocus || f == FilterInterceptions || f == FilterVigilance || f == FilterGoalkeeping ||
+		f == FilterReflexes || f == FilterDiving || f == FilterHandling || f == FilterSweeping || f == FilterThrowing
+}
+
+// validateValue checks single value of the filter.
+func (f Filters) validateValue(value string) error {
+	if f.Name.isSkill() {
+		strings.ToValidUTF8(value, "")
+
+		_, err := strconv.Atoi(value)
 		if err != nil {
-			return ErrInvalidFilter.New("%s %s", f.Value, err)
+			return ErrInvalidFilter.New("%s %s", value, err)
 		}
 		return nil
 	}
 
-	if f.Name == FilterHeight || f.Name == FilterWeight || f.Name == FilterPrice {
-		strings.ToValidUTF8(f.Value, "")
+	if f.Name == FilterHeight || f.Name == FilterWeight || f.Name == FilterPrice || f.Name == FilterRating {
+		strings.ToValidUTF8(value, "")
 
-		_, err := strconv.ParseFloat(f.Value, 64)
+		_, err := strconv.ParseFloat(value, 64)
 		if err != nil {
-			return ErrInvalidFilter.New("%s %s", f.Value, err)
+			return ErrInvalidFilter.New("%s %s", value, err)
 		}
 		return nil
 	}
 
-	if f.Name == FilterQuality {
-		strings.ToValidUTF8(f.Value, "")
+	if f.Name != FilterQuality && f.Name != FilterDominantFoot && f.Name != FilterType {
+		return ErrInvalidFilter.New("invalid name parameter - %s", f.Name)
+	}
 
-		if f.SearchOperator != sqlsearchoperators.EQ {
-			return ErrInvalidFilter.New("'%s' not suitable for %s", f.SearchOperator, f.Name)
-		}
+	if f.SearchOperator != sqlsearchoperators.EQ && f.SearchOperator != sqlsearchoperators.IN {
+		return ErrInvalidFilter.New("'%s' not suitable for %s", f.SearchOperator, f.Name)
+	}
+
+	if f.Name == FilterQuality {
+		strings.ToValidUTF8(value, "")
 
-		quality := Quality(f.Value)
+		quality := Quality(value)
 		if quality == QualityWood || quality == QualitySilver || quality == QualityGold || quality == QualityDiamond {
 			return nil
 		}
-		return ErrInvalidFilter.New("%s %s", f.Value, "is not an indicator of quality card")
+		return ErrInvalidFilter.New("%s %s", value, "is not an indicator of quality card")
 	}
 
 	if f.Name == FilterDominantFoot {
-		strings.ToValidUTF8(f.Value, "")
+		strings.ToValidUTF8(value, "")
 
-		if f.SearchOperator != sqlsearchoperators.EQ {
-			return ErrInvalidFilter.New("'%s' not suitable for %s", f.SearchOperator, f.Name)
-		}
-
-		dominantFoot := DominantFoot(f.Value)
+		dominantFoot := DominantFoot(value)
 		if dominantFoot == DominantFootLeft || dominantFoot == DominantFootRight {
 			return nil
 		}
-		return ErrInvalidFilter.New("%s %s", f.Value, "is not an indicator of dominant foot card")
+		return ErrInvalidFilter.New("%s %s", value, "is not an indicator of dominant foot card")
 	}
 
-	if f.Name == FilterType {
-		strings.ToValidUTF8(f.Value, "")
-
-		if f.SearchOperator != sqlsearchoperators.EQ {
-			return ErrInvalidFilter.New("'%s' not suitable for %s", f.SearchOperator, f.Name)
-		}
+	strings.ToValidUTF8(value, "")
 
-		filterType := Type(f.Value)
-		if filterType == TypeWon || filterType == TypeBought {
-			return nil
-		}
-		return ErrInvalidFilter.New("%s %s", f.Value, "is not an indicator of type card")
+	filterType := Type(value)
+	if filterType == TypeWon || filterType == TypeBought {
+		return nil
 	}
-
-	return ErrInvalidFilter.New("invalid name parameter - %s", f.Name)
+	return ErrInvalidFilter.New("%s %s", value, "is not an indicator of type card")
 }
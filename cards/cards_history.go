@@ -0,0 +1,109 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package cards
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ultimatedivision/pkg/pagination"
+)
+
+// History describes a single record of the card provenance ledger.
+// Records are append-only and are never updated or deleted, even if the card itself is deleted.
+type History struct {
+	ID             uuid.UUID   `json:"id"`
+	CardID         uuid.UUID   `json:"cardId"`
+	Kind           HistoryKind `json:"kind"`
+	OldValue       string      `json:"oldValue"`
+	NewValue       string      `json:"newValue"`
+	Cause          Cause       `json:"cause"`
+	UserID         uuid.UUID   `json:"userId"`
+	CounterpartyID uuid.UUID   `json:"counterpartyId"`
+	Price          big.Int     `json:"price"`
+	CreatedAt      time.Time   `json:"createdAt"`
+}
+
+// NewHistory returns the record about the change of the card, the record belongs to the owner of the card after the change
+// or to the user of the event if the card is not owned by anyone yet.
+func NewHistory(card Card, kind HistoryKind, oldValue, newValue string, event Event) History {
+	userID := card.UserID
+	if userID == uuid.Nil {
+		userID = event.UserID
+	}
+
+	return History{
+		ID:             uuid.New(),
+		CardID:         card.ID,
+		Kind:           kind,
+		OldValue:       oldValue,
+		NewValue:       newValue,
+		Cause:          event.Cause,
+		UserID:         userID,
+		CounterpartyID: event.CounterpartyID,
+		Price:          event.Price,
+		CreatedAt:      time.Now().UTC(),
+	}
+}
+
+// HistoryKind defines the list of possible card changes which are written to the history.
+type HistoryKind string
+
+const (
+	// HistoryKindCreated indicates that the card was created.
+	HistoryKindCreated HistoryKind = "created"
+	// HistoryKindOwnership indicates that the owner of the card was changed.
+	HistoryKindOwnership HistoryKind = "ownership"
+	// HistoryKindStatus indicates that the status of the card was changed.
+	HistoryKindStatus HistoryKind = "status"
+	// HistoryKindType indicates that the type of the card was changed.
+	HistoryKindType HistoryKind = "type"
+	// HistoryKindMinted indicates that the minted status of the card was changed.
+	HistoryKindMinted HistoryKind = "minted"
+	// HistoryKindAge indicates that the age of the card was changed.
+	HistoryKindAge HistoryKind = "age"
+	// HistoryKindScouted indicates that the card was scouted and the estimated range of its potential was changed.
+	HistoryKindScouted HistoryKind = "scouted"
+)
+
+// Cause defines the list of possible reasons of the card change.
+type Cause string
+
+const (
+	// CauseAdmin indicates that the card was changed by admin.
+	CauseAdmin Cause = "admin"
+	// CauseLootBox indicates that the card was changed by opening of the lootbox.
+	CauseLootBox Cause = "lootbox"
+	// CauseStore indicates that the card was changed by the store.
+	CauseStore Cause = "store"
+	// CauseMarketplace indicates that the card was changed by the marketplace.
+	CauseMarketplace Cause = "marketplace"
+	// CauseMint indicates that the card was changed by minting of the nft.
+	CauseMint Cause = "mint"
+	// CauseSeason indicates that the card was changed by the end of the season.
+	CauseSeason Cause = "season"
+	// CauseYouthAcademy indicates that the card was changed by the youth academy.
+	CauseYouthAcademy Cause = "youthacademy"
+	// CauseScouting indicates that the card was changed by the scouting.
+	CauseScouting Cause = "scouting"
+	// CauseTrade indicates that the card was changed by the trade between users.
+	CauseTrade Cause = "trade"
+)
+
+// Event describes why the card was changed, with whom and for how much.
+// UserID is the user the change is made for, it is used while the card has no owner, e.g. when it is bought in the store.
+type Event struct {
+	Cause          Cause
+	UserID         uuid.UUID
+	CounterpartyID uuid.UUID
+	Price          big.Int
+}
+
+// HistoryPage holds history page entity which is used to show listed page of card history.
+type HistoryPage struct {
+	History []History       `json:"history"`
+	Page    pagination.Page `json:"page"`
+}
@@ -0,0 +1,212 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package cards
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+const (
+	// URLParameterQuery indicates url parameter which holds json encoded condition of the query.
+	URLParameterQuery = "query"
+	// URLParameterSort indicates url parameter which holds comma separated sort keys, e.g. "-rating,tactics".
+	URLParameterSort = "sort"
+
+	// MaxQueryDepth defines the max nesting of the condition groups.
+	MaxQueryDepth = 5
+	// MaxQueryConditions defines the max number of the conditions in the query.
+	MaxQueryConditions = 50
+	// MaxQuerySortKeys defines the max number of the sort keys in the query.
+	MaxQuerySortKeys = 5
+)
+
+// Query describes expressive cards query with nested AND/OR groups of filters and sorting.
+type Query struct {
+	Where *Condition `json:"where"`
+	Sort  []Sort     `json:"sort"`
+}
+
+// Condition is a node of the query, it is either a group of conditions combined by the logical operator or a single filter.
+type Condition struct {
+	Operator   LogicalOperator `json:"operator,omitempty"`
+	Conditions []Condition     `json:"conditions,omitempty"`
+	Filter     *Filters        `json:"filter,omitempty"`
+}
+
+// LogicalOperator defines the list of possible logical operators of the condition group.
+type LogicalOperator string
+
+const (
+	// LogicalOperatorAnd indicates that all conditions of the group must be satisfied.
+	LogicalOperatorAnd LogicalOperator = "and"
+	// LogicalOperatorOr indicates that at least one condition of the group must be satisfied.
+	LogicalOperatorOr LogicalOperator = "or"
+)
+
+// Sort describes sort key of the query.
+type Sort struct {
+	Name  Filter `json:"name"`
+	Order Order  `json:"order"`
+}
+
+// Order defines the list of possible sort orders.
+type Order string
+
+const (
+	// OrderAsc indicates ascending sort order.
+	OrderAsc Order = "asc"
+	// OrderDesc indicates descending sort order.
+	OrderDesc Order = "desc"
+)
+
+// IsEmpty returns true if query has neither conditions nor sort keys.
+func (query Query) IsEmpty() bool {
+	return query.Where == nil && len(query.Sort) == 0
+}
+
+// And returns query extended with condition which must be satisfied together with existing ones.
+func (query Query) And(condition Condition) Query {
+	if query.Where == nil {
+		query.Where = &condition
+		return query
+	}
+
+	query.Where = &Condition{
+		Operator:   LogicalOperatorAnd,
+		Conditions: []Condition{*query.Where, condition},
+	}
+	return query
+}
+
+// Validate checks that query is well-formed and uses only allowed filters, operators and sort keys.
+func (query Query) Validate() error {
+	if query.Where != nil {
+		count := 0
+		if err := query.Where.validate(1, &count); err != nil {
+			return err
+		}
+	}
+
+	if len(query.Sort) > MaxQuerySortKeys {
+		return ErrInvalidFilter.New("too many sort keys, max is %d", MaxQuerySortKeys)
+	}
+	for _, sort := range query.Sort {
+		if err := sort.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validate checks condition and its nested conditions.
+func (condition Condition) validate(depth int, count *int) error {
+	if depth > MaxQueryDepth {
+		return ErrInvalidFilter.New("conditions are nested too deep, max depth is %d", MaxQueryDepth)
+	}
+
+	*count++
+	if *count > MaxQueryConditions {
+		return ErrInvalidFilter.New("too many conditions, max is %d", MaxQueryConditions)
+	}
+
+	if condition.Filter != nil {
+		if len(condition.Conditions) > 0 {
+			return ErrInvalidFilter.New("condition could not have both filter and nested conditions")
+		}
+		return condition.Filter.Validate()
+	}
+
+	if condition.Operator != LogicalOperatorAnd && condition.Operator != LogicalOperatorOr {
+		return ErrInvalidFilter.New("invalid logical operator - %s", condition.Operator)
+	}
+	if len(condition.Conditions) == 0 {
+		return ErrInvalidFilter.New("group of conditions could not be empty")
+	}
+
+	for _, nested := range condition.Conditions {
+		if err := nested.validate(depth+1, count); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that cards could be sorted by sort key.
+func (sort Sort) Validate() error {
+	if sort.Order != OrderAsc && sort.Order != OrderDesc {
+		return ErrInvalidFilter.New("invalid sort order - %s", sort.Order)
+	}
+
+	if sort.Name.isSkill() || sort.Name == FilterQuality || sort.Name == FilterHeight || sort.Name == FilterWeight ||
+		sort.Name == FilterPrice || sort.Name == FilterRating || sort.Name == FilterPlayerName {
+		return nil
+	}
+
+	return ErrInvalidFilter.New("invalid sort parameter - %s", sort.Name)
+}
+
+// NewQueryFromFilters converts plain list of filters to the query, several qualities are combined with OR.
+func NewQueryFromFilters(filters []Filters) Query {
+	var (
+		qualities []Condition
+		where     []Condition
+	)
+
+	for i := range filters {
+		condition := Condition{Filter: &filters[i]}
+		if filters[i].Name == FilterQuality {
+			qualities = append(qualities, condition)
+			continue
+		}
+		where = append(where, condition)
+	}
+
+	switch len(qualities) {
+	case 0:
+	case 1:
+		where = append(where, qualities[0])
+	default:
+		where = append(where, Condition{Operator: LogicalOperatorOr, Conditions: qualities})
+	}
+
+	if len(where) == 0 {
+		return Query{}
+	}
+
+	return Query{Where: &Condition{Operator: LogicalOperatorAnd, Conditions: where}}
+}
+
+// DecodingURLParameters decodes url parameters to the query.
+// Plain filters are combined with condition from the query parameter by AND.
+func (query *Query) DecodingURLParameters(urlQuery url.Values) error {
+	var filters SliceFilters
+	if err := filters.DecodingURLParameters(urlQuery); err != nil {
+		return err
+	}
+	*query = NewQueryFromFilters(filters)
+
+	if value := urlQuery.Get(URLParameterQuery); value != "" {
+		var condition Condition
+		if err := json.Unmarshal([]byte(value), &condition); err != nil {
+			return ErrInvalidFilter.New("invalid query parameter - %s", err)
+		}
+		*query = query.And(condition)
+	}
+
+	if value := urlQuery.Get(URLParameterSort); value != "" {
+		for _, key := range strings.Split(value, ",") {
+			sort := Sort{Name: Filter(strings.TrimSpace(key)), Order: OrderAsc}
+			if strings.HasPrefix(string(sort.Name), "-") {
+				sort = Sort{Name: sort.Name[1:], Order: OrderDesc}
+			}
+			query.Sort = append(query.Sort, sort)
+		}
+	}
+
+	return nil
+}
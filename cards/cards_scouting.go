@@ -0,0 +1,24 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package cards
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+)
+
+// ErrNoScoutingReport indicated that scouting report of the card does not exist.
+var ErrNoScoutingReport = errs.Class("scouting report does not exist")
+
+// ScoutingReport describes estimated range of the hidden card potential.
+// The range always contains the real potential and narrows each time the card is scouted.
+type ScoutingReport struct {
+	CardID       uuid.UUID `json:"cardId"`
+	MinPotential int       `json:"minPotential"`
+	MaxPotential int       `json:"maxPotential"`
+	ScoutsCount  int       `json:"scoutsCount"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
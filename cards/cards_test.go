@@ -5,6 +5,8 @@ package cards_test
 
 import (
 	"context"
+	"math/big"
+	"net/url"
 	"strconv"
 	"strings"
 	"testing"
@@ -111,6 +113,9 @@ func TestCards(t *testing.T) {
 		Sweeping:         48,
 		Throwing:         49,
 		IsMinted:         0,
+		Age:              24,
+		Potential:        70,
+		Nationality:      "Spain",
 	}
 
 	card2 := cards.Card{
@@ -174,6 +179,9 @@ func TestCards(t *testing.T) {
 		Sweeping:         48,
 		Throwing:         49,
 		IsMinted:         0,
+		Age:              24,
+		Potential:        70,
+		Nationality:      "Brazil",
 	}
 
 	division1 := divisions.Division{
@@ -241,6 +249,29 @@ func TestCards(t *testing.T) {
 		Page:  1,
 	}
 
+	history1 := cards.History{
+		ID:        uuid.New(),
+		CardID:    card1.ID,
+		Kind:      cards.HistoryKindCreated,
+		NewValue:  string(cards.TypeWon),
+		Cause:     cards.CauseLootBox,
+		UserID:    user1.ID,
+		CreatedAt: time.Now().UTC().Add(-time.Hour),
+	}
+
+	history2 := cards.History{
+		ID:             uuid.New(),
+		CardID:         card1.ID,
+		Kind:           cards.HistoryKindOwnership,
+		OldValue:       user1.ID.String(),
+		NewValue:       user2.ID.String(),
+		Cause:          cards.CauseMarketplace,
+		UserID:         user2.ID,
+		CounterpartyID: user1.ID,
+		Price:          *big.NewInt(100),
+		CreatedAt:      time.Now().UTC(),
+	}
+
 	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db ultimatedivision.DB) {
 		repositoryCards := db.Cards()
 		repositoryUsers := db.Users()
@@ -318,6 +349,50 @@ func TestCards(t *testing.T) {
 			compareCards(t, card1, allCards.Cards[0])
 		})
 
+		t.Run("list with query", func(t *testing.T) {
+			query := cards.Query{
+				Where: &cards.Condition{
+					Operator: cards.LogicalOperatorOr,
+					Conditions: []cards.Condition{
+						{Filter: &cards.Filters{Name: cards.FilterTactics, Value: "1", SearchOperator: sqlsearchoperators.EQ}},
+						{Filter: &cards.Filters{Name: cards.FilterQuality, Value: "gold,diamond", SearchOperator: sqlsearchoperators.IN}},
+					},
+				},
+				Sort: []cards.Sort{{Name: cards.FilterTactics, Order: cards.OrderDesc}},
+			}
+			require.NoError(t, query.Validate())
+
+			allCards, err := repositoryCards.ListWithQuery(ctx, query, cursor1)
+			require.NoError(t, err)
+			require.Equal(t, 2, len(allCards.Cards))
+			assert.Equal(t, 2, allCards.Page.TotalCount)
+			compareCards(t, card2, allCards.Cards[0])
+			compareCards(t, card1, allCards.Cards[1])
+		})
+
+		t.Run("list with query and continuation token", func(t *testing.T) {
+			query := cards.Query{
+				Sort: []cards.Sort{{Name: cards.FilterTactics, Order: cards.OrderDesc}, {Name: cards.FilterPrice, Order: cards.OrderAsc}},
+			}
+			require.NoError(t, query.Validate())
+
+			firstPage, err := repositoryCards.ListWithQuery(ctx, query, pagination.Cursor{Limit: 1, Page: 1})
+			require.NoError(t, err)
+			require.Equal(t, 1, len(firstPage.Cards))
+			compareCards(t, card2, firstPage.Cards[0])
+			require.NotEmpty(t, firstPage.Page.NextToken)
+
+			secondPage, err := repositoryCards.ListWithQuery(ctx, query, pagination.Cursor{Limit: 1, Token: firstPage.Page.NextToken})
+			require.NoError(t, err)
+			require.Equal(t, 1, len(secondPage.Cards))
+			compareCards(t, card1, secondPage.Cards[0])
+			assert.Empty(t, secondPage.Page.NextToken)
+
+			_, err = repositoryCards.ListWithQuery(ctx, query, pagination.Cursor{Limit: 1, Token: pagination.EncodeToken("1")})
+			require.Error(t, err)
+			assert.True(t, pagination.ErrInvalidToken.Has(err))
+		})
+
 		t.Run("list by player name", func(t *testing.T) {
 			strings.ToValidUTF8(filter3.Value, "")
 
@@ -347,6 +422,45 @@ func TestCards(t *testing.T) {
 			assert.Equal(t, values, []string{"gold", "wood", "1", "won"})
 		})
 
+		t.Run("build cards query", func(t *testing.T) {
+			query := cards.Query{
+				Where: &cards.Condition{
+					Operator: cards.LogicalOperatorAnd,
+					Conditions: []cards.Condition{
+						{Filter: &cards.Filters{Name: cards.FilterTactics, Value: "10,20", SearchOperator: sqlsearchoperators.BETWEEN}},
+						{
+							Operator: cards.LogicalOperatorOr,
+							Conditions: []cards.Condition{
+								{Filter: &cards.Filters{Name: cards.FilterQuality, Value: "gold, diamond", SearchOperator: sqlsearchoperators.IN}},
+								{Filter: &cards.Filters{Name: cards.FilterRating, Value: "50", SearchOperator: sqlsearchoperators.GTE}},
+							},
+						},
+					},
+				},
+				Sort: []cards.Sort{{Name: cards.FilterRating, Order: cards.OrderDesc}, {Name: cards.FilterWeight, Order: cards.OrderAsc}},
+			}
+			require.NoError(t, query.Validate())
+
+			whereClause, orderByClause, values, err := database.BuildCardsQuery(query, 2)
+			require.NoError(t, err)
+
+			rating := "((cards.tactics + cards.physique + cards.technique + cards.offense + cards.defence + cards.goalkeeping) / 6.0)"
+			assert.Equal(t, `(cards.tactics BETWEEN $2 AND $3 AND (cards.quality IN ($4, $5) OR `+rating+` >= $6))`, whereClause)
+			assert.Equal(t, ` ORDER BY `+rating+` DESC, cards.weight ASC, cards.id`, orderByClause)
+			assert.Equal(t, []interface{}{"10", "20", "gold", "diamond", "50"}, values)
+		})
+
+		t.Run("build cards query with invalid column", func(t *testing.T) {
+			query := cards.Query{
+				Where: &cards.Condition{Filter: &cards.Filters{Name: "id; DROP TABLE cards", Value: "1", SearchOperator: sqlsearchoperators.EQ}},
+			}
+			require.Error(t, query.Validate())
+
+			_, _, _, err := database.BuildCardsQuery(query, 1)
+			require.Error(t, err)
+			assert.True(t, cards.ErrInvalidFilter.Has(err))
+		})
+
 		t.Run("build where string for player name", func(t *testing.T) {
 
 			strings.ToValidUTF8(filter3.Value, "")
@@ -398,6 +512,49 @@ func TestCards(t *testing.T) {
 			compareCards(t, card2, allCards.Cards[0])
 		})
 
+		t.Run("update aging sql no rows", func(t *testing.T) {
+			err := repositoryCards.UpdateAging(ctx, cards.Card{ID: uuid.New()})
+			require.Error(t, err)
+			require.Equal(t, cards.ErrNoCard.Has(err), true)
+		})
+
+		t.Run("update aging", func(t *testing.T) {
+			card1.Age++
+			card1.Physique--
+			card1.Acceleration--
+			card1.Balance--
+			err := repositoryCards.UpdateAging(ctx, card1)
+			require.NoError(t, err)
+
+			card, err := repositoryCards.Get(ctx, card1.ID)
+			require.NoError(t, err)
+			compareCards(t, card1, card)
+		})
+
+		t.Run("apply aging once in the season", func(t *testing.T) {
+			aged := card1
+			aged.Age++
+			aged.Physique--
+			aging := cards.Aging{
+				SeasonID: 1,
+				Cards:    []cards.Card{aged},
+				History:  []cards.History{cards.NewHistory(card1, cards.HistoryKindAge, "", "", cards.Event{Cause: cards.CauseSeason})},
+			}
+
+			retired, err := repositoryCards.ApplyAging(ctx, aging)
+			require.NoError(t, err)
+			assert.Empty(t, retired)
+
+			_, err = repositoryCards.ApplyAging(ctx, aging)
+			require.Error(t, err)
+			assert.True(t, cards.ErrSeasonAged.Has(err))
+
+			card, err := repositoryCards.Get(ctx, card1.ID)
+			require.NoError(t, err)
+			compareCards(t, aged, card)
+			card1 = aged
+		})
+
 		t.Run("UpdateType", func(t *testing.T) {
 			card1.Type = cards.TypeOrdered
 			err := repositoryCards.UpdateType(ctx, card1.ID, card1.Type)
@@ -444,6 +601,96 @@ func TestCards(t *testing.T) {
 			compareCards(t, card[0], card1)
 		})
 
+		t.Run("create history", func(t *testing.T) {
+			err := repositoryCards.CreateHistory(ctx, history1)
+			require.NoError(t, err)
+
+			err = repositoryCards.CreateHistory(ctx, history2)
+			require.NoError(t, err)
+		})
+
+		t.Run("list history by card id", func(t *testing.T) {
+			history, err := repositoryCards.ListHistoryByCardID(ctx, card1.ID)
+			require.NoError(t, err)
+			require.Equal(t, 2, len(history))
+			compareHistory(t, history1, history[0])
+			compareHistory(t, history2, history[1])
+		})
+
+		t.Run("list history", func(t *testing.T) {
+			historyPage, err := repositoryCards.ListHistory(ctx, cursor1)
+			require.NoError(t, err)
+			require.Equal(t, 2, len(historyPage.History))
+			assert.Equal(t, 2, historyPage.Page.TotalCount)
+			compareHistory(t, history2, historyPage.History[0])
+		})
+
+		t.Run("list history by user id", func(t *testing.T) {
+			historyPage, err := repositoryCards.ListHistoryByUserID(ctx, user1.ID, cursor1)
+			require.NoError(t, err)
+			require.Equal(t, 2, len(historyPage.History))
+
+			historyPage, err = repositoryCards.ListHistoryByUserID(ctx, uuid.New(), cursor1)
+			require.NoError(t, err)
+			assert.Equal(t, 0, len(historyPage.History))
+		})
+
+		t.Run("update with history", func(t *testing.T) {
+			history := cards.NewHistory(card1, cards.HistoryKindStatus, "", "", cards.Event{Cause: cards.CauseAdmin})
+			err := repositoryCards.UpdateStatus(ctx, uuid.New(), cards.StatusActive, history)
+			require.Error(t, err)
+			require.True(t, cards.ErrNoCard.Has(err))
+
+			// the record is not written if the card is not changed.
+			cardHistory, err := repositoryCards.ListHistoryByCardID(ctx, card1.ID)
+			require.NoError(t, err)
+			require.Equal(t, 2, len(cardHistory))
+
+			require.NoError(t, repositoryCards.UpdateStatus(ctx, card1.ID, card1.Status, history))
+			cardHistory, err = repositoryCards.ListHistoryByCardID(ctx, card1.ID)
+			require.NoError(t, err)
+			require.Equal(t, 3, len(cardHistory))
+			compareHistory(t, history, cardHistory[2])
+		})
+
+		t.Run("get scouting report sql no rows", func(t *testing.T) {
+			_, err := repositoryCards.GetScoutingReport(ctx, card1.ID)
+			require.Error(t, err)
+			require.Equal(t, cards.ErrNoScoutingReport.Has(err), true)
+		})
+
+		t.Run("upsert scouting report", func(t *testing.T) {
+			report := cards.ScoutingReport{
+				CardID:       card1.ID,
+				MinPotential: 55,
+				MaxPotential: 95,
+				ScoutsCount:  1,
+				UpdatedAt:    time.Now().UTC(),
+			}
+			err := repositoryCards.UpsertScoutingReport(ctx, report)
+			require.NoError(t, err)
+
+			report.MinPotential, report.MaxPotential, report.ScoutsCount = 65, 80, 2
+			err = repositoryCards.UpsertScoutingReport(ctx, report)
+			require.NoError(t, err)
+
+			reportFromDB, err := repositoryCards.GetScoutingReport(ctx, card1.ID)
+			require.NoError(t, err)
+			assert.Equal(t, report.CardID, reportFromDB.CardID)
+			assert.Equal(t, report.MinPotential, reportFromDB.MinPotential)
+			assert.Equal(t, report.MaxPotential, reportFromDB.MaxPotential)
+			assert.Equal(t, report.ScoutsCount, reportFromDB.ScoutsCount)
+			assert.WithinDuration(t, report.UpdatedAt, reportFromDB.UpdatedAt, time.Second)
+		})
+
+		t.Run("list scouting reports", func(t *testing.T) {
+			reports, err := repositoryCards.ListScoutingReports(ctx, []uuid.UUID{card1.ID, card2.ID})
+			require.NoError(t, err)
+			require.Equal(t, 1, len(reports))
+			assert.Equal(t, card1.ID, reports[0].CardID)
+			assert.Equal(t, 2, reports[0].ScoutsCount)
+		})
+
 		t.Run("delete sql no rows", func(t *testing.T) {
 			err := repositoryCards.Delete(ctx, uuid.New())
 			require.Error(t, err)
@@ -516,4 +763,115 @@ func compareCards(t *testing.T, expected, actual cards.Card) {
 	assert.Equal(t, expected.Sweeping, actual.Sweeping)
 	assert.Equal(t, expected.Throwing, actual.Throwing)
 	assert.Equal(t, expected.IsMinted, actual.IsMinted)
+	assert.Equal(t, expected.Age, actual.Age)
+	assert.Equal(t, expected.Potential, actual.Potential)
+	assert.Equal(t, expected.Nationality, actual.Nationality)
+}
+
+func compareHistory(t *testing.T, expected, actual cards.History) {
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.CardID, actual.CardID)
+	assert.Equal(t, expected.Kind, actual.Kind)
+	assert.Equal(t, expected.OldValue, actual.OldValue)
+	assert.Equal(t, expected.NewValue, actual.NewValue)
+	assert.Equal(t, expected.Cause, actual.Cause)
+	assert.Equal(t, expected.UserID, actual.UserID)
+	assert.Equal(t, expected.CounterpartyID, actual.CounterpartyID)
+	assert.Equal(t, expected.Price.String(), actual.Price.String())
+	assert.WithinDuration(t, expected.CreatedAt, actual.CreatedAt, time.Second)
+}
+
+func TestNewHistory(t *testing.T) {
+	buyerID := uuid.New()
+	event := cards.Event{Cause: cards.CauseStore, UserID: buyerID}
+
+	history := cards.NewHistory(cards.Card{ID: uuid.New()}, cards.HistoryKindType, "", "", event)
+	assert.Equal(t, buyerID, history.UserID)
+
+	ownerID := uuid.New()
+	history = cards.NewHistory(cards.Card{ID: uuid.New(), UserID: ownerID}, cards.HistoryKindType, "", "", event)
+	assert.Equal(t, ownerID, history.UserID)
+}
+
+func TestCardsKeyset(t *testing.T) {
+	user := users.User{
+		ID:           uuid.New(),
+		Email:        "keyset@example.com",
+		PasswordHash: []byte{0},
+		NickName:     "keyset",
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	// the cards are created out of the order of their ids, so the keyset listing does not depend on the order of creation.
+	ordered := []cards.Card{
+		{ID: uuid.MustParse("00000000-0000-4000-8000-000000000001"), PlayerName: "First"},
+		{ID: uuid.MustParse("00000000-0000-4000-8000-000000000002"), PlayerName: "Second"},
+		{ID: uuid.MustParse("00000000-0000-4000-8000-000000000003"), PlayerName: "Third"},
+	}
+	for i := range ordered {
+		ordered[i].Quality = cards.QualityWood
+		ordered[i].DominantFoot = "left"
+		ordered[i].Status = cards.StatusActive
+		ordered[i].Type = cards.TypeWon
+		ordered[i].UserID = user.ID
+	}
+
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db ultimatedivision.DB) {
+		repositoryCards := db.Cards()
+		require.NoError(t, db.Users().Create(ctx, user))
+		for _, i := range []int{2, 0, 1} {
+			require.NoError(t, repositoryCards.Create(ctx, ordered[i]))
+		}
+
+		t.Run("list by user id with continuation token", func(t *testing.T) {
+			firstPage, err := repositoryCards.ListByUserID(ctx, user.ID, pagination.Cursor{Limit: 2, Page: 1})
+			require.NoError(t, err)
+			require.Equal(t, 2, len(firstPage.Cards))
+			compareCards(t, ordered[0], firstPage.Cards[0])
+			compareCards(t, ordered[1], firstPage.Cards[1])
+			require.NotEmpty(t, firstPage.Page.NextToken)
+
+			secondPage, err := repositoryCards.ListByUserID(ctx, user.ID, pagination.Cursor{Limit: 2, Token: firstPage.Page.NextToken})
+			require.NoError(t, err)
+			require.Equal(t, 1, len(secondPage.Cards))
+			compareCards(t, ordered[2], secondPage.Cards[0])
+			assert.Empty(t, secondPage.Page.NextToken)
+		})
+
+		t.Run("list from the start", func(t *testing.T) {
+			firstPage, err := repositoryCards.List(ctx, pagination.Cursor{Limit: 2, Token: pagination.EncodeToken(uuid.Nil.String())})
+			require.NoError(t, err)
+			require.Equal(t, 2, len(firstPage.Cards))
+			compareCards(t, ordered[0], firstPage.Cards[0])
+			compareCards(t, ordered[1], firstPage.Cards[1])
+			require.NotEmpty(t, firstPage.Page.NextToken)
+
+			secondPage, err := repositoryCards.List(ctx, pagination.Cursor{Limit: 2, Token: firstPage.Page.NextToken})
+			require.NoError(t, err)
+			require.Equal(t, 1, len(secondPage.Cards))
+			compareCards(t, ordered[2], secondPage.Cards[0])
+			assert.Empty(t, secondPage.Page.NextToken)
+		})
+
+		t.Run("invalid token", func(t *testing.T) {
+			_, err := repositoryCards.List(ctx, pagination.Cursor{Limit: 2, Token: "invalid"})
+			require.Error(t, err)
+			assert.True(t, pagination.ErrInvalidToken.Has(err))
+		})
+	})
+}
+
+func TestDecodingURLParameters(t *testing.T) {
+	urlQuery := url.Values{
+		"limit":      {"10"},
+		"token":      {pagination.EncodeToken(uuid.New().String())},
+		"tactics_gt": {"50"},
+	}
+
+	var filters cards.SliceFilters
+	require.NoError(t, filters.DecodingURLParameters(urlQuery))
+	require.Len(t, filters, 1)
+	assert.Equal(t, cards.FilterTactics, filters[0].Name)
+	assert.Equal(t, sqlsearchoperators.GT, filters[0].SearchOperator)
+	assert.Equal(t, "50", filters[0].Value)
 }
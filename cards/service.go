@@ -5,6 +5,7 @@ package cards
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"math"
 	"math/rand"
@@ -40,8 +41,8 @@ func NewService(cards DB, config Config) *Service {
 	}
 }
 
-// Create adds card in DB.
-func (service *Service) Create(ctx context.Context, userID uuid.UUID, percentageQualities []int, cardType Type) (Card, error) {
+// Create adds card in DB and writes its creation to the card history.
+func (service *Service) Create(ctx context.Context, userID uuid.UUID, percentageQualities []int, cardType Type, event Event) (Card, error) {
 	var (
 		err  error
 		card Card
@@ -50,7 +51,26 @@ func (service *Service) Create(ctx context.Context, userID uuid.UUID, percentage
 	if card, err = service.Generate(ctx, userID, percentageQualities, cardType); err != nil {
 		return card, ErrCards.Wrap(err)
 	}
-	return card, ErrCards.Wrap(service.cards.Create(ctx, card))
+	history := NewHistory(card, HistoryKindCreated, "", string(card.Type), event)
+	return card, ErrCards.Wrap(service.cards.Create(ctx, card, history))
+}
+
+// CreateYouth adds young card with hidden potential in DB and writes its creation to the card history.
+// Potential of the card is higher than its rating by potentialGrowth, so the card grows while it is younger than the peak age.
+func (service *Service) CreateYouth(ctx context.Context, userID uuid.UUID, percentageQualities []int, age, potentialGrowth int) (Card, error) {
+	card, err := service.Generate(ctx, userID, percentageQualities, TypeWon)
+	if err != nil {
+		return card, ErrCards.Wrap(err)
+	}
+
+	card.Age = age
+	card.Potential += potentialGrowth
+	if card.Potential > MaxSkill {
+		card.Potential = MaxSkill
+	}
+
+	history := NewHistory(card, HistoryKindCreated, "", string(card.Type), Event{Cause: CauseYouthAcademy})
+	return card, ErrCards.Wrap(service.cards.Create(ctx, card, history))
 }
 
 // Generate generates card.
@@ -153,9 +173,15 @@ func (service *Service) Generate(ctx context.Context, userID uuid.UUID, percenta
 		}
 	}
 
+	nationality, err := service.GenerateNationality()
+	if err != nil {
+		return Card{}, ErrCards.Wrap(err)
+	}
+
 	card := Card{
 		ID:               uuid.New(),
 		PlayerName:       playerName,
+		Nationality:      nationality,
 		Quality:          Quality(quality),
 		Height:           round(rand.Float64()*(maxHeight-minHeight)+minHeight, 0.01),
 		Weight:           round(rand.Float64()*(maxWeight-minWeight)+minWeight, 0.01),
@@ -214,7 +240,9 @@ func (service *Service) Generate(ctx context.Context, userID uuid.UUID, percenta
 		Sweeping:         generateSkill(goalkeeping),
 		Throwing:         generateSkill(goalkeeping),
 		IsMinted:         NotMinted,
+		Age:              rand.Intn(service.config.Aging.MaxAge-service.config.Aging.MinAge+1) + service.config.Aging.MinAge,
 	}
+	card.Potential = int(math.Ceil(card.Rating()))
 
 	return card, nil
 }
@@ -313,6 +341,32 @@ func (service *Service) GeneratePlayerName() (string, error) {
 	return fullName, ErrCards.Wrap(err)
 }
 
+// GenerateNationality generates nationality of card.
+func (service *Service) GenerateNationality() (_ string, err error) {
+	file, err := os.Open(service.config.PathToNationalitiesDataset)
+	if err != nil {
+		return "", ErrCards.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, file.Close())
+	}()
+
+	totalCount, err := fileutils.CountLines(file)
+	if err != nil {
+		return "", ErrCards.Wrap(err)
+	}
+	if totalCount == 0 {
+		return "", ErrCards.New("nationalities dataset is empty")
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return "", ErrCards.Wrap(err)
+	}
+
+	nationality, err := fileutils.ReadLine(file, rand.Intn(totalCount)+1)
+	return nationality, ErrCards.Wrap(err)
+}
+
 // Get returns card from DB.
 func (service *Service) Get(ctx context.Context, cardID uuid.UUID) (Card, error) {
 	card, err := service.cards.Get(ctx, cardID)
@@ -352,21 +406,24 @@ func (service *Service) ListByTypeNoOrdered(ctx context.Context) ([]Card, error)
 
 // ListWithFilters returns all cards from DB, taking the necessary filters.
 func (service *Service) ListWithFilters(ctx context.Context, userID uuid.UUID, filters []Filters, cursor pagination.Cursor) (Page, error) {
+	return service.ListWithQuery(ctx, userID, NewQueryFromFilters(filters), cursor)
+}
+
+// ListWithQuery returns user`s cards from DB matching the query.
+func (service *Service) ListWithQuery(ctx context.Context, userID uuid.UUID, query Query, cursor pagination.Cursor) (Page, error) {
 	var cardsListPage Page
 
-	for _, v := range filters {
-		err := v.Validate()
-		if err != nil {
-			return cardsListPage, err
-		}
+	if err := query.Validate(); err != nil {
+		return cardsListPage, err
 	}
 
-	filter := Filters{
-		Name:           "user_id",
-		Value:          userID.String(),
-		SearchOperator: sqlsearchoperators.EQ,
-	}
-	filters = append(filters, filter)
+	query = query.And(Condition{
+		Filter: &Filters{
+			Name:           FilterUserID,
+			Value:          userID.String(),
+			SearchOperator: sqlsearchoperators.EQ,
+		},
+	})
 
 	if cursor.Limit <= 0 {
 		cursor.Limit = service.config.Cursor.Limit
@@ -375,7 +432,7 @@ func (service *Service) ListWithFilters(ctx context.Context, userID uuid.UUID, f
 		cursor.Page = service.config.Cursor.Page
 	}
 
-	cardsListPage, err := service.cards.ListWithFilters(ctx, filters, cursor)
+	cardsListPage, err := service.cards.ListWithQuery(ctx, query, cursor)
 	return cardsListPage, ErrCards.Wrap(err)
 }
 
@@ -445,24 +502,140 @@ func (service *Service) GetCardsFromSquadCards(ctx context.Context, id uuid.UUID
 	return cards, ErrCards.Wrap(err)
 }
 
-// UpdateStatus updates status of card in database.
-func (service *Service) UpdateStatus(ctx context.Context, id uuid.UUID, status Status) error {
-	return ErrCards.Wrap(service.cards.UpdateStatus(ctx, id, status))
+// UpdateStatus updates status of card in database and writes the change to the card history.
+func (service *Service) UpdateStatus(ctx context.Context, id uuid.UUID, status Status, event Event) error {
+	card, err := service.cards.Get(ctx, id)
+	if err != nil {
+		return ErrCards.Wrap(err)
+	}
+
+	history := NewHistory(card, HistoryKindStatus, strconv.Itoa(int(card.Status)), strconv.Itoa(int(status)), event)
+	return ErrCards.Wrap(service.cards.UpdateStatus(ctx, id, status, history))
+}
+
+// UpdateMintedStatus updates minted status of card in database and writes the change to the card history.
+func (service *Service) UpdateMintedStatus(ctx context.Context, id uuid.UUID, status int, event Event) error {
+	card, err := service.cards.Get(ctx, id)
+	if err != nil {
+		return ErrCards.Wrap(err)
+	}
+
+	history := NewHistory(card, HistoryKindMinted, strconv.Itoa(card.IsMinted), strconv.Itoa(status), event)
+	return ErrCards.Wrap(service.cards.UpdateMintedStatus(ctx, id, status, history))
+}
+
+// UpdateType updates type of card in the database and writes the change to the card history.
+func (service *Service) UpdateType(ctx context.Context, id uuid.UUID, typeCard Type, event Event) error {
+	card, err := service.cards.Get(ctx, id)
+	if err != nil {
+		return ErrCards.Wrap(err)
+	}
+
+	history := NewHistory(card, HistoryKindType, string(card.Type), string(typeCard), event)
+	return ErrCards.Wrap(service.cards.UpdateType(ctx, id, typeCard, history))
+}
+
+// UpdateUserID updates user's id for card in database and writes the change of ownership to the card history.
+func (service *Service) UpdateUserID(ctx context.Context, cardID uuid.UUID, userID uuid.UUID, event Event) error {
+	card, err := service.cards.Get(ctx, cardID)
+	if err != nil {
+		return ErrCards.Wrap(err)
+	}
+
+	previousUserID := card.UserID
+	card.UserID = userID
+	history := NewHistory(card, HistoryKindOwnership, previousUserID.String(), userID.String(), event)
+	return ErrCards.Wrap(service.cards.UpdateUserID(ctx, cardID, userID, history))
+}
+
+// NextScoutingReport returns the next scouting report of the card, which narrows estimated range of its potential,
+// and the record of the card history with the price of the scouting. Nothing is saved, the report is saved
+// together with the payment for the scouting.
+func (service *Service) NextScoutingReport(ctx context.Context, cardID, userID uuid.UUID) (ScoutingReport, History, error) {
+	card, err := service.cards.Get(ctx, cardID)
+	if err != nil {
+		return ScoutingReport{}, History{}, ErrCards.Wrap(err)
+	}
+
+	var oldValue string
+	report, err := service.cards.GetScoutingReport(ctx, cardID)
+	switch {
+	case ErrNoScoutingReport.Has(err):
+		report = ScoutingReport{CardID: cardID, MinPotential: 1, MaxPotential: MaxSkill}
+	case err != nil:
+		return report, History{}, ErrCards.Wrap(err)
+	default:
+		oldValue = fmt.Sprintf("%d-%d", report.MinPotential, report.MaxPotential)
+	}
+
+	report.ScoutsCount++
+	uncertainty := service.config.Scouting.InitialUncertainty - service.config.Scouting.UncertaintyStep*(report.ScoutsCount-1)
+	if uncertainty < service.config.Scouting.MinUncertainty {
+		uncertainty = service.config.Scouting.MinUncertainty
+	}
+
+	// the range is shifted randomly, so the potential is not always in its center.
+	minPotential := card.Potential - rand.Intn(2*uncertainty+1)
+	maxPotential := minPotential + 2*uncertainty
+	if minPotential > report.MinPotential {
+		report.MinPotential = minPotential
+	}
+	if maxPotential < report.MaxPotential {
+		report.MaxPotential = maxPotential
+	}
+	report.UpdatedAt = time.Now().UTC()
+
+	event := Event{
+		Cause:          CauseScouting,
+		CounterpartyID: userID,
+		Price:          service.config.Scouting.Price,
+	}
+	newValue := fmt.Sprintf("%d-%d", report.MinPotential, report.MaxPotential)
+	return report, NewHistory(card, HistoryKindScouted, oldValue, newValue, event), nil
+}
+
+// GetScoutingReport returns scouting report of the card from the database.
+func (service *Service) GetScoutingReport(ctx context.Context, cardID uuid.UUID) (ScoutingReport, error) {
+	report, err := service.cards.GetScoutingReport(ctx, cardID)
+	return report, ErrCards.Wrap(err)
 }
 
-// UpdateMintedStatus updates minted status of card in database.
-func (service *Service) UpdateMintedStatus(ctx context.Context, id uuid.UUID, status int) error {
-	return ErrCards.Wrap(service.cards.UpdateMintedStatus(ctx, id, status))
+// ListScoutingReports returns scouting reports of the cards which were scouted from the database.
+func (service *Service) ListScoutingReports(ctx context.Context, cardIDs []uuid.UUID) ([]ScoutingReport, error) {
+	reports, err := service.cards.ListScoutingReports(ctx, cardIDs)
+	return reports, ErrCards.Wrap(err)
 }
 
-// UpdateType updates type of card in the database.
-func (service *Service) UpdateType(ctx context.Context, id uuid.UUID, typeCard Type) error {
-	return ErrCards.Wrap(service.cards.UpdateType(ctx, id, typeCard))
+// ListHistoryByCardID returns full provenance of the card from the database.
+func (service *Service) ListHistoryByCardID(ctx context.Context, cardID uuid.UUID) ([]History, error) {
+	history, err := service.cards.ListHistoryByCardID(ctx, cardID)
+	return history, ErrCards.Wrap(err)
+}
+
+// ListHistory returns history of all cards from the database.
+func (service *Service) ListHistory(ctx context.Context, cursor pagination.Cursor) (HistoryPage, error) {
+	if cursor.Limit <= 0 {
+		cursor.Limit = service.config.Cursor.Limit
+	}
+	if cursor.Page <= 0 {
+		cursor.Page = service.config.Cursor.Page
+	}
+
+	historyPage, err := service.cards.ListHistory(ctx, cursor)
+	return historyPage, ErrCards.Wrap(err)
 }
 
-// UpdateUserID updates user's id for card in database.
-func (service *Service) UpdateUserID(ctx context.Context, cardID uuid.UUID, userID uuid.UUID) error {
-	return ErrCards.Wrap(service.cards.UpdateUserID(ctx, cardID, userID))
+// ListHistoryByUserID returns history of the cards where user was owner or counterparty from the database.
+func (service *Service) ListHistoryByUserID(ctx context.Context, userID uuid.UUID, cursor pagination.Cursor) (HistoryPage, error) {
+	if cursor.Limit <= 0 {
+		cursor.Limit = service.config.Cursor.Limit
+	}
+	if cursor.Page <= 0 {
+		cursor.Page = service.config.Cursor.Page
+	}
+
+	historyPage, err := service.cards.ListHistoryByUserID(ctx, userID, cursor)
+	return historyPage, ErrCards.Wrap(err)
 }
 
 // Delete deletes card record in database.
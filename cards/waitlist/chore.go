@@ -84,11 +84,11 @@ func (chore *Chore) RunCasperCheckMintEvent(ctx context.Context) error {
 			chore.log.Error("could not update nft", ChoreError.Wrap(err))
 		}
 
-		if err = chore.cards.UpdateUserID(ctx, nft.CardID, user.ID); err != nil {
+		if err = chore.cards.UpdateUserID(ctx, nft.CardID, user.ID, cards.Event{Cause: cards.CauseMint}); err != nil {
 			chore.log.Error("could not update user ID by card id", ChoreError.Wrap(err))
 		}
 
-		if err = chore.cards.UpdateMintedStatus(ctx, nft.CardID, cards.Minted); err != nil {
+		if err = chore.cards.UpdateMintedStatus(ctx, nft.CardID, cards.Minted, cards.Event{Cause: cards.CauseMint}); err != nil {
 			chore.log.Error("could not update minted status to 1", ChoreError.Wrap(err))
 		}
 
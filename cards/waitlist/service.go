@@ -358,11 +358,11 @@ func (service *Service) RunCasperCheckMintEvent(ctx context.Context) (err error)
 		log.Println(err)
 	}
 
-	if err = service.cards.UpdateUserID(ctx, nft.CardID, user.ID); err != nil {
+	if err = service.cards.UpdateUserID(ctx, nft.CardID, user.ID, cards.Event{Cause: cards.CauseMint}); err != nil {
 		log.Println(err)
 	}
 
-	if err = service.cards.UpdateMintedStatus(ctx, nft.CardID, cards.Minted); err != nil {
+	if err = service.cards.UpdateMintedStatus(ctx, nft.CardID, cards.Minted, cards.Event{Cause: cards.CauseMint}); err != nil {
 		log.Println(err)
 	}
 
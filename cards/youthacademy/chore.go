@@ -0,0 +1,93 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package youthacademy
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/BoostyLabs/thelooper"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/cards"
+	"ultimatedivision/cards/avatars"
+	"ultimatedivision/clubs"
+)
+
+var (
+	// ChoreError represents youth academy chore error type.
+	ChoreError = errs.Class("youth academy chore error")
+)
+
+// Config defines values needed by youth academy to generate young cards.
+type Config struct {
+	RenewalInterval     time.Duration             `json:"renewalInterval"`
+	PercentageQualities cards.PercentageQualities `json:"percentageQualities"`
+	Age                 int                       `json:"age"`
+	MinPotentialGrowth  int                       `json:"minPotentialGrowth"`
+	MaxPotentialGrowth  int                       `json:"maxPotentialGrowth"`
+}
+
+// Chore periodically generates young low-rated cards with hidden potential for owners of the active clubs.
+//
+// architecture: Chore.
+type Chore struct {
+	Loop    *thelooper.Loop
+	config  Config
+	cards   *cards.Service
+	clubs   *clubs.Service
+	avatars *avatars.Service
+}
+
+// NewChore instantiates Chore.
+func NewChore(config Config, cards *cards.Service, clubs *clubs.Service, avatars *avatars.Service) *Chore {
+	return &Chore{
+		Loop:    thelooper.NewLoop(config.RenewalInterval),
+		config:  config,
+		cards:   cards,
+		clubs:   clubs,
+		avatars: avatars,
+	}
+}
+
+// Run runs the generation of young cards for clubs.
+func (chore *Chore) Run(ctx context.Context) error {
+	return chore.Loop.Run(ctx, func(ctx context.Context) error {
+		allClubs, err := chore.clubs.List(ctx)
+		if err != nil {
+			return ChoreError.Wrap(err)
+		}
+
+		percentageQualities := []int{
+			chore.config.PercentageQualities.Wood,
+			chore.config.PercentageQualities.Silver,
+			chore.config.PercentageQualities.Gold,
+			chore.config.PercentageQualities.Diamond,
+		}
+
+		for _, club := range allClubs {
+			if club.Status != clubs.StatusActive {
+				continue
+			}
+
+			potentialGrowth := rand.Intn(chore.config.MaxPotentialGrowth-chore.config.MinPotentialGrowth+1) + chore.config.MinPotentialGrowth
+			card, err := chore.cards.CreateYouth(ctx, club.OwnerID, percentageQualities, chore.config.Age, potentialGrowth)
+			if err != nil {
+				return ChoreError.Wrap(err)
+			}
+
+			if _, err = chore.avatars.Generate(ctx, card, card.ID.String()); err != nil {
+				return ChoreError.Wrap(err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Close closes the chore for generation of young cards.
+func (chore *Chore) Close() {
+	chore.Loop.Close()
+}
@@ -0,0 +1,27 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package badges
+
+import (
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/internal/remotefilestorage/storj"
+)
+
+// ErrNoBadgeFile indicated that badge component file does not exist.
+var ErrNoBadgeFile = errs.Class("badge component file does not exist")
+
+// Config defines configuration for badges.
+type Config struct {
+	PathToBadgeComponents string `json:"pathToBadgeComponents"`
+
+	ShapesFolder  string `json:"shapesFolder"`
+	ShapeFile     string `json:"shapeFile"`
+	SymbolsFolder string `json:"symbolsFolder"`
+	SymbolFile    string `json:"symbolFile"`
+
+	FileStorage storj.Config `json:"fileStorage"`
+	Bucket      string       `json:"bucket"`
+	URLToBadge  string       `json:"urlToBadge"`
+}
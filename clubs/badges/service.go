@@ -0,0 +1,136 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package badges
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/clubs"
+	"ultimatedivision/internal/remotefilestorage/storj"
+	"ultimatedivision/pkg/imageprocessing"
+)
+
+// ErrBadges indicated that there was an error in service.
+var ErrBadges = errs.Class("badges service error")
+
+// Service is handling badges related logic.
+//
+// architecture: Service
+type Service struct {
+	clubs  *clubs.Service
+	config Config
+}
+
+// NewService is a constructor for badges service.
+func NewService(clubs *clubs.Service, config Config) *Service {
+	return &Service{
+		clubs:  clubs,
+		config: config,
+	}
+}
+
+// Update sets crest and kit colours of the user's club, generates its badge and uploads it to the remote storage.
+// Returns updated club.
+func (service *Service) Update(ctx context.Context, userID, clubID uuid.UUID, crest clubs.Crest, kit clubs.Kit) (clubs.Club, error) {
+	club, err := service.clubs.Get(ctx, clubID)
+	if err != nil {
+		return clubs.Club{}, ErrBadges.Wrap(err)
+	}
+	if club.OwnerID != userID {
+		return clubs.Club{}, clubs.ErrInvalidOperation.New("club does not belong to the user")
+	}
+
+	if err = kit.Validate(); err != nil {
+		return clubs.Club{}, err
+	}
+
+	return service.update(ctx, club, crest, kit)
+}
+
+// Create creates club of the user with the default crest and kit colours and generates its badge.
+// Returns id of the created club.
+func (service *Service) Create(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	clubID, err := service.clubs.Create(ctx, userID)
+	if err != nil {
+		return uuid.Nil, ErrBadges.Wrap(err)
+	}
+
+	club, err := service.clubs.Get(ctx, clubID)
+	if err != nil {
+		return uuid.Nil, ErrBadges.Wrap(err)
+	}
+
+	_, err = service.update(ctx, club, clubs.DefaultCrest, clubs.DefaultKit)
+	return clubID, err
+}
+
+// update generates badge of the club from the crest and kit colours, uploads it to the remote storage
+// and saves identity of the club.
+func (service *Service) update(ctx context.Context, club clubs.Club, crest clubs.Crest, kit clubs.Kit) (clubs.Club, error) {
+	badge, err := service.Generate(crest, kit)
+	if err != nil {
+		return clubs.Club{}, err
+	}
+
+	client, err := storj.NewClient(service.config.FileStorage)
+	if err != nil {
+		return clubs.Club{}, ErrBadges.Wrap(err)
+	}
+
+	objectName := fmt.Sprintf("%s.%s", club.ID, imageprocessing.TypeFilePNG)
+	if err = client.Upload(ctx, service.config.Bucket, objectName, badge); err != nil {
+		return clubs.Club{}, ErrBadges.Wrap(err)
+	}
+
+	club.Crest, club.Kit, club.BadgeURL = crest, kit, fmt.Sprintf(service.config.URLToBadge, objectName)
+	if err = service.clubs.UpdateIdentity(ctx, club.ID, club.Crest, club.Kit, club.BadgeURL); err != nil {
+		return clubs.Club{}, ErrBadges.Wrap(err)
+	}
+
+	return club, nil
+}
+
+// Generate generates png badge from the crest components coloured in the kit colours:
+// the shape in the primary colour and the symbol over it in the secondary colour.
+func (service *Service) Generate(crest clubs.Crest, kit clubs.Kit) ([]byte, error) {
+	shape, err := service.component(service.config.ShapesFolder, service.config.ShapeFile, crest.Shape, kit.PrimaryColor)
+	if err != nil {
+		return nil, err
+	}
+
+	symbol, err := service.component(service.config.SymbolsFolder, service.config.SymbolFile, crest.Symbol, kit.SecondaryColor)
+	if err != nil {
+		return nil, err
+	}
+
+	badge, err := imageprocessing.EncodePNG(imageprocessing.Layering([]image.Image{shape, symbol}, 0, 0))
+	return badge, ErrBadges.Wrap(err)
+}
+
+// component returns coloured layer of the crest component with the number from the folder.
+func (service *Service) component(folder, file string, number int, color string) (image.Image, error) {
+	path := filepath.Join(service.config.PathToBadgeComponents, folder)
+
+	count, err := imageprocessing.LayerComponentsCount(path, file)
+	if err != nil {
+		return nil, ErrNoBadgeFile.Wrap(err)
+	}
+	if number < 1 || number > count {
+		return nil, clubs.ErrInvalidOperation.New("crest component %d does not exist in %s", number, folder)
+	}
+
+	layer, err := imageprocessing.CreateLayer(path, fmt.Sprintf(file, number))
+	if err != nil {
+		return nil, ErrNoBadgeFile.Wrap(err)
+	}
+
+	colorized, err := imageprocessing.Colorize(layer, color)
+	return colorized, ErrBadges.Wrap(err)
+}
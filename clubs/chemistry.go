@@ -0,0 +1,248 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package clubs
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/google/uuid"
+
+	"ultimatedivision/cards"
+)
+
+// chemistry settings.
+const (
+	// PartnershipMatches defines the number of matches played together after which the partnership gives full bonus.
+	PartnershipMatches = 10
+	// MaxChemistryBonus defines the share of the squad effectiveness which is added by the full chemistry.
+	MaxChemistryBonus = 0.1
+	// MaxChemistry defines the max value of the chemistry score.
+	MaxChemistry = 100
+
+	// nationalityStrength and partnershipStrength define the share of the link strength given by the shared
+	// nationality and by the full partnership.
+	nationalityStrength = 0.5
+	partnershipStrength = 0.5
+)
+
+// Chemistry describes how well cards of the squad play together.
+type Chemistry struct {
+	Score float64         `json:"score"`
+	Links []ChemistryLink `json:"links"`
+}
+
+// ChemistryLink describes chemistry between two cards which play in adjacent positions.
+type ChemistryLink struct {
+	Card1ID         uuid.UUID `json:"card1Id"`
+	Card2ID         uuid.UUID `json:"card2Id"`
+	SameNationality bool      `json:"sameNationality"`
+	MatchesTogether int       `json:"matchesTogether"`
+}
+
+// strength returns strength of the link in range of 0 to 1.
+func (link ChemistryLink) strength() float64 {
+	var strength float64
+	if link.SameNationality {
+		strength += nationalityStrength
+	}
+
+	matches := link.MatchesTogether
+	if matches > PartnershipMatches {
+		matches = PartnershipMatches
+	}
+
+	return strength + partnershipStrength*float64(matches)/PartnershipMatches
+}
+
+// Partnership describes how many matches two cards have played together in the starting lineup.
+type Partnership struct {
+	Card1ID       uuid.UUID `json:"card1Id"`
+	Card2ID       uuid.UUID `json:"card2Id"`
+	MatchesPlayed int       `json:"matchesPlayed"`
+}
+
+// NewPartnership returns partnership of two cards with ordered ids, so the same pair always has the same key.
+func NewPartnership(card1ID, card2ID uuid.UUID) Partnership {
+	if bytes.Compare(card1ID[:], card2ID[:]) > 0 {
+		card1ID, card2ID = card2ID, card1ID
+	}
+
+	return Partnership{Card1ID: card1ID, Card2ID: card2ID}
+}
+
+// lane returns the lane of the position in the field from the left flank (-2) to the right flank (2).
+func (position Position) lane() int {
+	switch position {
+	case LB, LWB, LM, LW:
+		return -2
+	case LCD, LCDM, LCM, LCAM, LST:
+		return -1
+	case RCD, RCDM, RCM, RCAM, RST:
+		return 1
+	case RB, RWB, RM, RW:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// areAdjacent checks if two positions are adjacent in the squad.
+// Positions of the same line are adjacent if there is no other position between them,
+// positions of the neighbouring lines are adjacent if their lanes differ by no more than one.
+func areAdjacent(position1, position2 Position, positions []Position) bool {
+	line1, line2 := position1.Line(), position2.Line()
+	lane1, lane2 := position1.lane(), position2.lane()
+
+	if line1 != line2 {
+		if line1-line2 != 1 && line2-line1 != 1 {
+			return false
+		}
+		return lane1-lane2 <= 1 && lane2-lane1 <= 1
+	}
+
+	if lane1 > lane2 {
+		lane1, lane2 = lane2, lane1
+	}
+	for _, position := range positions {
+		if position == position1 || position == position2 || position.Line() != line1 {
+			continue
+		}
+		if lane := position.lane(); lane > lane1 && lane < lane2 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cardsOfSquadCards returns cards of the squad cards by their ids, including substitutes which came on during the match.
+func (service *Service) cardsOfSquadCards(ctx context.Context, squadCards []SquadCard) (map[uuid.UUID]cards.Card, error) {
+	cardsByID := make(map[uuid.UUID]cards.Card, len(squadCards))
+	if len(squadCards) == 0 {
+		return cardsByID, nil
+	}
+
+	cardsFromSquad, err := service.cards.GetCardsFromSquadCards(ctx, squadCards[0].SquadID)
+	if err != nil {
+		return nil, ErrClubs.Wrap(err)
+	}
+	for _, card := range cardsFromSquad {
+		cardsByID[card.ID] = card
+	}
+
+	for _, squadCard := range squadCards {
+		if _, ok := cardsByID[squadCard.CardID]; ok || squadCard.CardID == uuid.Nil {
+			continue
+		}
+
+		card, err := service.cards.Get(ctx, squadCard.CardID)
+		if err != nil {
+			return nil, ErrClubs.Wrap(err)
+		}
+		cardsByID[card.ID] = card
+	}
+
+	return cardsByID, nil
+}
+
+// CalculateChemistry returns chemistry of the squad cards.
+func (service *Service) CalculateChemistry(ctx context.Context, squadCards []SquadCard) (Chemistry, error) {
+	cardsByID, err := service.cardsOfSquadCards(ctx, squadCards)
+	if err != nil {
+		return Chemistry{}, err
+	}
+
+	return service.chemistry(ctx, squadCards, cardsByID)
+}
+
+// GetSquadChemistry returns chemistry of the current lineup of the squad.
+func (service *Service) GetSquadChemistry(ctx context.Context, squadID uuid.UUID) (Chemistry, error) {
+	squadCards, err := service.ListLineup(ctx, squadID)
+	if err != nil {
+		return Chemistry{}, ErrClubs.Wrap(err)
+	}
+
+	return service.CalculateChemistry(ctx, squadCards)
+}
+
+// chemistry calculates chemistry of the squad cards which are already loaded.
+func (service *Service) chemistry(ctx context.Context, squadCards []SquadCard, cardsByID map[uuid.UUID]cards.Card) (Chemistry, error) {
+	var (
+		cardIDs   []uuid.UUID
+		positions []Position
+		lineup    []SquadCard
+	)
+	for _, squadCard := range squadCards {
+		if squadCard.CardID == uuid.Nil {
+			continue
+		}
+		cardIDs = append(cardIDs, squadCard.CardID)
+		positions = append(positions, squadCard.Position)
+		lineup = append(lineup, squadCard)
+	}
+
+	chemistry := Chemistry{Links: []ChemistryLink{}}
+	if len(lineup) < 2 {
+		return chemistry, nil
+	}
+
+	partnerships, err := service.clubs.ListPartnerships(ctx, cardIDs)
+	if err != nil {
+		return Chemistry{}, ErrClubs.Wrap(err)
+	}
+
+	matchesTogether := make(map[Partnership]int, len(partnerships))
+	for _, partnership := range partnerships {
+		matchesTogether[NewPartnership(partnership.Card1ID, partnership.Card2ID)] = partnership.MatchesPlayed
+	}
+
+	var strength float64
+	for i := 0; i < len(lineup); i++ {
+		for j := i + 1; j < len(lineup); j++ {
+			if !areAdjacent(lineup[i].Position, lineup[j].Position, positions) {
+				continue
+			}
+
+			card1, card2 := cardsByID[lineup[i].CardID], cardsByID[lineup[j].CardID]
+			link := ChemistryLink{
+				Card1ID:         lineup[i].CardID,
+				Card2ID:         lineup[j].CardID,
+				SameNationality: card1.Nationality != "" && card1.Nationality == card2.Nationality,
+				MatchesTogether: matchesTogether[NewPartnership(lineup[i].CardID, lineup[j].CardID)],
+			}
+
+			strength += link.strength()
+			chemistry.Links = append(chemistry.Links, link)
+		}
+	}
+
+	if len(chemistry.Links) > 0 {
+		chemistry.Score = MaxChemistry * strength / float64(len(chemistry.Links))
+	}
+
+	return chemistry, nil
+}
+
+// RecordPartnerships increases the number of matches played together for each pair of the cards of starting lineup.
+func (service *Service) RecordPartnerships(ctx context.Context, squadCards []SquadCard) error {
+	var partnerships []Partnership
+	for i := 0; i < len(squadCards); i++ {
+		if squadCards[i].CardID == uuid.Nil {
+			continue
+		}
+		for j := i + 1; j < len(squadCards); j++ {
+			if squadCards[j].CardID == uuid.Nil {
+				continue
+			}
+			partnerships = append(partnerships, NewPartnership(squadCards[i].CardID, squadCards[j].CardID))
+		}
+	}
+
+	if len(partnerships) == 0 {
+		return nil
+	}
+
+	return ErrClubs.Wrap(service.clubs.AddPartnerships(ctx, partnerships))
+}
@@ -39,7 +39,9 @@ type DB interface {
 	ListByUserID(ctx context.Context, userID uuid.UUID) ([]Club, error)
 	// Get returns club.
 	Get(ctx context.Context, clubID uuid.UUID) (Club, error)
-	// GetSquadByClubID returns squad by club id.
+	// ListSquadsByClubID returns all squads of the club.
+	ListSquadsByClubID(ctx context.Context, clubID uuid.UUID) ([]Squad, error)
+	// GetSquadByClubID returns active squad by club id.
 	GetSquadByClubID(ctx context.Context, clubID uuid.UUID) (Squad, error)
 	// GetSquadIDByCardID returns squad by card id.
 	GetSquadIDByCardID(ctx context.Context, cardID uuid.UUID) (uuid.UUID, error)
@@ -51,22 +53,56 @@ type DB interface {
 	GetCaptainID(ctx context.Context, squadID uuid.UUID) (uuid.UUID, error)
 	// ListSquadCards returns all cards from squad.
 	ListSquadCards(ctx context.Context, squadID uuid.UUID) ([]SquadCard, error)
+	// ListSubstitutes returns substitutes of the squad ordered by priority.
+	ListSubstitutes(ctx context.Context, squadID uuid.UUID) ([]SquadSubstitute, error)
+	// AddSubstitute adds card to the bench of the squad.
+	AddSubstitute(ctx context.Context, substitute SquadSubstitute) error
+	// DeleteSubstitute deletes card from the bench of the squad.
+	DeleteSubstitute(ctx context.Context, squadID, cardID uuid.UUID) error
 	// AddSquadCard adds new card to the squad.
 	AddSquadCard(ctx context.Context, squadCards SquadCard) error
 	// DeleteSquadCard deletes card from squad.
 	DeleteSquadCard(ctx context.Context, squadID, cardID uuid.UUID) error
-	// DeleteByCardID deletes card from squad by card id.
+	// DeleteByCardID deletes card from all squads and benches by card id.
 	DeleteByCardID(ctx context.Context, cardID uuid.UUID) error
+	// DeleteSquad deletes squad of the club.
+	DeleteSquad(ctx context.Context, squadID uuid.UUID) error
 	// UpdateTacticCaptain updates tactic and capitan in the squad.
 	UpdateTacticCaptain(ctx context.Context, squad Squad) error
 	// UpdateStatuses update statuses of users clubs.
 	UpdateStatuses(ctx context.Context, allClubs []Club) error
 	// UpdatePositions updates positions of cards in the squad.
 	UpdatePositions(ctx context.Context, squadCards []SquadCard) error
+	// UpdateActiveSquad makes squad active and all other squads of the club inactive.
+	UpdateActiveSquad(ctx context.Context, clubID, squadID uuid.UUID) error
 	// UpdateFormation updates formation in the squad.
 	UpdateFormation(ctx context.Context, newFormation Formation, squadID uuid.UUID) error
+	// ReplaceLineup replaces formation, cards and substitutes of the squad by the lineup in one transaction.
+	ReplaceLineup(ctx context.Context, squadID uuid.UUID, lineup Lineup) error
 	// UpdateClubToNewDivision updates club to new division.
 	UpdateClubToNewDivision(ctx context.Context, clubID uuid.UUID, newDivisionID uuid.UUID) error
+	// CreateCustomFormation creates custom formation of the club and returns its id.
+	CreateCustomFormation(ctx context.Context, formation CustomFormation) (Formation, error)
+	// GetCustomFormation returns custom formation by id.
+	GetCustomFormation(ctx context.Context, id Formation) (CustomFormation, error)
+	// ListCustomFormations returns all custom formations of the club.
+	ListCustomFormations(ctx context.Context, clubID uuid.UUID) ([]CustomFormation, error)
+	// DeleteCustomFormation deletes custom formation.
+	DeleteCustomFormation(ctx context.Context, id Formation) error
+	// AddPartnerships increases the number of matches played together for each pair of cards.
+	AddPartnerships(ctx context.Context, partnerships []Partnership) error
+	// ListPartnerships returns partnerships between the cards.
+	ListPartnerships(ctx context.Context, cardIDs []uuid.UUID) ([]Partnership, error)
+	// UpdateIdentity updates crest, kit colours and badge url of the club.
+	UpdateIdentity(ctx context.Context, club Club) error
+	// UpdateTeamInstructions updates tactical settings of the squad.
+	UpdateTeamInstructions(ctx context.Context, squadID uuid.UUID, instructions TeamInstructions) error
+	// UpsertPlayerInstruction adds or updates instruction for the card of the squad.
+	UpsertPlayerInstruction(ctx context.Context, instruction PlayerInstruction) error
+	// DeletePlayerInstruction deletes instruction for the card of the squad.
+	DeletePlayerInstruction(ctx context.Context, squadID, cardID uuid.UUID) error
+	// ListPlayerInstructions returns instructions for the cards of the squad.
+	ListPlayerInstructions(ctx context.Context, squadID uuid.UUID) ([]PlayerInstruction, error)
 }
 
 // Status defines list of possible club statuses.
@@ -92,16 +128,21 @@ type Club struct {
 	Status     Status    `json:"status"`
 	DivisionID uuid.UUID `json:"divisionId"`
 	CreatedAt  time.Time `json:"createdAt"`
+	Crest      Crest     `json:"crest"`
+	Kit        Kit       `json:"kit"`
+	BadgeURL   string    `json:"badgeUrl"`
 }
 
-// Squad describes squads of clubs.
+// Squad describes named lineup preset of the club, only the active squad of the club plays matches.
 type Squad struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"-"`
-	ClubID    uuid.UUID `json:"clubId"`
-	Formation Formation `json:"formation"`
-	Tactic    Tactic    `json:"tactic"`
-	CaptainID uuid.UUID `json:"captainId"`
+	ID           uuid.UUID        `json:"id"`
+	Name         string           `json:"name"`
+	ClubID       uuid.UUID        `json:"clubId"`
+	Formation    Formation        `json:"formation"`
+	Tactic       Tactic           `json:"tactic"`
+	CaptainID    uuid.UUID        `json:"captainId"`
+	IsActive     bool             `json:"isActive"`
+	Instructions TeamInstructions `json:"instructions"`
 }
 
 // SquadCard defines all cards from squad.
@@ -111,9 +152,25 @@ type SquadCard struct {
 	Position Position  `json:"position"`
 }
 
+// SquadSubstitute describes card on the bench of the squad, substitutes with lower priority come on first.
+type SquadSubstitute struct {
+	SquadID  uuid.UUID `json:"squadId"`
+	CardID   uuid.UUID `json:"cardId"`
+	Priority int       `json:"priority"`
+}
+
 // SquadSize defines number of cards in the full squad.
 const SquadSize int = 11
 
+// MaxSubstitutes defines max number of cards on the bench of the squad.
+const MaxSubstitutes int = 7
+
+// MaxSquads defines max number of lineup presets of the club.
+const MaxSquads int = 5
+
+// DefaultSquadName defines name of the squad if user did not name it.
+const DefaultSquadName = "main"
+
 // Formation defines a list of possible formations.
 type Formation int
 
@@ -140,6 +197,11 @@ const (
 	ThreeFiveTwo Formation = 10
 )
 
+// IsCustom checks that formation ID belongs to the custom formation of the club.
+func (f Formation) IsCustom() bool {
+	return f >= FirstCustomFormation
+}
+
 // IsValid check that formation ID is valid.
 func (f Formation) IsValid() bool {
 	switch f {
@@ -247,10 +309,10 @@ func sortSquadCards(cards []SquadCard) {
 }
 
 // convertPositions converts cards positions positions that are present in the formation, to 0-10 view.
-func convertPositions(squadCards []SquadCard, formation Formation) []SquadCard {
+func convertPositions(squadCards []SquadCard, positions []Position) []SquadCard {
 	for i := 0; i < len(squadCards); i++ {
-		for j := 0; j < len(FormationToPosition[formation]); j++ {
-			if squadCards[i].Position == FormationToPosition[formation][j] {
+		for j := 0; j < len(positions); j++ {
+			if squadCards[i].Position == positions[j] {
 				squadCards[i].Position = Position(j)
 				break
 			}
@@ -80,6 +80,15 @@ func TestTeam(t *testing.T) {
 		ClubID:    testClub1.ID,
 		Tactic:    clubs.Balanced,
 		Formation: clubs.FourTwoFour,
+		IsActive:  true,
+	}
+
+	testSquad2 := clubs.Squad{
+		ID:        uuid.New(),
+		Name:      "reserve",
+		ClubID:    testClub1.ID,
+		Tactic:    clubs.Defence,
+		Formation: clubs.FiveThreeTwo,
 	}
 
 	testCard1 := cards.Card{
@@ -124,6 +133,12 @@ func TestTeam(t *testing.T) {
 		CaptainID: testCard1.ID,
 	}
 
+	testCustomFormation := clubs.CustomFormation{
+		ClubID:    testClub1.ID,
+		Name:      "3-4-3",
+		Positions: []clubs.Position{clubs.GK, clubs.LCD, clubs.CCD, clubs.RCD, clubs.LM, clubs.LCM, clubs.RCM, clubs.RM, clubs.LW, clubs.CST, clubs.RW},
+	}
+
 	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db ultimatedivision.DB) {
 		repositoryClubs := db.Clubs()
 		repositoryCards := db.Cards()
@@ -175,6 +190,37 @@ func TestTeam(t *testing.T) {
 			compareSquads(t, squadDB, testSquad)
 		})
 
+		t.Run("List squads", func(t *testing.T) {
+			_, err := repositoryClubs.CreateSquad(ctx, testSquad2)
+			require.NoError(t, err)
+
+			squadsDB, err := repositoryClubs.ListSquadsByClubID(ctx, testClub1.ID)
+			require.NoError(t, err)
+			require.Equal(t, 2, len(squadsDB))
+			compareSquads(t, squadsDB[0], testSquad2)
+			compareSquads(t, squadsDB[1], testSquad)
+		})
+
+		t.Run("Update active squad sql no rows", func(t *testing.T) {
+			err := repositoryClubs.UpdateActiveSquad(ctx, id, id)
+			require.Error(t, err)
+			require.Equal(t, clubs.ErrNoSquad.Has(err), true)
+		})
+
+		t.Run("Update active squad", func(t *testing.T) {
+			err := repositoryClubs.UpdateActiveSquad(ctx, testClub1.ID, testSquad2.ID)
+			require.NoError(t, err)
+
+			squadDB, err := repositoryClubs.GetSquadByClubID(ctx, testClub1.ID)
+			require.NoError(t, err)
+			testSquad2.IsActive = true
+			compareSquads(t, squadDB, testSquad2)
+
+			err = repositoryClubs.UpdateActiveSquad(ctx, testClub1.ID, testSquad.ID)
+			require.NoError(t, err)
+			testSquad2.IsActive = false
+		})
+
 		t.Run("Get club", func(t *testing.T) {
 			clubDB, err := repositoryClubs.Get(ctx, testSquad.ClubID)
 			require.NoError(t, err)
@@ -182,6 +228,25 @@ func TestTeam(t *testing.T) {
 			compareClubs(t, clubDB, testClub1)
 		})
 
+		t.Run("Update identity sql no rows", func(t *testing.T) {
+			err := repositoryClubs.UpdateIdentity(ctx, clubs.Club{ID: id})
+			require.Error(t, err)
+			require.Equal(t, clubs.ErrNoClub.Has(err), true)
+		})
+
+		t.Run("Update identity", func(t *testing.T) {
+			testClub1.Crest = clubs.Crest{Shape: 2, Symbol: 3}
+			testClub1.Kit = clubs.Kit{PrimaryColor: "1E90FF", SecondaryColor: "FFD700"}
+			testClub1.BadgeURL = "https://badges.test/" + testClub1.ID.String() + ".png"
+
+			err := repositoryClubs.UpdateIdentity(ctx, testClub1)
+			require.NoError(t, err)
+
+			clubDB, err := repositoryClubs.Get(ctx, testClub1.ID)
+			require.NoError(t, err)
+			compareClubs(t, clubDB, testClub1)
+		})
+
 		t.Run("get formation sql no rows", func(t *testing.T) {
 			_, err := repositoryClubs.GetFormation(ctx, id)
 			require.Error(t, err)
@@ -208,6 +273,37 @@ func TestTeam(t *testing.T) {
 			require.NoError(t, err)
 		})
 
+		t.Run("Add substitutes", func(t *testing.T) {
+			err := repositoryClubs.AddSubstitute(ctx, clubs.SquadSubstitute{SquadID: testSquad2.ID, CardID: testCard2.ID, Priority: 2})
+			require.NoError(t, err)
+
+			err = repositoryClubs.AddSubstitute(ctx, clubs.SquadSubstitute{SquadID: testSquad2.ID, CardID: testCard1.ID, Priority: 1})
+			require.NoError(t, err)
+		})
+
+		t.Run("List substitutes", func(t *testing.T) {
+			substitutes, err := repositoryClubs.ListSubstitutes(ctx, testSquad2.ID)
+			require.NoError(t, err)
+			require.Equal(t, 2, len(substitutes))
+			assert.Equal(t, testCard1.ID, substitutes[0].CardID)
+			assert.Equal(t, testCard2.ID, substitutes[1].CardID)
+		})
+
+		t.Run("Delete substitute sql no rows", func(t *testing.T) {
+			err := repositoryClubs.DeleteSubstitute(ctx, id, id)
+			require.Error(t, err)
+			require.Equal(t, clubs.ErrNoSquadCard.Has(err), true)
+		})
+
+		t.Run("Delete substitute", func(t *testing.T) {
+			err := repositoryClubs.DeleteSubstitute(ctx, testSquad2.ID, testCard1.ID)
+			require.NoError(t, err)
+
+			substitutes, err := repositoryClubs.ListSubstitutes(ctx, testSquad2.ID)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(substitutes))
+		})
+
 		t.Run("List cards from squad", func(t *testing.T) {
 			squadCardsDB, err := repositoryClubs.ListSquadCards(ctx, testSquad.ID)
 			require.NoError(t, err)
@@ -267,6 +363,478 @@ func TestTeam(t *testing.T) {
 			err = repositoryClubs.DeleteSquadCard(ctx, testSquad.ID, testCard2.ID)
 			require.NoError(t, err)
 		})
+
+		t.Run("Delete by card id removes card from the bench", func(t *testing.T) {
+			err := repositoryClubs.DeleteByCardID(ctx, testCard2.ID)
+			require.NoError(t, err)
+
+			substitutes, err := repositoryClubs.ListSubstitutes(ctx, testSquad2.ID)
+			require.NoError(t, err)
+			assert.Equal(t, 0, len(substitutes))
+
+			err = repositoryClubs.DeleteByCardID(ctx, testCard2.ID)
+			require.Error(t, err)
+			require.Equal(t, clubs.ErrNoSquadCard.Has(err), true)
+		})
+
+		t.Run("Replace lineup sql no rows", func(t *testing.T) {
+			err := repositoryClubs.ReplaceLineup(ctx, id, clubs.Lineup{Formation: clubs.FourFourTwo, SquadCards: []clubs.SquadCard{testSquadCard1}})
+			require.Error(t, err)
+			require.Equal(t, clubs.ErrNoSquad.Has(err), true)
+
+			squadCardsDB, err := repositoryClubs.ListSquadCards(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, 0, len(squadCardsDB))
+		})
+
+		t.Run("Replace lineup", func(t *testing.T) {
+			lineup := clubs.Lineup{
+				Formation:   clubs.FourThreeThree,
+				SquadCards:  []clubs.SquadCard{testSquadCard1},
+				Substitutes: []uuid.UUID{testCard2.ID},
+			}
+			err := repositoryClubs.ReplaceLineup(ctx, testSquad.ID, lineup)
+			require.NoError(t, err)
+
+			formation, err := repositoryClubs.GetFormation(ctx, testSquad.ID)
+			require.NoError(t, err)
+			assert.Equal(t, clubs.FourThreeThree, formation)
+
+			squadCardsDB, err := repositoryClubs.ListSquadCards(ctx, testSquad.ID)
+			require.NoError(t, err)
+			comparePlayers(t, squadCardsDB, []clubs.SquadCard{testSquadCard1})
+
+			substitutes, err := repositoryClubs.ListSubstitutes(ctx, testSquad.ID)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(substitutes))
+
+			err = repositoryClubs.ReplaceLineup(ctx, testSquad.ID, clubs.Lineup{Formation: updatedSquad.Formation})
+			require.NoError(t, err)
+
+			squadCardsDB, err = repositoryClubs.ListSquadCards(ctx, testSquad.ID)
+			require.NoError(t, err)
+			assert.Equal(t, 0, len(squadCardsDB))
+
+			substitutes, err = repositoryClubs.ListSubstitutes(ctx, testSquad.ID)
+			require.NoError(t, err)
+			assert.Equal(t, 0, len(substitutes))
+		})
+
+		t.Run("Create custom formation", func(t *testing.T) {
+			formationID, err := repositoryClubs.CreateCustomFormation(ctx, testCustomFormation)
+			require.NoError(t, err)
+			assert.Equal(t, true, formationID.IsCustom())
+			testCustomFormation.ID = formationID
+		})
+
+		t.Run("Get custom formation sql no rows", func(t *testing.T) {
+			_, err := repositoryClubs.GetCustomFormation(ctx, clubs.FirstCustomFormation-1)
+			require.Error(t, err)
+			require.Equal(t, clubs.ErrNoCustomFormation.Has(err), true)
+		})
+
+		t.Run("Get custom formation", func(t *testing.T) {
+			formationDB, err := repositoryClubs.GetCustomFormation(ctx, testCustomFormation.ID)
+			require.NoError(t, err)
+			assert.Equal(t, testCustomFormation, formationDB)
+		})
+
+		t.Run("List custom formations", func(t *testing.T) {
+			formationsDB, err := repositoryClubs.ListCustomFormations(ctx, testClub1.ID)
+			require.NoError(t, err)
+			assert.Equal(t, []clubs.CustomFormation{testCustomFormation}, formationsDB)
+		})
+
+		t.Run("Delete custom formation sql no rows", func(t *testing.T) {
+			err := repositoryClubs.DeleteCustomFormation(ctx, clubs.FirstCustomFormation-1)
+			require.Error(t, err)
+			require.Equal(t, clubs.ErrNoCustomFormation.Has(err), true)
+		})
+
+		t.Run("Delete custom formation", func(t *testing.T) {
+			err := repositoryClubs.DeleteCustomFormation(ctx, testCustomFormation.ID)
+			require.NoError(t, err)
+
+			formationsDB, err := repositoryClubs.ListCustomFormations(ctx, testClub1.ID)
+			require.NoError(t, err)
+			assert.Equal(t, 0, len(formationsDB))
+		})
+
+		t.Run("Add partnerships", func(t *testing.T) {
+			partnership := clubs.NewPartnership(testCard2.ID, testCard1.ID)
+			err := repositoryClubs.AddPartnerships(ctx, []clubs.Partnership{partnership})
+			require.NoError(t, err)
+
+			err = repositoryClubs.AddPartnerships(ctx, []clubs.Partnership{partnership})
+			require.NoError(t, err)
+		})
+
+		t.Run("List partnerships", func(t *testing.T) {
+			partnershipsDB, err := repositoryClubs.ListPartnerships(ctx, []uuid.UUID{testCard1.ID, testCard2.ID})
+			require.NoError(t, err)
+			require.Equal(t, 1, len(partnershipsDB))
+
+			partnership := clubs.NewPartnership(testCard1.ID, testCard2.ID)
+			partnership.MatchesPlayed = 2
+			assert.Equal(t, partnership, partnershipsDB[0])
+
+			partnershipsDB, err = repositoryClubs.ListPartnerships(ctx, []uuid.UUID{testCard1.ID})
+			require.NoError(t, err)
+			assert.Equal(t, 0, len(partnershipsDB))
+		})
+
+		t.Run("Update team instructions sql no rows", func(t *testing.T) {
+			err := repositoryClubs.UpdateTeamInstructions(ctx, id, clubs.DefaultTeamInstructions)
+			require.Error(t, err)
+			require.Equal(t, clubs.ErrNoSquad.Has(err), true)
+		})
+
+		t.Run("Update team instructions", func(t *testing.T) {
+			instructions := clubs.TeamInstructions{
+				Pressing:      clubs.IntensityHigh,
+				Width:         clubs.IntensityLow,
+				Tempo:         clubs.IntensityHigh,
+				DefensiveLine: clubs.IntensityLow,
+			}
+			err := repositoryClubs.UpdateTeamInstructions(ctx, testSquad.ID, instructions)
+			require.NoError(t, err)
+
+			squadDB, err := repositoryClubs.GetSquad(ctx, testSquad.ID)
+			require.NoError(t, err)
+			assert.Equal(t, instructions, squadDB.Instructions)
+		})
+
+		t.Run("Upsert player instruction", func(t *testing.T) {
+			instruction := clubs.PlayerInstruction{SquadID: testSquad.ID, CardID: testCard1.ID, Instruction: clubs.InstructionStayBack}
+			err := repositoryClubs.UpsertPlayerInstruction(ctx, instruction)
+			require.NoError(t, err)
+
+			instruction.Instruction = clubs.InstructionGetForward
+			err = repositoryClubs.UpsertPlayerInstruction(ctx, instruction)
+			require.NoError(t, err)
+
+			instructionsDB, err := repositoryClubs.ListPlayerInstructions(ctx, testSquad.ID)
+			require.NoError(t, err)
+			assert.Equal(t, []clubs.PlayerInstruction{instruction}, instructionsDB)
+		})
+
+		t.Run("Delete player instruction sql no rows", func(t *testing.T) {
+			err := repositoryClubs.DeletePlayerInstruction(ctx, testSquad.ID, testCard2.ID)
+			require.Error(t, err)
+			require.Equal(t, clubs.ErrNoSquadCard.Has(err), true)
+		})
+
+		t.Run("Delete player instruction", func(t *testing.T) {
+			err := repositoryClubs.DeletePlayerInstruction(ctx, testSquad.ID, testCard1.ID)
+			require.NoError(t, err)
+
+			instructionsDB, err := repositoryClubs.ListPlayerInstructions(ctx, testSquad.ID)
+			require.NoError(t, err)
+			assert.Equal(t, 0, len(instructionsDB))
+		})
+
+		t.Run("Delete squad sql no rows", func(t *testing.T) {
+			err := repositoryClubs.DeleteSquad(ctx, id)
+			require.Error(t, err)
+			require.Equal(t, clubs.ErrNoSquad.Has(err), true)
+		})
+
+		t.Run("Delete squad", func(t *testing.T) {
+			err := repositoryClubs.DeleteSquad(ctx, testSquad2.ID)
+			require.NoError(t, err)
+
+			squadsDB, err := repositoryClubs.ListSquadsByClubID(ctx, testClub1.ID)
+			require.NoError(t, err)
+			assert.Equal(t, 1, len(squadsDB))
+		})
+	})
+}
+
+func TestValidatePositions(t *testing.T) {
+	for formation, positions := range clubs.FormationToPosition {
+		assert.NoError(t, clubs.ValidatePositions(positions), formation)
+	}
+
+	invalid := [][]clubs.Position{
+		// ten positions.
+		{clubs.GK, clubs.LB, clubs.LCD, clubs.RCD, clubs.RB, clubs.LM, clubs.LCM, clubs.RCM, clubs.RM, clubs.LST},
+		// two goalkeepers.
+		{clubs.GK, clubs.GK, clubs.LCD, clubs.RCD, clubs.RB, clubs.LM, clubs.LCM, clubs.RCM, clubs.RM, clubs.LST, clubs.RST},
+		// repeated position.
+		{clubs.GK, clubs.LB, clubs.LB, clubs.RCD, clubs.RB, clubs.LM, clubs.LCM, clubs.RCM, clubs.RM, clubs.LST, clubs.RST},
+		// six defenders.
+		{clubs.GK, clubs.LB, clubs.LCD, clubs.CCD, clubs.RCD, clubs.RB, clubs.LWB, clubs.LCM, clubs.RCM, clubs.LST, clubs.RST},
+		// no attackers.
+		{clubs.GK, clubs.LB, clubs.LCD, clubs.RCD, clubs.RB, clubs.LM, clubs.LCM, clubs.CCM, clubs.RCM, clubs.RM, clubs.CCAM},
+		// unknown position.
+		{clubs.GK, clubs.LB, clubs.LCD, clubs.RCD, clubs.RB, clubs.LM, clubs.LCM, clubs.RCM, clubs.RM, clubs.LST, 0},
+	}
+	for _, positions := range invalid {
+		err := clubs.ValidatePositions(positions)
+		require.Error(t, err)
+		assert.Equal(t, true, clubs.ErrInvalidOperation.Has(err), positions)
+	}
+}
+
+func TestValidateSquad(t *testing.T) {
+	testUser := users.User{
+		ID:           uuid.New(),
+		Email:        "validate@gmail.com",
+		PasswordHash: []byte{1},
+		NickName:     "validate",
+		LastLogin:    time.Now().UTC(),
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	division := divisions.Division{
+		ID:             uuid.New(),
+		Name:           10,
+		PassingPercent: 10,
+		CreatedAt:      time.Now().UTC(),
+	}
+
+	testClub := clubs.Club{
+		ID:         uuid.New(),
+		OwnerID:    testUser.ID,
+		Name:       testUser.NickName,
+		Status:     clubs.StatusActive,
+		DivisionID: division.ID,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	testActiveCard := cards.Card{
+		ID:     uuid.New(),
+		UserID: testUser.ID,
+		Status: cards.StatusActive,
+	}
+
+	testCardOnSale := cards.Card{
+		ID:     uuid.New(),
+		UserID: testUser.ID,
+		Status: cards.StatusSale,
+	}
+
+	testSquad := clubs.Squad{
+		ID:        uuid.New(),
+		Name:      "test squad",
+		ClubID:    testClub.ID,
+		Tactic:    clubs.Balanced,
+		Formation: clubs.FourFourTwo,
+		IsActive:  true,
+	}
+
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db ultimatedivision.DB) {
+		repositoryClubs := db.Clubs()
+		repositoryCards := db.Cards()
+		repositoryUsers := db.Users()
+
+		cardsService := cards.NewService(repositoryCards, cards.Config{})
+		clubsService := clubs.NewService(repositoryClubs, users.NewService(repositoryUsers), cardsService, db.Divisions())
+
+		err := repositoryUsers.Create(ctx, testUser)
+		require.NoError(t, err)
+
+		err = db.Divisions().Create(ctx, division)
+		require.NoError(t, err)
+
+		_, err = repositoryClubs.Create(ctx, testClub)
+		require.NoError(t, err)
+
+		_, err = repositoryClubs.CreateSquad(ctx, testSquad)
+		require.NoError(t, err)
+
+		err = repositoryCards.Create(ctx, testActiveCard)
+		require.NoError(t, err)
+
+		err = repositoryCards.Create(ctx, testCardOnSale)
+		require.NoError(t, err)
+
+		err = repositoryClubs.AddSquadCard(ctx, clubs.SquadCard{SquadID: testSquad.ID, CardID: testActiveCard.ID, Position: clubs.GK})
+		require.NoError(t, err)
+
+		err = repositoryClubs.AddSquadCard(ctx, clubs.SquadCard{SquadID: testSquad.ID, CardID: testCardOnSale.ID, Position: clubs.LCD})
+		require.NoError(t, err)
+
+		t.Run("squad of another user", func(t *testing.T) {
+			_, err := clubsService.ValidateSquad(ctx, uuid.New(), testSquad.ID)
+			require.Error(t, err)
+			assert.Equal(t, true, clubs.ErrInvalidOperation.Has(err))
+		})
+
+		t.Run("validate", func(t *testing.T) {
+			report, err := clubsService.ValidateSquad(ctx, testUser.ID, uuid.Nil)
+			require.NoError(t, err)
+			assert.Equal(t, testSquad.ID, report.SquadID)
+			assert.Equal(t, false, report.IsValid)
+
+			problems := make(map[clubs.ProblemCode]int)
+			for _, problem := range report.Problems {
+				problems[problem.Code]++
+				if problem.Code == clubs.ProblemUnavailableCard {
+					assert.Equal(t, testCardOnSale.ID, problem.CardID)
+				}
+			}
+			assert.Equal(t, clubs.SquadSize-2, problems[clubs.ProblemMissingPosition])
+			assert.Equal(t, 1, problems[clubs.ProblemUnavailableCard])
+			assert.Equal(t, 0, problems[clubs.ProblemDuplicatePosition])
+		})
+
+		t.Run("squads of another user", func(t *testing.T) {
+			_, err := clubsService.ListSquads(ctx, uuid.New(), testClub.ID)
+			require.Error(t, err)
+			assert.Equal(t, true, clubs.ErrInvalidOperation.Has(err))
+
+			err = clubsService.DeleteSubstitute(ctx, uuid.New(), testSquad.ID, testActiveCard.ID)
+			require.Error(t, err)
+			assert.Equal(t, true, clubs.ErrInvalidOperation.Has(err))
+
+			_, err = clubsService.GetUserSquad(ctx, uuid.New(), testSquad.ID)
+			require.Error(t, err)
+			assert.Equal(t, true, clubs.ErrInvalidOperation.Has(err))
+
+			squad, err := clubsService.GetUserSquad(ctx, testUser.ID, testSquad.ID)
+			require.NoError(t, err)
+			assert.Equal(t, testSquad.ID, squad.ID)
+		})
+
+		t.Run("formations of another user", func(t *testing.T) {
+			positions := []clubs.Position{clubs.GK, clubs.LB, clubs.LCD, clubs.RCD, clubs.RB, clubs.LM, clubs.LCM, clubs.RCM, clubs.RM, clubs.LST, clubs.RST}
+
+			_, err := clubsService.CreateCustomFormation(ctx, uuid.New(), testClub.ID, "stolen", positions)
+			require.Error(t, err)
+			assert.Equal(t, true, clubs.ErrInvalidOperation.Has(err))
+
+			_, err = clubsService.ListCustomFormations(ctx, uuid.New(), testClub.ID)
+			require.Error(t, err)
+			assert.Equal(t, true, clubs.ErrInvalidOperation.Has(err))
+
+			id, err := clubsService.CreateCustomFormation(ctx, testUser.ID, testClub.ID, "own", positions)
+			require.NoError(t, err)
+
+			err = clubsService.DeleteCustomFormation(ctx, uuid.New(), testClub.ID, id)
+			require.Error(t, err)
+			assert.Equal(t, true, clubs.ErrInvalidOperation.Has(err))
+
+			formations, err := clubsService.ListCustomFormations(ctx, testUser.ID, testClub.ID)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(formations))
+			assert.Equal(t, id, formations[0].ID)
+		})
+
+		t.Run("add substitute on sale", func(t *testing.T) {
+			err := clubsService.AddSubstitute(ctx, testUser.ID, testSquad.ID, testCardOnSale.ID)
+			require.Error(t, err)
+			assert.Equal(t, true, clubs.ErrInvalidOperation.Has(err))
+
+			substitutes, err := clubsService.ListSubstitutes(ctx, testSquad.ID)
+			require.NoError(t, err)
+			assert.Empty(t, substitutes)
+		})
+
+		t.Run("import squad", func(t *testing.T) {
+			code, err := clubs.EncodeSquadCode(clubs.SquadExport{
+				Version:      clubs.SquadCodeVersion,
+				Formation:    clubs.FourFourTwo,
+				Tactic:       clubs.Attack,
+				Instructions: clubs.DefaultTeamInstructions,
+				Players: []clubs.ExportedPlayer{
+					{Position: clubs.GK, Profile: clubs.CardProfile{Goalkeeping: 80}, IsCaptain: true},
+				},
+			})
+			require.NoError(t, err)
+
+			_, err = clubsService.ImportSquad(ctx, uuid.New(), testSquad.ID, code)
+			require.Error(t, err)
+			assert.Equal(t, true, clubs.ErrInvalidOperation.Has(err))
+
+			lineup, err := clubsService.ImportSquad(ctx, testUser.ID, testSquad.ID, code)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(lineup.SquadCards))
+			assert.Equal(t, testActiveCard.ID, lineup.SquadCards[0].CardID)
+
+			squadCards, err := repositoryClubs.ListSquadCards(ctx, testSquad.ID)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(squadCards))
+			assert.Equal(t, clubs.GK, squadCards[0].Position)
+
+			squad, err := repositoryClubs.GetSquad(ctx, testSquad.ID)
+			require.NoError(t, err)
+			assert.Equal(t, testActiveCard.ID, squad.CaptainID)
+			assert.Equal(t, clubs.Attack, squad.Tactic)
+		})
+	})
+}
+
+func TestTeamInstructionsValidate(t *testing.T) {
+	require.NoError(t, clubs.DefaultTeamInstructions.Validate())
+
+	invalid := []clubs.TeamInstructions{
+		{Pressing: 0, Width: clubs.IntensityLow, Tempo: clubs.IntensityLow, DefensiveLine: clubs.IntensityLow},
+		{Pressing: clubs.IntensityHigh, Width: 4, Tempo: clubs.IntensityLow, DefensiveLine: clubs.IntensityLow},
+		{Pressing: clubs.IntensityHigh, Width: clubs.IntensityHigh, Tempo: -1, DefensiveLine: clubs.IntensityLow},
+		{Pressing: clubs.IntensityHigh, Width: clubs.IntensityHigh, Tempo: clubs.IntensityHigh},
+	}
+	for _, instructions := range invalid {
+		err := instructions.Validate()
+		require.Error(t, err)
+		assert.Equal(t, true, clubs.ErrInvalidOperation.Has(err), instructions)
+	}
+
+	assert.Equal(t, false, clubs.Instruction("hold").IsValid())
+}
+
+func TestSquadCode(t *testing.T) {
+	export := clubs.SquadExport{
+		Version:      clubs.SquadCodeVersion,
+		Formation:    clubs.FourFourTwo,
+		Tactic:       clubs.Attack,
+		Instructions: clubs.DefaultTeamInstructions,
+		Players: []clubs.ExportedPlayer{
+			{Position: clubs.GK, Profile: clubs.CardProfile{Goalkeeping: 80, Physique: 60}},
+			{Position: clubs.LST, Profile: clubs.CardProfile{Offence: 90, Technique: 75}, IsCaptain: true},
+		},
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		code, err := clubs.EncodeSquadCode(export)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(code), clubs.MaxSquadCodeLength)
+
+		decoded, err := clubs.DecodeSquadCode(code)
+		require.NoError(t, err)
+		assert.Equal(t, export, decoded)
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		for _, code := range []string{"", "not a code", "AAAA"} {
+			_, err := clubs.DecodeSquadCode(code)
+			require.Error(t, err)
+			assert.Equal(t, true, clubs.ErrInvalidOperation.Has(err), code)
+		}
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		unsupported := export
+		unsupported.Version = clubs.SquadCodeVersion + 1
+
+		code, err := clubs.EncodeSquadCode(unsupported)
+		require.NoError(t, err)
+
+		_, err = clubs.DecodeSquadCode(code)
+		require.Error(t, err)
+		assert.Equal(t, true, clubs.ErrInvalidOperation.Has(err))
+	})
+
+	t.Run("position is not in formation", func(t *testing.T) {
+		invalid := export
+		invalid.Players = []clubs.ExportedPlayer{{Position: clubs.CST}}
+
+		code, err := clubs.EncodeSquadCode(invalid)
+		require.NoError(t, err)
+
+		_, err = clubs.DecodeSquadCode(code)
+		require.Error(t, err)
+		assert.Equal(t, true, clubs.ErrInvalidOperation.Has(err))
 	})
 }
 
@@ -274,6 +842,9 @@ func compareClubs(t *testing.T, clubDB clubs.Club, clubTest clubs.Club) {
 	assert.Equal(t, clubDB.ID, clubTest.ID)
 	assert.Equal(t, clubDB.OwnerID, clubTest.OwnerID)
 	assert.Equal(t, clubDB.Name, clubTest.Name)
+	assert.Equal(t, clubDB.Crest, clubTest.Crest)
+	assert.Equal(t, clubDB.Kit, clubTest.Kit)
+	assert.Equal(t, clubDB.BadgeURL, clubTest.BadgeURL)
 	assert.WithinDuration(t, clubDB.CreatedAt, clubTest.CreatedAt, 1*time.Second)
 }
 
@@ -283,6 +854,8 @@ func compareSquads(t *testing.T, squadDB clubs.Squad, squadTest clubs.Squad) {
 	assert.Equal(t, squadDB.Tactic, squadTest.Tactic)
 	assert.Equal(t, squadDB.Formation, squadTest.Formation)
 	assert.Equal(t, squadDB.CaptainID, squadDB.CaptainID)
+	assert.Equal(t, squadDB.Name, squadTest.Name)
+	assert.Equal(t, squadDB.IsActive, squadTest.IsActive)
 }
 
 func comparePlayers(t *testing.T, playersDB []clubs.SquadCard, playersTest []clubs.SquadCard) {
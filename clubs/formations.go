@@ -0,0 +1,212 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package clubs
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+)
+
+// ErrNoCustomFormation indicated that custom formation does not exist.
+var ErrNoCustomFormation = errs.Class("custom formation does not exist")
+
+// FirstCustomFormation defines the first id of custom formations, ids of the built-in formations are less than it.
+const FirstCustomFormation Formation = 1000
+
+// MaxCustomFormations defines the max number of custom formations of the club.
+const MaxCustomFormations = 10
+
+// bounds of the number of cards in each line of the custom formation.
+const (
+	minDefenders   = 3
+	maxDefenders   = 5
+	minMidfielders = 2
+	maxMidfielders = 6
+	minAttackers   = 1
+	maxAttackers   = 4
+)
+
+// CustomFormation describes formation created by the club from the 11 positions.
+type CustomFormation struct {
+	ID        Formation  `json:"id"`
+	ClubID    uuid.UUID  `json:"clubId"`
+	Name      string     `json:"name"`
+	Positions []Position `json:"positions"`
+}
+
+// Line defines a list of possible lines of the squad in the field.
+type Line int
+
+const (
+	// LineGoalkeeper defines goalkeeper line.
+	LineGoalkeeper Line = 0
+	// LineDefence defines defence line.
+	LineDefence Line = 1
+	// LineMidfield defines midfield line.
+	LineMidfield Line = 2
+	// LineAttack defines attack line.
+	LineAttack Line = 3
+)
+
+// Line returns the line of the squad to which the position belongs.
+func (position Position) Line() Line {
+	switch position {
+	case LB, LCD, CCD, RCD, RB, LWB, RWB:
+		return LineDefence
+	case LCDM, CCDM, RCDM, LCM, CCM, RCM, LM, RM, LCAM, CCAM, RCAM:
+		return LineMidfield
+	case LW, RW, LST, RST, CST:
+		return LineAttack
+	default:
+		return LineGoalkeeper
+	}
+}
+
+// IsValid checks if position exists.
+func (position Position) IsValid() bool {
+	return position >= GK && position <= CST
+}
+
+// ValidatePositions checks that positions make a valid formation: 11 different positions, exactly one goalkeeper
+// and the number of defenders, midfielders and attackers in bounds.
+func ValidatePositions(positions []Position) error {
+	if len(positions) != SquadSize {
+		return ErrInvalidOperation.New("formation must have %d positions", SquadSize)
+	}
+
+	lines := make(map[Line]int)
+	unique := make(map[Position]bool, len(positions))
+	for _, position := range positions {
+		if !position.IsValid() {
+			return ErrInvalidOperation.New("position %d is not valid", position)
+		}
+		if unique[position] {
+			return ErrInvalidOperation.New("position %d is repeated", position)
+		}
+		unique[position] = true
+		lines[position.Line()]++
+	}
+
+	switch {
+	case lines[LineGoalkeeper] != 1:
+		return ErrInvalidOperation.New("formation must have exactly one goalkeeper")
+	case lines[LineDefence] < minDefenders || lines[LineDefence] > maxDefenders:
+		return ErrInvalidOperation.New("formation must have from %d to %d defenders", minDefenders, maxDefenders)
+	case lines[LineMidfield] < minMidfielders || lines[LineMidfield] > maxMidfielders:
+		return ErrInvalidOperation.New("formation must have from %d to %d midfielders", minMidfielders, maxMidfielders)
+	case lines[LineAttack] < minAttackers || lines[LineAttack] > maxAttackers:
+		return ErrInvalidOperation.New("formation must have from %d to %d attackers", minAttackers, maxAttackers)
+	}
+
+	return nil
+}
+
+// CreateCustomFormation creates custom formation of the user's club.
+func (service *Service) CreateCustomFormation(ctx context.Context, userID, clubID uuid.UUID, name string, positions []Position) (Formation, error) {
+	if _, err := service.clubOfUser(ctx, userID, clubID); err != nil {
+		return 0, err
+	}
+
+	return service.createCustomFormation(ctx, clubID, name, positions)
+}
+
+// createCustomFormation creates custom formation of the club.
+func (service *Service) createCustomFormation(ctx context.Context, clubID uuid.UUID, name string, positions []Position) (Formation, error) {
+	if err := ValidatePositions(positions); err != nil {
+		return 0, err
+	}
+
+	formations, err := service.clubs.ListCustomFormations(ctx, clubID)
+	if err != nil {
+		return 0, ErrClubs.Wrap(err)
+	}
+
+	if len(formations) >= MaxCustomFormations {
+		return 0, ErrInvalidOperation.New("club could not have more than %d custom formations", MaxCustomFormations)
+	}
+
+	formation := CustomFormation{
+		ClubID:    clubID,
+		Name:      name,
+		Positions: positions,
+	}
+
+	id, err := service.clubs.CreateCustomFormation(ctx, formation)
+	return id, ErrClubs.Wrap(err)
+}
+
+// ListCustomFormations returns all custom formations of the user's club.
+func (service *Service) ListCustomFormations(ctx context.Context, userID, clubID uuid.UUID) ([]CustomFormation, error) {
+	if _, err := service.clubOfUser(ctx, userID, clubID); err != nil {
+		return nil, err
+	}
+
+	formations, err := service.clubs.ListCustomFormations(ctx, clubID)
+	return formations, ErrClubs.Wrap(err)
+}
+
+// DeleteCustomFormation deletes custom formation of the user's club, formation which is used by the squad could not be deleted.
+func (service *Service) DeleteCustomFormation(ctx context.Context, userID, clubID uuid.UUID, id Formation) error {
+	if _, err := service.clubOfUser(ctx, userID, clubID); err != nil {
+		return err
+	}
+
+	formation, err := service.clubs.GetCustomFormation(ctx, id)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+
+	if formation.ClubID != clubID {
+		return ErrInvalidOperation.New("formation does not belong to club")
+	}
+
+	squads, err := service.clubs.ListSquadsByClubID(ctx, clubID)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+
+	for _, squad := range squads {
+		if squad.Formation == id {
+			return ErrInvalidOperation.New("formation is used by squad %s", squad.Name)
+		}
+	}
+
+	return ErrClubs.Wrap(service.clubs.DeleteCustomFormation(ctx, id))
+}
+
+// FormationPositions returns positions of the formation of the club, built-in or custom one.
+func (service *Service) FormationPositions(ctx context.Context, clubID uuid.UUID, formation Formation) ([]Position, error) {
+	if !formation.IsCustom() {
+		if !formation.IsValid() {
+			return nil, ErrInvalidOperation.New("formation is not correct")
+		}
+
+		// positions are copied, so callers could sort them without changing the built-in formation.
+		return append([]Position(nil), FormationToPosition[formation]...), nil
+	}
+
+	customFormation, err := service.clubs.GetCustomFormation(ctx, formation)
+	if err != nil {
+		return nil, ErrClubs.Wrap(err)
+	}
+
+	if customFormation.ClubID != clubID {
+		return nil, ErrInvalidOperation.New("formation does not belong to club")
+	}
+
+	return customFormation.Positions, nil
+}
+
+// squadFormationPositions returns formation and its positions of the squad.
+func (service *Service) squadFormationPositions(ctx context.Context, squadID uuid.UUID) (Formation, []Position, error) {
+	squad, err := service.clubs.GetSquad(ctx, squadID)
+	if err != nil {
+		return 0, nil, ErrClubs.Wrap(err)
+	}
+
+	positions, err := service.FormationPositions(ctx, squad.ClubID, squad.Formation)
+	return squad.Formation, positions, ErrClubs.Wrap(err)
+}
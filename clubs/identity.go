@@ -0,0 +1,73 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package clubs
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// Crest describes components of the club crest, each component is the number of the image in its folder.
+type Crest struct {
+	Shape  int `json:"shape"`
+	Symbol int `json:"symbol"`
+}
+
+// Kit describes kit colours of the club as hex strings without '#', e.g. "1E90FF".
+type Kit struct {
+	PrimaryColor   string `json:"primaryColor"`
+	SecondaryColor string `json:"secondaryColor"`
+}
+
+var (
+	// DefaultCrest defines crest of the club which has not chosen its own one.
+	DefaultCrest = Crest{Shape: 1, Symbol: 1}
+	// DefaultKit defines kit colours of the club which has not chosen its own ones.
+	DefaultKit = Kit{PrimaryColor: "FFFFFF", SecondaryColor: "000000"}
+)
+
+// hexColor matches colours in hex format without '#'.
+var hexColor = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+// Validate checks if kit colours are valid hex colours.
+func (kit Kit) Validate() error {
+	if !hexColor.MatchString(kit.PrimaryColor) || !hexColor.MatchString(kit.SecondaryColor) {
+		return ErrInvalidOperation.New("kit colours must be hex colours without '#'")
+	}
+
+	return nil
+}
+
+// UpdateIdentity updates crest, kit colours and badge url of the club.
+func (service *Service) UpdateIdentity(ctx context.Context, clubID uuid.UUID, crest Crest, kit Kit, badgeURL string) error {
+	if err := kit.Validate(); err != nil {
+		return err
+	}
+
+	club := Club{
+		ID:       clubID,
+		Crest:    crest,
+		Kit:      kit,
+		BadgeURL: badgeURL,
+	}
+
+	return ErrClubs.Wrap(service.clubs.UpdateIdentity(ctx, club))
+}
+
+// GetBadgeURLBySquadID returns badge url of the club which owns the squad.
+func (service *Service) GetBadgeURLBySquadID(ctx context.Context, squadID uuid.UUID) (string, error) {
+	squad, err := service.clubs.GetSquad(ctx, squadID)
+	if err != nil {
+		return "", ErrClubs.Wrap(err)
+	}
+
+	club, err := service.clubs.Get(ctx, squad.ClubID)
+	if err != nil {
+		return "", ErrClubs.Wrap(err)
+	}
+
+	return club.BadgeURL, nil
+}
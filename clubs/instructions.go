@@ -0,0 +1,204 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package clubs
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Intensity defines a list of possible values of the tactical settings of the squad.
+type Intensity int
+
+const (
+	// IntensityLow defines low pressing, narrow width, slow tempo or deep defensive line.
+	IntensityLow Intensity = 1
+	// IntensityMedium defines the default value of the setting.
+	IntensityMedium Intensity = 2
+	// IntensityHigh defines high pressing, wide width, fast tempo or high defensive line.
+	IntensityHigh Intensity = 3
+)
+
+// IsValid checks if intensity is valid.
+func (intensity Intensity) IsValid() bool {
+	return intensity >= IntensityLow && intensity <= IntensityHigh
+}
+
+// TeamInstructions describes tactical settings of the squad.
+type TeamInstructions struct {
+	Pressing      Intensity `json:"pressing"`
+	Width         Intensity `json:"width"`
+	Tempo         Intensity `json:"tempo"`
+	DefensiveLine Intensity `json:"defensiveLine"`
+}
+
+// DefaultTeamInstructions defines tactical settings of the new squads.
+var DefaultTeamInstructions = TeamInstructions{
+	Pressing:      IntensityMedium,
+	Width:         IntensityMedium,
+	Tempo:         IntensityMedium,
+	DefensiveLine: IntensityMedium,
+}
+
+// Validate checks that all tactical settings are valid.
+func (instructions TeamInstructions) Validate() error {
+	if !instructions.Pressing.IsValid() {
+		return ErrInvalidOperation.New("pressing is not correct")
+	}
+	if !instructions.Width.IsValid() {
+		return ErrInvalidOperation.New("width is not correct")
+	}
+	if !instructions.Tempo.IsValid() {
+		return ErrInvalidOperation.New("tempo is not correct")
+	}
+	if !instructions.DefensiveLine.IsValid() {
+		return ErrInvalidOperation.New("defensive line is not correct")
+	}
+
+	return nil
+}
+
+// Instruction defines a list of possible instructions for the card of the squad.
+type Instruction string
+
+const (
+	// InstructionNone indicates that the card follows the tactic of the squad.
+	InstructionNone Instruction = ""
+	// InstructionStayBack indicates that the card stays back and helps in defence.
+	InstructionStayBack Instruction = "stay_back"
+	// InstructionGetForward indicates that the card gets forward and joins the attack.
+	InstructionGetForward Instruction = "get_forward"
+)
+
+// IsValid checks if instruction is valid.
+func (instruction Instruction) IsValid() bool {
+	return instruction == InstructionNone || instruction == InstructionStayBack || instruction == InstructionGetForward
+}
+
+// PlayerInstruction describes instruction for the card of the squad.
+type PlayerInstruction struct {
+	SquadID     uuid.UUID   `json:"squadId"`
+	CardID      uuid.UUID   `json:"cardId"`
+	Instruction Instruction `json:"instruction"`
+}
+
+// SquadInstructions describes tactical settings of the squad and instructions for its cards.
+type SquadInstructions struct {
+	Team    TeamInstructions    `json:"team"`
+	Players []PlayerInstruction `json:"players"`
+}
+
+// GetUserSquad returns squad of the user, returns ErrInvalidOperation if the squad belongs to another user.
+func (service *Service) GetUserSquad(ctx context.Context, userID, squadID uuid.UUID) (Squad, error) {
+	return service.squadOfUser(ctx, userID, squadID)
+}
+
+// squadOfUser returns squad if it belongs to the user.
+func (service *Service) squadOfUser(ctx context.Context, userID, squadID uuid.UUID) (Squad, error) {
+	squad, err := service.clubs.GetSquad(ctx, squadID)
+	if err != nil {
+		return Squad{}, ErrClubs.Wrap(err)
+	}
+
+	club, err := service.clubs.Get(ctx, squad.ClubID)
+	if err != nil {
+		return Squad{}, ErrClubs.Wrap(err)
+	}
+
+	if club.OwnerID != userID {
+		return Squad{}, ErrInvalidOperation.New("squad does not belong to user")
+	}
+
+	return squad, nil
+}
+
+// clubOfUser returns club if it belongs to the user.
+func (service *Service) clubOfUser(ctx context.Context, userID, clubID uuid.UUID) (Club, error) {
+	club, err := service.clubs.Get(ctx, clubID)
+	if err != nil {
+		return Club{}, ErrClubs.Wrap(err)
+	}
+
+	if club.OwnerID != userID {
+		return Club{}, ErrInvalidOperation.New("club does not belong to user")
+	}
+
+	return club, nil
+}
+
+// UpdateTeamInstructions updates tactical settings of the user's squad.
+func (service *Service) UpdateTeamInstructions(ctx context.Context, userID, squadID uuid.UUID, instructions TeamInstructions) error {
+	if err := instructions.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := service.squadOfUser(ctx, userID, squadID); err != nil {
+		return err
+	}
+
+	return ErrClubs.Wrap(service.clubs.UpdateTeamInstructions(ctx, squadID, instructions))
+}
+
+// SetPlayerInstruction sets instruction for the card of the user's squad, none instruction removes the previous one.
+func (service *Service) SetPlayerInstruction(ctx context.Context, userID uuid.UUID, instruction PlayerInstruction) error {
+	if !instruction.Instruction.IsValid() {
+		return ErrInvalidOperation.New("instruction is not correct")
+	}
+
+	if _, err := service.squadOfUser(ctx, userID, instruction.SquadID); err != nil {
+		return err
+	}
+
+	squadCards, err := service.clubs.ListSquadCards(ctx, instruction.SquadID)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+
+	var squadCard *SquadCard
+	for i := range squadCards {
+		if squadCards[i].CardID == instruction.CardID {
+			squadCard = &squadCards[i]
+			break
+		}
+	}
+	if squadCard == nil {
+		return ErrNoSquadCard.New("card is not in the starting lineup")
+	}
+
+	if squadCard.Position == GK && instruction.Instruction == InstructionGetForward {
+		return ErrInvalidOperation.New("goalkeeper could not get forward")
+	}
+
+	if instruction.Instruction == InstructionNone {
+		err = service.clubs.DeletePlayerInstruction(ctx, instruction.SquadID, instruction.CardID)
+		if ErrNoSquadCard.Has(err) {
+			return nil
+		}
+		return ErrClubs.Wrap(err)
+	}
+
+	return ErrClubs.Wrap(service.clubs.UpsertPlayerInstruction(ctx, instruction))
+}
+
+// GetInstructions returns tactical settings and instructions for the cards of the user's squad.
+func (service *Service) GetInstructions(ctx context.Context, userID, squadID uuid.UUID) (SquadInstructions, error) {
+	squad, err := service.squadOfUser(ctx, userID, squadID)
+	if err != nil {
+		return SquadInstructions{}, err
+	}
+
+	players, err := service.clubs.ListPlayerInstructions(ctx, squadID)
+	if err != nil {
+		return SquadInstructions{}, ErrClubs.Wrap(err)
+	}
+
+	return SquadInstructions{Team: squad.Instructions, Players: players}, nil
+}
+
+// ListPlayerInstructions returns instructions for the cards of the squad.
+func (service *Service) ListPlayerInstructions(ctx context.Context, squadID uuid.UUID) ([]PlayerInstruction, error) {
+	instructions, err := service.clubs.ListPlayerInstructions(ctx, squadID)
+	return instructions, ErrClubs.Wrap(err)
+}
@@ -0,0 +1,224 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package clubs
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"ultimatedivision/cards"
+	"ultimatedivision/pkg/assignment"
+	"ultimatedivision/pkg/pagination"
+)
+
+// Lineup describes the best squad which could be built from the user's cards for the formation.
+type Lineup struct {
+	Formation     Formation   `json:"formation"`
+	SquadCards    []SquadCard `json:"squadCards"`
+	Substitutes   []uuid.UUID `json:"substitutes"`
+	Effectiveness float64     `json:"effectiveness"`
+}
+
+// positionEffectiveness returns effectiveness of the card in the position.
+func (service *Service) positionEffectiveness(card cards.Card, position Position) float64 {
+	switch position {
+	case GK:
+		return service.cards.EffectivenessGK(card)
+	case LB, RB, LWB, RWB:
+		return service.cards.EffectivenessLBorRB(card)
+	case CCD, LCD, RCD:
+		return service.cards.EffectivenessCD(card)
+	case CCDM, LCDM, RCDM:
+		return service.cards.EffectivenessCDM(card)
+	case CCM, LCM, RCM:
+		return service.cards.EffectivenessCM(card)
+	case CCAM, LCAM, RCAM:
+		return service.cards.EffectivenessCAM(card)
+	case LM, RM:
+		return service.cards.EffectivenessRMorLM(card)
+	case LW, RW:
+		return service.cards.EffectivenessRWorLW(card)
+	case CST, RST, LST:
+		return service.cards.EffectivenessST(card)
+	default:
+		return 0
+	}
+}
+
+// listAvailableCards returns all active cards of the user, cards on sale or retired could not be picked to the squad.
+func (service *Service) listAvailableCards(ctx context.Context, userID uuid.UUID) ([]cards.Card, error) {
+	var availableCards []cards.Card
+
+	cursor := pagination.Cursor{Page: 1}
+	for {
+		page, err := service.cards.ListByUserID(ctx, userID, cursor)
+		if err != nil {
+			return nil, ErrClubs.Wrap(err)
+		}
+
+		for _, card := range page.Cards {
+			if card.Status == cards.StatusActive {
+				availableCards = append(availableCards, card)
+			}
+		}
+
+		if page.Page.NextToken == "" {
+			return availableCards, nil
+		}
+		cursor.Token = page.Page.NextToken
+	}
+}
+
+// pickLineup assigns cards to the positions of the formation so the total effectiveness is maximal,
+// the best of the remaining cards are put on the bench.
+func (service *Service) pickLineup(availableCards []cards.Card, formation Formation, positions []Position) Lineup {
+
+	weights := make([][]float64, len(positions))
+	for i, position := range positions {
+		weights[i] = make([]float64, len(availableCards))
+		for j, card := range availableCards {
+			weights[i][j] = service.positionEffectiveness(card, position)
+		}
+	}
+
+	lineup := Lineup{Formation: formation}
+	picked := make(map[int]bool, len(positions))
+	for i, j := range assignment.Maximize(weights) {
+		if j < 0 {
+			continue
+		}
+
+		picked[j] = true
+		lineup.Effectiveness += weights[i][j]
+		lineup.SquadCards = append(lineup.SquadCards, SquadCard{
+			CardID:   availableCards[j].ID,
+			Position: positions[i],
+		})
+	}
+
+	// substitutes are ranked by effectiveness in their best position of the formation.
+	type substitute struct {
+		cardID        uuid.UUID
+		effectiveness float64
+	}
+	var substitutes []substitute
+	for j, card := range availableCards {
+		if picked[j] {
+			continue
+		}
+
+		var best float64
+		for i := range positions {
+			if weights[i][j] > best {
+				best = weights[i][j]
+			}
+		}
+		substitutes = append(substitutes, substitute{cardID: card.ID, effectiveness: best})
+	}
+
+	sort.SliceStable(substitutes, func(i, j int) bool {
+		return substitutes[i].effectiveness > substitutes[j].effectiveness
+	})
+	for i := 0; i < len(substitutes) && i < MaxSubstitutes; i++ {
+		lineup.Substitutes = append(lineup.Substitutes, substitutes[i].cardID)
+	}
+
+	return lineup
+}
+
+// AutoPick returns the best lineup of the user's cards for the formation, custom formations are taken from the active club.
+func (service *Service) AutoPick(ctx context.Context, userID uuid.UUID, formation Formation) (Lineup, error) {
+	var clubID uuid.UUID
+	if formation.IsCustom() {
+		squad, err := service.GetActiveSquadByUserID(ctx, userID)
+		if err != nil {
+			return Lineup{}, ErrClubs.Wrap(err)
+		}
+		clubID = squad.ClubID
+	}
+
+	positions, err := service.FormationPositions(ctx, clubID, formation)
+	if err != nil {
+		return Lineup{}, ErrClubs.Wrap(err)
+	}
+
+	availableCards, err := service.listAvailableCards(ctx, userID)
+	if err != nil {
+		return Lineup{}, ErrClubs.Wrap(err)
+	}
+
+	return service.pickLineup(availableCards, formation, positions), nil
+}
+
+// SuggestFormation returns the lineup of the formation in which the user's cards are the most effective.
+func (service *Service) SuggestFormation(ctx context.Context, userID uuid.UUID) (Lineup, error) {
+	availableCards, err := service.listAvailableCards(ctx, userID)
+	if err != nil {
+		return Lineup{}, ErrClubs.Wrap(err)
+	}
+
+	formations := make([]Formation, 0, len(FormationToPosition))
+	for formation := range FormationToPosition {
+		formations = append(formations, formation)
+	}
+	sort.Slice(formations, func(i, j int) bool {
+		return formations[i] < formations[j]
+	})
+
+	var best Lineup
+	for _, formation := range formations {
+		lineup := service.pickLineup(availableCards, formation, FormationToPosition[formation])
+		if best.Formation == 0 || lineup.Effectiveness > best.Effectiveness {
+			best = lineup
+		}
+	}
+
+	return best, nil
+}
+
+// AutoFillSquad replaces cards and substitutes of the squad by the best lineup of the user's cards for the formation.
+func (service *Service) AutoFillSquad(ctx context.Context, userID, squadID uuid.UUID, formation Formation) (Lineup, error) {
+	squad, err := service.clubs.GetSquad(ctx, squadID)
+	if err != nil {
+		return Lineup{}, ErrClubs.Wrap(err)
+	}
+
+	club, err := service.clubs.Get(ctx, squad.ClubID)
+	if err != nil {
+		return Lineup{}, ErrClubs.Wrap(err)
+	}
+
+	if club.OwnerID != userID {
+		return Lineup{}, ErrInvalidOperation.New("squad does not belong to user")
+	}
+
+	positions, err := service.FormationPositions(ctx, club.ID, formation)
+	if err != nil {
+		return Lineup{}, ErrClubs.Wrap(err)
+	}
+
+	availableCards, err := service.listAvailableCards(ctx, userID)
+	if err != nil {
+		return Lineup{}, ErrClubs.Wrap(err)
+	}
+
+	lineup := service.pickLineup(availableCards, formation, positions)
+
+	if err = service.replaceLineup(ctx, squadID, &lineup); err != nil {
+		return Lineup{}, err
+	}
+
+	return lineup, nil
+}
+
+// replaceLineup replaces formation, cards and substitutes of the squad by the lineup.
+func (service *Service) replaceLineup(ctx context.Context, squadID uuid.UUID, lineup *Lineup) error {
+	for i := range lineup.SquadCards {
+		lineup.SquadCards[i].SquadID = squadID
+	}
+
+	return ErrClubs.Wrap(service.clubs.ReplaceLineup(ctx, squadID, *lineup))
+}
@@ -60,6 +60,8 @@ func (service *Service) Create(ctx context.Context, userID uuid.UUID) (uuid.UUID
 		Name:       nickname,
 		CreatedAt:  time.Now().UTC(),
 		DivisionID: division.ID,
+		Crest:      DefaultCrest,
+		Kit:        DefaultKit,
 	}
 
 	allClubs, err := service.ListByUserID(ctx, userID)
@@ -90,13 +92,29 @@ func (service *Service) ListByDivision(ctx context.Context, division divisions.D
 	return clubs, ErrClubs.Wrap(err)
 }
 
-// CreateSquad creates new squad for club.
-func (service *Service) CreateSquad(ctx context.Context, clubID uuid.UUID) (uuid.UUID, error) {
+// CreateSquad creates new named lineup preset for club, the first squad of the club becomes active.
+func (service *Service) CreateSquad(ctx context.Context, clubID uuid.UUID, name string) (uuid.UUID, error) {
+	squads, err := service.clubs.ListSquadsByClubID(ctx, clubID)
+	if err != nil {
+		return uuid.Nil, ErrClubs.Wrap(err)
+	}
+
+	if len(squads) >= MaxSquads {
+		return uuid.Nil, ErrInvalidOperation.New("club could not have more than %d squads", MaxSquads)
+	}
+
+	if name == "" {
+		name = DefaultSquadName
+	}
+
 	newSquad := Squad{
-		ID:        uuid.New(),
-		ClubID:    clubID,
-		Formation: FourFourTwo,
-		Tactic:    Balanced,
+		ID:           uuid.New(),
+		Name:         name,
+		ClubID:       clubID,
+		Formation:    FourFourTwo,
+		Tactic:       Balanced,
+		IsActive:     len(squads) == 0,
+		Instructions: DefaultTeamInstructions,
 	}
 
 	squadID, err := service.clubs.CreateSquad(ctx, newSquad)
@@ -104,6 +122,72 @@ func (service *Service) CreateSquad(ctx context.Context, clubID uuid.UUID) (uuid
 	return squadID, ErrClubs.Wrap(err)
 }
 
+// ListSquads returns all lineup presets of the user's club.
+func (service *Service) ListSquads(ctx context.Context, userID, clubID uuid.UUID) ([]Squad, error) {
+	if _, err := service.clubOfUser(ctx, userID, clubID); err != nil {
+		return nil, err
+	}
+
+	squads, err := service.clubs.ListSquadsByClubID(ctx, clubID)
+	return squads, ErrClubs.Wrap(err)
+}
+
+// SetActiveSquad makes squad the active lineup preset of the user's club.
+func (service *Service) SetActiveSquad(ctx context.Context, userID, clubID, squadID uuid.UUID) error {
+	if _, err := service.clubOfUser(ctx, userID, clubID); err != nil {
+		return err
+	}
+
+	squad, err := service.clubs.GetSquad(ctx, squadID)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+
+	if squad.ClubID != clubID {
+		return ErrInvalidOperation.New("squad does not belong to club")
+	}
+
+	return ErrClubs.Wrap(service.clubs.UpdateActiveSquad(ctx, clubID, squadID))
+}
+
+// DeleteSquad deletes lineup preset of the user's club, active squad could not be deleted.
+func (service *Service) DeleteSquad(ctx context.Context, userID, clubID, squadID uuid.UUID) error {
+	if _, err := service.clubOfUser(ctx, userID, clubID); err != nil {
+		return err
+	}
+
+	squad, err := service.clubs.GetSquad(ctx, squadID)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+
+	if squad.ClubID != clubID {
+		return ErrInvalidOperation.New("squad does not belong to club")
+	}
+	if squad.IsActive {
+		return ErrInvalidOperation.New("active squad could not be deleted")
+	}
+
+	return ErrClubs.Wrap(service.clubs.DeleteSquad(ctx, squadID))
+}
+
+// GetActiveSquadByUserID returns active squad of the active club of the user.
+func (service *Service) GetActiveSquadByUserID(ctx context.Context, userID uuid.UUID) (Squad, error) {
+	allClubs, err := service.clubs.ListByUserID(ctx, userID)
+	if err != nil {
+		return Squad{}, ErrClubs.Wrap(err)
+	}
+
+	for _, club := range allClubs {
+		if club.Status == StatusActive {
+			squad, err := service.clubs.GetSquadByClubID(ctx, club.ID)
+			return squad, ErrClubs.Wrap(err)
+		}
+	}
+
+	return Squad{}, ErrNoClub.New("user does not have active club")
+}
+
 // AddSquadCard adds card to the squad.
 func (service *Service) AddSquadCard(ctx context.Context, userID, squadID uuid.UUID, newSquadCard SquadCard) error {
 	card, err := service.cards.Get(ctx, newSquadCard.CardID)
@@ -124,13 +208,22 @@ func (service *Service) AddSquadCard(ctx context.Context, userID, squadID uuid.U
 		return ErrClubs.New("squad is full")
 	}
 
-	formation, err := service.clubs.GetFormation(ctx, squadID)
+	_, positions, err := service.squadFormationPositions(ctx, squadID)
 	if err != nil {
 		return ErrClubs.Wrap(err)
 	}
 
+	if newSquadCard.Position < 0 || int(newSquadCard.Position) >= len(positions) {
+		return ErrInvalidOperation.New("position is not in the formation")
+	}
+
 	newSquadCard.SquadID = squadID
-	newSquadCard.Position = FormationToPosition[formation][newSquadCard.Position]
+	newSquadCard.Position = positions[newSquadCard.Position]
+
+	// card could not be in the starting lineup and on the bench at the same time.
+	if err = service.clubs.DeleteSubstitute(ctx, squadID, newSquadCard.CardID); err != nil && !ErrNoSquadCard.Has(err) {
+		return ErrClubs.Wrap(err)
+	}
 
 	for _, card := range squadCards {
 		if card.Position != newSquadCard.Position {
@@ -152,6 +245,95 @@ func (service *Service) Delete(ctx context.Context, squadID, cardID uuid.UUID) e
 	return ErrClubs.Wrap(service.clubs.DeleteSquadCard(ctx, squadID, cardID))
 }
 
+// AddSubstitute adds card to the bench of the user's squad, new substitute has the lowest priority.
+// Cards which are on sale or retired could not be added.
+func (service *Service) AddSubstitute(ctx context.Context, userID, squadID, cardID uuid.UUID) error {
+	if _, err := service.squadOfUser(ctx, userID, squadID); err != nil {
+		return err
+	}
+
+	var report SquadReport
+	_, available, err := service.availableCard(ctx, userID, cardID, 0, &report)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return ErrInvalidOperation.New("%s", report.Problems[0].Message)
+	}
+
+	squadCards, err := service.clubs.ListSquadCards(ctx, squadID)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+	for _, squadCard := range squadCards {
+		if squadCard.CardID == cardID {
+			return ErrInvalidOperation.New("card is already in the squad")
+		}
+	}
+
+	substitutes, err := service.clubs.ListSubstitutes(ctx, squadID)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+
+	if len(substitutes) >= MaxSubstitutes {
+		return ErrInvalidOperation.New("bench is full")
+	}
+
+	priority := 1
+	for _, substitute := range substitutes {
+		if substitute.CardID == cardID {
+			return ErrInvalidOperation.New("card is already on the bench")
+		}
+		if substitute.Priority >= priority {
+			priority = substitute.Priority + 1
+		}
+	}
+
+	substitute := SquadSubstitute{
+		SquadID:  squadID,
+		CardID:   cardID,
+		Priority: priority,
+	}
+
+	return ErrClubs.Wrap(service.clubs.AddSubstitute(ctx, substitute))
+}
+
+// DeleteSubstitute deletes card from the bench of the user's squad.
+func (service *Service) DeleteSubstitute(ctx context.Context, userID, squadID, cardID uuid.UUID) error {
+	if _, err := service.squadOfUser(ctx, userID, squadID); err != nil {
+		return err
+	}
+
+	return ErrClubs.Wrap(service.clubs.DeleteSubstitute(ctx, squadID, cardID))
+}
+
+// ListSubstitutes returns substitutes of the squad ordered by priority.
+func (service *Service) ListSubstitutes(ctx context.Context, squadID uuid.UUID) ([]SquadSubstitute, error) {
+	substitutes, err := service.clubs.ListSubstitutes(ctx, squadID)
+	return substitutes, ErrClubs.Wrap(err)
+}
+
+// ListSubstituteCards returns cards from the bench of the squad ordered by priority.
+func (service *Service) ListSubstituteCards(ctx context.Context, squadID uuid.UUID) ([]cards.Card, error) {
+	substitutes, err := service.clubs.ListSubstitutes(ctx, squadID)
+	if err != nil {
+		return nil, ErrClubs.Wrap(err)
+	}
+
+	substituteCards := make([]cards.Card, 0, len(substitutes))
+	for _, substitute := range substitutes {
+		card, err := service.cards.Get(ctx, substitute.CardID)
+		if err != nil {
+			return substituteCards, ErrClubs.Wrap(err)
+		}
+
+		substituteCards = append(substituteCards, card)
+	}
+
+	return substituteCards, nil
+}
+
 // DeleteByCardID deletes card from squad by card id.
 func (service *Service) DeleteByCardID(ctx context.Context, cardID uuid.UUID) error {
 	return ErrClubs.Wrap(service.clubs.DeleteByCardID(ctx, cardID))
@@ -184,12 +366,16 @@ func (service *Service) UpdateCardPosition(ctx context.Context, squadID uuid.UUI
 		}
 	}
 
-	formation, err := service.clubs.GetFormation(ctx, squadID)
+	_, positions, err := service.squadFormationPositions(ctx, squadID)
 	if err != nil {
 		return ErrClubs.Wrap(err)
 	}
 
-	newPosition = FormationToPosition[formation][newPosition]
+	if newPosition < 0 || int(newPosition) >= len(positions) {
+		return ErrInvalidOperation.New("position is not in the formation")
+	}
+
+	newPosition = positions[newPosition]
 
 	updatedCards := make([]SquadCard, 0, 2)
 
@@ -239,12 +425,12 @@ func (service *Service) ListSquadCardIDs(ctx context.Context, squadID uuid.UUID)
 		return squadCards, ErrClubs.Wrap(err)
 	}
 
-	formation, err := service.clubs.GetFormation(ctx, squadID)
+	_, positions, err := service.squadFormationPositions(ctx, squadID)
 	if err != nil {
 		return nil, ErrClubs.Wrap(err)
 	}
 
-	squadCards = convertPositions(squadCards, formation)
+	squadCards = convertPositions(squadCards, positions)
 
 	if len(squadCards) < SquadSize {
 		for i := 0; i < SquadSize; i++ {
@@ -274,6 +460,13 @@ func (service *Service) ListSquadCardIDs(ctx context.Context, squadID uuid.UUID)
 	return squadCards, ErrClubs.Wrap(err)
 }
 
+// ListLineup returns card ids from the squad with their positions in the field, unlike ListSquadCardIDs
+// positions are not converted to the 0-10 view of the formation.
+func (service *Service) ListLineup(ctx context.Context, squadID uuid.UUID) ([]SquadCard, error) {
+	squadCards, err := service.clubs.ListSquadCards(ctx, squadID)
+	return squadCards, ErrClubs.Wrap(err)
+}
+
 // ListSquadCards returns cards with positions from the squad.
 func (service *Service) ListSquadCards(ctx context.Context, squadID uuid.UUID) ([]GetSquadCard, error) {
 	squadCardIDs, err := service.ListSquadCardIDs(ctx, squadID)
@@ -366,6 +559,16 @@ func (service *Service) Get(ctx context.Context, clubID uuid.UUID) (Club, error)
 func (service *Service) ChangeFormation(ctx context.Context, newFormation Formation, squadID uuid.UUID) error {
 	var cardsWithNewPositions map[Position]uuid.UUID
 
+	squad, err := service.clubs.GetSquad(ctx, squadID)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+
+	positions, err := service.FormationPositions(ctx, squad.ClubID, newFormation)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+
 	squadCards, err := service.clubs.ListSquadCards(ctx, squadID)
 	if err != nil {
 		return ErrClubs.Wrap(err)
@@ -380,7 +583,7 @@ func (service *Service) ChangeFormation(ctx context.Context, newFormation Format
 		return ErrClubs.Wrap(err)
 	}
 
-	cardsWithNewPositions, err = service.CardsWithNewPositions(ctx, squadCards, FormationToPosition[newFormation])
+	cardsWithNewPositions, err = service.CardsWithNewPositions(ctx, squadCards, positions)
 	if err != nil {
 		return ErrClubs.Wrap(err)
 	}
@@ -399,7 +602,7 @@ func (service *Service) ChangeFormation(ctx context.Context, newFormation Format
 	return ErrClubs.Wrap(service.clubs.UpdatePositions(ctx, squadCardsWithNewPositions))
 }
 
-// CalculateEffectivenessOfSquad calculates effectiveness of user's squad.
+// CalculateEffectivenessOfSquad calculates effectiveness of user's squad, increased by its chemistry.
 func (service *Service) CalculateEffectivenessOfSquad(ctx context.Context, squadCards []SquadCard) (float64, error) {
 	var effectiveness float64
 
@@ -407,38 +610,25 @@ func (service *Service) CalculateEffectivenessOfSquad(ctx context.Context, squad
 		return float64(0), nil
 	}
 
-	cardsFromSquad, err := service.cards.GetCardsFromSquadCards(ctx, squadCards[0].SquadID)
+	cardsByID, err := service.cardsOfSquadCards(ctx, squadCards)
 	if err != nil {
-		return float64(0), ErrClubs.Wrap(err)
+		return float64(0), err
 	}
 
-	for index, squadCard := range squadCards {
-		if len(cardsFromSquad)-1 < index {
-			break
-		}
-		switch squadCard.Position {
-		case GK:
-			effectiveness += service.cards.EffectivenessGK(cardsFromSquad[index])
-		case LB, RB, LWB, RWB:
-			effectiveness += service.cards.EffectivenessLBorRB(cardsFromSquad[index])
-		case CCD, LCD, RCD:
-			effectiveness += service.cards.EffectivenessCD(cardsFromSquad[index])
-		case CCDM, LCDM, RCDM:
-			effectiveness += service.cards.EffectivenessCDM(cardsFromSquad[index])
-		case CCM, LCM, RCM:
-			effectiveness += service.cards.EffectivenessCM(cardsFromSquad[index])
-		case CCAM, LCAM, RCAM:
-			effectiveness += service.cards.EffectivenessCAM(cardsFromSquad[index])
-		case LM, RM:
-			effectiveness += service.cards.EffectivenessRMorLM(cardsFromSquad[index])
-		case LW, RW:
-			effectiveness += service.cards.EffectivenessRWorLW(cardsFromSquad[index])
-		case CST, RST, LST:
-			effectiveness += service.cards.EffectivenessST(cardsFromSquad[index])
+	for _, squadCard := range squadCards {
+		if squadCard.CardID == uuid.Nil {
+			continue
 		}
+
+		effectiveness += service.positionEffectiveness(cardsByID[squadCard.CardID], squadCard.Position)
+	}
+
+	chemistry, err := service.chemistry(ctx, squadCards, cardsByID)
+	if err != nil {
+		return float64(0), err
 	}
 
-	return effectiveness, nil
+	return effectiveness * (1 + chemistry.Score/MaxChemistry*MaxChemistryBonus), nil
 }
 
 // UpdateStatus updates status of club.
@@ -465,63 +655,27 @@ func RemoveIndex(s []SquadCard, index int) []SquadCard {
 	return append(s[:index], s[index+1:]...)
 }
 
-// EffectiveCardForPosition determines the effective card in the position.
+// EffectiveCardForPosition determines the most effective card in the position and its index in the list,
+// the first of equally effective cards is returned.
 func (service *Service) EffectiveCardForPosition(ctx context.Context, position Position, squadCards []SquadCard) (cards.Card, int, error) {
-	cardCoefficients := make(map[float64]cards.Card)
-	var index int
+	var (
+		effectiveCard cards.Card
+		index         int
+		max           = -1.0
+	)
 
-	for _, squadCard := range squadCards {
+	for i, squadCard := range squadCards {
 		card, err := service.cards.Get(ctx, squadCard.CardID)
 		if err != nil {
-			return card, index, ErrClubs.Wrap(err)
-		}
-		switch position {
-		case GK:
-			coefficient := service.cards.EffectivenessGK(card)
-			cardCoefficients[coefficient] = card
-		case CST, LST, RST:
-			coefficient := service.cards.EffectivenessST(card)
-			cardCoefficients[coefficient] = card
-		case LW, RW:
-			coefficient := service.cards.EffectivenessRWorLW(card)
-			cardCoefficients[coefficient] = card
-		case RM, LM:
-			coefficient := service.cards.EffectivenessRMorLM(card)
-			cardCoefficients[coefficient] = card
-		case CCAM, RCAM, LCAM:
-			coefficient := service.cards.EffectivenessCAM(card)
-			cardCoefficients[coefficient] = card
-		case CCM, LCM, RCM:
-			coefficient := service.cards.EffectivenessCM(card)
-			cardCoefficients[coefficient] = card
-		case CCDM, LCDM, RCDM:
-			coefficient := service.cards.EffectivenessCDM(card)
-			cardCoefficients[coefficient] = card
-		case LB, RB, RWB, LWB:
-			coefficient := service.cards.EffectivenessLBorRB(card)
-			cardCoefficients[coefficient] = card
-		case CCD, LCD, RCD:
-			coefficient := service.cards.EffectivenessCD(card)
-			cardCoefficients[coefficient] = card
+			return cards.Card{}, 0, ErrClubs.Wrap(err)
 		}
-	}
-
-	var max float64
-
-	for coeff := range cardCoefficients {
-		max = coeff
-		if coeff > max {
-			max = coeff
-		}
-	}
 
-	for key, v := range squadCards {
-		if v.CardID == cardCoefficients[max].ID {
-			index = key
+		if effectiveness := service.positionEffectiveness(card, position); effectiveness > max {
+			effectiveCard, index, max = card, i, effectiveness
 		}
 	}
 
-	return cardCoefficients[max], index, nil
+	return effectiveCard, index, nil
 }
 
 // CardsWithNewPositions returns cards with new position by new formation.
@@ -0,0 +1,297 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package clubs
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/cards"
+)
+
+// SquadCodeVersion defines the current version of the squad codes, codes of other versions could not be imported.
+const SquadCodeVersion = 1
+
+// MaxSquadCodeLength defines the max length of the squad code, which protects import from huge payloads.
+const MaxSquadCodeLength = 2048
+
+// importedFormationName defines the name of the custom formation created by the import of the squad.
+const importedFormationName = "Imported"
+
+// CardProfile describes main skills of the card, which are shared instead of the card itself.
+type CardProfile struct {
+	Tactics     int `json:"ta"`
+	Physique    int `json:"ph"`
+	Technique   int `json:"te"`
+	Offence     int `json:"of"`
+	Defence     int `json:"de"`
+	Goalkeeping int `json:"gk"`
+}
+
+// Rating returns overall rating of the profile.
+func (profile CardProfile) Rating() float64 {
+	return float64(profile.Tactics+profile.Physique+profile.Technique+profile.Offence+profile.Defence+profile.Goalkeeping) / cards.RatingSkillsCount
+}
+
+// ExportedPlayer describes the card of the shared squad by its position and profile.
+type ExportedPlayer struct {
+	Position  Position    `json:"p"`
+	Profile   CardProfile `json:"s"`
+	IsCaptain bool        `json:"c,omitempty"`
+}
+
+// SquadExport describes the shared squad, positions are set only for the custom formations.
+type SquadExport struct {
+	Version      int              `json:"v"`
+	Formation    Formation        `json:"f"`
+	Positions    []Position       `json:"p,omitempty"`
+	Tactic       Tactic           `json:"t"`
+	Instructions TeamInstructions `json:"i"`
+	Players      []ExportedPlayer `json:"c"`
+}
+
+// Validate checks that the shared squad could be imported.
+func (export SquadExport) Validate() error {
+	if export.Version != SquadCodeVersion {
+		return ErrInvalidOperation.New("squad code version %d is not supported", export.Version)
+	}
+
+	positions := export.Positions
+	if export.Formation.IsCustom() {
+		if err := ValidatePositions(positions); err != nil {
+			return err
+		}
+	} else {
+		if !export.Formation.IsValid() {
+			return ErrInvalidOperation.New("formation is not correct")
+		}
+		positions = FormationToPosition[export.Formation]
+	}
+
+	if export.Tactic < Attack || export.Tactic > Balanced {
+		return ErrInvalidOperation.New("tactic is not correct")
+	}
+
+	if err := export.Instructions.Validate(); err != nil {
+		return err
+	}
+
+	inFormation := make(map[Position]bool, len(positions))
+	for _, position := range positions {
+		inFormation[position] = true
+	}
+
+	used := make(map[Position]bool, len(export.Players))
+	for _, player := range export.Players {
+		if !inFormation[player.Position] {
+			return ErrInvalidOperation.New("position %d is not in the formation", player.Position)
+		}
+		if used[player.Position] {
+			return ErrInvalidOperation.New("position %d is repeated", player.Position)
+		}
+		used[player.Position] = true
+	}
+
+	return nil
+}
+
+// EncodeSquadCode encodes the shared squad to the compact url-safe code.
+func EncodeSquadCode(export SquadExport) (string, error) {
+	data, err := json.Marshal(export)
+	if err != nil {
+		return "", ErrClubs.Wrap(err)
+	}
+
+	var buffer bytes.Buffer
+	writer, err := flate.NewWriter(&buffer, flate.BestCompression)
+	if err != nil {
+		return "", ErrClubs.Wrap(err)
+	}
+	if _, err = writer.Write(data); err != nil {
+		return "", ErrClubs.Wrap(err)
+	}
+	if err = writer.Close(); err != nil {
+		return "", ErrClubs.Wrap(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buffer.Bytes()), nil
+}
+
+// DecodeSquadCode decodes and validates the shared squad from the code.
+func DecodeSquadCode(code string) (SquadExport, error) {
+	if code == "" || len(code) > MaxSquadCodeLength {
+		return SquadExport{}, ErrInvalidOperation.New("squad code is not correct")
+	}
+
+	compressed, err := base64.RawURLEncoding.DecodeString(code)
+	if err != nil {
+		return SquadExport{}, ErrInvalidOperation.New("squad code is not correct")
+	}
+
+	// decompressed data is limited, so the small code could not be unpacked into the huge payload.
+	reader := flate.NewReader(bytes.NewReader(compressed))
+	data, err := io.ReadAll(io.LimitReader(reader, 16*MaxSquadCodeLength))
+	if err = errs.Combine(err, reader.Close()); err != nil {
+		return SquadExport{}, ErrInvalidOperation.New("squad code is not correct")
+	}
+
+	var export SquadExport
+	if err = json.Unmarshal(data, &export); err != nil {
+		return SquadExport{}, ErrInvalidOperation.New("squad code is not correct")
+	}
+
+	return export, export.Validate()
+}
+
+// ExportSquad returns the code of the user's squad, cards are shared as profiles of their skills.
+func (service *Service) ExportSquad(ctx context.Context, userID, squadID uuid.UUID) (string, error) {
+	squad, err := service.squadOfUser(ctx, userID, squadID)
+	if err != nil {
+		return "", err
+	}
+
+	export := SquadExport{
+		Version:      SquadCodeVersion,
+		Formation:    squad.Formation,
+		Tactic:       squad.Tactic,
+		Instructions: squad.Instructions,
+		Players:      []ExportedPlayer{},
+	}
+
+	if squad.Formation.IsCustom() {
+		if export.Positions, err = service.FormationPositions(ctx, squad.ClubID, squad.Formation); err != nil {
+			return "", ErrClubs.Wrap(err)
+		}
+	}
+
+	squadCards, err := service.clubs.ListSquadCards(ctx, squadID)
+	if err != nil {
+		return "", ErrClubs.Wrap(err)
+	}
+
+	for _, squadCard := range squadCards {
+		card, err := service.cards.Get(ctx, squadCard.CardID)
+		if err != nil {
+			return "", ErrClubs.Wrap(err)
+		}
+
+		export.Players = append(export.Players, ExportedPlayer{
+			Position: squadCard.Position,
+			Profile: CardProfile{
+				Tactics:     card.Tactics,
+				Physique:    card.Physique,
+				Technique:   card.Technique,
+				Offence:     card.Offence,
+				Defence:     card.Defence,
+				Goalkeeping: card.Goalkeeping,
+			},
+			IsCaptain: card.ID == squad.CaptainID,
+		})
+	}
+
+	sort.Slice(export.Players, func(i, j int) bool {
+		return export.Players[i].Position < export.Players[j].Position
+	})
+
+	return EncodeSquadCode(export)
+}
+
+// ImportSquad replaces the lineup of the user's squad by the shared one, the user's available cards are assigned
+// to the shared positions so that the total effectiveness of the lineup is the highest, the rest of the cards
+// are put on the bench. Custom formation is taken from the club if it has the same positions, otherwise it is created.
+func (service *Service) ImportSquad(ctx context.Context, userID, squadID uuid.UUID, code string) (Lineup, error) {
+	export, err := DecodeSquadCode(code)
+	if err != nil {
+		return Lineup{}, err
+	}
+
+	squad, err := service.squadOfUser(ctx, userID, squadID)
+	if err != nil {
+		return Lineup{}, err
+	}
+
+	formation := export.Formation
+	if formation.IsCustom() {
+		if formation, err = service.importedFormation(ctx, squad.ClubID, export.Positions); err != nil {
+			return Lineup{}, err
+		}
+	}
+
+	availableCards, err := service.listAvailableCards(ctx, userID)
+	if err != nil {
+		return Lineup{}, ErrClubs.Wrap(err)
+	}
+
+	var captainPosition Position = -1
+	positions := make([]Position, 0, len(export.Players))
+	for _, player := range export.Players {
+		positions = append(positions, player.Position)
+		if player.IsCaptain {
+			captainPosition = player.Position
+		}
+	}
+
+	lineup := service.pickLineup(availableCards, formation, positions)
+
+	var captainID uuid.UUID
+	for _, squadCard := range lineup.SquadCards {
+		if squadCard.Position == captainPosition {
+			captainID = squadCard.CardID
+		}
+	}
+
+	if err = service.replaceLineup(ctx, squadID, &lineup); err != nil {
+		return Lineup{}, err
+	}
+
+	if err = service.UpdateSquad(ctx, squadID, export.Tactic, captainID); err != nil {
+		return Lineup{}, err
+	}
+
+	if err = service.clubs.UpdateTeamInstructions(ctx, squadID, export.Instructions); err != nil {
+		return Lineup{}, ErrClubs.Wrap(err)
+	}
+
+	return lineup, nil
+}
+
+// importedFormation returns custom formation of the club with the same positions or creates the new one.
+func (service *Service) importedFormation(ctx context.Context, clubID uuid.UUID, positions []Position) (Formation, error) {
+	formations, err := service.clubs.ListCustomFormations(ctx, clubID)
+	if err != nil {
+		return 0, ErrClubs.Wrap(err)
+	}
+
+	wanted := make(map[Position]bool, len(positions))
+	for _, position := range positions {
+		wanted[position] = true
+	}
+
+	for _, formation := range formations {
+		if len(formation.Positions) != len(positions) {
+			continue
+		}
+
+		same := true
+		for _, position := range formation.Positions {
+			if !wanted[position] {
+				same = false
+				break
+			}
+		}
+		if same {
+			return formation.ID, nil
+		}
+	}
+
+	return service.createCustomFormation(ctx, clubID, importedFormationName, positions)
+}
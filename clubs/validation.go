@@ -0,0 +1,238 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package clubs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"ultimatedivision/cards"
+)
+
+// OutOfPositionRatio defines the share of the best effectiveness of the card below which the card
+// is considered to play out of its position.
+const OutOfPositionRatio = 0.8
+
+// ProblemCode defines the list of possible problems of the squad.
+type ProblemCode string
+
+const (
+	// ProblemMissingPosition indicates that there is no card in the position of the formation.
+	ProblemMissingPosition ProblemCode = "missing_position"
+	// ProblemDuplicatePosition indicates that several cards are placed in the same position.
+	ProblemDuplicatePosition ProblemCode = "duplicate_position"
+	// ProblemInvalidPosition indicates that the card is placed in the position which is not in the formation.
+	ProblemInvalidPosition ProblemCode = "invalid_position"
+	// ProblemUnavailableCard indicates that the card is on sale, retired or does not belong to the club owner.
+	ProblemUnavailableCard ProblemCode = "unavailable_card"
+	// ProblemCaptainNotInSquad indicates that the captain is not in the starting lineup.
+	ProblemCaptainNotInSquad ProblemCode = "captain_not_in_squad"
+	// ProblemOutOfPosition indicates that the card plays much worse in its position than in its best one.
+	ProblemOutOfPosition ProblemCode = "out_of_position"
+)
+
+// Severity defines how serious the problem of the squad is.
+type Severity string
+
+const (
+	// SeverityError indicates that the squad could not play until the problem is fixed.
+	SeverityError Severity = "error"
+	// SeverityWarning indicates that the squad could play, but the problem makes it weaker.
+	SeverityWarning Severity = "warning"
+)
+
+// SquadProblem describes single problem of the squad.
+type SquadProblem struct {
+	Code     ProblemCode `json:"code"`
+	Severity Severity    `json:"severity"`
+	Position Position    `json:"position"`
+	CardID   uuid.UUID   `json:"cardId"`
+	Message  string      `json:"message"`
+}
+
+// SquadReport describes health of the squad, the squad is valid if it has no problems with error severity.
+type SquadReport struct {
+	SquadID  uuid.UUID      `json:"squadId"`
+	IsValid  bool           `json:"isValid"`
+	Problems []SquadProblem `json:"problems"`
+}
+
+// add adds the problem to the report.
+func (report *SquadReport) add(problem SquadProblem) {
+	if problem.Severity == SeverityError {
+		report.IsValid = false
+	}
+	report.Problems = append(report.Problems, problem)
+}
+
+// bestPositions defines one position of each role, which is enough to find the best effectiveness of the card.
+var bestPositions = []Position{GK, LB, CCD, CCDM, CCM, CCAM, LM, LW, CST}
+
+// ValidateSquad checks that the squad of the user is ready to play and returns all found problems,
+// the active squad of the user is checked if squad id is not set.
+func (service *Service) ValidateSquad(ctx context.Context, userID, squadID uuid.UUID) (SquadReport, error) {
+	if squadID == uuid.Nil {
+		activeSquad, err := service.GetActiveSquadByUserID(ctx, userID)
+		if err != nil {
+			return SquadReport{}, ErrClubs.Wrap(err)
+		}
+		squadID = activeSquad.ID
+	}
+
+	squad, err := service.clubs.GetSquad(ctx, squadID)
+	if err != nil {
+		return SquadReport{}, ErrClubs.Wrap(err)
+	}
+
+	club, err := service.clubs.Get(ctx, squad.ClubID)
+	if err != nil {
+		return SquadReport{}, ErrClubs.Wrap(err)
+	}
+
+	if club.OwnerID != userID {
+		return SquadReport{}, ErrInvalidOperation.New("squad does not belong to user")
+	}
+
+	positions, err := service.FormationPositions(ctx, club.ID, squad.Formation)
+	if err != nil {
+		return SquadReport{}, ErrClubs.Wrap(err)
+	}
+
+	squadCards, err := service.clubs.ListSquadCards(ctx, squadID)
+	if err != nil {
+		return SquadReport{}, ErrClubs.Wrap(err)
+	}
+
+	substitutes, err := service.clubs.ListSubstitutes(ctx, squadID)
+	if err != nil {
+		return SquadReport{}, ErrClubs.Wrap(err)
+	}
+
+	report := SquadReport{
+		SquadID:  squadID,
+		IsValid:  true,
+		Problems: []SquadProblem{},
+	}
+
+	inFormation := make(map[Position]bool, len(positions))
+	for _, position := range positions {
+		inFormation[position] = true
+	}
+
+	cardsInPosition := make(map[Position]int, len(squadCards))
+	isCaptainInSquad := false
+	for _, squadCard := range squadCards {
+		cardsInPosition[squadCard.Position]++
+		if squadCard.CardID == squad.CaptainID {
+			isCaptainInSquad = true
+		}
+
+		if !inFormation[squadCard.Position] {
+			report.add(SquadProblem{
+				Code:     ProblemInvalidPosition,
+				Severity: SeverityError,
+				Position: squadCard.Position,
+				CardID:   squadCard.CardID,
+				Message:  "position is not in the formation of the squad",
+			})
+		}
+
+		card, available, err := service.availableCard(ctx, club.OwnerID, squadCard.CardID, squadCard.Position, &report)
+		if err != nil {
+			return SquadReport{}, err
+		}
+		if !available {
+			continue
+		}
+
+		var bestEffectiveness float64
+		for _, position := range bestPositions {
+			if effectiveness := service.positionEffectiveness(card, position); effectiveness > bestEffectiveness {
+				bestEffectiveness = effectiveness
+			}
+		}
+		if service.positionEffectiveness(card, squadCard.Position) < bestEffectiveness*OutOfPositionRatio {
+			report.add(SquadProblem{
+				Code:     ProblemOutOfPosition,
+				Severity: SeverityWarning,
+				Position: squadCard.Position,
+				CardID:   squadCard.CardID,
+				Message:  fmt.Sprintf("%s plays out of position", card.PlayerName),
+			})
+		}
+	}
+
+	for _, position := range positions {
+		switch count := cardsInPosition[position]; {
+		case count == 0:
+			report.add(SquadProblem{
+				Code:     ProblemMissingPosition,
+				Severity: SeverityError,
+				Position: position,
+				Message:  "there is no card in the position",
+			})
+		case count > 1:
+			report.add(SquadProblem{
+				Code:     ProblemDuplicatePosition,
+				Severity: SeverityError,
+				Position: position,
+				Message:  fmt.Sprintf("%d cards are placed in the same position", count),
+			})
+		}
+	}
+
+	if squad.CaptainID != uuid.Nil && !isCaptainInSquad {
+		report.add(SquadProblem{
+			Code:     ProblemCaptainNotInSquad,
+			Severity: SeverityError,
+			CardID:   squad.CaptainID,
+			Message:  "captain is not in the starting lineup",
+		})
+	}
+
+	for _, substitute := range substitutes {
+		if _, _, err = service.availableCard(ctx, club.OwnerID, substitute.CardID, 0, &report); err != nil {
+			return SquadReport{}, err
+		}
+	}
+
+	return report, nil
+}
+
+// availableCard returns the card and reports a problem if the card could not be used by the owner of the club.
+func (service *Service) availableCard(ctx context.Context, ownerID, cardID uuid.UUID, position Position, report *SquadReport) (cards.Card, bool, error) {
+	problem := SquadProblem{
+		Code:     ProblemUnavailableCard,
+		Severity: SeverityError,
+		Position: position,
+		CardID:   cardID,
+	}
+
+	card, err := service.cards.Get(ctx, cardID)
+	if err != nil {
+		if !cards.ErrNoCard.Has(err) {
+			return cards.Card{}, false, ErrClubs.Wrap(err)
+		}
+
+		problem.Message = "card does not exist"
+		report.add(problem)
+		return cards.Card{}, false, nil
+	}
+
+	switch {
+	case card.UserID != ownerID:
+		problem.Message = fmt.Sprintf("%s does not belong to the club owner", card.PlayerName)
+	case card.Status == cards.StatusSale:
+		problem.Message = fmt.Sprintf("%s is on sale", card.PlayerName)
+	case card.Status == cards.StatusRetired:
+		problem.Message = fmt.Sprintf("%s is retired", card.PlayerName)
+	default:
+		return card, true, nil
+	}
+
+	report.add(problem)
+	return card, false, nil
+}
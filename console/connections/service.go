@@ -4,6 +4,8 @@
 package connections
 
 import (
+	"sync"
+
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/zeebo/errs"
@@ -17,12 +19,17 @@ var ErrConnections = errs.Class("connections service error")
 // architecture: Service
 type Service struct {
 	connections DB
+
+	// websocket connection supports only one concurrent writer, so writes to the connection are serialized by its lock.
+	mu         sync.Mutex
+	writeLocks map[*websocket.Conn]*sync.Mutex
 }
 
 // NewService is a constructor for connections service.
 func NewService(connections DB) *Service {
 	return &Service{
 		connections: connections,
+		writeLocks:  make(map[*websocket.Conn]*sync.Mutex),
 	}
 }
 
@@ -42,6 +49,34 @@ func (service *Service) Get(userID uuid.UUID) (*websocket.Conn, error) {
 	return connection, ErrConnections.Wrap(err)
 }
 
+// WriteJSON writes message to the connection of the user, concurrent writes to the same connection are serialized.
+func (service *Service) WriteJSON(userID uuid.UUID, message interface{}) error {
+	connection, err := service.connections.Get(userID)
+	if err != nil {
+		return ErrConnections.Wrap(err)
+	}
+
+	lock := service.writeLock(connection)
+	lock.Lock()
+	defer lock.Unlock()
+
+	return ErrConnections.Wrap(connection.WriteJSON(message))
+}
+
+// writeLock returns write lock of the connection.
+func (service *Service) writeLock(connection *websocket.Conn) *sync.Mutex {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	lock, ok := service.writeLocks[connection]
+	if !ok {
+		lock = new(sync.Mutex)
+		service.writeLocks[connection] = lock
+	}
+
+	return lock
+}
+
 // Close closes a connection by user.
 func (service *Service) Close(id uuid.UUID) error {
 	connection, err := service.connections.Get(id)
@@ -49,6 +84,10 @@ func (service *Service) Close(id uuid.UUID) error {
 		return ErrConnections.Wrap(err)
 	}
 
+	service.mu.Lock()
+	delete(service.writeLocks, connection)
+	service.mu.Unlock()
+
 	err = connection.Close()
 	if err != nil {
 		return ErrConnections.Wrap(err)
@@ -14,6 +14,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/zeebo/errs"
 
+	"ultimatedivision/finances"
 	"ultimatedivision/internal/logger"
 	"ultimatedivision/marketplace"
 	"ultimatedivision/marketplace/bids"
@@ -50,10 +51,12 @@ type BidResponse struct {
 	UserID    uuid.UUID `json:"userId"`
 	UserName  string    `json:"userName"`
 	Amount    float64   `json:"amount"`
+	Automatic bool      `json:"automatic"`
 	CreatedAt time.Time `json:"createdAt"`
 }
 
-// Bet is an endpoint that place bet of lot.
+// Bet is an endpoint that place bet of lot, the bet with the max amount is raised automatically
+// up to the max amount whenever the user is outbid.
 func (controller *Bids) Bet(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	ctx := r.Context()
@@ -65,8 +68,9 @@ func (controller *Bids) Bet(w http.ResponseWriter, r *http.Request) {
 	}
 
 	type request struct {
-		LotID  uuid.UUID `json:"lotId"`
-		Amount float64   `json:"amount"`
+		LotID     uuid.UUID `json:"lotId"`
+		Amount    float64   `json:"amount"`
+		MaxAmount float64   `json:"maxAmount"`
 	}
 
 	var req request
@@ -81,17 +85,17 @@ func (controller *Bids) Bet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bid := bids.Bid{
-		LotID:  req.LotID,
-		UserID: claims.UserID,
-		Amount: *amount,
+	maxAmount, err := evmsignature.EthereumFloatToWeiBig(req.MaxAmount)
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrBids.Wrap(err))
+		return
 	}
 
-	if err := controller.marketplace.UpdateShopperIDLot(ctx, bid.LotID, bid.UserID); err != nil {
-		if errs.Is(err, bids.ErrSmallAmountOfBid) {
-			controller.serveError(w, http.StatusBadRequest, ErrUsers.Wrap(err))
-			return
-		}
+	bid := bids.Bid{
+		LotID:     req.LotID,
+		UserID:    claims.UserID,
+		Amount:    *amount,
+		MaxAmount: *maxAmount,
 	}
 
 	if err = controller.bids.Create(ctx, bid); err != nil {
@@ -99,12 +103,27 @@ func (controller *Bids) Bet(w http.ResponseWriter, r *http.Request) {
 			controller.serveError(w, http.StatusBadRequest, ErrUsers.Wrap(err))
 			return
 		}
+		if finances.ErrInsufficientFunds.Has(err) {
+			controller.serveError(w, http.StatusBadRequest, ErrBids.Wrap(err))
+			return
+		}
+		if bids.ErrBidConflict.Has(err) {
+			controller.serveError(w, http.StatusConflict, ErrBids.Wrap(err))
+			return
+		}
 		controller.log.Error(fmt.Sprintf("could not create bet with lot %x, user %x and amount %v", bid.LotID, bid.UserID, bid.Amount), ErrBids.Wrap(err))
 		controller.serveError(w, http.StatusInternalServerError, ErrBids.Wrap(err))
 		return
 	}
 
-	lotData, err := controller.marketplace.GetMakeOfferByCardID(ctx, bid.LotID)
+	lot, err := controller.marketplace.GetLotByID(ctx, bid.LotID)
+	if err != nil {
+		controller.log.Error("could not get lot by id", ErrMarketplace.Wrap(err))
+		controller.serveError(w, http.StatusInternalServerError, ErrMarketplace.Wrap(err))
+		return
+	}
+
+	lotData, err := controller.marketplace.GetMakeOfferByCardID(ctx, lot.CardID)
 	if err != nil {
 		controller.log.Error("there is no such NFT data", ErrMarketplace.Wrap(err))
 		controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
@@ -177,6 +196,7 @@ func (controller *Bids) ListByLotID(w http.ResponseWriter, r *http.Request) {
 			UserID:    cardBid.UserID,
 			UserName:  cardBid.UserName,
 			Amount:    evmsignature.WeiBigToEthereumFloat(&cardBid.Amount),
+			Automatic: cardBid.Automatic,
 			CreatedAt: cardBid.CreatedAt,
 		}
 
@@ -188,6 +208,51 @@ func (controller *Bids) ListByLotID(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetMaxAmount is an endpoint that returns the hidden max amount of the user on the lot.
+func (controller *Bids) GetMaxAmount(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrBids.Wrap(err))
+		return
+	}
+
+	lotID, err := uuid.Parse(vars["lotId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrBids.Wrap(err))
+		return
+	}
+
+	proxyBid, err := controller.bids.GetProxyBid(ctx, lotID, claims.UserID)
+	if err != nil {
+		if bids.ErrNoProxyBid.Has(err) {
+			controller.serveError(w, http.StatusNotFound, ErrBids.Wrap(err))
+			return
+		}
+		controller.log.Error(fmt.Sprintf("could not get max amount of user %x on lot %x", claims.UserID, lotID), ErrBids.Wrap(err))
+		controller.serveError(w, http.StatusInternalServerError, ErrBids.Wrap(err))
+		return
+	}
+
+	type response struct {
+		LotID     uuid.UUID `json:"lotId"`
+		MaxAmount float64   `json:"maxAmount"`
+		CreatedAt time.Time `json:"createdAt"`
+	}
+	res := response{
+		LotID:     proxyBid.LotID,
+		MaxAmount: evmsignature.WeiBigToEthereumFloat(&proxyBid.MaxAmount),
+		CreatedAt: proxyBid.CreatedAt,
+	}
+
+	if err = json.NewEncoder(w).Encode(res); err != nil {
+		controller.log.Error("failed to write json response", ErrBids.Wrap(err))
+	}
+}
+
 // serveError replies to the request with specific code and error message.
 func (controller *Bids) serveError(w http.ResponseWriter, status int, err error) {
 	w.WriteHeader(status)
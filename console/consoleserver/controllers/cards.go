@@ -13,6 +13,7 @@ import (
 	"github.com/zeebo/errs"
 
 	"ultimatedivision/cards"
+	"ultimatedivision/finances"
 	"ultimatedivision/internal/logger"
 	"ultimatedivision/pkg/auth"
 	"ultimatedivision/pkg/pagination"
@@ -28,14 +29,16 @@ var (
 type Cards struct {
 	log logger.Logger
 
-	cards *cards.Service
+	cards    *cards.Service
+	finances *finances.Service
 }
 
 // NewCards is a constructor for cards controller.
-func NewCards(log logger.Logger, cards *cards.Service) *Cards {
+func NewCards(log logger.Logger, cards *cards.Service, finances *finances.Service) *Cards {
 	cardsController := &Cards{
-		log:   log,
-		cards: cards,
+		log:      log,
+		cards:    cards,
+		finances: finances,
 	}
 
 	return cardsController
@@ -101,6 +104,78 @@ func (controller *Cards) GetStatus(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// History is an endpoint that allows to view full provenance of the card.
+func (controller *Cards) History(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := uuid.Parse(vars["id"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrCards.Wrap(err))
+		return
+	}
+
+	history, err := controller.cards.ListHistoryByCardID(ctx, id)
+	if err != nil {
+		controller.log.Error("could not get card history", ErrCards.Wrap(err))
+		controller.serveError(w, http.StatusInternalServerError, ErrCards.Wrap(err))
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(history); err != nil {
+		controller.log.Error("failed to write json response", ErrCards.Wrap(err))
+		return
+	}
+}
+
+// Scout is an endpoint that allows to scout the card and reveal estimated range of its potential.
+func (controller *Cards) Scout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	w.Header().Set("Content-Type", "application/json")
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrCards.Wrap(err))
+		return
+	}
+
+	id, err := uuid.Parse(vars["id"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrCards.Wrap(err))
+		return
+	}
+
+	report, history, err := controller.cards.NextScoutingReport(ctx, id, claims.UserID)
+	if err != nil {
+		controller.log.Error("could not scout card", ErrCards.Wrap(err))
+		switch {
+		case cards.ErrNoCard.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrCards.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrCards.Wrap(err))
+		}
+		return
+	}
+
+	if err = controller.finances.ChargeScouting(ctx, claims.UserID, report, history); err != nil {
+		controller.log.Error("could not charge scouting of the card", ErrCards.Wrap(err))
+		switch {
+		case finances.ErrInsufficientFunds.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrCards.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrCards.Wrap(err))
+		}
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(report); err != nil {
+		controller.log.Error("failed to write json response", ErrCards.Wrap(err))
+		return
+	}
+}
+
 // List is an endpoint that allows will view cards.
 func (controller *Cards) List(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -108,7 +183,7 @@ func (controller *Cards) List(w http.ResponseWriter, r *http.Request) {
 	var (
 		cardsListPage cards.Page
 		err           error
-		filters       cards.SliceFilters
+		query         cards.Query
 		limit, page   int
 	)
 
@@ -139,15 +214,17 @@ func (controller *Cards) List(w http.ResponseWriter, r *http.Request) {
 	cursor := pagination.Cursor{
 		Limit: limit,
 		Page:  page,
+		Token: urlQuery.Get("token"),
 	}
 	playerName := urlQuery.Get(string(cards.FilterPlayerName))
 
 	if playerName == "" {
-		if err := filters.DecodingURLParameters(urlQuery); err != nil {
+		if err := query.DecodingURLParameters(urlQuery); err != nil {
 			controller.serveError(w, http.StatusBadRequest, ErrCards.Wrap(err))
+			return
 		}
-		if len(filters) > 0 {
-			cardsListPage, err = controller.cards.ListWithFilters(ctx, claims.UserID, filters, cursor)
+		if !query.IsEmpty() {
+			cardsListPage, err = controller.cards.ListWithQuery(ctx, claims.UserID, query, cursor)
 		} else {
 			cardsListPage, err = controller.cards.ListByUserID(ctx, claims.UserID, cursor)
 		}
@@ -164,6 +241,8 @@ func (controller *Cards) List(w http.ResponseWriter, r *http.Request) {
 		switch {
 		case cards.ErrNoCard.Has(err):
 			controller.serveError(w, http.StatusNotFound, ErrCards.Wrap(err))
+		case cards.ErrInvalidFilter.Has(err) || pagination.ErrInvalidToken.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrCards.Wrap(err))
 		default:
 			controller.serveError(w, http.StatusInternalServerError, ErrCards.Wrap(err))
 		}
@@ -5,6 +5,8 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -12,7 +14,9 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/zeebo/errs"
 
+	"ultimatedivision/cards"
 	"ultimatedivision/clubs"
+	"ultimatedivision/clubs/badges"
 	"ultimatedivision/internal/logger"
 	"ultimatedivision/pkg/auth"
 )
@@ -33,14 +37,16 @@ const (
 type Clubs struct {
 	log logger.Logger
 
-	clubs *clubs.Service
+	clubs  *clubs.Service
+	badges *badges.Service
 }
 
 // NewClubs is a constructor for clubs controller.
-func NewClubs(log logger.Logger, clubs *clubs.Service) *Clubs {
+func NewClubs(log logger.Logger, clubs *clubs.Service, badges *badges.Service) *Clubs {
 	clubsController := &Clubs{
-		log:   log,
-		clubs: clubs,
+		log:    log,
+		clubs:  clubs,
+		badges: badges,
 	}
 
 	return clubsController
@@ -57,8 +63,10 @@ type UpdateRequest struct {
 // ClubResponse is a struct for response clubs, squad and squadCards.
 type ClubResponse struct {
 	clubs.Club
-	Squad      clubs.Squad          `json:"squad"`
-	SquadCards []clubs.GetSquadCard `json:"squadCards"`
+	Squad       clubs.Squad          `json:"squad"`
+	SquadCards  []clubs.GetSquadCard `json:"squadCards"`
+	Substitutes []cards.Card         `json:"substitutes"`
+	Chemistry   clubs.Chemistry      `json:"chemistry"`
 }
 
 // Create is an endpoint that creates new club.
@@ -72,11 +80,15 @@ func (controller *Clubs) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := controller.clubs.Create(ctx, claims.UserID)
-	if err != nil {
+	id, err := controller.badges.Create(ctx, claims.UserID)
+	switch {
+	case err != nil && id == uuid.Nil:
 		controller.log.Error("could not create club", ErrClubs.Wrap(err))
 		controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
 		return
+	case err != nil:
+		// the club is created, its badge could be generated later by updating its identity.
+		controller.log.Error("could not generate badge of the club", ErrClubs.Wrap(err))
 	}
 
 	if err = json.NewEncoder(w).Encode(&id); err != nil {
@@ -97,9 +109,23 @@ func (controller *Clubs) CreateSquad(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	squadID, err := controller.clubs.CreateSquad(ctx, id)
+	var request struct {
+		Name string `json:"name"`
+	}
+	if err = json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	squadID, err := controller.clubs.CreateSquad(ctx, id, request.Name)
 	if err != nil {
 		controller.log.Error("could not create squad", ErrClubs.Wrap(err))
+
+		if clubs.ErrInvalidOperation.Has(err) {
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+			return
+		}
+
 		controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
 		return
 	}
@@ -163,10 +189,26 @@ func (controller *Clubs) Get(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		substitutes, err := controller.clubs.ListSubstituteCards(ctx, squad.ID)
+		if err != nil {
+			controller.log.Error("could not get squad substitutes", ErrClubs.Wrap(err))
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+			return
+		}
+
+		chemistry, err := controller.clubs.GetSquadChemistry(ctx, squad.ID)
+		if err != nil {
+			controller.log.Error("could not get squad chemistry", ErrClubs.Wrap(err))
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+			return
+		}
+
 		userClub := ClubResponse{
 			club,
 			squad,
 			squadCards,
+			substitutes,
+			chemistry,
 		}
 
 		userClubs = append(userClubs, userClub)
@@ -183,8 +225,8 @@ func (controller *Clubs) Get(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// UpdateStatus is an endpoint that updates status of users club.
-func (controller *Clubs) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+// ListSquads is an endpoint that returns all lineup presets of the club.
+func (controller *Clubs) ListSquads(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	ctx := r.Context()
 	params := mux.Vars(r)
@@ -201,39 +243,79 @@ func (controller *Clubs) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var club clubs.Club
+	squads, err := controller.clubs.ListSquads(ctx, claims.UserID, clubID)
+	if err != nil {
+		controller.log.Error("could not list squads", ErrClubs.Wrap(err))
 
-	if err = json.NewDecoder(r.Body).Decode(&club); err != nil {
-		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		switch {
+		case clubs.ErrNoClub.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		}
 		return
 	}
 
-	if !club.Status.IsValid() {
-		controller.serveError(w, http.StatusBadRequest, ErrClubs.New("invalid status"))
+	if err = json.NewEncoder(w).Encode(squads); err != nil {
+		controller.log.Error("failed to write json response", ErrClubs.Wrap(err))
 		return
 	}
+}
 
-	err = controller.clubs.UpdateStatus(ctx, claims.UserID, clubID, club.Status)
+// SetActiveSquad is an endpoint that makes squad the active lineup preset of the club.
+func (controller *Clubs) SetActiveSquad(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	params := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
 	if err != nil {
-		controller.log.Error("could not update club status", ErrClubs.Wrap(err))
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
 
-		if clubs.ErrNoClub.Has(err) {
+	clubID, err := uuid.Parse(params["clubId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	squadID, err := uuid.Parse(params["squadId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	if err = controller.clubs.SetActiveSquad(ctx, claims.UserID, clubID, squadID); err != nil {
+		controller.log.Error("could not set active squad", ErrClubs.Wrap(err))
+
+		switch {
+		case clubs.ErrNoClub.Has(err) || clubs.ErrNoSquad.Has(err):
 			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
-			return
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
 		}
-
-		controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
 		return
 	}
 }
 
-// UpdatePosition is an endpoint that updates card position in the squad.
-func (controller *Clubs) UpdatePosition(w http.ResponseWriter, r *http.Request) {
+// DeleteSquad is an endpoint that deletes lineup preset of the club.
+func (controller *Clubs) DeleteSquad(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	ctx := r.Context()
 	params := mux.Vars(r)
 
-	cardID, err := uuid.Parse(params["cardId"])
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
+
+	clubID, err := uuid.Parse(params["clubId"])
 	if err != nil {
 		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
 		return
@@ -245,38 +327,71 @@ func (controller *Clubs) UpdatePosition(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var squadCard clubs.SquadCard
+	if err = controller.clubs.DeleteSquad(ctx, claims.UserID, clubID, squadID); err != nil {
+		controller.log.Error("could not delete squad", ErrClubs.Wrap(err))
 
-	if err = json.NewDecoder(r.Body).Decode(&squadCard); err != nil {
-		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		switch {
+		case clubs.ErrNoClub.Has(err) || clubs.ErrNoSquad.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		}
 		return
 	}
+}
 
-	if squadCard.Position < minimumPositionValue || squadCard.Position > maximumPositionValue {
-		controller.serveError(w, http.StatusBadRequest, ErrClubs.New("invalid value of position"))
+// AddSubstitute is an endpoint that adds card to the bench of the squad.
+func (controller *Clubs) AddSubstitute(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	params := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
 		return
 	}
 
-	err = controller.clubs.UpdateCardPosition(ctx, squadID, cardID, squadCard.Position)
+	squadID, err := uuid.Parse(params["squadId"])
 	if err != nil {
-		controller.log.Error("could not update card position", ErrClubs.Wrap(err))
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
 
-		if clubs.ErrNoSquadCard.Has(err) {
+	cardID, err := uuid.Parse(params["cardId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	if err = controller.clubs.AddSubstitute(ctx, claims.UserID, squadID, cardID); err != nil {
+		controller.log.Error("could not add card to the bench", ErrClubs.Wrap(err))
+
+		switch {
+		case clubs.ErrNoSquad.Has(err):
 			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
-			return
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
 		}
-
-		controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
 		return
 	}
 }
 
-// UpdateTacticCaptain is an endpoint that updates squad tactic, capitan.
-func (controller *Clubs) UpdateTacticCaptain(w http.ResponseWriter, r *http.Request) {
+// DeleteSubstitute is an endpoint that removes card from the bench of the squad.
+func (controller *Clubs) DeleteSubstitute(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	ctx := r.Context()
 	params := mux.Vars(r)
-	var updatedSquad clubs.Squad
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
 
 	squadID, err := uuid.Parse(params["squadId"])
 	if err != nil {
@@ -284,26 +399,29 @@ func (controller *Clubs) UpdateTacticCaptain(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if err = json.NewDecoder(r.Body).Decode(&updatedSquad); err != nil {
+	cardID, err := uuid.Parse(params["cardId"])
+	if err != nil {
 		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
 		return
 	}
 
-	if err = controller.clubs.UpdateSquad(ctx, squadID, updatedSquad.Tactic, updatedSquad.CaptainID); err != nil {
-		controller.log.Error("could not update squad", ErrClubs.Wrap(err))
+	if err = controller.clubs.DeleteSubstitute(ctx, claims.UserID, squadID, cardID); err != nil {
+		controller.log.Error("could not delete card from the bench", ErrClubs.Wrap(err))
 
-		if clubs.ErrNoSquad.Has(err) {
+		switch {
+		case clubs.ErrNoSquad.Has(err) || clubs.ErrNoSquadCard.Has(err):
 			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
-			return
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
 		}
-
-		controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
 		return
 	}
 }
 
-// Add is an endpoint that adds new cards to the squad.
-func (controller *Clubs) Add(w http.ResponseWriter, r *http.Request) {
+// UpdateStatus is an endpoint that updates status of users club.
+func (controller *Clubs) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	ctx := r.Context()
 	params := mux.Vars(r)
@@ -314,50 +432,101 @@ func (controller *Clubs) Add(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var squadCard clubs.SquadCard
-
-	squadID, err := uuid.Parse(params["squadId"])
+	clubID, err := uuid.Parse(params["clubId"])
 	if err != nil {
 		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
 		return
 	}
 
-	cardID, err := uuid.Parse(params["cardId"])
-	if err != nil {
+	var club clubs.Club
+
+	if err = json.NewDecoder(r.Body).Decode(&club); err != nil {
 		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
 		return
 	}
 
-	squadCard.CardID = cardID
+	if !club.Status.IsValid() {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.New("invalid status"))
+		return
+	}
 
-	if err = json.NewDecoder(r.Body).Decode(&squadCard); err != nil {
-		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+	err = controller.clubs.UpdateStatus(ctx, claims.UserID, clubID, club.Status)
+	if err != nil {
+		controller.log.Error("could not update club status", ErrClubs.Wrap(err))
+
+		if clubs.ErrNoClub.Has(err) {
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+			return
+		}
+
+		controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		return
 	}
+}
 
-	squadCard.CardID = cardID
+// IdentityRequest is struct for update identity body payload.
+type IdentityRequest struct {
+	Crest clubs.Crest `json:"crest"`
+	Kit   clubs.Kit   `json:"kit"`
+}
 
-	if squadCard.Position < minimumPositionValue || squadCard.Position > maximumPositionValue {
-		controller.serveError(w, http.StatusBadRequest, ErrClubs.New("invalid value of position"))
+// UpdateIdentity is an endpoint that updates crest and kit colours of users club and generates its badge.
+func (controller *Clubs) UpdateIdentity(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	params := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
 		return
 	}
 
-	if err = controller.clubs.AddSquadCard(ctx, claims.UserID, squadID, squadCard); err != nil {
-		if clubs.ErrInvalidOperation.Has(err) {
+	clubID, err := uuid.Parse(params["clubId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	var request IdentityRequest
+	if err = json.NewDecoder(r.Body).Decode(&request); err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	club, err := controller.badges.Update(ctx, claims.UserID, clubID, request.Crest, request.Kit)
+	if err != nil {
+		controller.log.Error("could not update club identity", ErrClubs.Wrap(err))
+
+		switch {
+		case clubs.ErrNoClub.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+		case clubs.ErrInvalidOperation.Has(err):
 			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
-			return
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
 		}
-		controller.log.Error("could not add card to the squad", ErrClubs.Wrap(err))
-		controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(club); err != nil {
+		controller.log.Error("failed to write json response", ErrClubs.Wrap(err))
 		return
 	}
 }
 
-// Delete is an endpoint that removes card from squad.
-func (controller *Clubs) Delete(w http.ResponseWriter, r *http.Request) {
+// UpdatePosition is an endpoint that updates card position in the squad.
+func (controller *Clubs) UpdatePosition(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	ctx := r.Context()
 	params := mux.Vars(r)
 
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
+
 	cardID, err := uuid.Parse(params["cardId"])
 	if err != nil {
 		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
@@ -370,8 +539,25 @@ func (controller *Clubs) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = controller.clubs.Delete(ctx, squadID, cardID); err != nil {
-		controller.log.Error("could not delete card from the squad", ErrClubs.Wrap(err))
+	if !controller.checkSquadOwner(w, r, claims.UserID, squadID) {
+		return
+	}
+
+	var squadCard clubs.SquadCard
+
+	if err = json.NewDecoder(r.Body).Decode(&squadCard); err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	if squadCard.Position < minimumPositionValue || squadCard.Position > maximumPositionValue {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.New("invalid value of position"))
+		return
+	}
+
+	err = controller.clubs.UpdateCardPosition(ctx, squadID, cardID, squadCard.Position)
+	if err != nil {
+		controller.log.Error("could not update card position", ErrClubs.Wrap(err))
 
 		if clubs.ErrNoSquadCard.Has(err) {
 			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
@@ -383,34 +569,36 @@ func (controller *Clubs) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// ChangeFormation is a method that change formation and card position.
-func (controller *Clubs) ChangeFormation(w http.ResponseWriter, r *http.Request) {
+// UpdateTacticCaptain is an endpoint that updates squad tactic, capitan.
+func (controller *Clubs) UpdateTacticCaptain(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	ctx := r.Context()
 	params := mux.Vars(r)
+	var updatedSquad clubs.Squad
 
-	newFormationID, err := strconv.Atoi(params["formationId"])
+	claims, err := auth.GetClaims(ctx)
 	if err != nil {
-		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
 		return
 	}
 
-	formation := clubs.Formation(newFormationID)
+	squadID, err := uuid.Parse(params["squadId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
 
-	if !formation.IsValid() {
-		controller.serveError(w, http.StatusBadRequest, ErrClubs.New("formation is not correct"))
+	if !controller.checkSquadOwner(w, r, claims.UserID, squadID) {
 		return
 	}
 
-	squadID, err := uuid.Parse(params["squadId"])
-	if err != nil {
+	if err = json.NewDecoder(r.Body).Decode(&updatedSquad); err != nil {
 		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
 		return
 	}
 
-	err = controller.clubs.ChangeFormation(ctx, formation, squadID)
-	if err != nil {
-		controller.log.Error("could not change formation", ErrClubs.Wrap(err))
+	if err = controller.clubs.UpdateSquad(ctx, squadID, updatedSquad.Tactic, updatedSquad.CaptainID); err != nil {
+		controller.log.Error("could not update squad", ErrClubs.Wrap(err))
 
 		if clubs.ErrNoSquad.Has(err) {
 			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
@@ -422,6 +610,660 @@ func (controller *Clubs) ChangeFormation(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// GetInstructions is an endpoint that returns tactical settings of the squad and instructions for its cards.
+func (controller *Clubs) GetInstructions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	params := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
+
+	squadID, err := uuid.Parse(params["squadId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	instructions, err := controller.clubs.GetInstructions(ctx, claims.UserID, squadID)
+	if err != nil {
+		controller.log.Error("could not get instructions of the squad", ErrClubs.Wrap(err))
+
+		switch {
+		case clubs.ErrNoSquad.Has(err), clubs.ErrNoClub.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		}
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(instructions); err != nil {
+		controller.log.Error("failed to write json response", ErrClubs.Wrap(err))
+		return
+	}
+}
+
+// UpdateTeamInstructions is an endpoint that updates pressing, width, tempo and defensive line of the squad.
+func (controller *Clubs) UpdateTeamInstructions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	params := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
+
+	squadID, err := uuid.Parse(params["squadId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	var instructions clubs.TeamInstructions
+	if err = json.NewDecoder(r.Body).Decode(&instructions); err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	if err = controller.clubs.UpdateTeamInstructions(ctx, claims.UserID, squadID, instructions); err != nil {
+		controller.log.Error("could not update instructions of the squad", ErrClubs.Wrap(err))
+
+		switch {
+		case clubs.ErrNoSquad.Has(err), clubs.ErrNoClub.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		}
+		return
+	}
+}
+
+// PlayerInstructionRequest describes request to set instruction for the card of the squad.
+type PlayerInstructionRequest struct {
+	Instruction clubs.Instruction `json:"instruction"`
+}
+
+// SetPlayerInstruction is an endpoint that sets instruction for the card of the squad.
+func (controller *Clubs) SetPlayerInstruction(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	params := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
+
+	squadID, err := uuid.Parse(params["squadId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	cardID, err := uuid.Parse(params["cardId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	var request PlayerInstructionRequest
+	if err = json.NewDecoder(r.Body).Decode(&request); err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	instruction := clubs.PlayerInstruction{
+		SquadID:     squadID,
+		CardID:      cardID,
+		Instruction: request.Instruction,
+	}
+
+	if err = controller.clubs.SetPlayerInstruction(ctx, claims.UserID, instruction); err != nil {
+		controller.log.Error("could not set instruction for the card", ErrClubs.Wrap(err))
+
+		switch {
+		case clubs.ErrNoSquad.Has(err), clubs.ErrNoClub.Has(err), clubs.ErrNoSquadCard.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		}
+		return
+	}
+}
+
+// Add is an endpoint that adds new cards to the squad.
+func (controller *Clubs) Add(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	params := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
+
+	var squadCard clubs.SquadCard
+
+	squadID, err := uuid.Parse(params["squadId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	cardID, err := uuid.Parse(params["cardId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	squadCard.CardID = cardID
+
+	if err = json.NewDecoder(r.Body).Decode(&squadCard); err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+	}
+
+	squadCard.CardID = cardID
+
+	if squadCard.Position < minimumPositionValue || squadCard.Position > maximumPositionValue {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.New("invalid value of position"))
+		return
+	}
+
+	if err = controller.clubs.AddSquadCard(ctx, claims.UserID, squadID, squadCard); err != nil {
+		if clubs.ErrInvalidOperation.Has(err) {
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+			return
+		}
+		controller.log.Error("could not add card to the squad", ErrClubs.Wrap(err))
+		controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		return
+	}
+}
+
+// Delete is an endpoint that removes card from squad.
+func (controller *Clubs) Delete(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	params := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
+
+	cardID, err := uuid.Parse(params["cardId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	squadID, err := uuid.Parse(params["squadId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	if !controller.checkSquadOwner(w, r, claims.UserID, squadID) {
+		return
+	}
+
+	if err = controller.clubs.Delete(ctx, squadID, cardID); err != nil {
+		controller.log.Error("could not delete card from the squad", ErrClubs.Wrap(err))
+
+		if clubs.ErrNoSquadCard.Has(err) {
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+			return
+		}
+
+		controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		return
+	}
+}
+
+// ChangeFormation is a method that change formation and card position.
+func (controller *Clubs) ChangeFormation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	params := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
+
+	newFormationID, err := strconv.Atoi(params["formationId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	formation := clubs.Formation(newFormationID)
+
+	if !formation.IsValid() && !formation.IsCustom() {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.New("formation is not correct"))
+		return
+	}
+
+	squadID, err := uuid.Parse(params["squadId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	// the squad and the custom formations of its club could be used only by the owner of the club.
+	if !controller.checkSquadOwner(w, r, claims.UserID, squadID) {
+		return
+	}
+
+	err = controller.clubs.ChangeFormation(ctx, formation, squadID)
+	if err != nil {
+		controller.log.Error("could not change formation", ErrClubs.Wrap(err))
+
+		switch {
+		case clubs.ErrNoSquad.Has(err), clubs.ErrNoCustomFormation.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		}
+		return
+	}
+}
+
+// Lineup is an endpoint that returns the best lineup of user's cards for the formation,
+// if formation is not specified the formation in which user's cards are the most effective is suggested.
+func (controller *Clubs) Lineup(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
+
+	var lineup clubs.Lineup
+	if formationParam := r.URL.Query().Get("formation"); formationParam != "" {
+		formationID, err := strconv.Atoi(formationParam)
+		if err != nil {
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+			return
+		}
+
+		formation := clubs.Formation(formationID)
+		if !formation.IsValid() && !formation.IsCustom() {
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.New("formation is not correct"))
+			return
+		}
+
+		lineup, err = controller.clubs.AutoPick(ctx, claims.UserID, formation)
+	} else {
+		lineup, err = controller.clubs.SuggestFormation(ctx, claims.UserID)
+	}
+	if err != nil {
+		controller.log.Error("could not pick lineup", ErrClubs.Wrap(err))
+
+		switch {
+		case clubs.ErrNoClub.Has(err), clubs.ErrNoSquad.Has(err), clubs.ErrNoCustomFormation.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		}
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(lineup); err != nil {
+		controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		return
+	}
+}
+
+// AutoFillSquad is an endpoint that fills squad and its bench with the best lineup of user's cards for the formation.
+func (controller *Clubs) AutoFillSquad(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	params := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
+
+	squadID, err := uuid.Parse(params["squadId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	formationID, err := strconv.Atoi(params["formationId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	formation := clubs.Formation(formationID)
+	if !formation.IsValid() && !formation.IsCustom() {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.New("formation is not correct"))
+		return
+	}
+
+	lineup, err := controller.clubs.AutoFillSquad(ctx, claims.UserID, squadID, formation)
+	if err != nil {
+		controller.log.Error("could not auto fill squad", ErrClubs.Wrap(err))
+
+		switch {
+		case clubs.ErrNoSquad.Has(err), clubs.ErrNoClub.Has(err), clubs.ErrNoCustomFormation.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		}
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(lineup); err != nil {
+		controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		return
+	}
+}
+
+// ValidateSquad is an endpoint that returns health check report of the squad.
+func (controller *Clubs) ValidateSquad(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	params := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
+
+	squadID, err := uuid.Parse(params["squadId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	report, err := controller.clubs.ValidateSquad(ctx, claims.UserID, squadID)
+	if err != nil {
+		controller.log.Error("could not validate squad", ErrClubs.Wrap(err))
+
+		switch {
+		case clubs.ErrNoSquad.Has(err), clubs.ErrNoClub.Has(err), clubs.ErrNoCustomFormation.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		}
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(report); err != nil {
+		controller.log.Error("failed to write json response", ErrClubs.Wrap(err))
+		return
+	}
+}
+
+// SquadCodeRequest describes the code of the shared squad.
+type SquadCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// ExportSquad is an endpoint that returns the code of the squad, which could be shared with other users.
+func (controller *Clubs) ExportSquad(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	params := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
+
+	squadID, err := uuid.Parse(params["squadId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	code, err := controller.clubs.ExportSquad(ctx, claims.UserID, squadID)
+	if err != nil {
+		controller.log.Error("could not export squad", ErrClubs.Wrap(err))
+
+		switch {
+		case clubs.ErrNoSquad.Has(err), clubs.ErrNoClub.Has(err), clubs.ErrNoCustomFormation.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		}
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(SquadCodeRequest{Code: code}); err != nil {
+		controller.log.Error("failed to write json response", ErrClubs.Wrap(err))
+		return
+	}
+}
+
+// ImportSquad is an endpoint that replaces lineup of the squad by the shared one, built from user's cards.
+func (controller *Clubs) ImportSquad(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	params := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
+
+	squadID, err := uuid.Parse(params["squadId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	var request SquadCodeRequest
+	if err = json.NewDecoder(r.Body).Decode(&request); err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	lineup, err := controller.clubs.ImportSquad(ctx, claims.UserID, squadID, request.Code)
+	if err != nil {
+		controller.log.Error("could not import squad", ErrClubs.Wrap(err))
+
+		switch {
+		case clubs.ErrNoSquad.Has(err), clubs.ErrNoClub.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		}
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(lineup); err != nil {
+		controller.log.Error("failed to write json response", ErrClubs.Wrap(err))
+		return
+	}
+}
+
+// CustomFormationRequest describes request to create custom formation.
+type CustomFormationRequest struct {
+	Name      string           `json:"name"`
+	Positions []clubs.Position `json:"positions"`
+}
+
+// CreateCustomFormation is an endpoint that creates custom formation of the club.
+func (controller *Clubs) CreateCustomFormation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	params := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
+
+	clubID, err := uuid.Parse(params["clubId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	var request CustomFormationRequest
+	if err = json.NewDecoder(r.Body).Decode(&request); err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	id, err := controller.clubs.CreateCustomFormation(ctx, claims.UserID, clubID, request.Name, request.Positions)
+	if err != nil {
+		controller.log.Error("could not create custom formation", ErrClubs.Wrap(err))
+
+		switch {
+		case clubs.ErrNoClub.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		}
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(&id); err != nil {
+		controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		return
+	}
+}
+
+// ListCustomFormations is an endpoint that returns all custom formations of the club.
+func (controller *Clubs) ListCustomFormations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	params := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
+
+	clubID, err := uuid.Parse(params["clubId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	formations, err := controller.clubs.ListCustomFormations(ctx, claims.UserID, clubID)
+	if err != nil {
+		controller.log.Error("could not list custom formations", ErrClubs.Wrap(err))
+
+		switch {
+		case clubs.ErrNoClub.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		}
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(formations); err != nil {
+		controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		return
+	}
+}
+
+// DeleteCustomFormation is an endpoint that deletes custom formation of the club.
+func (controller *Clubs) DeleteCustomFormation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	params := mux.Vars(r)
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrClubs.Wrap(err))
+		return
+	}
+
+	clubID, err := uuid.Parse(params["clubId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	formationID, err := strconv.Atoi(params["formationId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		return
+	}
+
+	if err = controller.clubs.DeleteCustomFormation(ctx, claims.UserID, clubID, clubs.Formation(formationID)); err != nil {
+		controller.log.Error("could not delete custom formation", ErrClubs.Wrap(err))
+
+		switch {
+		case clubs.ErrNoCustomFormation.Has(err), clubs.ErrNoClub.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrClubs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+		}
+		return
+	}
+}
+
+// checkSquadOwner serves error and returns false if the squad does not belong to the user.
+func (controller *Clubs) checkSquadOwner(w http.ResponseWriter, r *http.Request, userID, squadID uuid.UUID) bool {
+	_, err := controller.clubs.GetUserSquad(r.Context(), userID, squadID)
+	if err == nil {
+		return true
+	}
+
+	controller.log.Error("could not get squad of the user", ErrClubs.Wrap(err))
+	switch {
+	case clubs.ErrNoSquad.Has(err) || clubs.ErrNoClub.Has(err):
+		controller.serveError(w, http.StatusNotFound, ErrClubs.Wrap(err))
+	case clubs.ErrInvalidOperation.Has(err):
+		controller.serveError(w, http.StatusForbidden, ErrClubs.Wrap(err))
+	default:
+		controller.serveError(w, http.StatusInternalServerError, ErrClubs.Wrap(err))
+	}
+	return false
+}
+
 // serveError replies to the request with specific code and error message.
 func (controller *Clubs) serveError(w http.ResponseWriter, status int, err error) {
 	w.WriteHeader(status)
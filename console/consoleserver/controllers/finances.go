@@ -0,0 +1,119 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/clubs"
+	"ultimatedivision/finances"
+	"ultimatedivision/internal/logger"
+	"ultimatedivision/pkg/auth"
+	"ultimatedivision/pkg/pagination"
+)
+
+var (
+	// ErrFinances is an internal error type for finances controller.
+	ErrFinances = errs.Class("finances controller error")
+)
+
+// Finances is a mvc controller that handles all club finances related views.
+type Finances struct {
+	log logger.Logger
+
+	finances *finances.Service
+}
+
+// NewFinances is a constructor for finances controller.
+func NewFinances(log logger.Logger, finances *finances.Service) *Finances {
+	financesController := &Finances{
+		log:      log,
+		finances: finances,
+	}
+
+	return financesController
+}
+
+// GetStatement is an endpoint that returns balance and transactions of the club.
+func (controller *Finances) GetStatement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	w.Header().Set("Content-Type", "application/json")
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrFinances.Wrap(err))
+		return
+	}
+
+	clubID, err := uuid.Parse(vars["clubId"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrFinances.Wrap(err))
+		return
+	}
+
+	var limit, page int
+	urlQuery := r.URL.Query()
+	limitQuery := urlQuery.Get("limit")
+	pageQuery := urlQuery.Get("page")
+
+	if limitQuery != "" {
+		if limit, err = strconv.Atoi(limitQuery); err != nil {
+			controller.serveError(w, http.StatusBadRequest, ErrFinances.Wrap(err))
+			return
+		}
+	}
+
+	if pageQuery != "" {
+		if page, err = strconv.Atoi(pageQuery); err != nil {
+			controller.serveError(w, http.StatusBadRequest, ErrFinances.Wrap(err))
+			return
+		}
+	}
+
+	cursor := pagination.Cursor{
+		Limit: limit,
+		Page:  page,
+	}
+
+	statement, err := controller.finances.GetStatement(ctx, claims.UserID, clubID, cursor)
+	if err != nil {
+		controller.log.Error("could not get statement of the club", ErrFinances.Wrap(err))
+		switch {
+		case clubs.ErrNoClub.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrFinances.Wrap(err))
+		case clubs.ErrInvalidOperation.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrFinances.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrFinances.Wrap(err))
+		}
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(&statement); err != nil {
+		controller.log.Error("failed to write json response", ErrFinances.Wrap(err))
+		return
+	}
+}
+
+// serveError replies to the request with specific code and error message.
+func (controller *Finances) serveError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+
+	var response struct {
+		Error string `json:"error"`
+	}
+
+	response.Error = err.Error()
+
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		controller.log.Error("failed to write json error response", ErrFinances.Wrap(err))
+	}
+}
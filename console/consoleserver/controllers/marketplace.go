@@ -6,6 +6,7 @@ package controllers
 import (
 	"encoding/json"
 	"math"
+	"math/big"
 	"net/http"
 	"strconv"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/zeebo/errs"
 
 	"ultimatedivision/cards"
+	"ultimatedivision/finances"
 	"ultimatedivision/internal/logger"
 	"ultimatedivision/marketplace"
 	"ultimatedivision/pkg/auth"
@@ -51,13 +53,16 @@ func (controller *Marketplace) ListActiveLots(w http.ResponseWriter, r *http.Req
 	var (
 		lotsPage    marketplace.Page
 		err         error
-		filters     cards.SliceFilters
+		query       cards.Query
 		limit, page int
 	)
 	urlQuery := r.URL.Query()
 	limitQuery := urlQuery.Get("limit")
 	pageQuery := urlQuery.Get("page")
 	playerName := urlQuery.Get(string(cards.FilterPlayerName))
+	// sale mode filters lots, not their cards, so it is removed from the query of the cards.
+	saleMode := marketplace.SaleMode(urlQuery.Get("saleMode"))
+	urlQuery.Del("saleMode")
 
 	if limitQuery != "" {
 		if limit, err = strconv.Atoi(limitQuery); err != nil {
@@ -76,15 +81,17 @@ func (controller *Marketplace) ListActiveLots(w http.ResponseWriter, r *http.Req
 	cursor := pagination.Cursor{
 		Limit: limit,
 		Page:  page,
+		Token: urlQuery.Get("token"),
 	}
 	if playerName == "" {
-		if err := filters.DecodingURLParameters(urlQuery); err != nil {
+		if err := query.DecodingURLParameters(urlQuery); err != nil {
 			controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
+			return
 		}
-		if len(filters) > 0 {
-			lotsPage, err = controller.marketplace.ListActiveLotsWithFilters(ctx, filters, cursor)
+		if !query.IsEmpty() {
+			lotsPage, err = controller.marketplace.ListActiveLotsWithQuery(ctx, query, saleMode, cursor)
 		} else {
-			lotsPage, err = controller.marketplace.ListActiveLots(ctx, cursor)
+			lotsPage, err = controller.marketplace.ListActiveLots(ctx, saleMode, cursor)
 		}
 	} else {
 		filter := cards.Filters{
@@ -92,13 +99,15 @@ func (controller *Marketplace) ListActiveLots(w http.ResponseWriter, r *http.Req
 			Value:          playerName,
 			SearchOperator: sqlsearchoperators.LIKE,
 		}
-		lotsPage, err = controller.marketplace.ListActiveLotsByPlayerName(ctx, filter, cursor)
+		lotsPage, err = controller.marketplace.ListActiveLotsByPlayerName(ctx, filter, saleMode, cursor)
 	}
 	if err != nil {
 		controller.log.Error("could not get active lots list", ErrMarketplace.Wrap(err))
 		switch {
 		case marketplace.ErrNoLot.Has(err):
 			controller.serveError(w, http.StatusNotFound, ErrMarketplace.Wrap(err))
+		case cards.ErrInvalidFilter.Has(err) || pagination.ErrInvalidToken.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
 		default:
 			controller.serveError(w, http.StatusInternalServerError, ErrMarketplace.Wrap(err))
 		}
@@ -106,20 +115,30 @@ func (controller *Marketplace) ListActiveLots(w http.ResponseWriter, r *http.Req
 	}
 
 	var lots []marketplace.Lot
+	now := time.Now().UTC()
 	for _, oneLot := range lotsPage.Lots {
 		lot := marketplace.Lot{
+			ID:           oneLot.ID,
 			CardID:       oneLot.Card.ID,
 			Type:         oneLot.Type,
+			SaleMode:     oneLot.SaleMode,
 			UserID:       oneLot.UserID,
 			ShopperID:    oneLot.ShopperID,
 			Status:       oneLot.Status,
 			StartPrice:   oneLot.StartPrice,
 			MaxPrice:     oneLot.MaxPrice,
+			FloorPrice:   oneLot.FloorPrice,
 			CurrentPrice: *evmsignature.WeiBigToEthereumBig(&oneLot.CurrentPrice),
 			StartTime:    oneLot.StartTime,
 			EndTime:      oneLot.EndTime,
 			Period:       oneLot.Period,
 			Card:         oneLot.Card,
+			Cards:        oneLot.Cards,
+		}
+		// the current price of the lots which are not auctions is the price for which they are bought now.
+		if oneLot.SaleMode != marketplace.SaleModeAuction {
+			buyNowPrice, _ := oneLot.BuyNowPrice(now)
+			lot.CurrentPrice = *evmsignature.WeiBigToEthereumBig(&buyNowPrice)
 		}
 		if lot.CurrentPrice.String() == "0" {
 			lot.CurrentPrice = lot.StartPrice
@@ -162,28 +181,39 @@ func (controller *Marketplace) GetLotByID(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	buyNowPrice, _ := lot.BuyNowPrice(time.Now().UTC())
 	getLot := struct {
-		CardID       uuid.UUID          `json:"cardId"`
-		Type         marketplace.Type   `json:"type"`
-		Status       marketplace.Status `json:"status"`
-		StartPrice   float64            `json:"startPrice"`
-		MaxPrice     float64            `json:"maxPrice"`
-		CurrentPrice float64            `json:"currentPrice"`
-		StartTime    time.Time          `json:"startTime"`
-		EndTime      time.Time          `json:"endTime"`
-		Period       marketplace.Period `json:"period"`
-		Card         cards.Card         `json:"card"`
+		ID           uuid.UUID            `json:"id"`
+		CardID       uuid.UUID            `json:"cardId"`
+		Type         marketplace.Type     `json:"type"`
+		SaleMode     marketplace.SaleMode `json:"saleMode"`
+		Status       marketplace.Status   `json:"status"`
+		StartPrice   float64              `json:"startPrice"`
+		MaxPrice     float64              `json:"maxPrice"`
+		FloorPrice   float64              `json:"floorPrice"`
+		CurrentPrice float64              `json:"currentPrice"`
+		BuyNowPrice  float64              `json:"buyNowPrice"`
+		StartTime    time.Time            `json:"startTime"`
+		EndTime      time.Time            `json:"endTime"`
+		Period       marketplace.Period   `json:"period"`
+		Card         cards.Card           `json:"card"`
+		Cards        []cards.Card         `json:"cards"`
 	}{
+		ID:           lot.ID,
 		CardID:       lot.Card.ID,
 		Type:         lot.Type,
+		SaleMode:     lot.SaleMode,
 		Status:       lot.Status,
 		StartPrice:   roundFloat(evmsignature.WeiBigToEthereumFloat(&lot.StartPrice), 3),
 		MaxPrice:     roundFloat(evmsignature.WeiBigToEthereumFloat(&lot.MaxPrice), 3),
+		FloorPrice:   roundFloat(evmsignature.WeiBigToEthereumFloat(&lot.FloorPrice), 3),
 		CurrentPrice: roundFloat(evmsignature.WeiBigToEthereumFloat(&lot.CurrentPrice), 3),
+		BuyNowPrice:  roundFloat(evmsignature.WeiBigToEthereumFloat(&buyNowPrice), 3),
 		StartTime:    lot.StartTime,
 		EndTime:      lot.EndTime,
 		Period:       lot.Period,
 		Card:         lot.Card,
+		Cards:        lot.Cards,
 	}
 
 	if err = json.NewEncoder(w).Encode(getLot); err != nil {
@@ -269,19 +299,28 @@ func (controller *Marketplace) CreateLot(w http.ResponseWriter, r *http.Request)
 	}
 
 	// TODO: remove after adding Casper contract.
-	if _, err = controller.marketplace.GetNFTByCardID(ctx, createLot.CardID); err != nil {
-		controller.log.Error("there is no such NFT", ErrMarketplace.Wrap(err))
-		controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
+	// bundles hold only not minted cards, so only the card of the single card lot has nft.
+	if cardIDs := createLot.ItemIDs(); len(cardIDs) == 1 {
+		if _, err = controller.marketplace.GetNFTByCardID(ctx, cardIDs[0]); err != nil {
+			controller.log.Error("there is no such NFT", ErrMarketplace.Wrap(err))
+			controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
+		}
 	}
 	createLot.UserID = claims.UserID
 
 	if err = createLot.ValidateCreateLot(); err != nil {
 		controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
+		return
 	}
 
 	if err = controller.marketplace.CreateLot(ctx, createLot); err != nil {
 		controller.log.Error("could not create lot", ErrMarketplace.Wrap(err))
-		controller.serveError(w, http.StatusInternalServerError, ErrMarketplace.Wrap(err))
+		switch {
+		case marketplace.ErrInvalidBundle.Has(err), marketplace.ErrCardNotAvailable.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrMarketplace.Wrap(err))
+		}
 		return
 	}
 }
@@ -354,8 +393,8 @@ func (controller *Marketplace) GetApproveData(w http.ResponseWriter, r *http.Req
 	}
 }
 
-// PlaceBetLot is an endpoint that returns lot by id.
-func (controller *Marketplace) PlaceBetLot(w http.ResponseWriter, r *http.Request) {
+// BuyLot is an endpoint that buys lot instantly at its buy now price.
+func (controller *Marketplace) BuyLot(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	ctx := r.Context()
 
@@ -365,22 +404,205 @@ func (controller *Marketplace) PlaceBetLot(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	var betLot marketplace.BetLot
-	if err = json.NewDecoder(r.Body).Decode(&betLot); err != nil {
+	var buyLot marketplace.BuyLot
+	if err = json.NewDecoder(r.Body).Decode(&buyLot); err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
+		return
+	}
+	buyLot.UserID = claims.UserID
+
+	price, err := controller.marketplace.BuyLot(ctx, buyLot)
+	if err != nil {
+		controller.log.Error("could not buy lot", ErrMarketplace.Wrap(err))
+		switch {
+		case marketplace.ErrNoLot.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrMarketplace.Wrap(err))
+		case finances.ErrInsufficientFunds.Has(err), marketplace.ErrLotNotAvailable.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrMarketplace.Wrap(err))
+		}
+		return
+	}
+
+	response := struct {
+		Price float64 `json:"price"`
+	}{
+		Price: roundFloat(evmsignature.WeiBigToEthereumFloat(&price), 3),
+	}
+
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		controller.log.Error("failed to write json response", ErrMarketplace.Wrap(err))
+		return
+	}
+}
+
+// ListSales is an endpoint that returns price history of the sold cards.
+func (controller *Marketplace) ListSales(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	filter, err := salesFilter(r)
+	if err != nil {
 		controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
 		return
 	}
-	betLot.UserID = claims.UserID
 
-	if err = betLot.ValidateBetLot(); err != nil {
+	var limit, page int
+	urlQuery := r.URL.Query()
+	if limitQuery := urlQuery.Get("limit"); limitQuery != "" {
+		if limit, err = strconv.Atoi(limitQuery); err != nil {
+			controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
+			return
+		}
+	}
+	if pageQuery := urlQuery.Get("page"); pageQuery != "" {
+		if page, err = strconv.Atoi(pageQuery); err != nil {
+			controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
+			return
+		}
+	}
+
+	cursor := pagination.Cursor{
+		Limit: limit,
+		Page:  page,
+	}
+
+	salesPage, err := controller.marketplace.ListSales(ctx, filter, cursor)
+	if err != nil {
+		controller.log.Error("could not list sales", ErrMarketplace.Wrap(err))
+		controller.serveError(w, http.StatusInternalServerError, ErrMarketplace.Wrap(err))
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(salesPage); err != nil {
+		controller.log.Error("failed to write json response", ErrMarketplace.Wrap(err))
+		return
+	}
+}
+
+// ListPriceStats is an endpoint that returns median and percentiles of the prices per quality and rating band.
+func (controller *Marketplace) ListPriceStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	filter, err := salesFilter(r)
+	if err != nil {
 		controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
+		return
 	}
 
-	if err = controller.marketplace.PlaceBetLot(ctx, betLot); err != nil {
-		controller.log.Error("could not place bet lot", ErrMarketplace.Wrap(err))
+	stats, err := controller.marketplace.ListPriceStats(ctx, filter)
+	if err != nil {
+		controller.log.Error("could not list price stats", ErrMarketplace.Wrap(err))
 		controller.serveError(w, http.StatusInternalServerError, ErrMarketplace.Wrap(err))
 		return
 	}
+
+	if err = json.NewEncoder(w).Encode(stats); err != nil {
+		controller.log.Error("failed to write json response", ErrMarketplace.Wrap(err))
+		return
+	}
+}
+
+// ListVolumes is an endpoint that returns volume of the sales over time, interval is a day by default.
+func (controller *Marketplace) ListVolumes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	filter, err := salesFilter(r)
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
+		return
+	}
+
+	interval := 24 * time.Hour
+	if intervalQuery := r.URL.Query().Get("interval"); intervalQuery != "" {
+		if interval, err = time.ParseDuration(intervalQuery); err != nil {
+			controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
+			return
+		}
+	}
+
+	volumes, err := controller.marketplace.ListVolumes(ctx, filter, interval)
+	if err != nil {
+		controller.log.Error("could not list volumes", ErrMarketplace.Wrap(err))
+		switch {
+		case marketplace.ErrInvalidInterval.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrMarketplace.Wrap(err))
+		}
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(volumes); err != nil {
+		controller.log.Error("failed to write json response", ErrMarketplace.Wrap(err))
+		return
+	}
+}
+
+// GetSuggestedPrice is an endpoint that returns suggested price of the card for the lot.
+func (controller *Marketplace) GetSuggestedPrice(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	cardID, err := uuid.Parse(vars["card_id"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrMarketplace.Wrap(err))
+		return
+	}
+
+	price, err := controller.marketplace.SuggestedPrice(ctx, cardID)
+	if err != nil {
+		controller.log.Error("could not get suggested price", ErrMarketplace.Wrap(err))
+		switch {
+		case cards.ErrNoCard.Has(err), marketplace.ErrNoSale.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrMarketplace.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrMarketplace.Wrap(err))
+		}
+		return
+	}
+
+	response := struct {
+		Price big.Int `json:"price"`
+	}{
+		Price: price,
+	}
+
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		controller.log.Error("failed to write json response", ErrMarketplace.Wrap(err))
+		return
+	}
+}
+
+// salesFilter returns sales filter from the quality, ratingBand and since (RFC 3339 time) url parameters.
+func salesFilter(r *http.Request) (marketplace.SalesFilter, error) {
+	urlQuery := r.URL.Query()
+	filter := marketplace.SalesFilter{
+		Quality: cards.Quality(urlQuery.Get("quality")),
+	}
+
+	if bandQuery := urlQuery.Get("ratingBand"); bandQuery != "" {
+		band, err := strconv.Atoi(bandQuery)
+		if err != nil {
+			return marketplace.SalesFilter{}, err
+		}
+		ratingBand := marketplace.RatingBand(band)
+		filter.Band = &ratingBand
+	}
+
+	if sinceQuery := urlQuery.Get("since"); sinceQuery != "" {
+		since, err := time.Parse(time.RFC3339, sinceQuery)
+		if err != nil {
+			return marketplace.SalesFilter{}, err
+		}
+		filter.Since = since
+	}
+
+	return filter, nil
 }
 
 // serveError replies to the request with specific code and error message.
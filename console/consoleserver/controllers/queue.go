@@ -77,6 +77,19 @@ func (controller *Queue) Create(w http.ResponseWriter, r *http.Request) {
 
 	switch request.Action {
 	case queue.ActionStartSearch:
+		report, err := controller.queue.ValidateSquad(ctx, client)
+		if err != nil {
+			controller.log.Error("could not validate squad", ErrQueue.Wrap(err))
+			controller.serveError(client.Connection, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !report.IsValid {
+			if err = client.WriteJSON(http.StatusBadRequest, report); err != nil {
+				controller.log.Error("could not write to websocket", ErrQueue.Wrap(err))
+			}
+			return
+		}
+
 		if err = controller.queue.Create(ctx, client); err != nil {
 			controller.log.Error("could not create user's queue", ErrQueue.Wrap(err))
 			controller.serveError(client.Connection, http.StatusInternalServerError, err.Error())
@@ -131,10 +131,7 @@ func (controller *Seasons) GetAllClubsStatistics(w http.ResponseWriter, r *http.
 		return
 	}
 
-	statistic := seasons.SeasonStatistics{
-		Division:   division,
-		Statistics: clubsStatistics,
-	}
+	statistic := seasons.NewSeasonStatistics(division, clubsStatistics)
 
 	if err := json.NewEncoder(w).Encode(statistic); err != nil {
 		controller.log.Error("failed to write json response", ErrSeasons.Wrap(err))
@@ -0,0 +1,262 @@
+// Copyright (C) 2022 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/finances"
+	"ultimatedivision/internal/logger"
+	"ultimatedivision/marketplace/trades"
+	"ultimatedivision/pkg/auth"
+)
+
+var (
+	// ErrTrades is an internal error type for trades controller.
+	ErrTrades = errs.Class("trades controller error")
+)
+
+// Trades is a mvc controller that handles all trades related views.
+type Trades struct {
+	log    logger.Logger
+	trades *trades.Service
+}
+
+// NewTrades is a constructor for trades controller.
+func NewTrades(log logger.Logger, trades *trades.Service) *Trades {
+	tradesController := &Trades{
+		log:    log,
+		trades: trades,
+	}
+
+	return tradesController
+}
+
+// List is an endpoint that returns trades proposed by the user or to the user.
+func (controller *Trades) List(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrTrades.Wrap(err))
+		return
+	}
+
+	tradesList, err := controller.trades.List(ctx, claims.UserID)
+	if err != nil {
+		controller.log.Error("could not list trades", ErrTrades.Wrap(err))
+		controller.serveError(w, http.StatusInternalServerError, ErrTrades.Wrap(err))
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(tradesList); err != nil {
+		controller.log.Error("failed to write json response", ErrTrades.Wrap(err))
+		return
+	}
+}
+
+// Get is an endpoint that returns trade by id.
+func (controller *Trades) Get(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrTrades.Wrap(err))
+		return
+	}
+
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrTrades.Wrap(err))
+		return
+	}
+
+	trade, err := controller.trades.Get(ctx, claims.UserID, id)
+	if err != nil {
+		controller.log.Error("could not get trade", ErrTrades.Wrap(err))
+		controller.serveTradeError(w, err)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(trade); err != nil {
+		controller.log.Error("failed to write json response", ErrTrades.Wrap(err))
+		return
+	}
+}
+
+// Propose is an endpoint that proposes trade to another user.
+func (controller *Trades) Propose(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrTrades.Wrap(err))
+		return
+	}
+
+	var trade trades.Trade
+	if err = json.NewDecoder(r.Body).Decode(&trade); err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrTrades.Wrap(err))
+		return
+	}
+	trade.ProposerID = claims.UserID
+	trade.CounterOfID = uuid.Nil
+
+	trade, err = controller.trades.Propose(ctx, trade)
+	if err != nil {
+		controller.log.Error("could not propose trade", ErrTrades.Wrap(err))
+		controller.serveTradeError(w, err)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(trade); err != nil {
+		controller.log.Error("failed to write json response", ErrTrades.Wrap(err))
+		return
+	}
+}
+
+// Accept is an endpoint that accepts trade proposed to the user.
+func (controller *Trades) Accept(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrTrades.Wrap(err))
+		return
+	}
+
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrTrades.Wrap(err))
+		return
+	}
+
+	if err = controller.trades.Accept(ctx, claims.UserID, id); err != nil {
+		controller.log.Error("could not accept trade", ErrTrades.Wrap(err))
+		controller.serveTradeError(w, err)
+		return
+	}
+}
+
+// Reject is an endpoint that rejects trade proposed to the user.
+func (controller *Trades) Reject(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrTrades.Wrap(err))
+		return
+	}
+
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrTrades.Wrap(err))
+		return
+	}
+
+	if err = controller.trades.Reject(ctx, claims.UserID, id); err != nil {
+		controller.log.Error("could not reject trade", ErrTrades.Wrap(err))
+		controller.serveTradeError(w, err)
+		return
+	}
+}
+
+// Counter is an endpoint that answers trade proposed to the user with the counter offer.
+func (controller *Trades) Counter(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrTrades.Wrap(err))
+		return
+	}
+
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrTrades.Wrap(err))
+		return
+	}
+
+	var counter trades.Trade
+	if err = json.NewDecoder(r.Body).Decode(&counter); err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrTrades.Wrap(err))
+		return
+	}
+
+	counter, err = controller.trades.Counter(ctx, claims.UserID, id, counter)
+	if err != nil {
+		controller.log.Error("could not counter trade", ErrTrades.Wrap(err))
+		controller.serveTradeError(w, err)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(counter); err != nil {
+		controller.log.Error("failed to write json response", ErrTrades.Wrap(err))
+		return
+	}
+}
+
+// Cancel is an endpoint that cancels trade proposed by the user.
+func (controller *Trades) Cancel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrTrades.Wrap(err))
+		return
+	}
+
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrTrades.Wrap(err))
+		return
+	}
+
+	if err = controller.trades.Cancel(ctx, claims.UserID, id); err != nil {
+		controller.log.Error("could not cancel trade", ErrTrades.Wrap(err))
+		controller.serveTradeError(w, err)
+		return
+	}
+}
+
+// serveTradeError replies to the request with the code which matches the error of the trades service.
+func (controller *Trades) serveTradeError(w http.ResponseWriter, err error) {
+	switch {
+	case trades.ErrNoTrade.Has(err):
+		controller.serveError(w, http.StatusNotFound, ErrTrades.Wrap(err))
+	case trades.ErrInvalidTrade.Has(err) || finances.ErrInsufficientFunds.Has(err):
+		controller.serveError(w, http.StatusBadRequest, ErrTrades.Wrap(err))
+	case trades.ErrTradeConflict.Has(err):
+		controller.serveError(w, http.StatusConflict, ErrTrades.Wrap(err))
+	default:
+		controller.serveError(w, http.StatusInternalServerError, ErrTrades.Wrap(err))
+	}
+}
+
+// serveError replies to the request with specific code and error message.
+func (controller *Trades) serveError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+
+	var response struct {
+		Error string `json:"error"`
+	}
+
+	response.Error = err.Error()
+
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		controller.log.Error("failed to write json error response", ErrTrades.Wrap(err))
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright (C) 2022 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/cards"
+	"ultimatedivision/internal/logger"
+	"ultimatedivision/marketplace"
+	"ultimatedivision/marketplace/watchlists"
+	"ultimatedivision/pkg/auth"
+)
+
+var (
+	// ErrWatchlists is an internal error type for watchlists controller.
+	ErrWatchlists = errs.Class("watchlists controller error")
+)
+
+// Watchlists is a mvc controller that handles all watchlists related views.
+type Watchlists struct {
+	log        logger.Logger
+	watchlists *watchlists.Service
+}
+
+// NewWatchlists is a constructor for watchlists controller.
+func NewWatchlists(log logger.Logger, watchlists *watchlists.Service) *Watchlists {
+	watchlistsController := &Watchlists{
+		log:        log,
+		watchlists: watchlists,
+	}
+
+	return watchlistsController
+}
+
+// ListWatches is an endpoint that returns watchlist of the user.
+func (controller *Watchlists) ListWatches(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrWatchlists.Wrap(err))
+		return
+	}
+
+	watches, err := controller.watchlists.ListWatches(ctx, claims.UserID)
+	if err != nil {
+		controller.log.Error("could not list watches", ErrWatchlists.Wrap(err))
+		controller.serveError(w, http.StatusInternalServerError, ErrWatchlists.Wrap(err))
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(watches); err != nil {
+		controller.log.Error("failed to write json response", ErrWatchlists.Wrap(err))
+		return
+	}
+}
+
+// Watch is an endpoint that adds lot to watchlist of the user.
+func (controller *Watchlists) Watch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrWatchlists.Wrap(err))
+		return
+	}
+
+	lotID, err := uuid.Parse(mux.Vars(r)["lot_id"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrWatchlists.Wrap(err))
+		return
+	}
+
+	var request struct {
+		Email bool `json:"email"`
+	}
+	if r.ContentLength != 0 {
+		if err = json.NewDecoder(r.Body).Decode(&request); err != nil {
+			controller.serveError(w, http.StatusBadRequest, ErrWatchlists.Wrap(err))
+			return
+		}
+	}
+
+	if err = controller.watchlists.Watch(ctx, claims.UserID, lotID, request.Email); err != nil {
+		controller.log.Error("could not watch lot", ErrWatchlists.Wrap(err))
+		switch {
+		case marketplace.ErrNoLot.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrWatchlists.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrWatchlists.Wrap(err))
+		}
+		return
+	}
+}
+
+// Unwatch is an endpoint that removes lot from watchlist of the user.
+func (controller *Watchlists) Unwatch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrWatchlists.Wrap(err))
+		return
+	}
+
+	lotID, err := uuid.Parse(mux.Vars(r)["lot_id"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrWatchlists.Wrap(err))
+		return
+	}
+
+	if err = controller.watchlists.Unwatch(ctx, claims.UserID, lotID); err != nil {
+		controller.log.Error("could not unwatch lot", ErrWatchlists.Wrap(err))
+		switch {
+		case watchlists.ErrNoWatch.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrWatchlists.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrWatchlists.Wrap(err))
+		}
+		return
+	}
+}
+
+// ListSearches is an endpoint that returns saved searches of the user.
+func (controller *Watchlists) ListSearches(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrWatchlists.Wrap(err))
+		return
+	}
+
+	searches, err := controller.watchlists.ListSearches(ctx, claims.UserID)
+	if err != nil {
+		controller.log.Error("could not list saved searches", ErrWatchlists.Wrap(err))
+		controller.serveError(w, http.StatusInternalServerError, ErrWatchlists.Wrap(err))
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(searches); err != nil {
+		controller.log.Error("failed to write json response", ErrWatchlists.Wrap(err))
+		return
+	}
+}
+
+// SaveSearch is an endpoint that saves search of the lots, the user is notified about new lots which match it.
+func (controller *Watchlists) SaveSearch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrWatchlists.Wrap(err))
+		return
+	}
+
+	var search watchlists.SavedSearch
+	if err = json.NewDecoder(r.Body).Decode(&search); err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrWatchlists.Wrap(err))
+		return
+	}
+	search.UserID = claims.UserID
+
+	search, err = controller.watchlists.SaveSearch(ctx, search)
+	if err != nil {
+		controller.log.Error("could not save search", ErrWatchlists.Wrap(err))
+		switch {
+		case cards.ErrInvalidFilter.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrWatchlists.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrWatchlists.Wrap(err))
+		}
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(search); err != nil {
+		controller.log.Error("failed to write json response", ErrWatchlists.Wrap(err))
+		return
+	}
+}
+
+// DeleteSearch is an endpoint that deletes saved search of the user.
+func (controller *Watchlists) DeleteSearch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		controller.serveError(w, http.StatusUnauthorized, ErrWatchlists.Wrap(err))
+		return
+	}
+
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrWatchlists.Wrap(err))
+		return
+	}
+
+	if err = controller.watchlists.DeleteSearch(ctx, claims.UserID, id); err != nil {
+		controller.log.Error("could not delete saved search", ErrWatchlists.Wrap(err))
+		switch {
+		case watchlists.ErrNoSavedSearch.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrWatchlists.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrWatchlists.Wrap(err))
+		}
+		return
+	}
+}
+
+// serveError replies to the request with specific code and error message.
+func (controller *Watchlists) serveError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+
+	var response struct {
+		Error string `json:"error"`
+	}
+
+	response.Error = err.Error()
+
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		controller.log.Error("failed to write json error response", ErrWatchlists.Wrap(err))
+	}
+}
@@ -19,14 +19,18 @@ import (
 	"ultimatedivision/cards"
 	"ultimatedivision/cards/waitlist"
 	"ultimatedivision/clubs"
+	"ultimatedivision/clubs/badges"
 	"ultimatedivision/console/connections"
 	"ultimatedivision/console/consoleserver/controllers"
+	"ultimatedivision/finances"
 	"ultimatedivision/gameplay/matchmaking"
 	"ultimatedivision/gameplay/queue"
 	"ultimatedivision/internal/logger"
 	"ultimatedivision/internal/metrics"
 	"ultimatedivision/marketplace"
 	"ultimatedivision/marketplace/bids"
+	"ultimatedivision/marketplace/trades"
+	"ultimatedivision/marketplace/watchlists"
 	"ultimatedivision/pkg/auth"
 	"ultimatedivision/seasons"
 	"ultimatedivision/store"
@@ -74,9 +78,11 @@ type Server struct {
 
 // NewServer is a constructor for console web server.
 func NewServer(config Config, log logger.Logger, listener net.Listener, cards *cards.Service, lootBoxes *lootboxes.Service,
-	marketplace *marketplace.Service, bids *bids.Service, clubs *clubs.Service, userAuth *userauth.Service, users *users.Service,
-	queue *queue.Service, seasons *seasons.Service, waitList *waitlist.Service, store *store.Service, metric *metrics.Metric,
-	currencyWaitList *currencywaitlist.Service, connections *connections.Service, matchmaking *matchmaking.Service) *Server {
+	marketplace *marketplace.Service, bids *bids.Service, watchlists *watchlists.Service, trades *trades.Service, clubs *clubs.Service, badges *badges.Service, finances *finances.Service,
+	userAuth *userauth.Service,
+	users *users.Service, queue *queue.Service, seasons *seasons.Service, waitList *waitlist.Service, store *store.Service,
+	metric *metrics.Metric, currencyWaitList *currencywaitlist.Service, connections *connections.Service,
+	matchmaking *matchmaking.Service) *Server {
 	server := &Server{
 		log:         log,
 		config:      config,
@@ -90,11 +96,14 @@ func NewServer(config Config, log logger.Logger, listener net.Listener, cards *c
 
 	authController := controllers.NewAuth(server.log, server.authService, server.cookieAuth, server.templates.auth, metric)
 	userController := controllers.NewUsers(server.log, users)
-	cardsController := controllers.NewCards(log, cards)
-	clubsController := controllers.NewClubs(log, clubs)
+	cardsController := controllers.NewCards(log, cards, finances)
+	clubsController := controllers.NewClubs(log, clubs, badges)
+	financesController := controllers.NewFinances(log, finances)
 	lootBoxesController := controllers.NewLootBoxes(log, lootBoxes)
 	marketplaceController := controllers.NewMarketplace(log, marketplace)
 	bidsController := controllers.NewBids(log, bids, marketplace)
+	watchlistsController := controllers.NewWatchlists(log, watchlists)
+	tradesController := controllers.NewTrades(log, trades)
 	// TODO: now use a new service - matchmaking for the game
 	// queueController := controllers.NewQueue(log, queue, connections).
 	seasonsController := controllers.NewSeasons(log, seasons)
@@ -156,22 +165,46 @@ func NewServer(config Config, log logger.Logger, listener net.Listener, cards *c
 	cardsRouter.HandleFunc("", cardsController.List).Methods(http.MethodGet)
 	cardsRouter.HandleFunc("/{id}", cardsController.Get).Methods(http.MethodGet)
 	cardsRouter.HandleFunc("/status/{id}", cardsController.GetStatus).Methods(http.MethodGet)
+	cardsRouter.HandleFunc("/{id}/history", cardsController.History).Methods(http.MethodGet)
+	cardsRouter.HandleFunc("/{id}/scout", cardsController.Scout).Methods(http.MethodPost)
 
 	clubsRouter := apiRouter.PathPrefix("/clubs").Subrouter()
 	clubsRouter.Use(server.withAuth)
 	clubsRouter.HandleFunc("", clubsController.Create).Methods(http.MethodPost)
 	clubsRouter.HandleFunc("", clubsController.Get).Methods(http.MethodGet)
+	clubsRouter.HandleFunc("/lineup", clubsController.Lineup).Methods(http.MethodGet)
 	clubsRouter.HandleFunc("/{clubId}", clubsController.UpdateStatus).Methods(http.MethodPatch)
+	clubsRouter.HandleFunc("/{clubId}/identity", clubsController.UpdateIdentity).Methods(http.MethodPut)
+	clubsRouter.HandleFunc("/{clubId}/finances", financesController.GetStatement).Methods(http.MethodGet)
+
+	formationsRouter := clubsRouter.PathPrefix("/{clubId}/formations").Subrouter()
+	formationsRouter.HandleFunc("", clubsController.CreateCustomFormation).Methods(http.MethodPost)
+	formationsRouter.HandleFunc("", clubsController.ListCustomFormations).Methods(http.MethodGet)
+	formationsRouter.HandleFunc("/{formationId}", clubsController.DeleteCustomFormation).Methods(http.MethodDelete)
 
 	squadRouter := clubsRouter.PathPrefix("/{clubId}/squads").Subrouter()
 	squadRouter.HandleFunc("", clubsController.CreateSquad).Methods(http.MethodPost)
+	squadRouter.HandleFunc("", clubsController.ListSquads).Methods(http.MethodGet)
 	squadRouter.HandleFunc("/{squadId}", clubsController.UpdateTacticCaptain).Methods(http.MethodPatch)
+	squadRouter.HandleFunc("/{squadId}", clubsController.DeleteSquad).Methods(http.MethodDelete)
+	squadRouter.HandleFunc("/{squadId}/active", clubsController.SetActiveSquad).Methods(http.MethodPut)
 	squadRouter.HandleFunc("/{squadId}/formation/{formationId}", clubsController.ChangeFormation).Methods(http.MethodPut)
+	squadRouter.HandleFunc("/{squadId}/autofill/{formationId}", clubsController.AutoFillSquad).Methods(http.MethodPut)
+	squadRouter.HandleFunc("/{squadId}/validation", clubsController.ValidateSquad).Methods(http.MethodGet)
+	squadRouter.HandleFunc("/{squadId}/export", clubsController.ExportSquad).Methods(http.MethodGet)
+	squadRouter.HandleFunc("/{squadId}/import", clubsController.ImportSquad).Methods(http.MethodPut)
+	squadRouter.HandleFunc("/{squadId}/instructions", clubsController.GetInstructions).Methods(http.MethodGet)
+	squadRouter.HandleFunc("/{squadId}/instructions", clubsController.UpdateTeamInstructions).Methods(http.MethodPut)
 
 	squadCardsRouter := squadRouter.PathPrefix("/{squadId}/cards").Subrouter()
 	squadCardsRouter.HandleFunc("/{cardId}", clubsController.Add).Methods(http.MethodPost)
 	squadCardsRouter.HandleFunc("/{cardId}", clubsController.Delete).Methods(http.MethodDelete)
 	squadCardsRouter.HandleFunc("/{cardId}", clubsController.UpdatePosition).Methods(http.MethodPatch)
+	squadCardsRouter.HandleFunc("/{cardId}/instruction", clubsController.SetPlayerInstruction).Methods(http.MethodPut)
+
+	substitutesRouter := squadRouter.PathPrefix("/{squadId}/substitutes").Subrouter()
+	substitutesRouter.HandleFunc("/{cardId}", clubsController.AddSubstitute).Methods(http.MethodPost)
+	substitutesRouter.HandleFunc("/{cardId}", clubsController.DeleteSubstitute).Methods(http.MethodDelete)
 
 	lootBoxesRouter := apiRouter.PathPrefix("/lootboxes").Subrouter()
 	lootBoxesRouter.Use(server.withAuth)
@@ -182,21 +215,42 @@ func NewServer(config Config, log logger.Logger, listener net.Listener, cards *c
 	marketplaceRouter.HandleFunc("", marketplaceController.ListActiveLots).Methods(http.MethodGet)
 	marketplaceRouterWithAuth := marketplaceRouter
 	marketplaceRouterWithAuth.Use(server.withAuth)
+	marketplaceRouterWithAuth.HandleFunc("/watchlist", watchlistsController.ListWatches).Methods(http.MethodGet)
+	marketplaceRouterWithAuth.HandleFunc("/watchlist/{lot_id}", watchlistsController.Watch).Methods(http.MethodPost)
+	marketplaceRouterWithAuth.HandleFunc("/watchlist/{lot_id}", watchlistsController.Unwatch).Methods(http.MethodDelete)
+	marketplaceRouterWithAuth.HandleFunc("/searches", watchlistsController.ListSearches).Methods(http.MethodGet)
+	marketplaceRouterWithAuth.HandleFunc("/searches", watchlistsController.SaveSearch).Methods(http.MethodPost)
+	marketplaceRouterWithAuth.HandleFunc("/searches/{id}", watchlistsController.DeleteSearch).Methods(http.MethodDelete)
+	marketplaceRouterWithAuth.HandleFunc("/sales", marketplaceController.ListSales).Methods(http.MethodGet)
+	marketplaceRouterWithAuth.HandleFunc("/sales/stats", marketplaceController.ListPriceStats).Methods(http.MethodGet)
+	marketplaceRouterWithAuth.HandleFunc("/sales/volumes", marketplaceController.ListVolumes).Methods(http.MethodGet)
+	marketplaceRouterWithAuth.HandleFunc("/suggested-price/{card_id}", marketplaceController.GetSuggestedPrice).Methods(http.MethodGet)
 	marketplaceRouterWithAuth.HandleFunc("/{id}", marketplaceController.GetLotByID).Methods(http.MethodGet)
 	marketplaceRouterWithAuth.HandleFunc("/end-time/{id}", marketplaceController.IsExpired).Methods(http.MethodGet)
 	marketplaceRouterWithAuth.HandleFunc("/price/{card_id}", marketplaceController.GetCurrentPriceByCardID).Methods(http.MethodGet)
 	marketplaceRouterWithAuth.HandleFunc("/lot-data/{card_id}", marketplaceController.GetLotData).Methods(http.MethodGet)
 	marketplaceRouterWithAuth.HandleFunc("", marketplaceController.CreateLot).Methods(http.MethodPost)
-	marketplaceRouterWithAuth.HandleFunc("/bet", marketplaceController.PlaceBetLot).Methods(http.MethodPost)
+	marketplaceRouterWithAuth.HandleFunc("/buy", marketplaceController.BuyLot).Methods(http.MethodPost)
 	marketplaceRouterWithAuth.HandleFunc("/is-minted/{card_id}", marketplaceController.IsMinted).Methods(http.MethodGet)
 
 	apiRouter.HandleFunc("/casper-approve", marketplaceController.GetApproveData).Methods(http.MethodGet)
 
+	tradesRouter := apiRouter.PathPrefix("/trades").Subrouter()
+	tradesRouter.Use(server.withAuth)
+	tradesRouter.HandleFunc("", tradesController.List).Methods(http.MethodGet)
+	tradesRouter.HandleFunc("", tradesController.Propose).Methods(http.MethodPost)
+	tradesRouter.HandleFunc("/{id}", tradesController.Get).Methods(http.MethodGet)
+	tradesRouter.HandleFunc("/{id}/accept", tradesController.Accept).Methods(http.MethodPost)
+	tradesRouter.HandleFunc("/{id}/reject", tradesController.Reject).Methods(http.MethodPost)
+	tradesRouter.HandleFunc("/{id}/counter", tradesController.Counter).Methods(http.MethodPost)
+	tradesRouter.HandleFunc("/{id}", tradesController.Cancel).Methods(http.MethodDelete)
+
 	bidsRouter := apiRouter.PathPrefix("/bids").Subrouter()
 	bidsRouter.Use(server.withAuth)
 	bidsRouter.HandleFunc("/offer/{card_id}", bidsController.GetMakeOfferData).Methods(http.MethodGet)
 	bidsRouter.HandleFunc("", bidsController.Bet).Methods(http.MethodPost)
 	bidsRouter.HandleFunc("/{lotId}", bidsController.ListByLotID).Methods(http.MethodGet)
+	bidsRouter.HandleFunc("/{lotId}/max", bidsController.GetMaxAmount).Methods(http.MethodGet)
 
 	queueRouter := apiRouter.PathPrefix("/queue").Subrouter()
 	queueRouter.Use(server.withAuth)
@@ -94,3 +94,17 @@ func (service *Service) SendVerificationEmailForChangeEmail(email, token string)
 
 	return Error.Wrap(service.sender.SendEmail(&verificationMessage))
 }
+
+// SendMarketplaceNotification is used to send email with notification about the lots on the marketplace.
+func (service *Service) SendMarketplaceNotification(email, subject, text string) error {
+	var notificationMessage mail.Message
+	url := "marketplace"
+
+	notificationMessage.To = []mail.Address{{Address: email, Name: "Marketplace"}}
+	notificationMessage.Date = time.Now().UTC()
+	notificationMessage.PlainText = fmt.Sprintf("%s\n%s/%s", text, service.config.Domain, url)
+	notificationMessage.Subject = subject
+	notificationMessage.From = mail.Address{Address: service.config.From}
+
+	return Error.Wrap(service.sender.SendEmail(&notificationMessage))
+}
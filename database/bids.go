@@ -7,10 +7,13 @@ import (
 	"context"
 	"database/sql"
 	"math/big"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/zeebo/errs"
 
+	"ultimatedivision/finances"
+	"ultimatedivision/marketplace"
 	"ultimatedivision/marketplace/bids"
 )
 
@@ -27,27 +30,131 @@ type bidsDB struct {
 	conn *sql.DB
 }
 
+const (
+	insertBidQuery = `INSERT INTO bids(id, lot_id, user_id, amount, automatic, created_at)
+	                  VALUES($1,$2,$3,$4,$5,$6)`
+	upsertProxyBidQuery = `INSERT INTO proxy_bids(lot_id, user_id, max_amount, created_at)
+	                       VALUES($1,$2,$3,$4)
+	                       ON CONFLICT(lot_id, user_id) DO UPDATE SET max_amount = EXCLUDED.max_amount, created_at = EXCLUDED.created_at`
+)
+
 // Create creates bid for lot in the database.
 func (bidsDB *bidsDB) Create(ctx context.Context, bid bids.Bid) error {
-	query := `INSERT INTO bids(id, lot_id, user_id, amount, created_at)
-	          VALUES($1,$2,$3,$4,$5)`
-	_, err := bidsDB.conn.ExecContext(ctx, query, bid.ID, bid.LotID, bid.UserID, bid.Amount.String(), bid.CreatedAt)
+	_, err := bidsDB.conn.ExecContext(ctx, insertBidQuery, bid.ID, bid.LotID, bid.UserID, bid.Amount.String(), bid.Automatic, bid.CreatedAt)
 	return ErrBids.Wrap(err)
 }
 
+// Place applies all changes of the placement in one transaction, returns ErrBidConflict if the lot is not active
+// anymore or its current bid or maximums of the users differ from the ones the placement was resolved from.
+func (bidsDB *bidsDB) Place(ctx context.Context, placement bids.Placement) error {
+	tx, err := bidsDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrBids.Wrap(err)
+	}
+
+	var lotID uuid.UUID
+	query := `SELECT id FROM lots WHERE id = $1 AND status = $2 AND end_time > $3 FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, placement.LotID, marketplace.StatusActive, time.Now().UTC()).Scan(&lotID)
+	if errs.Is(err, sql.ErrNoRows) {
+		return errs.Combine(bids.ErrBidConflict.New("lot is not active"), tx.Rollback())
+	}
+	if err != nil {
+		return ErrBids.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	var currentBidID uuid.UUID
+	query = `SELECT id FROM bids WHERE lot_id = $1 ORDER BY created_at DESC, amount DESC LIMIT 1`
+	err = tx.QueryRowContext(ctx, query, placement.LotID).Scan(&currentBidID)
+	if err != nil && !errs.Is(err, sql.ErrNoRows) {
+		return ErrBids.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+	if currentBidID != placement.CurrentBidID {
+		return errs.Combine(bids.ErrBidConflict.New("lot got another bid"), tx.Rollback())
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT lot_id, user_id, max_amount, created_at FROM proxy_bids WHERE lot_id = $1`, placement.LotID)
+	if err != nil {
+		return ErrBids.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+	proxyBids, err := scanProxyBids(rows)
+	if err = errs.Combine(err, rows.Close()); err != nil {
+		return ErrBids.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+	if !equalProxyBids(proxyBids, placement.ProxyBids) {
+		return errs.Combine(bids.ErrBidConflict.New("maximums of the lot are changed"), tx.Rollback())
+	}
+
+	if err = holdEscrow(ctx, tx, placement.Hold); err != nil {
+		return ErrBids.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	if proxyBid := placement.ProxyBid; proxyBid != nil {
+		_, err = tx.ExecContext(ctx, upsertProxyBidQuery, proxyBid.LotID, proxyBid.UserID, proxyBid.MaxAmount.String(), proxyBid.CreatedAt)
+		if err != nil {
+			return ErrBids.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	for _, release := range placement.Releases {
+		if err = withdrawProxyBid(ctx, tx, release); err != nil {
+			return ErrBids.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	if len(placement.Bids) == 0 {
+		return ErrBids.Wrap(tx.Commit())
+	}
+
+	for _, bid := range placement.Bids {
+		_, err = tx.ExecContext(ctx, insertBidQuery, bid.ID, bid.LotID, bid.UserID, bid.Amount.String(), bid.Automatic, bid.CreatedAt)
+		if err != nil {
+			return ErrBids.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	shopperBid := placement.Bids[len(placement.Bids)-1]
+	_, err = tx.ExecContext(ctx, "UPDATE lots SET current_price = $1, shopper_id = $2, end_time = $3 WHERE id = $4",
+		shopperBid.Amount.Bytes(), shopperBid.UserID, placement.EndTime, placement.LotID)
+	if err != nil {
+		return ErrBids.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	return ErrBids.Wrap(tx.Commit())
+}
+
+// equalProxyBids checks that both lists have the same maximums of the same users.
+func equalProxyBids(proxyBids, expected []bids.ProxyBid) bool {
+	if len(proxyBids) != len(expected) {
+		return false
+	}
+
+	maxAmounts := make(map[uuid.UUID]big.Int, len(proxyBids))
+	for _, proxyBid := range proxyBids {
+		maxAmounts[proxyBid.UserID] = proxyBid.MaxAmount
+	}
+	for _, proxyBid := range expected {
+		maxAmount, ok := maxAmounts[proxyBid.UserID]
+		if !ok || maxAmount.Cmp(&proxyBid.MaxAmount) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
 // GetCurrentBidByLotID returns current bid by lot id from the database.
 func (bidsDB *bidsDB) GetCurrentBidByLotID(ctx context.Context, lotID uuid.UUID) (bids.Bid, error) {
 	var (
 		bid    bids.Bid
 		amount string
 	)
-	query := `SELECT id, lot_id, user_id, amount, created_at
+	query := `SELECT id, lot_id, user_id, amount, automatic, created_at
 	          FROM bids
 	          WHERE lot_id = $1
-	          ORDER BY created_at DESC
+	          ORDER BY created_at DESC, amount DESC
 	          LIMIT 1`
 
-	err := bidsDB.conn.QueryRowContext(ctx, query, lotID).Scan(&bid.ID, &bid.LotID, &bid.UserID, &amount, &bid.CreatedAt)
+	err := bidsDB.conn.QueryRowContext(ctx, query, lotID).Scan(&bid.ID, &bid.LotID, &bid.UserID, &amount, &bid.Automatic, &bid.CreatedAt)
 	if errs.Is(sql.ErrNoRows, err) {
 		return bid, bids.ErrNoBid.Wrap(err)
 	}
@@ -65,9 +172,10 @@ func (bidsDB *bidsDB) ListByLotID(ctx context.Context, lotID uuid.UUID) (_ []bid
 		bidsList []bids.Bid
 		amount   string
 	)
-	query := `SELECT id, lot_id, user_id, amount, created_at
+	query := `SELECT id, lot_id, user_id, amount, automatic, created_at
 	          FROM bids
-	          WHERE lot_id = $1`
+	          WHERE lot_id = $1
+	          ORDER BY created_at, amount`
 
 	rows, err := bidsDB.conn.QueryContext(ctx, query, lotID)
 	if err != nil {
@@ -80,7 +188,7 @@ func (bidsDB *bidsDB) ListByLotID(ctx context.Context, lotID uuid.UUID) (_ []bid
 	for rows.Next() {
 		var bid bids.Bid
 		if err = rows.Scan(
-			&bid.ID, &bid.LotID, &bid.UserID, &amount, &bid.CreatedAt); err != nil {
+			&bid.ID, &bid.LotID, &bid.UserID, &amount, &bid.Automatic, &bid.CreatedAt); err != nil {
 			return nil, ErrBids.Wrap(err)
 		}
 		if _, ok := bid.Amount.SetString(amount, 10); !ok {
@@ -102,7 +210,7 @@ func (bidsDB *bidsDB) ListByUserID(ctx context.Context, userID uuid.UUID) (_ []b
 		bidsList []bids.Bid
 		amount   string
 	)
-	query := `SELECT id, lot_id, user_id, amount, created_at
+	query := `SELECT id, lot_id, user_id, amount, automatic, created_at
 	          FROM bids
 	          WHERE user_id = $1`
 
@@ -117,7 +225,7 @@ func (bidsDB *bidsDB) ListByUserID(ctx context.Context, userID uuid.UUID) (_ []b
 	for rows.Next() {
 		var bid bids.Bid
 		if err = rows.Scan(
-			&bid.ID, &bid.LotID, &bid.UserID, &amount, &bid.CreatedAt); err != nil {
+			&bid.ID, &bid.LotID, &bid.UserID, &amount, &bid.Automatic, &bid.CreatedAt); err != nil {
 			return nil, ErrBids.Wrap(err)
 		}
 		if _, ok := bid.Amount.SetString(amount, 10); !ok {
@@ -169,8 +277,12 @@ func (bidsDB *bidsDB) GetUserBidsAmountByLotID(ctx context.Context, userID, lotI
 	return bidsAmount, ErrBids.Wrap(err)
 }
 
-// DeleteByLotID deletes bids by lot id in the database.
+// DeleteByLotID deletes bids with proxy bids by lot id in the database.
 func (bidsDB *bidsDB) DeleteByLotID(ctx context.Context, lotID uuid.UUID) error {
+	if _, err := bidsDB.conn.ExecContext(ctx, "DELETE FROM proxy_bids WHERE lot_id=$1", lotID); err != nil {
+		return ErrBids.Wrap(err)
+	}
+
 	result, err := bidsDB.conn.ExecContext(ctx, "DELETE FROM bids WHERE lot_id=$1", lotID)
 	if err != nil {
 		return ErrBids.Wrap(err)
@@ -183,3 +295,99 @@ func (bidsDB *bidsDB) DeleteByLotID(ctx context.Context, lotID uuid.UUID) error
 
 	return ErrBids.Wrap(err)
 }
+
+// Withdraw deletes maximum amount of the user on the lot and returns the funds held for it in one transaction.
+func (bidsDB *bidsDB) Withdraw(ctx context.Context, release finances.Escrow) error {
+	tx, err := bidsDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrBids.Wrap(err)
+	}
+
+	if err = withdrawProxyBid(ctx, tx, release); err != nil {
+		return ErrBids.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	return ErrBids.Wrap(tx.Commit())
+}
+
+// withdrawProxyBid deletes maximum amount of the user on the lot within the database transaction,
+// so the system does not bid on behalf of the user whose funds are returned, and returns the funds.
+func withdrawProxyBid(ctx context.Context, tx *sql.Tx, release finances.Escrow) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM proxy_bids WHERE lot_id = $1 AND user_id = $2", release.LotID, release.UserID)
+	if err != nil {
+		return err
+	}
+
+	return releaseEscrow(ctx, tx, release)
+}
+
+// SetProxyBid creates or replaces maximum amount of the user on the lot in the database.
+func (bidsDB *bidsDB) SetProxyBid(ctx context.Context, proxyBid bids.ProxyBid) error {
+	_, err := bidsDB.conn.ExecContext(ctx, upsertProxyBidQuery, proxyBid.LotID, proxyBid.UserID, proxyBid.MaxAmount.String(), proxyBid.CreatedAt)
+	return ErrBids.Wrap(err)
+}
+
+// GetProxyBid returns maximum amount of the user on the lot from the database.
+func (bidsDB *bidsDB) GetProxyBid(ctx context.Context, lotID, userID uuid.UUID) (bids.ProxyBid, error) {
+	var (
+		proxyBid  bids.ProxyBid
+		maxAmount string
+	)
+	query := `SELECT lot_id, user_id, max_amount, created_at
+	          FROM proxy_bids
+	          WHERE lot_id = $1 AND user_id = $2`
+
+	err := bidsDB.conn.QueryRowContext(ctx, query, lotID, userID).Scan(&proxyBid.LotID, &proxyBid.UserID, &maxAmount, &proxyBid.CreatedAt)
+	if errs.Is(err, sql.ErrNoRows) {
+		return proxyBid, bids.ErrNoProxyBid.Wrap(err)
+	}
+	if err != nil {
+		return proxyBid, ErrBids.Wrap(err)
+	}
+	if _, ok := proxyBid.MaxAmount.SetString(maxAmount, 10); !ok {
+		return proxyBid, ErrBids.New("could not parse max amount equal %v from db", maxAmount)
+	}
+	proxyBid.CreatedAt = proxyBid.CreatedAt.UTC()
+
+	return proxyBid, nil
+}
+
+// ListProxyBidsByLotID returns maximum amounts of the users on the lot from the database.
+func (bidsDB *bidsDB) ListProxyBidsByLotID(ctx context.Context, lotID uuid.UUID) (_ []bids.ProxyBid, err error) {
+	query := `SELECT lot_id, user_id, max_amount, created_at
+	          FROM proxy_bids
+	          WHERE lot_id = $1
+	          ORDER BY max_amount DESC, created_at`
+
+	rows, err := bidsDB.conn.QueryContext(ctx, query, lotID)
+	if err != nil {
+		return nil, ErrBids.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	return scanProxyBids(rows)
+}
+
+// scanProxyBids scans maximums of the users from the rows.
+func scanProxyBids(rows *sql.Rows) ([]bids.ProxyBid, error) {
+	var proxyBids []bids.ProxyBid
+	for rows.Next() {
+		var (
+			proxyBid  bids.ProxyBid
+			maxAmount string
+		)
+		if err := rows.Scan(&proxyBid.LotID, &proxyBid.UserID, &maxAmount, &proxyBid.CreatedAt); err != nil {
+			return nil, ErrBids.Wrap(err)
+		}
+		if _, ok := proxyBid.MaxAmount.SetString(maxAmount, 10); !ok {
+			return nil, ErrBids.New("could not parse max amount equal %v from db", maxAmount)
+		}
+		proxyBid.CreatedAt = proxyBid.CreatedAt.UTC()
+
+		proxyBids = append(proxyBids, proxyBid)
+	}
+
+	return proxyBids, ErrBids.Wrap(rows.Err())
+}
@@ -8,16 +8,19 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
-	_ "github.com/lib/pq" // using postgres driver.
+	"github.com/lib/pq"
 	"github.com/zeebo/errs"
 
 	"ultimatedivision/cards"
 	"ultimatedivision/marketplace"
 	"ultimatedivision/pkg/pagination"
+	"ultimatedivision/pkg/sqlsearchoperators"
 )
 
 // ensures that cardsDB implements cards.DB.
@@ -38,20 +41,20 @@ const (
 		aggression, vision, awareness, crosses, physique, acceleration, running_speed, reaction_speed, agility, stamina, strength, jumping, 
 		balance, technique, dribbling, ball_control, weak_foot, skill_moves, finesse, curve, volleys, short_passing, long_passing, forward_pass, 
 		offense, finishing_ability, shot_power, accuracy, distance, penalty, free_kicks, corners, heading_accuracy, defence, offside_trap, 
-		sliding, tackles, ball_focus, interceptions, vigilance, goalkeeping, reflexes, diving, handling, sweeping, throwing, is_minted`
+		sliding, tackles, ball_focus, interceptions, vigilance, goalkeeping, reflexes, diving, handling, sweeping, throwing, is_minted, age, potential, nationality`
 )
 
-// Create adds card in the data base.
-func (cardsDB *cardsDB) Create(ctx context.Context, card cards.Card) error {
+// Create adds card in the data base with the records of its history.
+func (cardsDB *cardsDB) Create(ctx context.Context, card cards.Card, history ...cards.History) error {
 	query :=
 		`INSERT INTO
 			cards(` + allFields + `) 
 		VALUES 
 			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25,
 			$26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48, $49,
-			$50, $51, $52, $53, $54, $55, $56, $57, $58, $59, $60)`
+			$50, $51, $52, $53, $54, $55, $56, $57, $58, $59, $60, $61, $62, $63)`
 
-	_, err := cardsDB.conn.ExecContext(ctx, query,
+	return cardsDB.execWithHistory(ctx, history, false, query,
 		card.ID, card.PlayerName, card.Quality, card.Height, card.Weight,
 		card.DominantFoot, card.IsTattoo, card.Status, card.Type, card.UserID, card.Tactics, card.Positioning, card.Composure, card.Aggression,
 		card.Vision, card.Awareness, card.Crosses, card.Physique, card.Acceleration, card.RunningSpeed, card.ReactionSpeed, card.Agility,
@@ -59,10 +62,8 @@ func (cardsDB *cardsDB) Create(ctx context.Context, card cards.Card) error {
 		card.Finesse, card.Curve, card.Volleys, card.ShortPassing, card.LongPassing, card.ForwardPass, card.Offence, card.FinishingAbility,
 		card.ShotPower, card.Accuracy, card.Distance, card.Penalty, card.FreeKicks, card.Corners, card.HeadingAccuracy, card.Defence,
 		card.OffsideTrap, card.Sliding, card.Tackles, card.BallFocus, card.Interceptions, card.Vigilance, card.Goalkeeping, card.Reflexes,
-		card.Diving, card.Handling, card.Sweeping, card.Throwing, card.IsMinted,
+		card.Diving, card.Handling, card.Sweeping, card.Throwing, card.IsMinted, card.Age, card.Potential, card.Nationality,
 	)
-
-	return ErrCard.Wrap(err)
 }
 
 // Get returns card by id from the data base.
@@ -82,7 +83,7 @@ func (cardsDB *cardsDB) Get(ctx context.Context, id uuid.UUID) (cards.Card, erro
 		&card.BallControl, &card.WeakFoot, &card.SkillMoves, &card.Finesse, &card.Curve, &card.Volleys, &card.ShortPassing, &card.LongPassing,
 		&card.ForwardPass, &card.Offence, &card.FinishingAbility, &card.ShotPower, &card.Accuracy, &card.Distance, &card.Penalty, &card.FreeKicks,
 		&card.Corners, &card.HeadingAccuracy, &card.Defence, &card.OffsideTrap, &card.Sliding, &card.Tackles, &card.BallFocus, &card.Interceptions,
-		&card.Vigilance, &card.Goalkeeping, &card.Reflexes, &card.Diving, &card.Handling, &card.Sweeping, &card.Throwing, &card.IsMinted,
+		&card.Vigilance, &card.Goalkeeping, &card.Reflexes, &card.Diving, &card.Handling, &card.Sweeping, &card.Throwing, &card.IsMinted, &card.Age, &card.Potential, &card.Nationality,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return card, cards.ErrNoCard.Wrap(err)
@@ -120,7 +121,7 @@ func (cardsDB *cardsDB) GetByPlayerName(ctx context.Context, playerName string)
 		&card.BallControl, &card.WeakFoot, &card.SkillMoves, &card.Finesse, &card.Curve, &card.Volleys, &card.ShortPassing, &card.LongPassing,
 		&card.ForwardPass, &card.Offence, &card.FinishingAbility, &card.ShotPower, &card.Accuracy, &card.Distance, &card.Penalty, &card.FreeKicks,
 		&card.Corners, &card.HeadingAccuracy, &card.Defence, &card.OffsideTrap, &card.Sliding, &card.Tackles, &card.BallFocus, &card.Interceptions,
-		&card.Vigilance, &card.Goalkeeping, &card.Reflexes, &card.Diving, &card.Handling, &card.Sweeping, &card.Throwing, &card.IsMinted,
+		&card.Vigilance, &card.Goalkeeping, &card.Reflexes, &card.Diving, &card.Handling, &card.Sweeping, &card.Throwing, &card.IsMinted, &card.Age, &card.Potential, &card.Nationality,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return card, cards.ErrNoCard.Wrap(err)
@@ -132,16 +133,30 @@ func (cardsDB *cardsDB) GetByPlayerName(ctx context.Context, playerName string)
 // List returns all cards from the data base.
 func (cardsDB *cardsDB) List(ctx context.Context, cursor pagination.Cursor) (cards.Page, error) {
 	var cardsListPage cards.Page
-	offset := (cursor.Page - 1) * cursor.Limit
-	query :=
+	whereClause, values, err := keysetByID(cursor, "id", 1)
+	if err != nil {
+		return cardsListPage, ErrCard.Wrap(err)
+	}
+	if whereClause != "" {
+		whereClause = "WHERE " + whereClause
+	}
+	// the pages listed by page number keep the order of the table the admin templates are used to.
+	orderByClause := ""
+	if cursor.IsKeyset() {
+		orderByClause = "ORDER BY id"
+	}
+	limit, offset := keysetLimitOffset(cursor)
+	query := fmt.Sprintf(
 		`SELECT * FROM
 			cards 
+		%s
+		%s
 		LIMIT 
-			$1
+			%d
 		OFFSET 
-			$2`
+			%d`, whereClause, orderByClause, limit, offset)
 
-	rows, err := cardsDB.conn.QueryContext(ctx, query, cursor.Limit, offset)
+	rows, err := cardsDB.conn.QueryContext(ctx, query, values...)
 	if err != nil {
 		return cardsListPage, ErrCard.Wrap(err)
 	}
@@ -160,7 +175,7 @@ func (cardsDB *cardsDB) List(ctx context.Context, cursor pagination.Cursor) (car
 			&card.LongPassing, &card.ForwardPass, &card.Offence, &card.FinishingAbility, &card.ShotPower, &card.Accuracy, &card.Distance,
 			&card.Penalty, &card.FreeKicks, &card.Corners, &card.HeadingAccuracy, &card.Defence, &card.OffsideTrap, &card.Sliding, &card.Tackles,
 			&card.BallFocus, &card.Interceptions, &card.Vigilance, &card.Goalkeeping, &card.Reflexes, &card.Diving, &card.Handling, &card.Sweeping,
-			&card.Throwing, &card.IsMinted,
+			&card.Throwing, &card.IsMinted, &card.Age, &card.Potential, &card.Nationality,
 		); err != nil {
 			return cardsListPage, ErrCard.Wrap(err)
 		}
@@ -171,6 +186,10 @@ func (cardsDB *cardsDB) List(ctx context.Context, cursor pagination.Cursor) (car
 		return cardsListPage, ErrCard.Wrap(err)
 	}
 
+	if cursor.IsKeyset() {
+		return cardsListKeyset(cursor, data), nil
+	}
+
 	totalCount, err := cardsDB.totalCount(ctx)
 	if err != nil {
 		return cardsListPage, ErrCard.Wrap(err)
@@ -183,18 +202,27 @@ func (cardsDB *cardsDB) List(ctx context.Context, cursor pagination.Cursor) (car
 // ListByUserID returns all users cards from the database.
 func (cardsDB *cardsDB) ListByUserID(ctx context.Context, id uuid.UUID, cursor pagination.Cursor) (cards.Page, error) {
 	var userCardsPage cards.Page
-	offset := (cursor.Page - 1) * cursor.Limit
-	query :=
+	whereClause, values, err := keysetByID(cursor, "id", 2)
+	if err != nil {
+		return userCardsPage, ErrCard.Wrap(err)
+	}
+	if whereClause != "" {
+		whereClause = "AND " + whereClause
+	}
+	limit, offset := keysetLimitOffset(cursor)
+	query := fmt.Sprintf(
 		`SELECT * FROM  
 			cards 
 		WHERE 
-			user_id = $1
+			user_id = $1 %s
+		ORDER BY
+			id
 		LIMIT 
-			$2
+			%d
 		OFFSET 
-			$3`
+			%d`, whereClause, limit, offset)
 
-	rows, err := cardsDB.conn.QueryContext(ctx, query, id, cursor.Limit, offset)
+	rows, err := cardsDB.conn.QueryContext(ctx, query, append([]interface{}{id}, values...)...)
 	if err != nil {
 		return userCardsPage, ErrCard.Wrap(err)
 	}
@@ -213,7 +241,7 @@ func (cardsDB *cardsDB) ListByUserID(ctx context.Context, id uuid.UUID, cursor p
 			&card.LongPassing, &card.ForwardPass, &card.Offence, &card.FinishingAbility, &card.ShotPower, &card.Accuracy, &card.Distance,
 			&card.Penalty, &card.FreeKicks, &card.Corners, &card.HeadingAccuracy, &card.Defence, &card.OffsideTrap, &card.Sliding, &card.Tackles,
 			&card.BallFocus, &card.Interceptions, &card.Vigilance, &card.Goalkeeping, &card.Reflexes, &card.Diving, &card.Handling, &card.Sweeping,
-			&card.Throwing, &card.IsMinted,
+			&card.Throwing, &card.IsMinted, &card.Age, &card.Potential, &card.Nationality,
 		); err != nil {
 			return userCardsPage, ErrCard.Wrap(err)
 		}
@@ -224,12 +252,17 @@ func (cardsDB *cardsDB) ListByUserID(ctx context.Context, id uuid.UUID, cursor p
 		return userCardsPage, ErrCard.Wrap(err)
 	}
 
+	if cursor.IsKeyset() {
+		return cardsListKeyset(cursor, userCards), nil
+	}
+
 	totalCount, err := cardsDB.totalCountWithFilters(ctx, "WHERE user_id = $1", []interface{}{id})
 	if err != nil {
 		return userCardsPage, ErrCard.Wrap(err)
 	}
 
 	userCardsPage, err = cardsDB.listPaginated(ctx, cursor, userCards, totalCount)
+	userCardsPage.Page.NextToken = cardsNextToken(userCardsPage.Page, userCards)
 	return userCardsPage, ErrCard.Wrap(err)
 }
 
@@ -256,7 +289,7 @@ func (cardsDB *cardsDB) ListByTypeUnordered(ctx context.Context) ([]cards.Card,
 			&card.LongPassing, &card.ForwardPass, &card.Offence, &card.FinishingAbility, &card.ShotPower, &card.Accuracy, &card.Distance,
 			&card.Penalty, &card.FreeKicks, &card.Corners, &card.HeadingAccuracy, &card.Defence, &card.OffsideTrap, &card.Sliding, &card.Tackles,
 			&card.BallFocus, &card.Interceptions, &card.Vigilance, &card.Goalkeeping, &card.Reflexes, &card.Diving, &card.Handling, &card.Sweeping,
-			&card.Throwing, &card.IsMinted,
+			&card.Throwing, &card.IsMinted, &card.Age, &card.Potential, &card.Nationality,
 		); err != nil {
 			return nil, ErrCard.Wrap(err)
 		}
@@ -279,7 +312,7 @@ func (cardsDB *cardsDB) ListWithFilters(ctx context.Context, filters []cards.Fil
             cards.user_id, tactics, positioning, composure, aggression, vision, awareness, crosses, physique, acceleration, running_speed, reaction_speed, agility,
             stamina, strength, jumping, balance, technique, dribbling, ball_control, weak_foot, skill_moves, finesse, curve, volleys, short_passing, long_passing,
             forward_pass, offense, finishing_ability, shot_power, accuracy, distance, penalty, free_kicks, corners, heading_accuracy, defence, offside_trap, sliding,
-            tackles, ball_focus, interceptions, vigilance, goalkeeping, reflexes, diving, handling, sweeping, throwing, is_minted
+            tackles, ball_focus, interceptions, vigilance, goalkeeping, reflexes, diving, handling, sweeping, throwing, is_minted, age, potential, nationality
         FROM
             cards 
         %s
@@ -309,7 +342,7 @@ func (cardsDB *cardsDB) ListWithFilters(ctx context.Context, filters []cards.Fil
 			&card.ForwardPass, &card.Offence, &card.FinishingAbility, &card.ShotPower, &card.Accuracy, &card.Distance, &card.Penalty,
 			&card.FreeKicks, &card.Corners, &card.HeadingAccuracy, &card.Defence, &card.OffsideTrap, &card.Sliding, &card.Tackles, &card.BallFocus,
 			&card.Interceptions, &card.Vigilance, &card.Goalkeeping, &card.Reflexes, &card.Diving, &card.Handling, &card.Sweeping, &card.Throwing,
-			&card.IsMinted,
+			&card.IsMinted, &card.Age, &card.Potential, &card.Nationality,
 		); err != nil {
 			return cardsListPage, ErrCard.Wrap(err)
 		}
@@ -329,6 +362,106 @@ func (cardsDB *cardsDB) ListWithFilters(ctx context.Context, filters []cards.Fil
 	return cardsListPage, ErrCard.Wrap(err)
 }
 
+// ListWithQuery returns cards matching the query from DB.
+func (cardsDB *cardsDB) ListWithQuery(ctx context.Context, query cards.Query, cursor pagination.Cursor) (cards.Page, error) {
+	var cardsListPage cards.Page
+	whereClause, orderByClause, values, err := BuildCardsQuery(query, 1)
+	if err != nil {
+		return cardsListPage, ErrCard.Wrap(err)
+	}
+	keys, err := cardsQuerySortKeys(query)
+	if err != nil {
+		return cardsListPage, ErrCard.Wrap(err)
+	}
+	keysetClause, keysetValues, err := cardsQueryKeyset(keys, cursor, len(values)+1)
+	if err != nil {
+		return cardsListPage, ErrCard.Wrap(err)
+	}
+	if whereClause != "" {
+		whereClause = " WHERE " + whereClause
+	}
+	listWhereClause := whereClause
+	if keysetClause != "" {
+		if listWhereClause == "" {
+			listWhereClause = " WHERE " + keysetClause
+		} else {
+			listWhereClause += " AND " + keysetClause
+		}
+	}
+
+	limit, offset := keysetLimitOffset(cursor)
+	sqlQuery := fmt.Sprintf(`
+        SELECT
+            cards.id, player_name, quality, height, weight, dominant_foot, is_tattoo, cards.status, cards.type,
+            cards.user_id, tactics, positioning, composure, aggression, vision, awareness, crosses, physique, acceleration, running_speed, reaction_speed, agility,
+            stamina, strength, jumping, balance, technique, dribbling, ball_control, weak_foot, skill_moves, finesse, curve, volleys, short_passing, long_passing,
+            forward_pass, offense, finishing_ability, shot_power, accuracy, distance, penalty, free_kicks, corners, heading_accuracy, defence, offside_trap, sliding,
+            tackles, ball_focus, interceptions, vigilance, goalkeeping, reflexes, diving, handling, sweeping, throwing, is_minted, age, potential, nationality
+            %s
+        FROM
+            cards
+        %s
+        %s
+        %s
+        LIMIT 
+            %d
+        OFFSET 
+            %d
+        `, cardsQueryKeyColumns(keys), cardsQueryJoinLots, listWhereClause, orderByClause, limit, offset)
+
+	rows, err := cardsDB.conn.QueryContext(ctx, sqlQuery, append(values, keysetValues...)...)
+	if err != nil {
+		return cardsListPage, ErrCard.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	data := []cards.Card{}
+	var rowKeys [][]string
+	for rows.Next() {
+		card := cards.Card{}
+		key := make([]string, len(keys))
+		if err = rows.Scan(append([]interface{}{
+			&card.ID, &card.PlayerName, &card.Quality, &card.Height, &card.Weight,
+			&card.DominantFoot, &card.IsTattoo, &card.Status, &card.Type, &card.UserID, &card.Tactics, &card.Positioning,
+			&card.Composure, &card.Aggression, &card.Vision, &card.Awareness, &card.Crosses, &card.Physique, &card.Acceleration, &card.RunningSpeed,
+			&card.ReactionSpeed, &card.Agility, &card.Stamina, &card.Strength, &card.Jumping, &card.Balance, &card.Technique, &card.Dribbling,
+			&card.BallControl, &card.WeakFoot, &card.SkillMoves, &card.Finesse, &card.Curve, &card.Volleys, &card.ShortPassing, &card.LongPassing,
+			&card.ForwardPass, &card.Offence, &card.FinishingAbility, &card.ShotPower, &card.Accuracy, &card.Distance, &card.Penalty,
+			&card.FreeKicks, &card.Corners, &card.HeadingAccuracy, &card.Defence, &card.OffsideTrap, &card.Sliding, &card.Tackles, &card.BallFocus,
+			&card.Interceptions, &card.Vigilance, &card.Goalkeeping, &card.Reflexes, &card.Diving, &card.Handling, &card.Sweeping, &card.Throwing,
+			&card.IsMinted, &card.Age, &card.Potential, &card.Nationality,
+		}, sortKeyDestinations(key)...)...); err != nil {
+			return cardsListPage, ErrCard.Wrap(err)
+		}
+
+		data = append(data, card)
+		rowKeys = append(rowKeys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return cardsListPage, ErrCard.Wrap(err)
+	}
+
+	if cursor.IsKeyset() {
+		cardsListPage = cards.Page{Cards: data, Page: pagination.Page{Limit: cursor.Limit}}
+		if len(data) > cursor.Limit {
+			cardsListPage.Cards = data[:cursor.Limit]
+			cardsListPage.Page.NextToken = pagination.EncodeToken(rowKeys[cursor.Limit-1]...)
+		}
+		return cardsListPage, nil
+	}
+
+	totalCount, err := cardsDB.totalCountWithFilters(ctx, cardsQueryJoinLots+whereClause, values)
+	if err != nil {
+		return cardsListPage, ErrCard.Wrap(err)
+	}
+
+	cardsListPage, err = cardsDB.listPaginated(ctx, cursor, data, totalCount)
+	cardsListPage.Page.NextToken = queryNextToken(cardsListPage.Page, rowKeys)
+	return cardsListPage, ErrCard.Wrap(err)
+}
+
 // ListCardIDsWithFiltersWhereActiveLot returns card ids where active lots from DB, taking the necessary filters.
 func (cardsDB *cardsDB) ListCardIDsWithFiltersWhereActiveLot(ctx context.Context, filters []cards.Filters) ([]uuid.UUID, error) {
 	whereClause, valuesString := BuildWhereClauseDependsOnCardsFilters(filters)
@@ -396,7 +529,7 @@ func (cardsDB *cardsDB) ListByUserIDAndPlayerName(ctx context.Context, userID uu
 			&card.ForwardPass, &card.Offence, &card.FinishingAbility, &card.ShotPower, &card.Accuracy, &card.Distance, &card.Penalty,
 			&card.FreeKicks, &card.Corners, &card.HeadingAccuracy, &card.Defence, &card.OffsideTrap, &card.Sliding, &card.Tackles, &card.BallFocus,
 			&card.Interceptions, &card.Vigilance, &card.Goalkeeping, &card.Reflexes, &card.Diving, &card.Handling, &card.Sweeping, &card.Throwing,
-			&card.IsMinted,
+			&card.IsMinted, &card.Age, &card.Potential, &card.Nationality,
 		); err != nil {
 			return cardsListPage, ErrCard.Wrap(err)
 		}
@@ -474,6 +607,36 @@ func (cardsDB *cardsDB) listPaginated(ctx context.Context, cursor pagination.Cur
 	return cardsListPage, nil
 }
 
+// cardsListKeyset returns keyset page of cards, cardsList holds one extra card if there is a next page.
+func cardsListKeyset(cursor pagination.Cursor, cardsList []cards.Card) cards.Page {
+	page := cards.Page{
+		Cards: cardsList,
+		Page:  pagination.Page{Limit: cursor.Limit},
+	}
+	if len(cardsList) > cursor.Limit {
+		page.Cards = cardsList[:cursor.Limit]
+		page.Page.NextToken = pagination.EncodeToken(page.Cards[cursor.Limit-1].ID.String())
+	}
+	return page
+}
+
+// cardsNextToken returns continuation token for the page of cards listed by page number, it is empty on the last page.
+func cardsNextToken(page pagination.Page, cardsList []cards.Card) string {
+	if len(cardsList) == 0 || page.Offset+len(cardsList) >= page.TotalCount {
+		return ""
+	}
+	return pagination.EncodeToken(cardsList[len(cardsList)-1].ID.String())
+}
+
+// queryNextToken returns continuation token of the query listed by page number, it holds sort keys of the last row
+// and is empty on the last page.
+func queryNextToken(page pagination.Page, rowKeys [][]string) string {
+	if len(rowKeys) == 0 || page.Offset+len(rowKeys) >= page.TotalCount {
+		return ""
+	}
+	return pagination.EncodeToken(rowKeys[len(rowKeys)-1]...)
+}
+
 // totalCount counts all the cards in the table.
 func (cardsDB *cardsDB) totalCount(ctx context.Context) (int, error) {
 	var count int
@@ -496,6 +659,32 @@ func (cardsDB *cardsDB) totalCountWithFilters(ctx context.Context, whereClause s
 	return count, ErrCard.Wrap(err)
 }
 
+// keysetByID returns condition and its values to continue listing ordered by id column after the continuation token.
+func keysetByID(cursor pagination.Cursor, column string, placeholder int) (string, []interface{}, error) {
+	if !cursor.IsKeyset() {
+		return "", nil, nil
+	}
+
+	key, err := pagination.DecodeToken(cursor.Token, 1)
+	if err != nil {
+		return "", nil, err
+	}
+	id, err := uuid.Parse(key[0])
+	if err != nil {
+		return "", nil, pagination.ErrInvalidToken.Wrap(err)
+	}
+
+	return fmt.Sprintf("%s > $%d", column, placeholder), []interface{}{id}, nil
+}
+
+// keysetLimitOffset returns LIMIT and OFFSET of the listing, keyset listing requests one extra row to know if there is a next page.
+func keysetLimitOffset(cursor pagination.Cursor) (int, int) {
+	if cursor.IsKeyset() {
+		return cursor.Limit + 1, 0
+	}
+	return cursor.Limit, (cursor.Page - 1) * cursor.Limit
+}
+
 // ValidDBParameters build valid parameter with string to sinterface.
 func ValidDBParameters(stringSlice []string) []interface{} {
 	interfaceSlice := make([]interface{}, 0, len(stringSlice))
@@ -598,64 +787,405 @@ func BuildWhereClauseDependsOnPlayerNameCards(filter cards.Filters) (string, []s
 	return query, values
 }
 
-// UpdateStatus updates status card in the database.
-func (cardsDB *cardsDB) UpdateStatus(ctx context.Context, id uuid.UUID, status cards.Status) error {
-	result, err := cardsDB.conn.ExecContext(ctx, "UPDATE cards SET status=$1 WHERE id=$2", status, id)
+// cardsQueryColumns maps query filters to the sql expressions, only these expressions get into the compiled query.
+var cardsQueryColumns = map[cards.Filter]string{
+	cards.FilterTactics:          "cards.tactics",
+	cards.FilterPositioning:      "cards.positioning",
+	cards.FilterComposure:        "cards.composure",
+	cards.FilterAggression:       "cards.aggression",
+	cards.FilterVision:           "cards.vision",
+	cards.FilterAwareness:        "cards.awareness",
+	cards.FilterCrosses:          "cards.crosses",
+	cards.FilterPhysique:         "cards.physique",
+	cards.FilterAcceleration:     "cards.acceleration",
+	cards.FilterRunningSpeed:     "cards.running_speed",
+	cards.FilterReactionSpeed:    "cards.reaction_speed",
+	cards.FilterAgility:          "cards.agility",
+	cards.FilterStamina:          "cards.stamina",
+	cards.FilterStrength:         "cards.strength",
+	cards.FilterJumping:          "cards.jumping",
+	cards.FilterBalance:          "cards.balance",
+	cards.FilterTechnique:        "cards.technique",
+	cards.FilterDribbling:        "cards.dribbling",
+	cards.FilterBallControl:      "cards.ball_control",
+	cards.FilterWeakFoot:         "cards.weak_foot",
+	cards.FilterSkillMoves:       "cards.skill_moves",
+	cards.FilterFinesse:          "cards.finesse",
+	cards.FilterCurve:            "cards.curve",
+	cards.FilterVolleys:          "cards.volleys",
+	cards.FilterShortPassing:     "cards.short_passing",
+	cards.FilterLongPassing:      "cards.long_passing",
+	cards.FilterForwardPass:      "cards.forward_pass",
+	cards.FilterOffense:          "cards.offense",
+	cards.FilterFinishingAbility: "cards.finishing_ability",
+	cards.FilterShotPower:        "cards.shot_power",
+	cards.FilterAccuracy:         "cards.accuracy",
+	cards.FilterDistance:         "cards.distance",
+	cards.FilterPenalty:          "cards.penalty",
+	cards.FilterFreeKicks:        "cards.free_kicks",
+	cards.FilterCorners:          "cards.corners",
+	cards.FilterHeadingAccuracy:  "cards.heading_accuracy",
+	cards.FilterDefence:          "cards.defence",
+	cards.FilterOffsideTrap:      "cards.offside_trap",
+	cards.FilterSliding:          "cards.sliding",
+	cards.FilterTackles:          "cards.tackles",
+	cards.FilterBallFocus:        "cards.ball_focus",
+	cards.FilterInterceptions:    "cards.interceptions",
+	cards.FilterVigilance:        "cards.vigilance",
+	cards.FilterGoalkeeping:      "cards.goalkeeping",
+	cards.FilterReflexes:         "cards.reflexes",
+	cards.FilterDiving:           "cards.diving",
+	cards.FilterHandling:         "cards.handling",
+	cards.FilterSweeping:         "cards.sweeping",
+	cards.FilterThrowing:         "cards.throwing",
+	cards.FilterQuality:          "cards.quality",
+	cards.FilterHeight:           "cards.height",
+	cards.FilterWeight:           "cards.weight",
+	cards.FilterDominantFoot:     "cards.dominant_foot",
+	cards.FilterType:             "cards.type",
+	cards.FilterPlayerName:       "cards.player_name",
+	cards.FilterUserID:           "cards.user_id",
+	cards.FilterRating: fmt.Sprintf("((cards.tactics + cards.physique + cards.technique + cards.offense + cards.defence + cards.goalkeeping) / %d.0)",
+		cards.RatingSkillsCount),
+}
+
+// cardsQueryPrice is an expression of the lot price, prices are stored as big-endian bytes, so they are compared by length first.
+const cardsQueryPrice = "CASE WHEN lots.current_price IS NULL OR length(lots.current_price) = 0 THEN lots.start_price ELSE lots.current_price END"
+
+// cardsQueryJoinLots joins active lots to the cards through the cards of the lots, so the price could be used in the query,
+// card is in at most one active lot.
+const cardsQueryJoinLots = " LEFT JOIN (lot_cards JOIN lots ON lots.id = lot_cards.lot_id AND lots.status = '" + string(marketplace.StatusActive) + "') ON lot_cards.card_id = cards.id "
+
+// BuildCardsQuery compiles cards query to the sql condition and ORDER BY clause.
+// Placeholders are numbered starting from firstPlaceholder, expects that lots are joined to the cards.
+func BuildCardsQuery(query cards.Query, firstPlaceholder int) (string, string, []interface{}, error) {
+	compiler := cardsQueryCompiler{placeholder: firstPlaceholder}
+
+	var whereClause string
+	if query.Where != nil {
+		var err error
+		if whereClause, err = compiler.condition(*query.Where); err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	keys, err := cardsQuerySortKeys(query)
 	if err != nil {
-		return ErrCard.Wrap(err)
+		return "", "", nil, err
 	}
 
-	rowNum, err := result.RowsAffected()
-	if rowNum == 0 {
-		return cards.ErrNoCard.New("")
+	orderBy := make([]string, 0, len(keys))
+	for _, key := range keys {
+		orderBy = append(orderBy, key.String())
 	}
 
-	return ErrCard.Wrap(err)
+	return whereClause, " ORDER BY " + strings.Join(orderBy, ", "), compiler.values, nil
 }
 
-// UpdateMintedStatus updates status card in the database.
-func (cardsDB *cardsDB) UpdateMintedStatus(ctx context.Context, id uuid.UUID, status int) error {
-	result, err := cardsDB.conn.ExecContext(ctx, "UPDATE cards SET is_minted=$1 WHERE id=$2", status, id)
+// cardsSortKey is a single expression of the cards query order, empty order means ascending order of the tie-breaker.
+type cardsSortKey struct {
+	expression string
+	order      string
+}
+
+// String returns sort key as a part of the ORDER BY clause.
+func (key cardsSortKey) String() string {
+	return strings.TrimSpace(key.expression + " " + key.order)
+}
+
+// cardsQuerySortKeys returns sort keys of the query, cards.id is always the last key so the order is total.
+// Price is split to the null flag, length and value, so its keys are never null and could be compared in keyset condition.
+func cardsQuerySortKeys(query cards.Query) ([]cardsSortKey, error) {
+	var keys []cardsSortKey
+	for _, sort := range query.Sort {
+		order := "ASC"
+		if sort.Order == cards.OrderDesc {
+			order = "DESC"
+		}
+
+		if sort.Name == cards.FilterPrice {
+			keys = append(keys,
+				cardsSortKey{expression: fmt.Sprintf("((%s) IS NULL)", cardsQueryPrice), order: order},
+				cardsSortKey{expression: fmt.Sprintf("COALESCE(length(%s), 0)", cardsQueryPrice), order: order},
+				cardsSortKey{expression: fmt.Sprintf("COALESCE(%s, ''::BYTEA)", cardsQueryPrice), order: order},
+			)
+			continue
+		}
+
+		column, ok := cardsQueryColumns[sort.Name]
+		if !ok {
+			return nil, cards.ErrInvalidFilter.New("invalid sort parameter - %s", sort.Name)
+		}
+		keys = append(keys, cardsSortKey{expression: column, order: order})
+	}
+
+	return append(keys, cardsSortKey{expression: "cards.id"}), nil
+}
+
+// cardsQueryKeyColumns returns text values of the sort keys to be selected after the listed columns.
+func cardsQueryKeyColumns(keys []cardsSortKey) string {
+	var columns string
+	for _, key := range keys {
+		columns += fmt.Sprintf(", (%s)::TEXT", key.expression)
+	}
+	return columns
+}
+
+// cardsQueryKeyset returns condition and its values to continue listing of the cards query after the continuation token.
+// Token holds text values of the sort keys of the last listed row, they are compared in the order of the keys.
+func cardsQueryKeyset(keys []cardsSortKey, cursor pagination.Cursor, placeholder int) (string, []interface{}, error) {
+	if !cursor.IsKeyset() {
+		return "", nil, nil
+	}
+
+	key, err := pagination.DecodeToken(cursor.Token, len(keys))
 	if err != nil {
-		return ErrCard.Wrap(err)
+		return "", nil, err
 	}
 
-	rowNum, err := result.RowsAffected()
-	if rowNum == 0 {
-		return cards.ErrNoCard.New("")
+	values := make([]interface{}, 0, len(keys))
+	conditions := make([]string, 0, len(keys))
+	for i, sortKey := range keys {
+		values = append(values, key[i])
+
+		operator := ">"
+		if sortKey.order == "DESC" {
+			operator = "<"
+		}
+
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf("%s = $%d", keys[j].expression, placeholder+j))
+		}
+		parts = append(parts, fmt.Sprintf("%s %s $%d", sortKey.expression, operator, placeholder+i))
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
 	}
 
-	return ErrCard.Wrap(err)
+	return "(" + strings.Join(conditions, " OR ") + ")", values, nil
 }
 
-// UpdateType updates type of card in the database.
-func (cardsDB *cardsDB) UpdateType(ctx context.Context, id uuid.UUID, typeCard cards.Type) error {
-	result, err := cardsDB.conn.ExecContext(ctx, "UPDATE cards SET type=$1 WHERE id=$2", typeCard, id)
-	if err != nil {
-		return ErrCard.Wrap(err)
+// sortKeyDestinations returns scan destinations of the selected sort keys.
+func sortKeyDestinations(key []string) []interface{} {
+	destinations := make([]interface{}, 0, len(key))
+	for i := range key {
+		destinations = append(destinations, &key[i])
 	}
+	return destinations
+}
 
-	rowNum, err := result.RowsAffected()
-	if rowNum == 0 {
-		return cards.ErrNoCard.New("")
+// cardsQueryCompiler holds state of the cards query compilation.
+type cardsQueryCompiler struct {
+	placeholder int
+	values      []interface{}
+}
+
+// bind adds value to the list of the query values and returns its placeholder.
+func (compiler *cardsQueryCompiler) bind(value interface{}) string {
+	compiler.values = append(compiler.values, value)
+	placeholder := "$" + strconv.Itoa(compiler.placeholder)
+	compiler.placeholder++
+	return placeholder
+}
+
+// condition compiles condition of the query.
+func (compiler *cardsQueryCompiler) condition(condition cards.Condition) (string, error) {
+	if condition.Filter != nil {
+		return compiler.filter(*condition.Filter)
 	}
 
-	return ErrCard.Wrap(err)
+	var operator string
+	switch condition.Operator {
+	case cards.LogicalOperatorAnd:
+		operator = " AND "
+	case cards.LogicalOperatorOr:
+		operator = " OR "
+	default:
+		return "", cards.ErrInvalidFilter.New("invalid logical operator - %s", condition.Operator)
+	}
+
+	var conditions []string
+	for _, nested := range condition.Conditions {
+		compiled, err := compiler.condition(nested)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, compiled)
+	}
+	if len(conditions) == 0 {
+		return "", cards.ErrInvalidFilter.New("group of conditions could not be empty")
+	}
+
+	return "(" + strings.Join(conditions, operator) + ")", nil
 }
 
-// UpdateUserID updates user id card in the database.
-func (cardsDB *cardsDB) UpdateUserID(ctx context.Context, cardID, userID uuid.UUID) error {
-	result, err := cardsDB.conn.ExecContext(ctx, "UPDATE cards SET user_id=$1 WHERE id=$2", userID, cardID)
-	if err != nil {
-		return ErrCard.Wrap(err)
+// filter compiles single filter of the query.
+func (compiler *cardsQueryCompiler) filter(filter cards.Filters) (string, error) {
+	if filter.Name == cards.FilterPrice {
+		return compiler.priceFilter(filter)
 	}
 
+	column, ok := cardsQueryColumns[filter.Name]
+	if !ok {
+		return "", cards.ErrInvalidFilter.New("invalid name parameter - %s", filter.Name)
+	}
+
+	values := filter.Values()
+	switch filter.SearchOperator {
+	case sqlsearchoperators.EQ, sqlsearchoperators.GT, sqlsearchoperators.LT, sqlsearchoperators.GTE, sqlsearchoperators.LTE, sqlsearchoperators.LIKE:
+		return fmt.Sprintf("%s %s %s", column, filter.SearchOperator, compiler.bind(filter.Value)), nil
+	case sqlsearchoperators.IN:
+		placeholders := make([]string, 0, len(values))
+		for _, value := range values {
+			placeholders = append(placeholders, compiler.bind(value))
+		}
+		return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", ")), nil
+	case sqlsearchoperators.BETWEEN:
+		if len(values) != 2 {
+			return "", cards.ErrInvalidFilter.New("%s requires exactly two values for %s", filter.SearchOperator, filter.Name)
+		}
+		return fmt.Sprintf("%s BETWEEN %s AND %s", column, compiler.bind(values[0]), compiler.bind(values[1])), nil
+	default:
+		return "", cards.ErrInvalidFilter.New("'%s' not suitable for %s", filter.SearchOperator, filter.Name)
+	}
+}
+
+// priceFilter compiles filter by lot price, byte strings of the prices are compared together with their length.
+func (compiler *cardsQueryCompiler) priceFilter(filter cards.Filters) (string, error) {
+	var prices [][]byte
+	for _, value := range filter.Values() {
+		price, ok := new(big.Float).SetPrec(256).SetString(value)
+		if !ok || price.Sign() < 0 {
+			return "", cards.ErrInvalidFilter.New("%s is not a valid price", value)
+		}
+		amount, _ := price.Int(nil)
+		prices = append(prices, amount.Bytes())
+	}
+
+	compare := func(operator sqlsearchoperators.SearchOperator, price []byte) string {
+		placeholder := compiler.bind(price) + "::BYTEA"
+		return fmt.Sprintf("(length(%s), %s) %s (length(%s), %s)", cardsQueryPrice, cardsQueryPrice, operator, placeholder, placeholder)
+	}
+
+	switch filter.SearchOperator {
+	case sqlsearchoperators.EQ, sqlsearchoperators.GT, sqlsearchoperators.LT, sqlsearchoperators.GTE, sqlsearchoperators.LTE:
+		return compare(filter.SearchOperator, prices[0]), nil
+	case sqlsearchoperators.IN:
+		placeholders := make([]string, 0, len(prices))
+		for _, price := range prices {
+			placeholders = append(placeholders, compiler.bind(price)+"::BYTEA")
+		}
+		return fmt.Sprintf("%s IN (%s)", cardsQueryPrice, strings.Join(placeholders, ", ")), nil
+	case sqlsearchoperators.BETWEEN:
+		if len(prices) != 2 {
+			return "", cards.ErrInvalidFilter.New("%s requires exactly two values for %s", filter.SearchOperator, filter.Name)
+		}
+		return "(" + compare(sqlsearchoperators.GTE, prices[0]) + " AND " + compare(sqlsearchoperators.LTE, prices[1]) + ")", nil
+	default:
+		return "", cards.ErrInvalidFilter.New("'%s' not suitable for %s", filter.SearchOperator, filter.Name)
+	}
+}
+
+// UpdateStatus updates status card in the database with the records of the card history.
+func (cardsDB *cardsDB) UpdateStatus(ctx context.Context, id uuid.UUID, status cards.Status, history ...cards.History) error {
+	return cardsDB.execWithHistory(ctx, history, true, "UPDATE cards SET status=$1 WHERE id=$2", status, id)
+}
+
+// UpdateMintedStatus updates minted status of card in the database with the records of the card history.
+func (cardsDB *cardsDB) UpdateMintedStatus(ctx context.Context, id uuid.UUID, status int, history ...cards.History) error {
+	return cardsDB.execWithHistory(ctx, history, true, "UPDATE cards SET is_minted=$1 WHERE id=$2", status, id)
+}
+
+// UpdateType updates type of card in the database with the records of the card history.
+func (cardsDB *cardsDB) UpdateType(ctx context.Context, id uuid.UUID, typeCard cards.Type, history ...cards.History) error {
+	return cardsDB.execWithHistory(ctx, history, true, "UPDATE cards SET type=$1 WHERE id=$2", typeCard, id)
+}
+
+// UpdateAging updates age and physical skills of the card in the database with the records of the card history.
+func (cardsDB *cardsDB) UpdateAging(ctx context.Context, card cards.Card, history ...cards.History) error {
+	query := `UPDATE cards
+	          SET age = $1, physique = $2, acceleration = $3, running_speed = $4, reaction_speed = $5, agility = $6,
+	              stamina = $7, strength = $8, jumping = $9, balance = $10
+	          WHERE id = $11`
+
+	return cardsDB.execWithHistory(ctx, history, true, query, card.Age, card.Physique, card.Acceleration, card.RunningSpeed,
+		card.ReactionSpeed, card.Agility, card.Stamina, card.Strength, card.Jumping, card.Balance, card.ID)
+}
+
+// ApplyAging applies aging of the cards at the end of the season in one transaction, returns ids of the retired cards.
+// The season is recorded in the same transaction, so the cards are aged only once in the season.
+func (cardsDB *cardsDB) ApplyAging(ctx context.Context, aging cards.Aging) ([]uuid.UUID, error) {
+	tx, err := cardsDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, ErrCard.Wrap(err)
+	}
+	result, err := tx.ExecContext(ctx, "INSERT INTO cards_aging(season_id, aged_at) VALUES($1, $2) ON CONFLICT DO NOTHING",
+		aging.SeasonID, time.Now().UTC())
+	if err != nil {
+		return nil, ErrCard.Wrap(errs.Combine(err, tx.Rollback()))
+	}
 	rowNum, err := result.RowsAffected()
+	if err != nil {
+		return nil, ErrCard.Wrap(errs.Combine(err, tx.Rollback()))
+	}
 	if rowNum == 0 {
-		return cards.ErrNoCard.New("")
+		return nil, errs.Combine(cards.ErrSeasonAged.New("season %d", aging.SeasonID), tx.Rollback())
 	}
 
-	return ErrCard.Wrap(err)
+	query := `UPDATE cards
+	          SET age = $1, physique = $2, acceleration = $3, running_speed = $4, reaction_speed = $5, agility = $6,
+	              stamina = $7, strength = $8, jumping = $9, balance = $10
+	          WHERE id = $11 AND age = $12`
+
+	for i, card := range aging.Cards {
+		result, err = tx.ExecContext(ctx, query, card.Age, card.Physique, card.Acceleration, card.RunningSpeed,
+			card.ReactionSpeed, card.Agility, card.Stamina, card.Strength, card.Jumping, card.Balance, card.ID, card.Age-1)
+		if err != nil {
+			return nil, ErrCard.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+		if rowNum, err = result.RowsAffected(); err != nil {
+			return nil, ErrCard.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+		// the card is deleted after it was listed.
+		if rowNum == 0 {
+			continue
+		}
+		if i < len(aging.History) {
+			if err = insertCardHistory(ctx, tx, aging.History[i]); err != nil {
+				return nil, ErrCard.Wrap(errs.Combine(err, tx.Rollback()))
+			}
+		}
+	}
+
+	var retired []uuid.UUID
+	for _, retirement := range aging.Retirements {
+		result, err = tx.ExecContext(ctx, "UPDATE cards SET status = $1 WHERE id = $2 AND status = $3",
+			cards.StatusRetired, retirement.CardID, cards.StatusActive)
+		if err != nil {
+			return nil, ErrCard.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+		if rowNum, err = result.RowsAffected(); err != nil {
+			return nil, ErrCard.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+		// the card is put on sale after it was listed, it retires after the lot is closed.
+		if rowNum == 0 {
+			continue
+		}
+		if err = insertCardHistory(ctx, tx, retirement); err != nil {
+			return nil, ErrCard.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+		retired = append(retired, retirement.CardID)
+	}
+
+	if err = removeCardsFromSquads(ctx, tx, retired); err != nil {
+		return nil, ErrCard.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	return retired, ErrCard.Wrap(tx.Commit())
+}
+
+// UpdateUserID updates user id card in the database with the records of the card history.
+func (cardsDB *cardsDB) UpdateUserID(ctx context.Context, cardID, userID uuid.UUID, history ...cards.History) error {
+	return cardsDB.execWithHistory(ctx, history, true, "UPDATE cards SET user_id=$1 WHERE id=$2", userID, cardID)
 }
 
 // Delete deletes record card in the database.
@@ -708,7 +1238,7 @@ func (cardsDB *cardsDB) GetSquadCards(ctx context.Context, id uuid.UUID) ([]card
 			&card.ForwardPass, &card.Offence, &card.FinishingAbility, &card.ShotPower, &card.Accuracy, &card.Distance, &card.Penalty,
 			&card.FreeKicks, &card.Corners, &card.HeadingAccuracy, &card.Defence, &card.OffsideTrap, &card.Sliding, &card.Tackles, &card.BallFocus,
 			&card.Interceptions, &card.Vigilance, &card.Goalkeeping, &card.Reflexes, &card.Diving, &card.Handling, &card.Sweeping, &card.Throwing,
-			&card.IsMinted,
+			&card.IsMinted, &card.Age, &card.Potential, &card.Nationality,
 		); err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				return cardsFromSquad, cards.ErrNoCard.Wrap(err)
@@ -724,3 +1254,245 @@ func (cardsDB *cardsDB) GetSquadCards(ctx context.Context, id uuid.UUID) ([]card
 
 	return cardsFromSquad, nil
 }
+
+// insertCardHistoryQuery inserts the record of the card history, it is shared with the lots settlement.
+const insertCardHistoryQuery = `INSERT INTO cards_history(id, card_id, kind, old_value, new_value, cause, user_id, counterparty_id, price, created_at)
+                                VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+// CreateHistory adds record of the card history in the database.
+func (cardsDB *cardsDB) CreateHistory(ctx context.Context, history cards.History) error {
+	_, err := cardsDB.conn.ExecContext(ctx, insertCardHistoryQuery,
+		history.ID, history.CardID, history.Kind, history.OldValue, history.NewValue, history.Cause,
+		history.UserID, history.CounterpartyID, history.Price.Bytes(), history.CreatedAt)
+
+	return ErrCard.Wrap(err)
+}
+
+// insertCardHistory adds records of the card history in the transaction.
+func insertCardHistory(ctx context.Context, tx *sql.Tx, history ...cards.History) error {
+	for _, record := range history {
+		_, err := tx.ExecContext(ctx, insertCardHistoryQuery,
+			record.ID, record.CardID, record.Kind, record.OldValue, record.NewValue, record.Cause,
+			record.UserID, record.CounterpartyID, record.Price.Bytes(), record.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// execWithHistory executes the query which changes the card and adds the records of the card history in one transaction,
+// the card must be changed by the query if mustAffect is set.
+func (cardsDB *cardsDB) execWithHistory(ctx context.Context, history []cards.History, mustAffect bool, query string, args ...interface{}) error {
+	tx, err := cardsDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrCard.Wrap(err)
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return ErrCard.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	if mustAffect {
+		rowNum, err := result.RowsAffected()
+		if err != nil {
+			return ErrCard.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+		if rowNum == 0 {
+			return errs.Combine(cards.ErrNoCard.New(""), tx.Rollback())
+		}
+	}
+
+	if err = insertCardHistory(ctx, tx, history...); err != nil {
+		return ErrCard.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	return ErrCard.Wrap(tx.Commit())
+}
+
+// GetScoutingReport returns scouting report of the card from the database.
+func (cardsDB *cardsDB) GetScoutingReport(ctx context.Context, cardID uuid.UUID) (cards.ScoutingReport, error) {
+	query := `SELECT card_id, min_potential, max_potential, scouts_count, updated_at
+	          FROM scouting_reports
+	          WHERE card_id = $1`
+
+	var report cards.ScoutingReport
+	err := cardsDB.conn.QueryRowContext(ctx, query, cardID).Scan(
+		&report.CardID, &report.MinPotential, &report.MaxPotential, &report.ScoutsCount, &report.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return report, cards.ErrNoScoutingReport.Wrap(err)
+		}
+		return report, ErrCard.Wrap(err)
+	}
+
+	return report, nil
+}
+
+// ListScoutingReports returns scouting reports of the cards which were scouted from the database.
+func (cardsDB *cardsDB) ListScoutingReports(ctx context.Context, cardIDs []uuid.UUID) (_ []cards.ScoutingReport, err error) {
+	if len(cardIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT card_id, min_potential, max_potential, scouts_count, updated_at
+	          FROM scouting_reports
+	          WHERE card_id = ANY($1)`
+
+	rows, err := cardsDB.conn.QueryContext(ctx, query, pq.Array(cardIDs))
+	if err != nil {
+		return nil, ErrCard.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var reports []cards.ScoutingReport
+	for rows.Next() {
+		var report cards.ScoutingReport
+		if err = rows.Scan(&report.CardID, &report.MinPotential, &report.MaxPotential, &report.ScoutsCount, &report.UpdatedAt); err != nil {
+			return nil, ErrCard.Wrap(err)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, ErrCard.Wrap(rows.Err())
+}
+
+// upsertScoutingReportQuery adds or updates scouting report of the card, it is shared with the paid scouting.
+const upsertScoutingReportQuery = `INSERT INTO scouting_reports(card_id, min_potential, max_potential, scouts_count, updated_at)
+	          VALUES($1, $2, $3, $4, $5)
+	          ON CONFLICT (card_id) DO UPDATE
+	          SET min_potential = EXCLUDED.min_potential, max_potential = EXCLUDED.max_potential,
+	              scouts_count = EXCLUDED.scouts_count, updated_at = EXCLUDED.updated_at`
+
+// UpsertScoutingReport adds or updates scouting report of the card in the database with the records of the card history.
+func (cardsDB *cardsDB) UpsertScoutingReport(ctx context.Context, report cards.ScoutingReport, history ...cards.History) error {
+	return cardsDB.execWithHistory(ctx, history, false, upsertScoutingReportQuery,
+		report.CardID, report.MinPotential, report.MaxPotential, report.ScoutsCount, report.UpdatedAt)
+}
+
+// ListHistoryByCardID returns history of the card from the database ordered by time.
+func (cardsDB *cardsDB) ListHistoryByCardID(ctx context.Context, cardID uuid.UUID) (_ []cards.History, err error) {
+	query := `SELECT id, card_id, kind, old_value, new_value, cause, user_id, counterparty_id, price, created_at
+	          FROM cards_history
+	          WHERE card_id = $1
+	          ORDER BY created_at`
+
+	rows, err := cardsDB.conn.QueryContext(ctx, query, cardID)
+	if err != nil {
+		return nil, ErrCard.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	return scanHistory(rows)
+}
+
+// ListHistory returns history of all cards from the database.
+func (cardsDB *cardsDB) ListHistory(ctx context.Context, cursor pagination.Cursor) (_ cards.HistoryPage, err error) {
+	var historyPage cards.HistoryPage
+	offset := (cursor.Page - 1) * cursor.Limit
+	query := `SELECT id, card_id, kind, old_value, new_value, cause, user_id, counterparty_id, price, created_at
+	          FROM cards_history
+	          ORDER BY created_at DESC
+	          LIMIT $1
+	          OFFSET $2`
+
+	rows, err := cardsDB.conn.QueryContext(ctx, query, cursor.Limit, offset)
+	if err != nil {
+		return historyPage, ErrCard.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	history, err := scanHistory(rows)
+	if err != nil {
+		return historyPage, err
+	}
+
+	var totalCount int
+	if err = cardsDB.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM cards_history`).Scan(&totalCount); err != nil {
+		return historyPage, ErrCard.Wrap(err)
+	}
+
+	return listHistoryPaginated(cursor, history, totalCount), nil
+}
+
+// ListHistoryByUserID returns history of the cards where user was owner or counterparty from the database.
+func (cardsDB *cardsDB) ListHistoryByUserID(ctx context.Context, userID uuid.UUID, cursor pagination.Cursor) (_ cards.HistoryPage, err error) {
+	var historyPage cards.HistoryPage
+	offset := (cursor.Page - 1) * cursor.Limit
+	query := `SELECT id, card_id, kind, old_value, new_value, cause, user_id, counterparty_id, price, created_at
+	          FROM cards_history
+	          WHERE user_id = $1 OR counterparty_id = $1
+	          ORDER BY created_at DESC
+	          LIMIT $2
+	          OFFSET $3`
+
+	rows, err := cardsDB.conn.QueryContext(ctx, query, userID, cursor.Limit, offset)
+	if err != nil {
+		return historyPage, ErrCard.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	history, err := scanHistory(rows)
+	if err != nil {
+		return historyPage, err
+	}
+
+	var totalCount int
+	query = `SELECT COUNT(*) FROM cards_history WHERE user_id = $1 OR counterparty_id = $1`
+	if err = cardsDB.conn.QueryRowContext(ctx, query, userID).Scan(&totalCount); err != nil {
+		return historyPage, ErrCard.Wrap(err)
+	}
+
+	return listHistoryPaginated(cursor, history, totalCount), nil
+}
+
+// scanHistory scans records of the card history from rows.
+func scanHistory(rows *sql.Rows) ([]cards.History, error) {
+	var history []cards.History
+	for rows.Next() {
+		var (
+			record cards.History
+			price  []byte
+		)
+		if err := rows.Scan(&record.ID, &record.CardID, &record.Kind, &record.OldValue, &record.NewValue, &record.Cause,
+			&record.UserID, &record.CounterpartyID, &price, &record.CreatedAt); err != nil {
+			return nil, ErrCard.Wrap(err)
+		}
+		record.Price.SetBytes(price)
+		record.CreatedAt = record.CreatedAt.UTC()
+
+		history = append(history, record)
+	}
+
+	return history, ErrCard.Wrap(rows.Err())
+}
+
+// listHistoryPaginated returns paginated list of the card history.
+func listHistoryPaginated(cursor pagination.Cursor, history []cards.History, totalCount int) cards.HistoryPage {
+	pageCount := totalCount / cursor.Limit
+	if totalCount%cursor.Limit != 0 {
+		pageCount++
+	}
+
+	return cards.HistoryPage{
+		History: history,
+		Page: pagination.Page{
+			Offset:      (cursor.Page - 1) * cursor.Limit,
+			Limit:       cursor.Limit,
+			CurrentPage: cursor.Page,
+			PageCount:   pageCount,
+			TotalCount:  totalCount,
+		},
+	}
+}
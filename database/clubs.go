@@ -9,6 +9,7 @@ import (
 	"errors"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/zeebo/errs"
 
 	"ultimatedivision/clubs"
@@ -37,13 +38,15 @@ func (clubsDB *clubsDB) Create(ctx context.Context, club clubs.Club) (uuid.UUID,
 		return uuid.Nil, ErrClubs.Wrap(err)
 	}
 
-	query := `INSERT INTO clubs(id, owner_id, club_name, status, division_id, created_at)
-              VALUES($1,$2,$3,$4,$5,$6)
+	query := `INSERT INTO clubs(id, owner_id, club_name, status, division_id, created_at, crest_shape, crest_symbol,
+                                  primary_color, secondary_color, badge_url)
+              VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
               RETURNING id`
 
 	var clubID uuid.UUID
 	err = clubsDB.conn.QueryRowContext(ctx, query,
-		club.ID, club.OwnerID, club.Name, club.Status, club.DivisionID, club.CreatedAt).Scan(&clubID)
+		club.ID, club.OwnerID, club.Name, club.Status, club.DivisionID, club.CreatedAt, club.Crest.Shape, club.Crest.Symbol,
+		club.Kit.PrimaryColor, club.Kit.SecondaryColor, club.BadgeURL).Scan(&clubID)
 	if err != nil {
 		err = tx.Rollback()
 		if err != nil {
@@ -63,7 +66,7 @@ func (clubsDB *clubsDB) Create(ctx context.Context, club clubs.Club) (uuid.UUID,
 
 // List returns all clubs.
 func (clubsDB *clubsDB) List(ctx context.Context) ([]clubs.Club, error) {
-	query := `SELECT id, owner_id, club_name, status, division_id, created_at
+	query := `SELECT id, owner_id, club_name, status, division_id, created_at, crest_shape, crest_symbol, primary_color, secondary_color, badge_url
 			  FROM clubs`
 
 	rows, err := clubsDB.conn.QueryContext(ctx, query)
@@ -78,7 +81,8 @@ func (clubsDB *clubsDB) List(ctx context.Context) ([]clubs.Club, error) {
 
 	for rows.Next() {
 		var club clubs.Club
-		err = rows.Scan(&club.ID, &club.OwnerID, &club.Name, &club.Status, &club.DivisionID, &club.CreatedAt)
+		err = rows.Scan(&club.ID, &club.OwnerID, &club.Name, &club.Status, &club.DivisionID, &club.CreatedAt,
+			&club.Crest.Shape, &club.Crest.Symbol, &club.Kit.PrimaryColor, &club.Kit.SecondaryColor, &club.BadgeURL)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				return allClubs, clubs.ErrNoClub.Wrap(err)
@@ -94,7 +98,7 @@ func (clubsDB *clubsDB) List(ctx context.Context) ([]clubs.Club, error) {
 
 // ListByDivision returns all clubs in division.
 func (clubsDB *clubsDB) ListByDivision(ctx context.Context, division uuid.UUID) ([]clubs.Club, error) {
-	query := `SELECT id, owner_id, club_name, status, division_id, created_at
+	query := `SELECT id, owner_id, club_name, status, division_id, created_at, crest_shape, crest_symbol, primary_color, secondary_color, badge_url
 			  FROM clubs
 			  WHERE division_id=$1`
 
@@ -110,7 +114,8 @@ func (clubsDB *clubsDB) ListByDivision(ctx context.Context, division uuid.UUID)
 
 	for rows.Next() {
 		var club clubs.Club
-		err = rows.Scan(&club.ID, &club.OwnerID, &club.Name, &club.Status, &club.DivisionID, &club.CreatedAt)
+		err = rows.Scan(&club.ID, &club.OwnerID, &club.Name, &club.Status, &club.DivisionID, &club.CreatedAt,
+			&club.Crest.Shape, &club.Crest.Symbol, &club.Kit.PrimaryColor, &club.Kit.SecondaryColor, &club.BadgeURL)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				return allClubs, clubs.ErrNoClub.Wrap(err)
@@ -126,14 +131,15 @@ func (clubsDB *clubsDB) ListByDivision(ctx context.Context, division uuid.UUID)
 
 // CreateSquad creates squad for clubs in the database.
 func (clubsDB *clubsDB) CreateSquad(ctx context.Context, squad clubs.Squad) (uuid.UUID, error) {
-	query := `INSERT INTO squads(id, squad_name, club_id, tactic, formation, captain_id)
-              VALUES($1,$2,$3,$4,$5,$6)
+	query := `INSERT INTO squads(id, squad_name, club_id, tactic, formation, captain_id, is_active, pressing, width, tempo, defensive_line)
+              VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
               RETURNING id`
 
 	var squadID uuid.UUID
 
 	err := clubsDB.conn.QueryRowContext(ctx, query,
-		squad.ID, squad.Name, squad.ClubID, squad.Tactic, squad.Formation, squad.CaptainID).Scan(&squadID)
+		squad.ID, squad.Name, squad.ClubID, squad.Tactic, squad.Formation, squad.CaptainID, squad.IsActive,
+		squad.Instructions.Pressing, squad.Instructions.Width, squad.Instructions.Tempo, squad.Instructions.DefensiveLine).Scan(&squadID)
 
 	return squadID, ErrClubs.Wrap(err)
 }
@@ -167,19 +173,106 @@ func (clubsDB *clubsDB) DeleteSquadCard(ctx context.Context, squadID, cardID uui
 	return ErrClubs.Wrap(err)
 }
 
-// DeleteByCardID deletes card from squad by card id.
+// DeleteByCardID deletes card from all squads and benches by card id.
 func (clubsDB *clubsDB) DeleteByCardID(ctx context.Context, cardID uuid.UUID) error {
-	query := `DELETE FROM squad_cards
-              WHERE card_id = $1`
+	tx, err := clubsDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+
+	var rowNum int64
+	for _, query := range []string{
+		`DELETE FROM squad_cards WHERE card_id = $1`,
+		`DELETE FROM squad_substitutes WHERE card_id = $1`,
+	} {
+		result, err := tx.ExecContext(ctx, query, cardID)
+		if err != nil {
+			return ErrClubs.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return ErrClubs.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+		rowNum += affected
+	}
+
+	if rowNum == 0 {
+		return errs.Combine(clubs.ErrNoSquadCard.New("squad card does not exist"), tx.Rollback())
+	}
+
+	return ErrClubs.Wrap(tx.Commit())
+}
 
-	result, err := clubsDB.conn.ExecContext(ctx, query, cardID)
+// DeleteSquad deletes squad of the club, cards of the squad and its bench are deleted by cascade.
+func (clubsDB *clubsDB) DeleteSquad(ctx context.Context, squadID uuid.UUID) error {
+	query := `DELETE FROM squads
+              WHERE id = $1`
+
+	result, err := clubsDB.conn.ExecContext(ctx, query, squadID)
 	if err != nil {
 		return ErrClubs.Wrap(err)
 	}
 
 	rowNum, err := result.RowsAffected()
 	if rowNum == 0 {
-		return clubs.ErrNoSquadCard.New("squad card does not exist")
+		return clubs.ErrNoSquad.New("squad does not exist")
+	}
+
+	return ErrClubs.Wrap(err)
+}
+
+// ListSubstitutes returns substitutes of the squad ordered by priority.
+func (clubsDB *clubsDB) ListSubstitutes(ctx context.Context, squadID uuid.UUID) (_ []clubs.SquadSubstitute, err error) {
+	query := `SELECT id, card_id, priority
+              FROM squad_substitutes
+              WHERE id = $1
+              ORDER BY priority`
+
+	rows, err := clubsDB.conn.QueryContext(ctx, query, squadID)
+	if err != nil {
+		return nil, ErrClubs.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var substitutes []clubs.SquadSubstitute
+	for rows.Next() {
+		var substitute clubs.SquadSubstitute
+		if err = rows.Scan(&substitute.SquadID, &substitute.CardID, &substitute.Priority); err != nil {
+			return nil, ErrClubs.Wrap(err)
+		}
+
+		substitutes = append(substitutes, substitute)
+	}
+
+	return substitutes, ErrClubs.Wrap(rows.Err())
+}
+
+// AddSubstitute adds card to the bench of the squad.
+func (clubsDB *clubsDB) AddSubstitute(ctx context.Context, substitute clubs.SquadSubstitute) error {
+	query := `INSERT INTO squad_substitutes(id, card_id, priority)
+              VALUES($1,$2,$3)`
+
+	_, err := clubsDB.conn.ExecContext(ctx, query, substitute.SquadID, substitute.CardID, substitute.Priority)
+
+	return ErrClubs.Wrap(err)
+}
+
+// DeleteSubstitute deletes card from the bench of the squad.
+func (clubsDB *clubsDB) DeleteSubstitute(ctx context.Context, squadID, cardID uuid.UUID) error {
+	query := `DELETE FROM squad_substitutes
+              WHERE card_id = $1 and id = $2`
+
+	result, err := clubsDB.conn.ExecContext(ctx, query, cardID, squadID)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+
+	rowNum, err := result.RowsAffected()
+	if rowNum == 0 {
+		return clubs.ErrNoSquadCard.New("substitute does not exist")
 	}
 
 	return ErrClubs.Wrap(err)
@@ -187,7 +280,7 @@ func (clubsDB *clubsDB) DeleteByCardID(ctx context.Context, cardID uuid.UUID) er
 
 // ListByUserID returns clubs owned by the user.
 func (clubsDB *clubsDB) ListByUserID(ctx context.Context, userID uuid.UUID) ([]clubs.Club, error) {
-	query := `SELECT id, owner_id, club_name, status, created_at
+	query := `SELECT id, owner_id, club_name, status, division_id, created_at, crest_shape, crest_symbol, primary_color, secondary_color, badge_url
 			  FROM clubs
 			  WHERE owner_id = $1`
 
@@ -203,7 +296,8 @@ func (clubsDB *clubsDB) ListByUserID(ctx context.Context, userID uuid.UUID) ([]c
 
 	for rows.Next() {
 		var club clubs.Club
-		err = rows.Scan(&club.ID, &club.OwnerID, &club.Name, &club.Status, &club.CreatedAt)
+		err = rows.Scan(&club.ID, &club.OwnerID, &club.Name, &club.Status, &club.DivisionID, &club.CreatedAt,
+			&club.Crest.Shape, &club.Crest.Symbol, &club.Kit.PrimaryColor, &club.Kit.SecondaryColor, &club.BadgeURL)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				return allClubs, clubs.ErrNoClub.New("club does not exist")
@@ -219,14 +313,15 @@ func (clubsDB *clubsDB) ListByUserID(ctx context.Context, userID uuid.UUID) ([]c
 
 // Get returns club.
 func (clubsDB *clubsDB) Get(ctx context.Context, clubID uuid.UUID) (clubs.Club, error) {
-	query := `SELECT id, owner_id, club_name, status, division_id, created_at
+	query := `SELECT id, owner_id, club_name, status, division_id, created_at, crest_shape, crest_symbol, primary_color, secondary_color, badge_url
 			  FROM clubs
 			  WHERE id = $1`
 
 	row := clubsDB.conn.QueryRowContext(ctx, query, clubID)
 
 	var club clubs.Club
-	err := row.Scan(&club.ID, &club.OwnerID, &club.Name, &club.Status, &club.DivisionID, &club.CreatedAt)
+	err := row.Scan(&club.ID, &club.OwnerID, &club.Name, &club.Status, &club.DivisionID, &club.CreatedAt,
+		&club.Crest.Shape, &club.Crest.Symbol, &club.Kit.PrimaryColor, &club.Kit.SecondaryColor, &club.BadgeURL)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return club, clubs.ErrNoClub.Wrap(err)
@@ -238,17 +333,48 @@ func (clubsDB *clubsDB) Get(ctx context.Context, clubID uuid.UUID) (clubs.Club,
 	return club, nil
 }
 
-// GetSquadByClubID returns squad from database.
+// ListSquadsByClubID returns all squads of the club from database.
+func (clubsDB *clubsDB) ListSquadsByClubID(ctx context.Context, clubID uuid.UUID) (_ []clubs.Squad, err error) {
+	query := `SELECT id, squad_name, club_id, tactic, formation, captain_id, is_active, pressing, width, tempo, defensive_line
+			  FROM squads
+			  WHERE club_id = $1
+			  ORDER BY squad_name`
+
+	rows, err := clubsDB.conn.QueryContext(ctx, query, clubID)
+	if err != nil {
+		return nil, ErrClubs.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var squads []clubs.Squad
+	for rows.Next() {
+		var squad clubs.Squad
+		err = rows.Scan(&squad.ID, &squad.Name, &squad.ClubID, &squad.Tactic, &squad.Formation, &squad.CaptainID, &squad.IsActive,
+			&squad.Instructions.Pressing, &squad.Instructions.Width, &squad.Instructions.Tempo, &squad.Instructions.DefensiveLine)
+		if err != nil {
+			return nil, ErrClubs.Wrap(err)
+		}
+
+		squads = append(squads, squad)
+	}
+
+	return squads, ErrClubs.Wrap(rows.Err())
+}
+
+// GetSquadByClubID returns active squad from database.
 func (clubsDB *clubsDB) GetSquadByClubID(ctx context.Context, clubID uuid.UUID) (clubs.Squad, error) {
-	query := `SELECT id, squad_name, club_id, tactic, formation, captain_id
+	query := `SELECT id, squad_name, club_id, tactic, formation, captain_id, is_active, pressing, width, tempo, defensive_line
 			  FROM squads
-			  WHERE club_id = $1`
+			  WHERE club_id = $1 AND is_active`
 
 	row := clubsDB.conn.QueryRowContext(ctx, query, clubID)
 
 	var squad clubs.Squad
 
-	err := row.Scan(&squad.ID, &squad.Name, &squad.ClubID, &squad.Tactic, &squad.Formation, &squad.CaptainID)
+	err := row.Scan(&squad.ID, &squad.Name, &squad.ClubID, &squad.Tactic, &squad.Formation, &squad.CaptainID, &squad.IsActive,
+		&squad.Instructions.Pressing, &squad.Instructions.Width, &squad.Instructions.Tempo, &squad.Instructions.DefensiveLine)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return squad, clubs.ErrNoSquad.Wrap(err)
@@ -264,7 +390,12 @@ func (clubsDB *clubsDB) GetSquadByClubID(ctx context.Context, clubID uuid.UUID)
 func (clubsDB *clubsDB) GetSquadIDByCardID(ctx context.Context, cardID uuid.UUID) (uuid.UUID, error) {
 	query := `SELECT id
 			  FROM squad_cards
-			  WHERE card_id = $1`
+			  WHERE card_id = $1
+			  UNION
+			  SELECT id
+			  FROM squad_substitutes
+			  WHERE card_id = $1
+			  LIMIT 1`
 
 	row := clubsDB.conn.QueryRowContext(ctx, query, cardID)
 
@@ -284,7 +415,7 @@ func (clubsDB *clubsDB) GetSquadIDByCardID(ctx context.Context, cardID uuid.UUID
 
 // GetSquad returns squad from database.
 func (clubsDB *clubsDB) GetSquad(ctx context.Context, squadID uuid.UUID) (clubs.Squad, error) {
-	query := `SELECT id, squad_name, club_id, tactic, formation, captain_id
+	query := `SELECT id, squad_name, club_id, tactic, formation, captain_id, is_active, pressing, width, tempo, defensive_line
 			  FROM squads
 			  WHERE id = $1`
 
@@ -292,7 +423,8 @@ func (clubsDB *clubsDB) GetSquad(ctx context.Context, squadID uuid.UUID) (clubs.
 
 	var squad clubs.Squad
 
-	err := row.Scan(&squad.ID, &squad.Name, &squad.ClubID, &squad.Tactic, &squad.Formation, &squad.CaptainID)
+	err := row.Scan(&squad.ID, &squad.Name, &squad.ClubID, &squad.Tactic, &squad.Formation, &squad.CaptainID, &squad.IsActive,
+		&squad.Instructions.Pressing, &squad.Instructions.Width, &squad.Instructions.Tempo, &squad.Instructions.DefensiveLine)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return squad, clubs.ErrNoSquad.Wrap(err)
@@ -355,6 +487,100 @@ func (clubsDB *clubsDB) UpdateTacticCaptain(ctx context.Context, squad clubs.Squ
 	return ErrClubs.Wrap(err)
 }
 
+// UpdateTeamInstructions updates tactical settings of the squad.
+func (clubsDB *clubsDB) UpdateTeamInstructions(ctx context.Context, squadID uuid.UUID, instructions clubs.TeamInstructions) error {
+	query := `UPDATE squads
+			  SET pressing = $1, width = $2, tempo = $3, defensive_line = $4
+  			  WHERE id = $5`
+
+	result, err := clubsDB.conn.ExecContext(ctx, query,
+		instructions.Pressing, instructions.Width, instructions.Tempo, instructions.DefensiveLine, squadID)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+	rowNum, err := result.RowsAffected()
+	if rowNum == 0 {
+		return clubs.ErrNoSquad.New("squad does not exist")
+	}
+
+	return ErrClubs.Wrap(err)
+}
+
+// UpsertPlayerInstruction adds or updates instruction for the card of the squad.
+func (clubsDB *clubsDB) UpsertPlayerInstruction(ctx context.Context, instruction clubs.PlayerInstruction) error {
+	query := `INSERT INTO squad_instructions(id, card_id, instruction)
+              VALUES($1,$2,$3)
+              ON CONFLICT(id, card_id) DO UPDATE
+              SET instruction = EXCLUDED.instruction`
+
+	_, err := clubsDB.conn.ExecContext(ctx, query, instruction.SquadID, instruction.CardID, instruction.Instruction)
+
+	return ErrClubs.Wrap(err)
+}
+
+// DeletePlayerInstruction deletes instruction for the card of the squad.
+func (clubsDB *clubsDB) DeletePlayerInstruction(ctx context.Context, squadID, cardID uuid.UUID) error {
+	query := `DELETE FROM squad_instructions
+              WHERE id = $1 AND card_id = $2`
+
+	result, err := clubsDB.conn.ExecContext(ctx, query, squadID, cardID)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+
+	rowNum, err := result.RowsAffected()
+	if rowNum == 0 {
+		return clubs.ErrNoSquadCard.New("instruction does not exist")
+	}
+
+	return ErrClubs.Wrap(err)
+}
+
+// ListPlayerInstructions returns instructions for the cards of the squad.
+func (clubsDB *clubsDB) ListPlayerInstructions(ctx context.Context, squadID uuid.UUID) (_ []clubs.PlayerInstruction, err error) {
+	query := `SELECT id, card_id, instruction
+              FROM squad_instructions
+              WHERE id = $1`
+
+	rows, err := clubsDB.conn.QueryContext(ctx, query, squadID)
+	if err != nil {
+		return nil, ErrClubs.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var instructions []clubs.PlayerInstruction
+	for rows.Next() {
+		var instruction clubs.PlayerInstruction
+		if err = rows.Scan(&instruction.SquadID, &instruction.CardID, &instruction.Instruction); err != nil {
+			return nil, ErrClubs.Wrap(err)
+		}
+
+		instructions = append(instructions, instruction)
+	}
+
+	return instructions, ErrClubs.Wrap(rows.Err())
+}
+
+// UpdateActiveSquad makes squad active and all other squads of the club inactive.
+func (clubsDB *clubsDB) UpdateActiveSquad(ctx context.Context, clubID, squadID uuid.UUID) error {
+	query := `UPDATE squads
+			  SET is_active = (id = $2)
+  			  WHERE club_id = $1`
+
+	result, err := clubsDB.conn.ExecContext(ctx, query, clubID, squadID)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+	rowNum, err := result.RowsAffected()
+	if rowNum == 0 {
+		return clubs.ErrNoSquad.New("squad does not exist")
+	}
+
+	return ErrClubs.Wrap(err)
+}
+
 // UpdateFormation updates formation in the squad.
 func (clubsDB *clubsDB) UpdateFormation(ctx context.Context, newFormation clubs.Formation, squadID uuid.UUID) error {
 	query := `UPDATE squads
@@ -373,6 +599,52 @@ func (clubsDB *clubsDB) UpdateFormation(ctx context.Context, newFormation clubs.
 	return ErrSquad.Wrap(err)
 }
 
+// ReplaceLineup replaces formation, cards and substitutes of the squad by the lineup in one transaction.
+func (clubsDB *clubsDB) ReplaceLineup(ctx context.Context, squadID uuid.UUID, lineup clubs.Lineup) error {
+	tx, err := clubsDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE squads SET formation = $1 WHERE id = $2", lineup.Formation, squadID)
+	if err != nil {
+		return ErrClubs.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+	rowNum, err := result.RowsAffected()
+	if err != nil {
+		return ErrClubs.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+	if rowNum == 0 {
+		return errs.Combine(clubs.ErrNoSquad.New("squad does not exist"), tx.Rollback())
+	}
+
+	for _, query := range []string{
+		"DELETE FROM squad_cards WHERE id = $1",
+		"DELETE FROM squad_substitutes WHERE id = $1",
+	} {
+		if _, err = tx.ExecContext(ctx, query, squadID); err != nil {
+			return ErrClubs.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	for _, squadCard := range lineup.SquadCards {
+		_, err = tx.ExecContext(ctx, "INSERT INTO squad_cards(id, card_id, card_position) VALUES($1,$2,$3)",
+			squadID, squadCard.CardID, squadCard.Position)
+		if err != nil {
+			return ErrClubs.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	for i, cardID := range lineup.Substitutes {
+		_, err = tx.ExecContext(ctx, "INSERT INTO squad_substitutes(id, card_id, priority) VALUES($1,$2,$3)", squadID, cardID, i+1)
+		if err != nil {
+			return ErrClubs.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	return ErrClubs.Wrap(tx.Commit())
+}
+
 // GetFormation returns formation of the squad.
 func (clubsDB *clubsDB) GetFormation(ctx context.Context, squadID uuid.UUID) (clubs.Formation, error) {
 	var formation clubs.Formation
@@ -497,3 +769,193 @@ func (clubsDB *clubsDB) UpdateClubToNewDivision(ctx context.Context, clubID uuid
 
 	return ErrSquad.Wrap(err)
 }
+
+// CreateCustomFormation creates custom formation of the club and returns its id.
+func (clubsDB *clubsDB) CreateCustomFormation(ctx context.Context, formation clubs.CustomFormation) (clubs.Formation, error) {
+	query := `INSERT INTO custom_formations(club_id, name, positions)
+              VALUES($1,$2,$3)
+              RETURNING id`
+
+	var id clubs.Formation
+	err := clubsDB.conn.QueryRowContext(ctx, query, formation.ClubID, formation.Name, pq.Array(positionsToInts(formation.Positions))).Scan(&id)
+
+	return id, ErrClubs.Wrap(err)
+}
+
+// GetCustomFormation returns custom formation by id.
+func (clubsDB *clubsDB) GetCustomFormation(ctx context.Context, id clubs.Formation) (clubs.CustomFormation, error) {
+	query := `SELECT id, club_id, name, positions
+              FROM custom_formations
+              WHERE id = $1`
+
+	var (
+		formation clubs.CustomFormation
+		positions pq.Int64Array
+	)
+	err := clubsDB.conn.QueryRowContext(ctx, query, id).Scan(&formation.ID, &formation.ClubID, &formation.Name, &positions)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return formation, clubs.ErrNoCustomFormation.Wrap(err)
+		}
+
+		return formation, ErrClubs.Wrap(err)
+	}
+	formation.Positions = intsToPositions(positions)
+
+	return formation, nil
+}
+
+// ListCustomFormations returns all custom formations of the club.
+func (clubsDB *clubsDB) ListCustomFormations(ctx context.Context, clubID uuid.UUID) (_ []clubs.CustomFormation, err error) {
+	query := `SELECT id, club_id, name, positions
+              FROM custom_formations
+              WHERE club_id = $1
+              ORDER BY id`
+
+	rows, err := clubsDB.conn.QueryContext(ctx, query, clubID)
+	if err != nil {
+		return nil, ErrClubs.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var formations []clubs.CustomFormation
+	for rows.Next() {
+		var (
+			formation clubs.CustomFormation
+			positions pq.Int64Array
+		)
+		if err = rows.Scan(&formation.ID, &formation.ClubID, &formation.Name, &positions); err != nil {
+			return nil, ErrClubs.Wrap(err)
+		}
+		formation.Positions = intsToPositions(positions)
+
+		formations = append(formations, formation)
+	}
+
+	return formations, ErrClubs.Wrap(rows.Err())
+}
+
+// DeleteCustomFormation deletes custom formation.
+func (clubsDB *clubsDB) DeleteCustomFormation(ctx context.Context, id clubs.Formation) error {
+	query := `DELETE FROM custom_formations
+              WHERE id = $1`
+
+	result, err := clubsDB.conn.ExecContext(ctx, query, id)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+
+	rowNum, err := result.RowsAffected()
+	if rowNum == 0 {
+		return clubs.ErrNoCustomFormation.New("custom formation does not exist")
+	}
+
+	return ErrClubs.Wrap(err)
+}
+
+// positionsToInts converts positions to the integers which are stored in the database.
+func positionsToInts(positions []clubs.Position) []int64 {
+	ints := make([]int64, 0, len(positions))
+	for _, position := range positions {
+		ints = append(ints, int64(position))
+	}
+
+	return ints
+}
+
+// intsToPositions converts integers from the database to the positions.
+func intsToPositions(ints []int64) []clubs.Position {
+	positions := make([]clubs.Position, 0, len(ints))
+	for _, i := range ints {
+		positions = append(positions, clubs.Position(i))
+	}
+
+	return positions
+}
+
+// AddPartnerships increases the number of matches played together for each pair of cards.
+func (clubsDB *clubsDB) AddPartnerships(ctx context.Context, partnerships []clubs.Partnership) (err error) {
+	tx, err := clubsDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+
+	query := `INSERT INTO card_partnerships(card1_id, card2_id, matches_played)
+              VALUES($1,$2,1)
+              ON CONFLICT(card1_id, card2_id) DO UPDATE
+              SET matches_played = card_partnerships.matches_played + 1`
+
+	for _, partnership := range partnerships {
+		if _, err = tx.ExecContext(ctx, query, partnership.Card1ID, partnership.Card2ID); err != nil {
+			return ErrClubs.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	return ErrClubs.Wrap(tx.Commit())
+}
+
+// ListPartnerships returns partnerships between the cards.
+func (clubsDB *clubsDB) ListPartnerships(ctx context.Context, cardIDs []uuid.UUID) (_ []clubs.Partnership, err error) {
+	query := `SELECT card1_id, card2_id, matches_played
+              FROM card_partnerships
+              WHERE card1_id = ANY($1) AND card2_id = ANY($1)`
+
+	rows, err := clubsDB.conn.QueryContext(ctx, query, pq.Array(cardIDs))
+	if err != nil {
+		return nil, ErrClubs.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var partnerships []clubs.Partnership
+	for rows.Next() {
+		var partnership clubs.Partnership
+		if err = rows.Scan(&partnership.Card1ID, &partnership.Card2ID, &partnership.MatchesPlayed); err != nil {
+			return nil, ErrClubs.Wrap(err)
+		}
+
+		partnerships = append(partnerships, partnership)
+	}
+
+	return partnerships, ErrClubs.Wrap(rows.Err())
+}
+
+// UpdateIdentity updates crest, kit colours and badge url of the club.
+func (clubsDB *clubsDB) UpdateIdentity(ctx context.Context, club clubs.Club) error {
+	query := `UPDATE clubs
+              SET crest_shape = $1, crest_symbol = $2, primary_color = $3, secondary_color = $4, badge_url = $5
+              WHERE id = $6`
+
+	result, err := clubsDB.conn.ExecContext(ctx, query, club.Crest.Shape, club.Crest.Symbol,
+		club.Kit.PrimaryColor, club.Kit.SecondaryColor, club.BadgeURL, club.ID)
+	if err != nil {
+		return ErrClubs.Wrap(err)
+	}
+
+	rowNum, err := result.RowsAffected()
+	if rowNum == 0 {
+		return clubs.ErrNoClub.New("club does not exist")
+	}
+
+	return ErrClubs.Wrap(err)
+}
+
+// removeCardsFromSquads removes the cards from the lineups and the benches of the squads within the database
+// transaction, the cards stop being the captains of the squads and their instructions are deleted.
+func removeCardsFromSquads(ctx context.Context, tx *sql.Tx, cardIDs []uuid.UUID) error {
+	for _, query := range []string{
+		`DELETE FROM squad_cards WHERE card_id = ANY($1)`,
+		`DELETE FROM squad_substitutes WHERE card_id = ANY($1)`,
+		`DELETE FROM squad_instructions WHERE card_id = ANY($1)`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, pq.Array(cardIDs)); err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, `UPDATE squads SET captain_id = $1 WHERE captain_id = ANY($2)`, uuid.Nil, pq.Array(cardIDs))
+	return err
+}
@@ -21,12 +21,15 @@ import (
 	"ultimatedivision/clubs"
 	"ultimatedivision/console/connections"
 	"ultimatedivision/divisions"
+	"ultimatedivision/finances"
 	"ultimatedivision/gameplay/gameengine"
 	"ultimatedivision/gameplay/matches"
 	"ultimatedivision/gameplay/matchmaking"
 	"ultimatedivision/gameplay/queue"
 	"ultimatedivision/marketplace"
 	"ultimatedivision/marketplace/bids"
+	"ultimatedivision/marketplace/trades"
+	"ultimatedivision/marketplace/watchlists"
 	"ultimatedivision/seasons"
 	"ultimatedivision/store"
 	"ultimatedivision/store/lootboxes"
@@ -165,7 +168,33 @@ func (db *database) CreateSchema(ctx context.Context) (err error) {
             handling          INTEGER                   NOT NULL,
             sweeping          INTEGER                   NOT NULL,
             throwing          INTEGER                   NOT NULL,
-            is_minted         INTEGER                   NOT NULL
+            is_minted         INTEGER                   NOT NULL,
+            age               INTEGER                   NOT NULL,
+            potential         INTEGER                   NOT NULL,
+            nationality       VARCHAR                   NOT NULL
+        );
+        CREATE TABLE IF NOT EXISTS cards_history (
+            id              BYTEA   PRIMARY KEY      NOT NULL,
+            card_id         BYTEA                    NOT NULL,
+            kind            VARCHAR                  NOT NULL,
+            old_value       VARCHAR                  NOT NULL,
+            new_value       VARCHAR                  NOT NULL,
+            cause           VARCHAR                  NOT NULL,
+            user_id         BYTEA                    NOT NULL,
+            counterparty_id BYTEA                    NOT NULL,
+            price           BYTEA                    NOT NULL,
+            created_at      TIMESTAMP WITH TIME ZONE NOT NULL
+        );
+        CREATE TABLE IF NOT EXISTS cards_aging (
+            season_id  INTEGER PRIMARY KEY      NOT NULL,
+            aged_at    TIMESTAMP WITH TIME ZONE NOT NULL
+        );
+        CREATE TABLE IF NOT EXISTS scouting_reports (
+            card_id       BYTEA   PRIMARY KEY REFERENCES cards(id) ON DELETE CASCADE NOT NULL,
+            min_potential INTEGER                                                    NOT NULL,
+            max_potential INTEGER                                                    NOT NULL,
+            scouts_count  INTEGER                                                    NOT NULL,
+            updated_at    TIMESTAMP WITH TIME ZONE                                   NOT NULL
         );
         CREATE TABLE IF NOT EXISTS avatars (
             card_id          BYTEA   PRIMARY KEY REFERENCES cards(id) ON DELETE CASCADE NOT NULL,
@@ -198,12 +227,17 @@ func (db *database) CreateSchema(ctx context.Context) (err error) {
             created_at      TIMESTAMP WITH TIME ZONE NOT NULL
         );
         CREATE TABLE IF NOT EXISTS clubs (
-            id          BYTEA     PRIMARY KEY                                NOT NULL,
-            owner_id    BYTEA     REFERENCES users(id) ON DELETE CASCADE     NOT NULL,
-            club_name   VARCHAR                                              NOT NULL,
-            status      INTEGER                                              NOT NULL,
-            division_id BYTEA     REFERENCES divisions(id) ON DELETE CASCADE NOT NULL,
-            created_at  TIMESTAMP WITH TIME ZONE                             NOT NULL
+            id              BYTEA     PRIMARY KEY                                NOT NULL,
+            owner_id        BYTEA     REFERENCES users(id) ON DELETE CASCADE     NOT NULL,
+            club_name       VARCHAR                                              NOT NULL,
+            status          INTEGER                                              NOT NULL,
+            division_id     BYTEA     REFERENCES divisions(id) ON DELETE CASCADE NOT NULL,
+            created_at      TIMESTAMP WITH TIME ZONE                             NOT NULL,
+            crest_shape     INTEGER                                              NOT NULL,
+            crest_symbol    INTEGER                                              NOT NULL,
+            primary_color   VARCHAR                                              NOT NULL,
+            secondary_color VARCHAR                                              NOT NULL,
+            badge_url       VARCHAR                                              NOT NULL
         );
         CREATE TABLE IF NOT EXISTS squads (
             id            BYTEA   PRIMARY KEY                            NOT NULL,
@@ -211,7 +245,25 @@ func (db *database) CreateSchema(ctx context.Context) (err error) {
             club_id       BYTEA   REFERENCES clubs(id) ON DELETE CASCADE NOT NULL,
             tactic        INTEGER                                        NOT NULL,
             formation     INTEGER                                        NOT NULL,
-            captain_id    BYTEA
+            captain_id     BYTEA,
+            is_active      BOOLEAN                                        NOT NULL,
+            pressing       INTEGER DEFAULT 2                              NOT NULL,
+            width          INTEGER DEFAULT 2                              NOT NULL,
+            tempo          INTEGER DEFAULT 2                              NOT NULL,
+            defensive_line INTEGER DEFAULT 2                              NOT NULL
+        );
+        CREATE SEQUENCE IF NOT EXISTS custom_formations_id_seq START 1000;
+        CREATE TABLE IF NOT EXISTS custom_formations (
+            id        INTEGER   PRIMARY KEY DEFAULT nextval('custom_formations_id_seq') NOT NULL,
+            club_id   BYTEA     REFERENCES clubs(id) ON DELETE CASCADE                 NOT NULL,
+            name      VARCHAR                                                          NOT NULL,
+            positions INTEGER[]                                                        NOT NULL
+        );
+        CREATE TABLE IF NOT EXISTS card_partnerships (
+            card1_id       BYTEA   REFERENCES cards(id) ON DELETE CASCADE NOT NULL,
+            card2_id       BYTEA   REFERENCES cards(id) ON DELETE CASCADE NOT NULL,
+            matches_played INTEGER                                        NOT NULL,
+            PRIMARY KEY(card1_id, card2_id)
         );
         CREATE TABLE IF NOT EXISTS squad_cards (
             id            BYTEA   REFERENCES squads(id) ON DELETE CASCADE NOT NULL,
@@ -219,6 +271,18 @@ func (db *database) CreateSchema(ctx context.Context) (err error) {
             card_position INTEGER                                         NOT NULL,
             PRIMARY KEY(id, card_id)
         );
+        CREATE TABLE IF NOT EXISTS squad_substitutes (
+            id       BYTEA   REFERENCES squads(id) ON DELETE CASCADE NOT NULL,
+            card_id  BYTEA   REFERENCES cards(id) ON DELETE CASCADE  NOT NULL,
+            priority INTEGER                                         NOT NULL,
+            PRIMARY KEY(id, card_id)
+        );
+        CREATE TABLE IF NOT EXISTS squad_instructions (
+            id          BYTEA   REFERENCES squads(id) ON DELETE CASCADE NOT NULL,
+            card_id     BYTEA   REFERENCES cards(id) ON DELETE CASCADE  NOT NULL,
+            instruction VARCHAR                                         NOT NULL,
+            PRIMARY KEY(id, card_id)
+        );
         CREATE TABLE IF NOT EXISTS lootboxes(
             user_id      BYTEA   REFERENCES users(id) ON DELETE CASCADE NOT NULL,
             lootbox_id   BYTEA                                          NOT NULL,
@@ -226,25 +290,96 @@ func (db *database) CreateSchema(ctx context.Context) (err error) {
             PRIMARY KEY(user_id, lootbox_id)
         );
         CREATE TABLE IF NOT EXISTS lots (
-            card_id       BYTEA                    REFERENCES cards(id) PRIMARY KEY       NOT NULL,
+            id            BYTEA                    PRIMARY KEY                            NOT NULL,
+            card_id       BYTEA                    REFERENCES cards(id)                   NOT NULL,
             type          VARCHAR                                                         NOT NULL,
+            sale_mode     VARCHAR                  DEFAULT 'auction'                      NOT NULL,
             user_id       BYTEA                    REFERENCES users(id) ON DELETE CASCADE NOT NULL,
             shopper_id    BYTEA,
             status        VARCHAR                                                         NOT NULL,
             start_price   BYTEA                                                           NOT NULL,
             max_price     BYTEA,
+            floor_price   BYTEA,
             current_price BYTEA,
             start_time    TIMESTAMP WITH TIME ZONE                                        NOT NULL,
             end_time      TIMESTAMP WITH TIME ZONE                                        NOT NULL,
-            period        INTEGER                                                         NOT NULL
+            period        INTEGER                                                         NOT NULL,
+            settlement    VARCHAR                  DEFAULT ''                             NOT NULL,
+            final_listing_hash VARCHAR             DEFAULT ''                             NOT NULL
+        );
+        CREATE TABLE IF NOT EXISTS lot_cards (
+            lot_id   BYTEA   REFERENCES lots(id) ON DELETE CASCADE  NOT NULL,
+            card_id  BYTEA   REFERENCES cards(id) ON DELETE CASCADE NOT NULL,
+            position INTEGER                                        NOT NULL,
+            PRIMARY KEY(lot_id, card_id)
+        );
+        CREATE TABLE IF NOT EXISTS sales (
+            id          BYTEA                    PRIMARY KEY NOT NULL,
+            card_id     BYTEA                                NOT NULL,
+            quality     VARCHAR                              NOT NULL,
+            rating      NUMERIC(16,2)                        NOT NULL,
+            rating_band INTEGER                              NOT NULL,
+            tactics     INTEGER                              NOT NULL,
+            physique    INTEGER                              NOT NULL,
+            technique   INTEGER                              NOT NULL,
+            offence     INTEGER                              NOT NULL,
+            defence     INTEGER                              NOT NULL,
+            goalkeeping INTEGER                              NOT NULL,
+            age         INTEGER                              NOT NULL,
+            price       NUMERIC                              NOT NULL,
+            sold_at     TIMESTAMP WITH TIME ZONE             NOT NULL
+        );
+        CREATE TABLE IF NOT EXISTS watchlists (
+            user_id         BYTEA                    REFERENCES users(id) ON DELETE CASCADE     NOT NULL,
+            lot_id          BYTEA                    REFERENCES lots(id) ON DELETE CASCADE      NOT NULL,
+            email           BOOLEAN                  DEFAULT false                              NOT NULL,
+            notified_price  BYTEA,
+            ending_notified BOOLEAN                  DEFAULT false                              NOT NULL,
+            created_at      TIMESTAMP WITH TIME ZONE                                            NOT NULL,
+            PRIMARY KEY(user_id, lot_id)
+        );
+        CREATE TABLE IF NOT EXISTS saved_searches (
+            id          BYTEA                    PRIMARY KEY                                NOT NULL,
+            user_id     BYTEA                    REFERENCES users(id) ON DELETE CASCADE     NOT NULL,
+            name        VARCHAR                                                             NOT NULL,
+            query       JSONB                                                               NOT NULL,
+            sale_mode   VARCHAR                  DEFAULT ''                                 NOT NULL,
+            email       BOOLEAN                  DEFAULT false                              NOT NULL,
+            notified_at TIMESTAMP WITH TIME ZONE                                            NOT NULL,
+            created_at  TIMESTAMP WITH TIME ZONE                                            NOT NULL
+        );
+        CREATE TABLE IF NOT EXISTS trades (
+            id            BYTEA                    PRIMARY KEY                                NOT NULL,
+            proposer_id   BYTEA                    REFERENCES users(id) ON DELETE CASCADE     NOT NULL,
+            recipient_id  BYTEA                    REFERENCES users(id) ON DELETE CASCADE     NOT NULL,
+            amount        BYTEA                                                               NOT NULL,
+            status        VARCHAR                                                             NOT NULL,
+            counter_of_id BYTEA                                                               NOT NULL,
+            created_at    TIMESTAMP WITH TIME ZONE                                            NOT NULL,
+            expires_at    TIMESTAMP WITH TIME ZONE                                            NOT NULL
+        );
+        CREATE TABLE IF NOT EXISTS trade_cards (
+            trade_id BYTEA   REFERENCES trades(id) ON DELETE CASCADE NOT NULL,
+            card_id  BYTEA   REFERENCES cards(id) ON DELETE CASCADE  NOT NULL,
+            offered  BOOLEAN                                         NOT NULL,
+            position INTEGER                                         NOT NULL,
+            PRIMARY KEY(trade_id, card_id)
         );
         CREATE TABLE IF NOT EXISTS bids (
             id         BYTEA                    PRIMARY KEY                                NOT NULL,
             lot_id     BYTEA                                                               NOT NULL,
             user_id    BYTEA                    REFERENCES users(id) ON DELETE CASCADE     NOT NULL,
             amount     DECIMAL                                                             NOT NULL,
+            automatic  BOOLEAN                  DEFAULT false                              NOT NULL,
             created_at TIMESTAMP WITH TIME ZONE                                            NOT NULL
         );
+        CREATE TABLE IF NOT EXISTS proxy_bids (
+            lot_id     BYTEA                                                               NOT NULL,
+            user_id    BYTEA                    REFERENCES users(id) ON DELETE CASCADE     NOT NULL,
+            max_amount DECIMAL                                                             NOT NULL,
+            created_at TIMESTAMP WITH TIME ZONE                                            NOT NULL,
+            PRIMARY KEY(lot_id, user_id)
+        );
         CREATE TABLE IF NOT EXISTS seasons(
             id          SERIAL PRIMARY KEY       NOT NULL,
             division_id BYTEA                    NOT NULL,
@@ -279,6 +414,39 @@ func (db *database) CreateSchema(ctx context.Context) (err error) {
             card_id  BYTEA   REFERENCES cards(id) ON DELETE CASCADE   NOT NULL,
             minute   INTEGER                                          NOT NULL
         );
+        CREATE TABLE IF NOT EXISTS match_substitutions(
+            id          BYTEA   PRIMARY KEY                              NOT NULL,
+            match_id    BYTEA   REFERENCES matches(id) ON DELETE CASCADE NOT NULL,
+            user_id     BYTEA   REFERENCES users(id) ON DELETE CASCADE   NOT NULL,
+            card_out_id BYTEA   REFERENCES cards(id) ON DELETE CASCADE   NOT NULL,
+            card_in_id  BYTEA   REFERENCES cards(id) ON DELETE CASCADE   NOT NULL,
+            minute      INTEGER                                          NOT NULL,
+            reason      VARCHAR                                          NOT NULL
+        );
+        CREATE TABLE IF NOT EXISTS finance_transactions(
+            id          BYTEA   PRIMARY KEY      NOT NULL,
+            type        VARCHAR                  NOT NULL,
+            description VARCHAR                  NOT NULL,
+            created_at  TIMESTAMP WITH TIME ZONE NOT NULL
+        );
+        CREATE TABLE IF NOT EXISTS finance_entries(
+            transaction_id BYTEA   REFERENCES finance_transactions(id) ON DELETE CASCADE NOT NULL,
+            account        VARCHAR                                                       NOT NULL,
+            direction      VARCHAR                                                       NOT NULL,
+            amount         BYTEA                                                         NOT NULL
+        );
+        CREATE TABLE IF NOT EXISTS finance_escrows(
+            lot_id  BYTEA   NOT NULL,
+            user_id BYTEA   NOT NULL,
+            account VARCHAR NOT NULL,
+            PRIMARY KEY(lot_id, user_id)
+        );
+        CREATE TABLE IF NOT EXISTS finance_upkeeps(
+            club_id    BYTEA                    REFERENCES clubs(id) ON DELETE CASCADE NOT NULL,
+            period     TIMESTAMP WITH TIME ZONE                                        NOT NULL,
+            transaction_id BYTEA                REFERENCES finance_transactions(id) ON DELETE CASCADE NOT NULL,
+            PRIMARY KEY(club_id, period)
+        );
         CREATE TABLE IF NOT EXISTS waitlist(
             token_id              BYTEA                                                      NOT NULL,
             token_number          SERIAL                                                     NOT NULL,
@@ -358,6 +526,11 @@ func (db *database) Games() gameengine.DB {
 	return &gameengineDB{conn: db.conn}
 }
 
+// Finances provides access to finances db.
+func (db *database) Finances() finances.DB {
+	return &financesDB{conn: db.conn}
+}
+
 // Avatars provides access to accounts db.
 func (db *database) Avatars() avatars.DB {
 	return &avatarsDB{conn: db.conn}
@@ -383,6 +556,16 @@ func (db *database) Bids() bids.DB {
 	return &bidsDB{conn: db.conn}
 }
 
+// Watchlists provides access to watchlists db.
+func (db *database) Watchlists() watchlists.DB {
+	return &watchlistsDB{conn: db.conn}
+}
+
+// Trades provides access to trades db.
+func (db *database) Trades() trades.DB {
+	return &tradesDB{conn: db.conn}
+}
+
 // Matches provides access to accounts db.
 func (db *database) Matches() matches.DB {
 	return &matchesDB{conn: db.conn}
@@ -0,0 +1,451 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/cards"
+	"ultimatedivision/finances"
+	"ultimatedivision/pkg/pagination"
+)
+
+// ensures that financesDB implements finances.DB.
+var _ finances.DB = (*financesDB)(nil)
+
+// ErrFinances indicates that there was an error in the database.
+var ErrFinances = errs.Class("finances repository error")
+
+// financesDB provides access to finances db.
+//
+// architecture: Database
+type financesDB struct {
+	conn *sql.DB
+}
+
+// Create creates transaction with all its entries in the database.
+func (financesDB *financesDB) Create(ctx context.Context, transaction finances.Transaction) error {
+	tx, err := financesDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrFinances.Wrap(err)
+	}
+
+	if err = insertTransaction(ctx, tx, transaction); err != nil {
+		return ErrFinances.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	return ErrFinances.Wrap(tx.Commit())
+}
+
+// Hold raises the funds held on the lot up to the amount of the escrow, returns ErrInsufficientFunds
+// if the balance of the account of the escrow is not enough.
+func (financesDB *financesDB) Hold(ctx context.Context, escrow finances.Escrow) error {
+	tx, err := financesDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrFinances.Wrap(err)
+	}
+
+	if err = holdEscrow(ctx, tx, escrow); err != nil {
+		return ErrFinances.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	return ErrFinances.Wrap(tx.Commit())
+}
+
+// Release returns all funds held on the lot to the account of the escrow.
+func (financesDB *financesDB) Release(ctx context.Context, escrow finances.Escrow) error {
+	tx, err := financesDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrFinances.Wrap(err)
+	}
+
+	if err = releaseEscrow(ctx, tx, escrow); err != nil {
+		return ErrFinances.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	return ErrFinances.Wrap(tx.Commit())
+}
+
+// CreatePayment records the transactions of the payment and returns the rest of the held funds in one transaction,
+// returns ErrInsufficientFunds if the held funds are not enough for the payment.
+func (financesDB *financesDB) CreatePayment(ctx context.Context, payment finances.Payment) error {
+	tx, err := financesDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrFinances.Wrap(err)
+	}
+
+	if err = insertPayment(ctx, tx, payment); err != nil {
+		return ErrFinances.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	return ErrFinances.Wrap(tx.Commit())
+}
+
+// insertTransaction inserts transaction with all its entries within the database transaction,
+// so the ledger could be changed together with the other tables.
+func insertTransaction(ctx context.Context, tx *sql.Tx, transaction finances.Transaction) error {
+	query := `INSERT INTO finance_transactions(id, type, description, created_at)
+              VALUES($1,$2,$3,$4)`
+
+	_, err := tx.ExecContext(ctx, query, transaction.ID, transaction.Type, transaction.Description, transaction.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	query = `INSERT INTO finance_entries(transaction_id, account, direction, amount)
+             VALUES($1,$2,$3,$4)`
+
+	for _, entry := range transaction.Entries {
+		_, err = tx.ExecContext(ctx, query, transaction.ID, entry.Account, entry.Direction, entry.Amount.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// insertTransactionWithinBalance inserts transaction within the database transaction if the balance of the account
+// does not become negative. The account is locked until the end of the database transaction, so the concurrent
+// checked changes of the account could not spend the same funds.
+func insertTransactionWithinBalance(ctx context.Context, tx *sql.Tx, transaction finances.Transaction, account finances.Account) error {
+	entries, err := lockEntries(ctx, tx, account)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range transaction.Entries {
+		if entry.Account == account {
+			entries = append(entries, entry)
+		}
+	}
+	if balance := finances.Balance(entries); balance.Sign() < 0 {
+		return finances.ErrInsufficientFunds.New("balance of %s is not enough for the transaction %s", account, transaction.Description)
+	}
+
+	return insertTransaction(ctx, tx, transaction)
+}
+
+// holdEscrow raises the funds held on the lot up to the amount of the escrow within the database transaction,
+// only the missing part is moved and only if the balance is enough. The funds are moved from the account
+// the first funds of the escrow were held from, so all held funds are returned to the same account.
+func holdEscrow(ctx context.Context, tx *sql.Tx, escrow finances.Escrow) error {
+	entries, err := lockEntries(ctx, tx, finances.EscrowAccount(escrow.LotID, escrow.UserID))
+	if err != nil {
+		return err
+	}
+
+	account, err := getEscrowAccount(ctx, tx, escrow)
+	if errs.Is(err, sql.ErrNoRows) {
+		_, err = tx.ExecContext(ctx, "INSERT INTO finance_escrows(lot_id, user_id, account) VALUES($1,$2,$3)",
+			escrow.LotID, escrow.UserID, escrow.Account)
+		account = escrow.Account
+	}
+	if err != nil {
+		return err
+	}
+	escrow.Account = account
+
+	transaction, ok := escrow.HoldTransaction(finances.Balance(entries))
+	if !ok {
+		return nil
+	}
+
+	return insertTransactionWithinBalance(ctx, tx, transaction, escrow.Account)
+}
+
+// releaseEscrow returns all funds held on the lot within the database transaction to the account they were held from,
+// the account of the escrow is used only if the account of the held funds is not recorded.
+func releaseEscrow(ctx context.Context, tx *sql.Tx, escrow finances.Escrow) error {
+	entries, err := lockEntries(ctx, tx, finances.EscrowAccount(escrow.LotID, escrow.UserID))
+	if err != nil {
+		return err
+	}
+
+	account, err := getEscrowAccount(ctx, tx, escrow)
+	switch {
+	case err == nil:
+		escrow.Account = account
+	case !errs.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM finance_escrows WHERE lot_id = $1 AND user_id = $2", escrow.LotID, escrow.UserID); err != nil {
+		return err
+	}
+
+	transaction, ok := escrow.ReleaseTransaction(finances.Balance(entries))
+	if !ok {
+		return nil
+	}
+
+	return insertTransaction(ctx, tx, transaction)
+}
+
+// getEscrowAccount returns the account the funds of the escrow are held from within the database transaction.
+func getEscrowAccount(ctx context.Context, tx *sql.Tx, escrow finances.Escrow) (finances.Account, error) {
+	var account finances.Account
+	err := tx.QueryRowContext(ctx, "SELECT account FROM finance_escrows WHERE lot_id = $1 AND user_id = $2",
+		escrow.LotID, escrow.UserID).Scan(&account)
+	return account, err
+}
+
+// insertPayment inserts the transactions of the payment within the database transaction if the held funds are enough
+// for them and returns the rest of the held funds to the account of the escrow.
+func insertPayment(ctx context.Context, tx *sql.Tx, payment finances.Payment) error {
+	escrowAccount := finances.EscrowAccount(payment.Escrow.LotID, payment.Escrow.UserID)
+	for _, transaction := range payment.Transactions {
+		if err := insertTransactionWithinBalance(ctx, tx, transaction, escrowAccount); err != nil {
+			return err
+		}
+	}
+
+	return releaseEscrow(ctx, tx, payment.Escrow)
+}
+
+// lockEntries locks the account until the end of the database transaction and returns all its entries.
+func lockEntries(ctx context.Context, tx *sql.Tx, account finances.Account) (_ []finances.Entry, err error) {
+	if _, err = tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", account); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT transaction_id, account, direction, amount FROM finance_entries WHERE account = $1", account)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	return scanEntries(rows)
+}
+
+// CreateScouting records the payment for the scouting together with the scouting report and its history in one transaction.
+func (financesDB *financesDB) CreateScouting(ctx context.Context, scouting finances.Scouting) error {
+	tx, err := financesDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrFinances.Wrap(err)
+	}
+
+	for _, transaction := range scouting.Transactions {
+		if err = insertTransactionWithinBalance(ctx, tx, transaction, scouting.Account); err != nil {
+			return ErrFinances.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	report := scouting.Report
+	_, err = tx.ExecContext(ctx, upsertScoutingReportQuery, report.CardID, report.MinPotential, report.MaxPotential, report.ScoutsCount, report.UpdatedAt)
+	if err != nil {
+		return ErrFinances.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	if err = insertCardHistory(ctx, tx, scouting.History); err != nil {
+		return ErrFinances.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	return ErrFinances.Wrap(tx.Commit())
+}
+
+// CreateUpkeep records the upkeep of the club for the period, returns ErrUpkeepCharged if the period is already charged.
+func (financesDB *financesDB) CreateUpkeep(ctx context.Context, upkeep finances.Upkeep) error {
+	tx, err := financesDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrFinances.Wrap(err)
+	}
+
+	if err = insertTransaction(ctx, tx, upkeep.Transaction); err != nil {
+		return ErrFinances.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	query := `INSERT INTO finance_upkeeps(club_id, period, transaction_id)
+	          VALUES($1,$2,$3)
+	          ON CONFLICT DO NOTHING`
+
+	result, err := tx.ExecContext(ctx, query, upkeep.ClubID, upkeep.Period, upkeep.Transaction.ID)
+	if err != nil {
+		return ErrFinances.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+	rowNum, err := result.RowsAffected()
+	if err != nil {
+		return ErrFinances.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+	if rowNum == 0 {
+		return errs.Combine(finances.ErrUpkeepCharged.New("club %s, period %s", upkeep.ClubID, upkeep.Period), tx.Rollback())
+	}
+
+	return ErrFinances.Wrap(tx.Commit())
+}
+
+// CreateStorePurchase records the payment for the card bought in the store together with the order of the card
+// in one transaction, returns cards.ErrNoCard if the card is already ordered.
+func (financesDB *financesDB) CreateStorePurchase(ctx context.Context, purchase finances.StorePurchase) error {
+	tx, err := financesDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrFinances.Wrap(err)
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE cards SET type = $1 WHERE id = $2 AND type = $3",
+		cards.TypeOrdered, purchase.History.CardID, cards.TypeUnordered)
+	if err != nil {
+		return ErrFinances.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+	rowNum, err := result.RowsAffected()
+	if err != nil {
+		return ErrFinances.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+	if rowNum == 0 {
+		return errs.Combine(cards.ErrNoCard.New("card is already ordered"), tx.Rollback())
+	}
+
+	if err = insertCardHistory(ctx, tx, purchase.History); err != nil {
+		return ErrFinances.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	for _, transaction := range purchase.Transactions {
+		if err = insertTransaction(ctx, tx, transaction); err != nil {
+			return ErrFinances.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	return ErrFinances.Wrap(tx.Commit())
+}
+
+// ListEntriesByAccount returns all entries of the account from the database.
+func (financesDB *financesDB) ListEntriesByAccount(ctx context.Context, account finances.Account) (_ []finances.Entry, err error) {
+	query := `SELECT transaction_id, account, direction, amount
+              FROM finance_entries
+              WHERE account = $1`
+
+	rows, err := financesDB.conn.QueryContext(ctx, query, account)
+	if err != nil {
+		return nil, ErrFinances.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	return scanEntries(rows)
+}
+
+// ListByAccount returns page of transactions which have entries of the account from the database.
+func (financesDB *financesDB) ListByAccount(ctx context.Context, account finances.Account, cursor pagination.Cursor) (_ finances.TransactionsPage, err error) {
+	var transactionsPage finances.TransactionsPage
+	offset := (cursor.Page - 1) * cursor.Limit
+	query := `SELECT id, type, description, created_at
+              FROM finance_transactions
+              WHERE id IN (SELECT transaction_id FROM finance_entries WHERE account = $1)
+              ORDER BY created_at DESC
+              LIMIT $2
+              OFFSET $3`
+
+	rows, err := financesDB.conn.QueryContext(ctx, query, account, cursor.Limit, offset)
+	if err != nil {
+		return transactionsPage, ErrFinances.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var (
+		transactions   []finances.Transaction
+		transactionIDs []uuid.UUID
+	)
+	for rows.Next() {
+		var transaction finances.Transaction
+		if err = rows.Scan(&transaction.ID, &transaction.Type, &transaction.Description, &transaction.CreatedAt); err != nil {
+			return transactionsPage, ErrFinances.Wrap(err)
+		}
+		transaction.CreatedAt = transaction.CreatedAt.UTC()
+
+		transactions = append(transactions, transaction)
+		transactionIDs = append(transactionIDs, transaction.ID)
+	}
+	if err = rows.Err(); err != nil {
+		return transactionsPage, ErrFinances.Wrap(err)
+	}
+
+	entries, err := financesDB.listEntriesByTransactionIDs(ctx, transactionIDs)
+	if err != nil {
+		return transactionsPage, err
+	}
+	for i := range transactions {
+		for _, entry := range entries {
+			if entry.TransactionID == transactions[i].ID {
+				transactions[i].Entries = append(transactions[i].Entries, entry)
+			}
+		}
+	}
+
+	var totalCount int
+	query = `SELECT COUNT(DISTINCT transaction_id) FROM finance_entries WHERE account = $1`
+	if err = financesDB.conn.QueryRowContext(ctx, query, account).Scan(&totalCount); err != nil {
+		return transactionsPage, ErrFinances.Wrap(err)
+	}
+
+	return listTransactionsPaginated(cursor, transactions, totalCount), nil
+}
+
+// listEntriesByTransactionIDs returns all entries of the transactions from the database.
+func (financesDB *financesDB) listEntriesByTransactionIDs(ctx context.Context, transactionIDs []uuid.UUID) (_ []finances.Entry, err error) {
+	if len(transactionIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT transaction_id, account, direction, amount
+              FROM finance_entries
+              WHERE transaction_id = ANY($1)`
+
+	rows, err := financesDB.conn.QueryContext(ctx, query, pq.Array(transactionIDs))
+	if err != nil {
+		return nil, ErrFinances.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	return scanEntries(rows)
+}
+
+// scanEntries scans ledger entries from the rows.
+func scanEntries(rows *sql.Rows) ([]finances.Entry, error) {
+	var entries []finances.Entry
+	for rows.Next() {
+		var (
+			entry  finances.Entry
+			amount []byte
+		)
+		if err := rows.Scan(&entry.TransactionID, &entry.Account, &entry.Direction, &amount); err != nil {
+			return nil, ErrFinances.Wrap(err)
+		}
+		entry.Amount.SetBytes(amount)
+
+		entries = append(entries, entry)
+	}
+
+	return entries, ErrFinances.Wrap(rows.Err())
+}
+
+// listTransactionsPaginated returns paginated list of the transactions.
+func listTransactionsPaginated(cursor pagination.Cursor, transactions []finances.Transaction, totalCount int) finances.TransactionsPage {
+	pageCount := totalCount / cursor.Limit
+	if totalCount%cursor.Limit != 0 {
+		pageCount++
+	}
+
+	return finances.TransactionsPage{
+		Transactions: transactions,
+		Page: pagination.Page{
+			Offset:      (cursor.Page - 1) * cursor.Limit,
+			Limit:       cursor.Limit,
+			CurrentPage: cursor.Page,
+			PageCount:   pageCount,
+			TotalCount:  totalCount,
+		},
+	}
+}
@@ -9,12 +9,14 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"github.com/zeebo/errs"
 
+	"ultimatedivision/cards"
 	"ultimatedivision/marketplace"
 	"ultimatedivision/pkg/pagination"
 )
@@ -33,22 +35,56 @@ type marketplaceDB struct {
 }
 
 const (
-	allFieldsOfLot = `card_id, type, user_id, shopper_id, status, start_price, max_price, current_price, start_time, end_time, period`
+	allFieldsOfLot = `id, card_id, type, sale_mode, user_id, shopper_id, status, start_price, max_price, floor_price, current_price, start_time, end_time, period, settlement, final_listing_hash`
 )
 
-// CreateLot creates lot in the db.
-func (marketplaceDB *marketplaceDB) CreateLot(ctx context.Context, lot marketplace.Lot) error {
+// CreateLot creates lot with all its cards in the db, the cards are put on sale in the same transaction
+// only if they are owned by the seller and are not on sale or retired.
+func (marketplaceDB *marketplaceDB) CreateLot(ctx context.Context, lot marketplace.Lot, history ...cards.History) error {
+	tx, err := marketplaceDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrMarketplace.Wrap(err)
+	}
+
 	query :=
 		`INSERT INTO 
 			lots(` + allFieldsOfLot + ` )
 		VALUES
-			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
 
-	_, err := marketplaceDB.conn.ExecContext(ctx, query,
-		lot.CardID, lot.Type, lot.UserID, lot.ShopperID, lot.Status,
-		lot.StartPrice.Bytes(), lot.MaxPrice.Bytes(), lot.CurrentPrice.Bytes(), lot.StartTime, lot.EndTime, lot.Period)
+	_, err = tx.ExecContext(ctx, query,
+		lot.ID, lot.CardID, lot.Type, lot.SaleMode, lot.UserID, lot.ShopperID, lot.Status,
+		lot.StartPrice.Bytes(), lot.MaxPrice.Bytes(), lot.FloorPrice.Bytes(), lot.CurrentPrice.Bytes(), lot.StartTime, lot.EndTime, lot.Period,
+		lot.Settlement, lot.FinalListingHash)
+	if err != nil {
+		return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+	}
 
-	return ErrMarketplace.Wrap(err)
+	for position, cardID := range lot.CardIDs() {
+		result, err := tx.ExecContext(ctx, `UPDATE cards SET status = $1 WHERE id = $2 AND user_id = $3 AND status NOT IN ($1, $4)`,
+			cards.StatusSale, cardID, lot.UserID, cards.StatusRetired)
+		if err != nil {
+			return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+		rowNum, err := result.RowsAffected()
+		if err != nil {
+			return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+		if rowNum == 0 {
+			return errs.Combine(marketplace.ErrCardNotAvailable.New("card %s could not be put on sale", cardID), tx.Rollback())
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO lot_cards(lot_id, card_id, position) VALUES($1, $2, $3)`, lot.ID, cardID, position)
+		if err != nil {
+			return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	if err = insertCardHistory(ctx, tx, history...); err != nil {
+		return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	return ErrMarketplace.Wrap(tx.Commit())
 }
 
 // GetLotByID returns lot by id from the data base.
@@ -56,13 +92,14 @@ func (marketplaceDB *marketplaceDB) GetLotByID(ctx context.Context, id uuid.UUID
 	var (
 		startPrice   []byte
 		maxPrice     []byte
+		floorPrice   []byte
 		currentPrice []byte
 		lot          marketplace.Lot
 	)
 
 	query :=
 		`SELECT 
-			lots.card_id, lots.type, lots.user_id, shopper_id, lots.status, start_price, max_price, current_price, start_time, end_time, period,
+			lots.id, lots.card_id, lots.type, lots.sale_mode, lots.user_id, shopper_id, lots.status, start_price, max_price, floor_price, current_price, start_time, end_time, period, settlement, final_listing_hash,
 			cards.id, player_name, quality, height, weight, dominant_foot, is_tattoo, cards.status, cards.type,
 			cards.user_id, tactics, positioning, composure, aggression, vision, awareness, crosses, physique, acceleration, running_speed, reaction_speed, agility,
 			stamina, strength, jumping, balance, technique, dribbling, ball_control, weak_foot, skill_moves, finesse, curve, volleys, short_passing, long_passing,
@@ -73,10 +110,10 @@ func (marketplaceDB *marketplaceDB) GetLotByID(ctx context.Context, id uuid.UUID
 		LEFT JOIN 
 			cards ON lots.card_id = cards.id
 		WHERE 
-			lots.card_id = $1`
+			lots.id = $1`
 
 	err := marketplaceDB.conn.QueryRowContext(ctx, query, id).Scan(
-		&lot.CardID, &lot.Type, &lot.UserID, &lot.ShopperID, &lot.Status, &startPrice, &maxPrice, &currentPrice, &lot.StartTime, &lot.EndTime, &lot.Period,
+		&lot.ID, &lot.CardID, &lot.Type, &lot.SaleMode, &lot.UserID, &lot.ShopperID, &lot.Status, &startPrice, &maxPrice, &floorPrice, &currentPrice, &lot.StartTime, &lot.EndTime, &lot.Period, &lot.Settlement, &lot.FinalListingHash,
 		&lot.Card.ID, &lot.Card.PlayerName, &lot.Card.Quality, &lot.Card.Height, &lot.Card.Weight, &lot.Card.DominantFoot, &lot.Card.IsTattoo, &lot.Card.Status, &lot.Card.Type, &lot.Card.UserID, &lot.Card.Tactics, &lot.Card.Positioning,
 		&lot.Card.Composure, &lot.Card.Aggression, &lot.Card.Vision, &lot.Card.Awareness, &lot.Card.Crosses, &lot.Card.Physique, &lot.Card.Acceleration, &lot.Card.RunningSpeed,
 		&lot.Card.ReactionSpeed, &lot.Card.Agility, &lot.Card.Stamina, &lot.Card.Strength, &lot.Card.Jumping, &lot.Card.Balance, &lot.Card.Technique, &lot.Card.Dribbling,
@@ -87,6 +124,7 @@ func (marketplaceDB *marketplaceDB) GetLotByID(ctx context.Context, id uuid.UUID
 	)
 	lot.StartPrice.SetBytes(startPrice)
 	lot.MaxPrice.SetBytes(maxPrice)
+	lot.FloorPrice.SetBytes(floorPrice)
 	lot.CurrentPrice.SetBytes(currentPrice)
 
 	switch {
@@ -99,11 +137,78 @@ func (marketplaceDB *marketplaceDB) GetLotByID(ctx context.Context, id uuid.UUID
 	}
 }
 
+// GetActiveLotByCardID returns active lot which holds the card from the data base.
+func (marketplaceDB *marketplaceDB) GetActiveLotByCardID(ctx context.Context, cardID uuid.UUID) (marketplace.Lot, error) {
+	var id uuid.UUID
+
+	query := `SELECT lots.id
+	          FROM lots
+	          JOIN lot_cards ON lot_cards.lot_id = lots.id
+	          WHERE lot_cards.card_id = $1 AND lots.status = $2`
+
+	err := marketplaceDB.conn.QueryRowContext(ctx, query, cardID, marketplace.StatusActive).Scan(&id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return marketplace.Lot{}, marketplace.ErrNoLot.Wrap(err)
+	case err != nil:
+		return marketplace.Lot{}, ErrMarketplace.Wrap(err)
+	}
+
+	return marketplaceDB.GetLotByID(ctx, id)
+}
+
+// ListLotCards returns cards of the lots by lot id from the data base in the order they were added to the lots.
+func (marketplaceDB *marketplaceDB) ListLotCards(ctx context.Context, lotIDs []uuid.UUID) (_ map[uuid.UUID][]cards.Card, err error) {
+	query := `SELECT lot_cards.lot_id,
+	              cards.id, player_name, quality, height, weight, dominant_foot, is_tattoo, status, type, user_id, tactics, positioning,
+	              composure, aggression, vision, awareness, crosses, physique, acceleration, running_speed,
+	              reaction_speed, agility, stamina, strength, jumping, balance, technique, dribbling,
+	              ball_control, weak_foot, skill_moves, finesse, curve, volleys, short_passing, long_passing,
+	              forward_pass, offense, finishing_ability, shot_power, accuracy, distance, penalty, free_kicks,
+	              corners, heading_accuracy, defence, offside_trap, sliding, tackles, ball_focus, interceptions,
+	              vigilance, goalkeeping, reflexes, diving, handling, sweeping, throwing, is_minted, age, potential, nationality
+	          FROM lot_cards
+	          JOIN cards ON cards.id = lot_cards.card_id
+	          WHERE lot_cards.lot_id = ANY($1)
+	          ORDER BY lot_cards.lot_id, lot_cards.position`
+
+	rows, err := marketplaceDB.conn.QueryContext(ctx, query, pq.Array(lotIDs))
+	if err != nil {
+		return nil, ErrMarketplace.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	lotCards := make(map[uuid.UUID][]cards.Card, len(lotIDs))
+	for rows.Next() {
+		var (
+			lotID uuid.UUID
+			card  cards.Card
+		)
+		if err = rows.Scan(&lotID,
+			&card.ID, &card.PlayerName, &card.Quality, &card.Height, &card.Weight, &card.DominantFoot, &card.IsTattoo, &card.Status, &card.Type, &card.UserID, &card.Tactics, &card.Positioning,
+			&card.Composure, &card.Aggression, &card.Vision, &card.Awareness, &card.Crosses, &card.Physique, &card.Acceleration, &card.RunningSpeed,
+			&card.ReactionSpeed, &card.Agility, &card.Stamina, &card.Strength, &card.Jumping, &card.Balance, &card.Technique, &card.Dribbling,
+			&card.BallControl, &card.WeakFoot, &card.SkillMoves, &card.Finesse, &card.Curve, &card.Volleys, &card.ShortPassing, &card.LongPassing,
+			&card.ForwardPass, &card.Offence, &card.FinishingAbility, &card.ShotPower, &card.Accuracy, &card.Distance, &card.Penalty, &card.FreeKicks,
+			&card.Corners, &card.HeadingAccuracy, &card.Defence, &card.OffsideTrap, &card.Sliding, &card.Tackles, &card.BallFocus, &card.Interceptions,
+			&card.Vigilance, &card.Goalkeeping, &card.Reflexes, &card.Diving, &card.Handling, &card.Sweeping, &card.Throwing, &card.IsMinted, &card.Age, &card.Potential, &card.Nationality,
+		); err != nil {
+			return nil, ErrMarketplace.Wrap(err)
+		}
+
+		lotCards[lotID] = append(lotCards[lotID], card)
+	}
+
+	return lotCards, ErrMarketplace.Wrap(rows.Err())
+}
+
 // GetLotEndTimeByID returns lot end time by id from data base.
 func (marketplaceDB *marketplaceDB) GetLotEndTimeByID(ctx context.Context, id uuid.UUID) (time.Time, error) {
 	var endTime time.Time
 
-	query := `SELECT end_time FROM lots WHERE card_id = $1`
+	query := `SELECT end_time FROM lots WHERE id = $1`
 
 	err := marketplaceDB.conn.QueryRowContext(ctx, query, id).Scan(&endTime)
 
@@ -124,7 +229,12 @@ func (marketplaceDB *marketplaceDB) GetCurrentPriceByCardID(ctx context.Context,
 		currentPriceInt big.Int
 	)
 
-	query := `SELECT current_price FROM lots WHERE card_id = $1`
+	query := `SELECT current_price
+	          FROM lots
+	          JOIN lot_cards ON lot_cards.lot_id = lots.id
+	          WHERE lot_cards.card_id = $1
+	          ORDER BY lots.start_time DESC
+	          LIMIT 1`
 
 	err := marketplaceDB.conn.QueryRowContext(ctx, query, cardID).Scan(&currentPrice)
 	currentPriceInt.SetBytes(currentPrice)
@@ -139,19 +249,27 @@ func (marketplaceDB *marketplaceDB) GetCurrentPriceByCardID(ctx context.Context,
 	}
 }
 
-// ListActiveLots returns active lots from the data base.
-func (marketplaceDB *marketplaceDB) ListActiveLots(ctx context.Context, cursor pagination.Cursor) (marketplace.Page, error) {
+// ListActiveLots returns active lots of the sale mode from the data base, lots of all modes are returned if mode is empty.
+func (marketplaceDB *marketplaceDB) ListActiveLots(ctx context.Context, saleMode marketplace.SaleMode, cursor pagination.Cursor) (marketplace.Page, error) {
 	var (
 		startPrice   []byte
 		maxPrice     []byte
+		floorPrice   []byte
 		currentPrice []byte
 		lotsListPage marketplace.Page
 	)
 
-	offset := (cursor.Page - 1) * cursor.Limit
-	query :=
+	whereClause, values, err := lotsKeyset(cursor, 3)
+	if err != nil {
+		return lotsListPage, ErrMarketplace.Wrap(err)
+	}
+	if whereClause != "" {
+		whereClause = "AND " + whereClause
+	}
+	limit, offset := keysetLimitOffset(cursor)
+	query := fmt.Sprintf(
 		`SELECT 
-			lots.card_id, lots.type, lots.user_id, shopper_id, lots.status, start_price, max_price, current_price, start_time, end_time, period,
+			lots.id, lots.card_id, lots.type, lots.sale_mode, lots.user_id, shopper_id, lots.status, start_price, max_price, floor_price, current_price, start_time, end_time, period, settlement, final_listing_hash,
 			cards.id, player_name, quality, height, weight, dominant_foot, is_tattoo, cards.status, cards.type,
 			cards.user_id, tactics, positioning, composure, aggression, vision, awareness, crosses, physique, acceleration, running_speed, reaction_speed, agility,
 			stamina, strength, jumping, balance, technique, dribbling, ball_control, weak_foot, skill_moves, finesse, curve, volleys, short_passing, long_passing,
@@ -162,13 +280,15 @@ func (marketplaceDB *marketplaceDB) ListActiveLots(ctx context.Context, cursor p
 		LEFT JOIN 
 			cards ON lots.card_id = cards.id
 		WHERE
-			lots.status = $1
+			lots.status = $1 AND ($2 = '' OR lots.sale_mode = $2) %s
+		ORDER BY
+			lots.start_time, lots.id
 		LIMIT 
-			$2
+			%d
 		OFFSET 
-			$3`
+			%d`, whereClause, limit, offset)
 
-	rows, err := marketplaceDB.conn.QueryContext(ctx, query, marketplace.StatusActive, cursor.Limit, offset)
+	rows, err := marketplaceDB.conn.QueryContext(ctx, query, append([]interface{}{marketplace.StatusActive, saleMode}, values...)...)
 	if err != nil {
 		return lotsListPage, ErrMarketplace.Wrap(err)
 	}
@@ -180,7 +300,7 @@ func (marketplaceDB *marketplaceDB) ListActiveLots(ctx context.Context, cursor p
 	for rows.Next() {
 		lot := marketplace.Lot{}
 		if err = rows.Scan(
-			&lot.CardID, &lot.Type, &lot.UserID, &lot.ShopperID, &lot.Status, &startPrice, &maxPrice, &currentPrice, &lot.StartTime, &lot.EndTime, &lot.Period,
+			&lot.ID, &lot.CardID, &lot.Type, &lot.SaleMode, &lot.UserID, &lot.ShopperID, &lot.Status, &startPrice, &maxPrice, &floorPrice, &currentPrice, &lot.StartTime, &lot.EndTime, &lot.Period, &lot.Settlement, &lot.FinalListingHash,
 			&lot.Card.ID, &lot.Card.PlayerName, &lot.Card.Quality, &lot.Card.Height, &lot.Card.Weight,
 			&lot.Card.DominantFoot, &lot.Card.IsTattoo, &lot.Card.Status, &lot.Card.Type, &lot.Card.UserID, &lot.Card.Tactics, &lot.Card.Positioning,
 			&lot.Card.Composure, &lot.Card.Aggression, &lot.Card.Vision, &lot.Card.Awareness, &lot.Card.Crosses, &lot.Card.Physique, &lot.Card.Acceleration, &lot.Card.RunningSpeed,
@@ -194,25 +314,36 @@ func (marketplaceDB *marketplaceDB) ListActiveLots(ctx context.Context, cursor p
 		}
 		lot.StartPrice.SetBytes(startPrice)
 		lot.MaxPrice.SetBytes(maxPrice)
+		lot.FloorPrice.SetBytes(floorPrice)
 		lot.CurrentPrice.SetBytes(currentPrice)
 
 		lots = append(lots, lot)
 	}
 
-	totalActiveCount, err := marketplaceDB.totalActiveCount(ctx)
+	if err = rows.Err(); err != nil {
+		return lotsListPage, ErrMarketplace.Wrap(err)
+	}
+
+	if cursor.IsKeyset() {
+		return lotsListKeyset(cursor, lots), nil
+	}
+
+	totalActiveCount, err := marketplaceDB.totalActiveCount(ctx, saleMode)
 	if err != nil {
 		return lotsListPage, ErrMarketplace.Wrap(err)
 	}
 
 	lotsListPage, err = marketplaceDB.listPaginated(ctx, cursor, lots, totalActiveCount)
+	lotsListPage.Page.NextToken = lotsNextToken(lotsListPage.Page, lots)
 	return lotsListPage, ErrMarketplace.Wrap(err)
 }
 
-// ListActiveLotsByCardID returns active lots from the data base by card id.
-func (marketplaceDB *marketplaceDB) ListActiveLotsByCardID(ctx context.Context, cardIds []uuid.UUID, cursor pagination.Cursor) (marketplace.Page, error) {
+// ListActiveLotsByCardID returns active lots of the sale mode from the data base by card id.
+func (marketplaceDB *marketplaceDB) ListActiveLotsByCardID(ctx context.Context, cardIds []uuid.UUID, saleMode marketplace.SaleMode, cursor pagination.Cursor) (marketplace.Page, error) {
 	var (
 		startPrice   []byte
 		maxPrice     []byte
+		floorPrice   []byte
 		currentPrice []byte
 		lotsListPage marketplace.Page
 	)
@@ -220,7 +351,7 @@ func (marketplaceDB *marketplaceDB) ListActiveLotsByCardID(ctx context.Context,
 	offset := (cursor.Page - 1) * cursor.Limit
 	query :=
 		`SELECT 
-			lots.card_id, lots.type, lots.user_id, shopper_id, lots.status, start_price, max_price, current_price, start_time, end_time, period,
+			lots.id, lots.card_id, lots.type, lots.sale_mode, lots.user_id, shopper_id, lots.status, start_price, max_price, floor_price, current_price, start_time, end_time, period, settlement, final_listing_hash,
 			cards.id, player_name, quality, height, weight, dominant_foot, is_tattoo, cards.status, cards.type,
 			cards.user_id, tactics, positioning, composure, aggression, vision, awareness, crosses, physique, acceleration, running_speed, reaction_speed, agility,
 			stamina, strength, jumping, balance, technique, dribbling, ball_control, weak_foot, skill_moves, finesse, curve, volleys, short_passing, long_passing,
@@ -231,13 +362,13 @@ func (marketplaceDB *marketplaceDB) ListActiveLotsByCardID(ctx context.Context,
 		LEFT JOIN 
 			cards ON lots.card_id = cards.id
 		WHERE
-			lots.status = $1 AND lots.card_id = ANY($2)
+			lots.status = $1 AND lots.id IN (SELECT lot_id FROM lot_cards WHERE card_id = ANY($2)) AND ($3 = '' OR lots.sale_mode = $3)
 		LIMIT 
-			$3 
+			$4 
 		OFFSET 
-			$4`
+			$5`
 
-	rows, err := marketplaceDB.conn.QueryContext(ctx, query, marketplace.StatusActive, pq.Array(cardIds), cursor.Limit, offset)
+	rows, err := marketplaceDB.conn.QueryContext(ctx, query, marketplace.StatusActive, pq.Array(cardIds), saleMode, cursor.Limit, offset)
 	if err != nil {
 		return lotsListPage, ErrMarketplace.Wrap(err)
 	}
@@ -249,7 +380,7 @@ func (marketplaceDB *marketplaceDB) ListActiveLotsByCardID(ctx context.Context,
 	for rows.Next() {
 		lot := marketplace.Lot{}
 		if err = rows.Scan(
-			&lot.CardID, &lot.Type, &lot.UserID, &lot.ShopperID, &lot.Status, &startPrice, &maxPrice, &currentPrice, &lot.StartTime, &lot.EndTime, &lot.Period,
+			&lot.ID, &lot.CardID, &lot.Type, &lot.SaleMode, &lot.UserID, &lot.ShopperID, &lot.Status, &startPrice, &maxPrice, &floorPrice, &currentPrice, &lot.StartTime, &lot.EndTime, &lot.Period, &lot.Settlement, &lot.FinalListingHash,
 			&lot.Card.ID, &lot.Card.PlayerName, &lot.Card.Quality, &lot.Card.Height, &lot.Card.Weight, &lot.Card.DominantFoot, &lot.Card.IsTattoo, &lot.Card.Status, &lot.Card.Type, &lot.Card.UserID, &lot.Card.Tactics, &lot.Card.Positioning,
 			&lot.Card.Composure, &lot.Card.Aggression, &lot.Card.Vision, &lot.Card.Awareness, &lot.Card.Crosses, &lot.Card.Physique, &lot.Card.Acceleration, &lot.Card.RunningSpeed,
 			&lot.Card.ReactionSpeed, &lot.Card.Agility, &lot.Card.Stamina, &lot.Card.Strength, &lot.Card.Jumping, &lot.Card.Balance, &lot.Card.Technique, &lot.Card.Dribbling,
@@ -262,12 +393,13 @@ func (marketplaceDB *marketplaceDB) ListActiveLotsByCardID(ctx context.Context,
 		}
 		lot.StartPrice.SetBytes(startPrice)
 		lot.MaxPrice.SetBytes(maxPrice)
+		lot.FloorPrice.SetBytes(floorPrice)
 		lot.CurrentPrice.SetBytes(currentPrice)
 
 		lots = append(lots, lot)
 	}
 
-	totalActiveCount, err := marketplaceDB.totalActiveCountWithFilters(ctx, cardIds)
+	totalActiveCount, err := marketplaceDB.totalActiveCountWithFilters(ctx, cardIds, saleMode)
 	if err != nil {
 		return lotsListPage, ErrCard.Wrap(err)
 	}
@@ -276,6 +408,140 @@ func (marketplaceDB *marketplaceDB) ListActiveLotsByCardID(ctx context.Context,
 	return lotsListPage, ErrMarketplace.Wrap(err)
 }
 
+// ListActiveLotsWithQuery returns active lots of the sale mode which cards match the query from the data base.
+func (marketplaceDB *marketplaceDB) ListActiveLotsWithQuery(ctx context.Context, query cards.Query, saleMode marketplace.SaleMode, cursor pagination.Cursor) (marketplace.Page, error) {
+	return marketplaceDB.listActiveLotsWithQuery(ctx, query, saleMode, time.Time{}, cursor)
+}
+
+// ListActiveLotsWithQuerySince returns active lots of the sale mode which cards match the query and which were
+// started after the time from the database.
+func (marketplaceDB *marketplaceDB) ListActiveLotsWithQuerySince(ctx context.Context, query cards.Query, saleMode marketplace.SaleMode, since time.Time, cursor pagination.Cursor) (marketplace.Page, error) {
+	return marketplaceDB.listActiveLotsWithQuery(ctx, query, saleMode, since, cursor)
+}
+
+// lotsQueryJoinCards joins to the lot its first card which matches the cards condition, so the bundle is matched
+// by any of its cards and is listed once.
+const lotsQueryJoinCards = ` JOIN cards ON cards.id = (
+	SELECT lot_cards.card_id FROM lot_cards JOIN cards ON cards.id = lot_cards.card_id
+	WHERE lot_cards.lot_id = lots.id AND (%s)
+	ORDER BY lot_cards.position
+	LIMIT 1) `
+
+// listActiveLotsWithQuery returns active lots of the sale mode which cards match the query and which were started
+// after the time, zero time matches all lots.
+func (marketplaceDB *marketplaceDB) listActiveLotsWithQuery(ctx context.Context, query cards.Query, saleMode marketplace.SaleMode, since time.Time, cursor pagination.Cursor) (marketplace.Page, error) {
+	var (
+		startPrice   []byte
+		maxPrice     []byte
+		floorPrice   []byte
+		currentPrice []byte
+		lotsListPage marketplace.Page
+	)
+
+	cardsClause, orderByClause, values, err := BuildCardsQuery(query, 4)
+	if err != nil {
+		return lotsListPage, ErrMarketplace.Wrap(err)
+	}
+	if cardsClause == "" {
+		cardsClause = "TRUE"
+	}
+	whereClause := fmt.Sprintf(lotsQueryJoinCards, cardsClause) +
+		" WHERE lots.status = $1 AND ($2 = '' OR lots.sale_mode = $2) AND lots.start_time > $3"
+	values = append([]interface{}{marketplace.StatusActive, saleMode, since}, values...)
+
+	keys, err := cardsQuerySortKeys(query)
+	if err != nil {
+		return lotsListPage, ErrMarketplace.Wrap(err)
+	}
+	// new lots are listed in the order they were started, so listing could be continued after the last one.
+	if !since.IsZero() {
+		keys = []cardsSortKey{{expression: "lots.start_time", order: "ASC"}, {expression: "lots.id", order: "ASC"}}
+		orderByClause = " ORDER BY " + keys[0].String() + ", " + keys[1].String()
+	}
+	keysetClause, keysetValues, err := cardsQueryKeyset(keys, cursor, len(values)+1)
+	if err != nil {
+		return lotsListPage, ErrMarketplace.Wrap(err)
+	}
+	listWhereClause := whereClause
+	if keysetClause != "" {
+		listWhereClause += " AND " + keysetClause
+	}
+
+	limit, offset := keysetLimitOffset(cursor)
+	sqlQuery := fmt.Sprintf(
+		`SELECT 
+			lots.id, lots.card_id, lots.type, lots.sale_mode, lots.user_id, shopper_id, lots.status, start_price, max_price, floor_price, current_price, start_time, end_time, period, settlement, final_listing_hash,
+			cards.id, player_name, quality, height, weight, dominant_foot, is_tattoo, cards.status, cards.type,
+			cards.user_id, tactics, positioning, composure, aggression, vision, awareness, crosses, physique, acceleration, running_speed, reaction_speed, agility,
+			stamina, strength, jumping, balance, technique, dribbling, ball_control, weak_foot, skill_moves, finesse, curve, volleys, short_passing, long_passing,
+			forward_pass, offense, finishing_ability, shot_power, accuracy, distance, penalty, free_kicks, corners, heading_accuracy, defence, offside_trap, sliding,
+			tackles, ball_focus, interceptions, vigilance, goalkeeping, reflexes, diving, handling, sweeping, throwing
+			%s
+		FROM 
+			lots
+		%s
+		%s
+		LIMIT 
+			%d
+		OFFSET 
+			%d`, cardsQueryKeyColumns(keys), listWhereClause, orderByClause, limit, offset)
+
+	rows, err := marketplaceDB.conn.QueryContext(ctx, sqlQuery, append(values, keysetValues...)...)
+	if err != nil {
+		return lotsListPage, ErrMarketplace.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	lots := []marketplace.Lot{}
+	var rowKeys [][]string
+	for rows.Next() {
+		lot := marketplace.Lot{}
+		key := make([]string, len(keys))
+		if err = rows.Scan(append([]interface{}{
+			&lot.ID, &lot.CardID, &lot.Type, &lot.SaleMode, &lot.UserID, &lot.ShopperID, &lot.Status, &startPrice, &maxPrice, &floorPrice, &currentPrice, &lot.StartTime, &lot.EndTime, &lot.Period, &lot.Settlement, &lot.FinalListingHash,
+			&lot.Card.ID, &lot.Card.PlayerName, &lot.Card.Quality, &lot.Card.Height, &lot.Card.Weight, &lot.Card.DominantFoot, &lot.Card.IsTattoo, &lot.Card.Status, &lot.Card.Type, &lot.Card.UserID, &lot.Card.Tactics, &lot.Card.Positioning,
+			&lot.Card.Composure, &lot.Card.Aggression, &lot.Card.Vision, &lot.Card.Awareness, &lot.Card.Crosses, &lot.Card.Physique, &lot.Card.Acceleration, &lot.Card.RunningSpeed,
+			&lot.Card.ReactionSpeed, &lot.Card.Agility, &lot.Card.Stamina, &lot.Card.Strength, &lot.Card.Jumping, &lot.Card.Balance, &lot.Card.Technique, &lot.Card.Dribbling,
+			&lot.Card.BallControl, &lot.Card.WeakFoot, &lot.Card.SkillMoves, &lot.Card.Finesse, &lot.Card.Curve, &lot.Card.Volleys, &lot.Card.ShortPassing, &lot.Card.LongPassing,
+			&lot.Card.ForwardPass, &lot.Card.Offence, &lot.Card.FinishingAbility, &lot.Card.ShotPower, &lot.Card.Accuracy, &lot.Card.Distance, &lot.Card.Penalty,
+			&lot.Card.FreeKicks, &lot.Card.Corners, &lot.Card.HeadingAccuracy, &lot.Card.Defence, &lot.Card.OffsideTrap, &lot.Card.Sliding, &lot.Card.Tackles, &lot.Card.BallFocus,
+			&lot.Card.Interceptions, &lot.Card.Vigilance, &lot.Card.Goalkeeping, &lot.Card.Reflexes, &lot.Card.Diving, &lot.Card.Handling, &lot.Card.Sweeping, &lot.Card.Throwing,
+		}, sortKeyDestinations(key)...)...); err != nil {
+			return lotsListPage, ErrMarketplace.Wrap(err)
+		}
+		lot.StartPrice.SetBytes(startPrice)
+		lot.MaxPrice.SetBytes(maxPrice)
+		lot.FloorPrice.SetBytes(floorPrice)
+		lot.CurrentPrice.SetBytes(currentPrice)
+
+		lots = append(lots, lot)
+		rowKeys = append(rowKeys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return lotsListPage, ErrMarketplace.Wrap(err)
+	}
+
+	if cursor.IsKeyset() {
+		lotsListPage = marketplace.Page{Lots: lots, Page: pagination.Page{Limit: cursor.Limit}}
+		if len(lots) > cursor.Limit {
+			lotsListPage.Lots = lots[:cursor.Limit]
+			lotsListPage.Page.NextToken = pagination.EncodeToken(rowKeys[cursor.Limit-1]...)
+		}
+		return lotsListPage, nil
+	}
+
+	totalActiveCount, err := marketplaceDB.totalActiveCountWithQuery(ctx, whereClause, values)
+	if err != nil {
+		return lotsListPage, ErrMarketplace.Wrap(err)
+	}
+
+	lotsListPage, err = marketplaceDB.listPaginated(ctx, cursor, lots, totalActiveCount)
+	lotsListPage.Page.NextToken = queryNextToken(lotsListPage.Page, rowKeys)
+	return lotsListPage, ErrMarketplace.Wrap(err)
+}
+
 // listPaginated returns paginated list of lots.
 func (marketplaceDB *marketplaceDB) listPaginated(ctx context.Context, cursor pagination.Cursor, lotsList []marketplace.Lot, totalActiveCount int) (marketplace.Page, error) {
 	var lotsListPage marketplace.Page
@@ -299,49 +565,131 @@ func (marketplaceDB *marketplaceDB) listPaginated(ctx context.Context, cursor pa
 	return lotsListPage, nil
 }
 
-// totalActiveCount counts active lots in the table.
-func (marketplaceDB *marketplaceDB) totalActiveCount(ctx context.Context) (int, error) {
+// lotsKeyset returns condition and its values to continue listing of lots ordered by start time after the continuation token.
+func lotsKeyset(cursor pagination.Cursor, placeholder int) (string, []interface{}, error) {
+	if !cursor.IsKeyset() {
+		return "", nil, nil
+	}
+
+	key, err := pagination.DecodeToken(cursor.Token, 2)
+	if err != nil {
+		return "", nil, err
+	}
+	startTime, err := time.Parse(time.RFC3339Nano, key[0])
+	if err != nil {
+		return "", nil, pagination.ErrInvalidToken.Wrap(err)
+	}
+	id, err := uuid.Parse(key[1])
+	if err != nil {
+		return "", nil, pagination.ErrInvalidToken.Wrap(err)
+	}
+
+	return fmt.Sprintf("(lots.start_time, lots.id) > ($%d, $%d)", placeholder, placeholder+1), []interface{}{startTime, id}, nil
+}
+
+// lotsKey returns sort key of the lot for the continuation token.
+func lotsKey(lot marketplace.Lot) string {
+	return pagination.EncodeToken(lot.StartTime.Format(time.RFC3339Nano), lot.ID.String())
+}
+
+// lotsListKeyset returns keyset page of lots, lotsList holds one extra lot if there is a next page.
+func lotsListKeyset(cursor pagination.Cursor, lotsList []marketplace.Lot) marketplace.Page {
+	page := marketplace.Page{
+		Lots: lotsList,
+		Page: pagination.Page{Limit: cursor.Limit},
+	}
+	if len(lotsList) > cursor.Limit {
+		page.Lots = lotsList[:cursor.Limit]
+		page.Page.NextToken = lotsKey(page.Lots[cursor.Limit-1])
+	}
+	return page
+}
+
+// lotsNextToken returns continuation token for the page of lots listed by page number, it is empty on the last page.
+func lotsNextToken(page pagination.Page, lotsList []marketplace.Lot) string {
+	if len(lotsList) == 0 || page.Offset+len(lotsList) >= page.TotalCount {
+		return ""
+	}
+	return lotsKey(lotsList[len(lotsList)-1])
+}
+
+// totalActiveCount counts active lots of the sale mode in the table.
+func (marketplaceDB *marketplaceDB) totalActiveCount(ctx context.Context, saleMode marketplace.SaleMode) (int, error) {
 	var count int
-	query := fmt.Sprintf(`SELECT COUNT(*) FROM lots WHERE lots.status = $1`)
-	err := marketplaceDB.conn.QueryRowContext(ctx, query, marketplace.StatusActive).Scan(&count)
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM lots WHERE lots.status = $1 AND ($2 = '' OR lots.sale_mode = $2)`)
+	err := marketplaceDB.conn.QueryRowContext(ctx, query, marketplace.StatusActive, saleMode).Scan(&count)
 	if errors.Is(err, sql.ErrNoRows) {
 		return 0, marketplace.ErrNoLot.Wrap(err)
 	}
 	return count, ErrMarketplace.Wrap(err)
 }
 
-// totalActiveCountWithFilters counts active lots with filtes in the table.
-func (marketplaceDB *marketplaceDB) totalActiveCountWithFilters(ctx context.Context, itemIds []uuid.UUID) (int, error) {
+// totalActiveCountWithFilters counts active lots of the sale mode with filtes in the table.
+func (marketplaceDB *marketplaceDB) totalActiveCountWithFilters(ctx context.Context, itemIds []uuid.UUID, saleMode marketplace.SaleMode) (int, error) {
 	var count int
-	query := fmt.Sprintf("SELECT COUNT(*) FROM lots WHERE lots.status = $1 AND card_id = ANY($2)")
-	err := marketplaceDB.conn.QueryRowContext(ctx, query, marketplace.StatusActive, pq.Array(itemIds)).Scan(&count)
+	query := fmt.Sprintf("SELECT COUNT(*) FROM lots WHERE lots.status = $1 AND lots.id IN (SELECT lot_id FROM lot_cards WHERE card_id = ANY($2)) AND ($3 = '' OR lots.sale_mode = $3)")
+	err := marketplaceDB.conn.QueryRowContext(ctx, query, marketplace.StatusActive, pq.Array(itemIds), saleMode).Scan(&count)
 	if errors.Is(err, sql.ErrNoRows) {
 		return 0, marketplace.ErrNoLot.Wrap(err)
 	}
 	return count, ErrMarketplace.Wrap(err)
 }
 
-// ListExpiredLot returns lots where end time lower than or equal to time now UTC from the data base.
+// totalActiveCountWithQuery counts active lots which cards match compiled query in the table,
+// the clause joins the cards to the lots.
+func (marketplaceDB *marketplaceDB) totalActiveCountWithQuery(ctx context.Context, whereClause string, values []interface{}) (int, error) {
+	var count int
+	query := fmt.Sprintf("SELECT COUNT(*) FROM lots %s", whereClause)
+	err := marketplaceDB.conn.QueryRowContext(ctx, query, values...).Scan(&count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, marketplace.ErrNoLot.Wrap(err)
+	}
+	return count, ErrMarketplace.Wrap(err)
+}
+
+// ListExpiredLot returns lots where end time lower than or equal to time now UTC and the bought lots which are not
+// settled yet from the data base.
 func (marketplaceDB *marketplaceDB) ListExpiredLot(ctx context.Context) ([]marketplace.Lot, error) {
-	var (
-		startPrice   []byte
-		maxPrice     []byte
-		currentPrice []byte
-		lots         []marketplace.Lot
-	)
+	query :=
+		`SELECT 
+			` + allFieldsOfLot + ` 
+		FROM 
+			lots
+		WHERE
+			(status = $1 AND end_time <= $2)
+		OR
+			(status = $3 AND settlement = ANY($4))
+		`
+
+	return marketplaceDB.listLots(ctx, query, marketplace.StatusActive, time.Now().UTC(), marketplace.StatusSoldBuynow,
+		pq.Array([]string{string(marketplace.SettlementPendingFinalListing), string(marketplace.SettlementOnChainConfirmed)}))
+}
 
+// ListSettledLots returns lots which are settled in the database.
+func (marketplaceDB *marketplaceDB) ListSettledLots(ctx context.Context) ([]marketplace.Lot, error) {
 	query :=
 		`SELECT 
 			` + allFieldsOfLot + ` 
 		FROM 
 			lots
 		WHERE
-			status = $1
-		AND
-			end_time <= $2
+			settlement = $1
 		`
 
-	rows, err := marketplaceDB.conn.QueryContext(ctx, query, marketplace.StatusActive, time.Now().UTC())
+	return marketplaceDB.listLots(ctx, query, marketplace.SettlementDBSettled)
+}
+
+// listLots returns lots selected by the query with all fields of the lot.
+func (marketplaceDB *marketplaceDB) listLots(ctx context.Context, query string, args ...interface{}) (_ []marketplace.Lot, err error) {
+	var (
+		startPrice   []byte
+		maxPrice     []byte
+		floorPrice   []byte
+		currentPrice []byte
+		lots         []marketplace.Lot
+	)
+
+	rows, err := marketplaceDB.conn.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, ErrMarketplace.Wrap(err)
 	}
@@ -352,13 +700,14 @@ func (marketplaceDB *marketplaceDB) ListExpiredLot(ctx context.Context) ([]marke
 	for rows.Next() {
 		lot := marketplace.Lot{}
 		if err = rows.Scan(
-			&lot.CardID, &lot.Type, &lot.UserID, &lot.ShopperID, &lot.Status,
-			&startPrice, &maxPrice, &currentPrice, &lot.StartTime, &lot.EndTime, &lot.Period,
+			&lot.ID, &lot.CardID, &lot.Type, &lot.SaleMode, &lot.UserID, &lot.ShopperID, &lot.Status,
+			&startPrice, &maxPrice, &floorPrice, &currentPrice, &lot.StartTime, &lot.EndTime, &lot.Period, &lot.Settlement, &lot.FinalListingHash,
 		); err != nil {
 			return nil, ErrMarketplace.Wrap(err)
 		}
 		lot.StartPrice.SetBytes(startPrice)
 		lot.MaxPrice.SetBytes(maxPrice)
+		lot.FloorPrice.SetBytes(floorPrice)
 		lot.CurrentPrice.SetBytes(currentPrice)
 
 		lots = append(lots, lot)
@@ -372,7 +721,7 @@ func (marketplaceDB *marketplaceDB) ListExpiredLot(ctx context.Context) ([]marke
 
 // UpdateShopperIDLot updates shopper id of lot in the database.
 func (marketplaceDB *marketplaceDB) UpdateShopperIDLot(ctx context.Context, id, shopperID uuid.UUID) error {
-	result, err := marketplaceDB.conn.ExecContext(ctx, "UPDATE lots SET shopper_id = $1 WHERE card_id = $2", shopperID, id)
+	result, err := marketplaceDB.conn.ExecContext(ctx, "UPDATE lots SET shopper_id = $1 WHERE id = $2", shopperID, id)
 	if err != nil {
 		return ErrMarketplace.Wrap(err)
 	}
@@ -387,7 +736,7 @@ func (marketplaceDB *marketplaceDB) UpdateShopperIDLot(ctx context.Context, id,
 
 // UpdateStatusLot updates status of lot in the database.
 func (marketplaceDB *marketplaceDB) UpdateStatusLot(ctx context.Context, id uuid.UUID, status marketplace.Status) error {
-	result, err := marketplaceDB.conn.ExecContext(ctx, "UPDATE lots SET status = $1 WHERE card_id = $2", status, id)
+	result, err := marketplaceDB.conn.ExecContext(ctx, "UPDATE lots SET status = $1 WHERE id = $2", status, id)
 	if err != nil {
 		return ErrMarketplace.Wrap(err)
 	}
@@ -402,7 +751,7 @@ func (marketplaceDB *marketplaceDB) UpdateStatusLot(ctx context.Context, id uuid
 
 // UpdateCurrentPriceLot updates current price of lot in the database.
 func (marketplaceDB *marketplaceDB) UpdateCurrentPriceLot(ctx context.Context, id uuid.UUID, currentPrice big.Int) error {
-	result, err := marketplaceDB.conn.ExecContext(ctx, "UPDATE lots SET current_price = $1 WHERE card_id = $2", currentPrice.Bytes(), id)
+	result, err := marketplaceDB.conn.ExecContext(ctx, "UPDATE lots SET current_price = $1 WHERE id = $2", currentPrice.Bytes(), id)
 	if err != nil {
 		return ErrMarketplace.Wrap(err)
 	}
@@ -417,7 +766,7 @@ func (marketplaceDB *marketplaceDB) UpdateCurrentPriceLot(ctx context.Context, i
 
 // UpdateEndTimeLot updates end time of lot in the database.
 func (marketplaceDB *marketplaceDB) UpdateEndTimeLot(ctx context.Context, id uuid.UUID, endTime time.Time) error {
-	result, err := marketplaceDB.conn.ExecContext(ctx, "UPDATE lots SET end_time = $1 WHERE card_id = $2", endTime, id)
+	result, err := marketplaceDB.conn.ExecContext(ctx, "UPDATE lots SET end_time = $1 WHERE id = $2", endTime, id)
 	if err != nil {
 		return ErrMarketplace.Wrap(err)
 	}
@@ -430,11 +779,141 @@ func (marketplaceDB *marketplaceDB) UpdateEndTimeLot(ctx context.Context, id uui
 	return ErrMarketplace.Wrap(err)
 }
 
+// UpdateSettlementLot moves lot from one settlement state to another in the database.
+func (marketplaceDB *marketplaceDB) UpdateSettlementLot(ctx context.Context, id uuid.UUID, from, to marketplace.SettlementState, finalListingHash string) error {
+	query := `UPDATE lots
+	          SET settlement = $1, final_listing_hash = $2
+	          WHERE id = $3 AND settlement = $4`
+
+	result, err := marketplaceDB.conn.ExecContext(ctx, query, to, finalListingHash, id, from)
+	if err != nil {
+		return ErrMarketplace.Wrap(err)
+	}
+
+	rowsNum, err := result.RowsAffected()
+	if err != nil {
+		return ErrMarketplace.Wrap(err)
+	}
+	if rowsNum == 0 {
+		return marketplace.ErrSettlementConflict.New("lot is not in %q settlement state", from)
+	}
+
+	return nil
+}
+
+// BuyLot claims the active lot for the shopper together with the funds of the purchase in one transaction
+// and moves lot to the pending final listing state, returns ErrLotNotAvailable if the lot is already claimed.
+func (marketplaceDB *marketplaceDB) BuyLot(ctx context.Context, purchase marketplace.Purchase) error {
+	tx, err := marketplaceDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrMarketplace.Wrap(err)
+	}
+
+	query := `UPDATE lots
+	          SET status = $1, shopper_id = $2, current_price = $3, settlement = $4
+	          WHERE id = $5 AND status = $6 AND shopper_id = $7 AND end_time > $8`
+
+	result, err := tx.ExecContext(ctx, query,
+		marketplace.StatusSoldBuynow, purchase.ShopperID, purchase.Price.Bytes(), marketplace.SettlementPendingFinalListing,
+		purchase.LotID, marketplace.StatusActive, purchase.PreviousShopperID, time.Now().UTC())
+	if err != nil {
+		return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	rowsNum, err := result.RowsAffected()
+	if err != nil {
+		return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+	if rowsNum == 0 {
+		return errs.Combine(marketplace.ErrLotNotAvailable.New("lot is already bought or got a new bid"), tx.Rollback())
+	}
+
+	if err = holdEscrow(ctx, tx, purchase.Hold); err != nil {
+		return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	if purchase.Release != nil {
+		if err = releaseEscrow(ctx, tx, *purchase.Release); err != nil {
+			return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	return ErrMarketplace.Wrap(tx.Commit())
+}
+
+// SettleLot applies all changes of the settlement in one transaction and moves lot to the db settled state.
+func (marketplaceDB *marketplaceDB) SettleLot(ctx context.Context, settlement marketplace.Settlement) error {
+	tx, err := marketplaceDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrMarketplace.Wrap(err)
+	}
+
+	query := `UPDATE lots
+	          SET status = $1, settlement = $2
+	          WHERE id = $3 AND settlement = $4`
+
+	result, err := tx.ExecContext(ctx, query, settlement.Status, marketplace.SettlementDBSettled, settlement.LotID, marketplace.SettlementOnChainConfirmed)
+	if err != nil {
+		return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	rowsNum, err := result.RowsAffected()
+	if err != nil {
+		return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+	if rowsNum == 0 {
+		return errs.Combine(marketplace.ErrSettlementConflict.New("lot is not confirmed on chain"), tx.Rollback())
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE cards SET status = $1, user_id = $2 WHERE id = ANY($3)", cards.StatusActive, settlement.OwnerID, pq.Array(settlement.CardIDs))
+	if err != nil {
+		return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	if settlement.Status == marketplace.StatusSold || settlement.Status == marketplace.StatusSoldBuynow {
+		if err = removeCardsFromSquads(ctx, tx, settlement.CardIDs); err != nil {
+			return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	if err = insertCardHistory(ctx, tx, settlement.History...); err != nil {
+		return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	for _, nft := range settlement.NFTs {
+		query = `UPDATE nfts
+		         SET wallet_address = $1
+		         WHERE chain = $2 AND token_id = $3`
+
+		_, err = tx.ExecContext(ctx, query, nft.WalletAddress, nft.Chain, nft.TokenID)
+		if err != nil {
+			return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	if settlement.Payment != nil {
+		if err = insertPayment(ctx, tx, *settlement.Payment); err != nil {
+			return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	if sale := settlement.Sale; sale != nil {
+		_, err = tx.ExecContext(ctx, insertSaleQuery,
+			sale.ID, sale.CardID, sale.Quality, sale.Rating, sale.RatingBand, sale.Tactics, sale.Physique,
+			sale.Technique, sale.Offence, sale.Defence, sale.Goalkeeping, sale.Age, sale.Price.String(), sale.SoldAt)
+		if err != nil {
+			return ErrMarketplace.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	return ErrMarketplace.Wrap(tx.Commit())
+}
+
 // Delete deletes lot in the database.
-func (marketplaceDB *marketplaceDB) Delete(ctx context.Context, cardID uuid.UUID) error {
-	query := "DELETE FROM lots WHERE card_id = $1"
+func (marketplaceDB *marketplaceDB) Delete(ctx context.Context, id uuid.UUID) error {
+	query := "DELETE FROM lots WHERE id = $1"
 
-	result, err := marketplaceDB.conn.ExecContext(ctx, query, cardID)
+	result, err := marketplaceDB.conn.ExecContext(ctx, query, id)
 	if err != nil {
 		return ErrMarketplace.Wrap(err)
 	}
@@ -446,3 +925,194 @@ func (marketplaceDB *marketplaceDB) Delete(ctx context.Context, cardID uuid.UUID
 
 	return ErrMarketplace.Wrap(err)
 }
+
+// insertSaleQuery adds sale, it is shared by the creation of the sale and the settlement of the lot.
+const insertSaleQuery = `INSERT INTO sales(id, card_id, quality, rating, rating_band, tactics, physique, technique, offence, defence, goalkeeping, age, price, sold_at)
+                         VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+// CreateSale adds sale in the database.
+func (marketplaceDB *marketplaceDB) CreateSale(ctx context.Context, sale marketplace.Sale) error {
+	_, err := marketplaceDB.conn.ExecContext(ctx, insertSaleQuery,
+		sale.ID, sale.CardID, sale.Quality, sale.Rating, sale.RatingBand, sale.Tactics, sale.Physique,
+		sale.Technique, sale.Offence, sale.Defence, sale.Goalkeeping, sale.Age, sale.Price.String(), sale.SoldAt)
+	return ErrMarketplace.Wrap(err)
+}
+
+// salesWhereClause filters sales by the time, quality and rating band of the sales filter passed as the first three arguments.
+const salesWhereClause = `sold_at >= $1 AND ($2 = '' OR quality = $2) AND ($3::INTEGER IS NULL OR rating_band = $3)`
+
+// ListSales returns page of sales which match the filter from the database ordered by time.
+func (marketplaceDB *marketplaceDB) ListSales(ctx context.Context, filter marketplace.SalesFilter, cursor pagination.Cursor) (_ marketplace.SalesPage, err error) {
+	var salesPage marketplace.SalesPage
+	offset := (cursor.Page - 1) * cursor.Limit
+	query :=
+		`SELECT
+			id, card_id, quality, rating, rating_band, tactics, physique, technique, offence, defence, goalkeeping, age, price, sold_at
+		FROM
+			sales
+		WHERE
+			` + salesWhereClause + `
+		ORDER BY
+			sold_at, id
+		LIMIT
+			$4
+		OFFSET
+			$5`
+
+	rows, err := marketplaceDB.conn.QueryContext(ctx, query, filter.Since, filter.Quality, filter.Band, cursor.Limit, offset)
+	if err != nil {
+		return salesPage, ErrMarketplace.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	sales := []marketplace.Sale{}
+	for rows.Next() {
+		var (
+			sale  marketplace.Sale
+			price string
+		)
+		if err = rows.Scan(
+			&sale.ID, &sale.CardID, &sale.Quality, &sale.Rating, &sale.RatingBand, &sale.Tactics, &sale.Physique,
+			&sale.Technique, &sale.Offence, &sale.Defence, &sale.Goalkeeping, &sale.Age, &price, &sale.SoldAt,
+		); err != nil {
+			return salesPage, ErrMarketplace.Wrap(err)
+		}
+		if _, ok := sale.Price.SetString(price, 10); !ok {
+			return salesPage, ErrMarketplace.New("could not parse price equal %v from db", price)
+		}
+		sale.SoldAt = sale.SoldAt.UTC()
+
+		sales = append(sales, sale)
+	}
+	if err = rows.Err(); err != nil {
+		return salesPage, ErrMarketplace.Wrap(err)
+	}
+
+	var totalCount int
+	query = `SELECT COUNT(*) FROM sales WHERE ` + salesWhereClause
+	if err = marketplaceDB.conn.QueryRowContext(ctx, query, filter.Since, filter.Quality, filter.Band).Scan(&totalCount); err != nil {
+		return salesPage, ErrMarketplace.Wrap(err)
+	}
+
+	pageCount := totalCount / cursor.Limit
+	if totalCount%cursor.Limit != 0 {
+		pageCount++
+	}
+
+	return marketplace.SalesPage{
+		Sales: sales,
+		Page: pagination.Page{
+			Offset:      offset,
+			Limit:       cursor.Limit,
+			CurrentPage: cursor.Page,
+			PageCount:   pageCount,
+			TotalCount:  totalCount,
+		},
+	}, nil
+}
+
+// ListPriceStats returns price stats of the sales which match the filter by qualities and rating bands from the database.
+// Percentiles are nearest rank percentiles of the prices.
+func (marketplaceDB *marketplaceDB) ListPriceStats(ctx context.Context, filter marketplace.SalesFilter) (_ []marketplace.PriceStats, err error) {
+	query :=
+		`SELECT
+			quality, rating_band, COUNT(*), MIN(price),
+			percentile_disc(0.25) WITHIN GROUP (ORDER BY price),
+			percentile_disc(0.5) WITHIN GROUP (ORDER BY price),
+			percentile_disc(0.75) WITHIN GROUP (ORDER BY price),
+			percentile_disc(0.9) WITHIN GROUP (ORDER BY price),
+			MAX(price)
+		FROM
+			sales
+		WHERE
+			` + salesWhereClause + `
+		GROUP BY
+			quality, rating_band`
+
+	rows, err := marketplaceDB.conn.QueryContext(ctx, query, filter.Since, filter.Quality, filter.Band)
+	if err != nil {
+		return nil, ErrMarketplace.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	stats := []marketplace.PriceStats{}
+	for rows.Next() {
+		var (
+			stat   marketplace.PriceStats
+			prices [6]string
+		)
+		if err = rows.Scan(
+			&stat.Quality, &stat.RatingBand, &stat.Count, &prices[0], &prices[1], &prices[2], &prices[3], &prices[4], &prices[5],
+		); err != nil {
+			return nil, ErrMarketplace.Wrap(err)
+		}
+		for i, price := range []*big.Int{&stat.Min, &stat.P25, &stat.Median, &stat.P75, &stat.P90, &stat.Max} {
+			if _, ok := price.SetString(prices[i], 10); !ok {
+				return nil, ErrMarketplace.New("could not parse price equal %v from db", prices[i])
+			}
+		}
+
+		stats = append(stats, stat)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, ErrMarketplace.Wrap(err)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Quality != stats[j].Quality {
+			return cards.QualityToValue[stats[i].Quality] < cards.QualityToValue[stats[j].Quality]
+		}
+		return stats[i].RatingBand < stats[j].RatingBand
+	})
+
+	return stats, nil
+}
+
+// ListVolumes returns volumes of the sales which match the filter by the intervals from the database.
+func (marketplaceDB *marketplaceDB) ListVolumes(ctx context.Context, filter marketplace.SalesFilter, interval time.Duration) (_ []marketplace.Volume, err error) {
+	query :=
+		`SELECT
+			to_timestamp(floor(extract(epoch FROM sold_at) / $4) * $4) AS period, COUNT(*), SUM(price)
+		FROM
+			sales
+		WHERE
+			` + salesWhereClause + `
+		GROUP BY
+			period
+		ORDER BY
+			period`
+
+	rows, err := marketplaceDB.conn.QueryContext(ctx, query, filter.Since, filter.Quality, filter.Band, interval.Seconds())
+	if err != nil {
+		return nil, ErrMarketplace.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	volumes := []marketplace.Volume{}
+	for rows.Next() {
+		var (
+			volume marketplace.Volume
+			amount string
+		)
+		if err = rows.Scan(&volume.From, &volume.Count, &amount); err != nil {
+			return nil, ErrMarketplace.Wrap(err)
+		}
+		if _, ok := volume.Amount.SetString(amount, 10); !ok {
+			return nil, ErrMarketplace.New("could not parse amount equal %v from db", amount)
+		}
+		volume.From = volume.From.UTC()
+
+		volumes = append(volumes, volume)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, ErrMarketplace.Wrap(err)
+	}
+
+	return volumes, nil
+}
@@ -7,10 +7,12 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/zeebo/errs"
 
+	"ultimatedivision/finances"
 	"ultimatedivision/gameplay/matches"
 	"ultimatedivision/pkg/pagination"
 )
@@ -65,14 +67,23 @@ func (matchesDB *matchesDB) Get(ctx context.Context, id uuid.UUID) (matches.Matc
 // ListMatches returns all matches from the database.
 func (matchesDB *matchesDB) ListMatches(ctx context.Context, cursor pagination.Cursor) (matches.Page, error) {
 	var matchesListPage matches.Page
-	offset := (cursor.Page - 1) * cursor.Limit
+	whereClause, values, err := keysetByID(cursor, "id", 1)
+	if err != nil {
+		return matchesListPage, ErrMatches.Wrap(err)
+	}
+	if whereClause != "" {
+		whereClause = "WHERE " + whereClause
+	}
+	limit, offset := keysetLimitOffset(cursor)
 
-	query := `SELECT id, user1_id, squad1_id, user1_points, user2_id, squad2_id, user2_points, season_id
+	query := fmt.Sprintf(`SELECT id, user1_id, squad1_id, user1_points, user2_id, squad2_id, user2_points, season_id
 	          FROM matches
-	          LIMIT $1
-	          OFFSET $2`
+	          %s
+	          ORDER BY id
+	          LIMIT %d
+	          OFFSET %d`, whereClause, limit, offset)
 
-	rows, err := matchesDB.conn.QueryContext(ctx, query, cursor.Limit, offset)
+	rows, err := matchesDB.conn.QueryContext(ctx, query, values...)
 	if err != nil {
 		return matchesListPage, ErrMatches.Wrap(err)
 	}
@@ -95,9 +106,29 @@ func (matchesDB *matchesDB) ListMatches(ctx context.Context, cursor pagination.C
 		return matchesListPage, ErrMatches.Wrap(err)
 	}
 
+	if cursor.IsKeyset() {
+		matchesListPage = matches.Page{
+			Matches: allMatches,
+			Page:    pagination.Page{Limit: cursor.Limit},
+		}
+		if len(allMatches) > cursor.Limit {
+			matchesListPage.Matches = allMatches[:cursor.Limit]
+			matchesListPage.Page.NextToken = pagination.EncodeToken(allMatches[cursor.Limit-1].ID.String())
+		}
+		return matchesListPage, nil
+	}
+
 	matchesListPage, err = matchesDB.listPaginated(ctx, cursor, allMatches)
+	if err != nil {
+		return matchesListPage, ErrMatches.Wrap(err)
+	}
 
-	return matchesListPage, ErrMatches.Wrap(err)
+	page := matchesListPage.Page
+	if len(allMatches) > 0 && page.Offset+len(allMatches) < page.TotalCount {
+		matchesListPage.Page.NextToken = pagination.EncodeToken(allMatches[len(allMatches)-1].ID.String())
+	}
+
+	return matchesListPage, nil
 }
 
 // listPaginated returns paginated list of matches.
@@ -183,6 +214,39 @@ func (matchesDB *matchesDB) UpdateMatch(ctx context.Context, match matches.Match
 	return ErrMatches.Wrap(err)
 }
 
+// RankMatch updates the number of points that users received for a played match and records the income
+// of the clubs for the match in one transaction.
+func (matchesDB *matchesDB) RankMatch(ctx context.Context, match matches.Match, income []finances.Transaction) error {
+	tx, err := matchesDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrMatches.Wrap(err)
+	}
+
+	query := `UPDATE matches
+	          SET user1_points = $1, user2_points = $2
+	          WHERE id = $3`
+
+	result, err := tx.ExecContext(ctx, query, match.User1Points, match.User2Points, match.ID)
+	if err != nil {
+		return ErrMatches.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+	rowNum, err := result.RowsAffected()
+	if err != nil {
+		return ErrMatches.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+	if rowNum == 0 {
+		return errs.Combine(matches.ErrNoMatch.New("match does not exist"), tx.Rollback())
+	}
+
+	for _, transaction := range income {
+		if err = insertTransaction(ctx, tx, transaction); err != nil {
+			return ErrMatches.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	return ErrMatches.Wrap(tx.Commit())
+}
+
 // AddGoals adds goals in the match.
 func (matchesDB *matchesDB) AddGoals(ctx context.Context, matchGoals []matches.MatchGoals) error {
 	query := `INSERT INTO match_results(id, match_id, user_id, card_id, minute)
@@ -313,3 +377,67 @@ func (matchesDB *matchesDB) GetMatchResult(ctx context.Context, matchID uuid.UUI
 
 	return goals, ErrMatches.Wrap(err)
 }
+
+// AddSubstitutions adds substitutions made in the match.
+func (matchesDB *matchesDB) AddSubstitutions(ctx context.Context, substitutions []matches.MatchSubstitution) error {
+	query := `INSERT INTO match_substitutions(id, match_id, user_id, card_out_id, card_in_id, minute, reason)
+	          VALUES($1,$2,$3,$4,$5,$6,$7)`
+
+	preparedQuery, err := matchesDB.conn.PrepareContext(ctx, query)
+	if err != nil {
+		return ErrMatches.Wrap(err)
+	}
+	defer func() {
+		err = preparedQuery.Close()
+	}()
+
+	for _, substitution := range substitutions {
+		_, err = preparedQuery.ExecContext(ctx, substitution.ID, substitution.MatchID, substitution.UserID,
+			substitution.CardOutID, substitution.CardInID, substitution.Minute, substitution.Reason)
+
+		if err != nil {
+			return ErrMatches.Wrap(err)
+		}
+	}
+
+	if err = preparedQuery.Close(); err != nil {
+		return ErrMatches.Wrap(err)
+	}
+
+	return ErrMatches.Wrap(err)
+}
+
+// ListMatchSubstitutions returns all substitutions made in the match from the database.
+func (matchesDB *matchesDB) ListMatchSubstitutions(ctx context.Context, matchID uuid.UUID) ([]matches.MatchSubstitution, error) {
+	query := `SELECT id, match_id, user_id, card_out_id, card_in_id, minute, reason
+              FROM match_substitutions
+              WHERE match_id = $1
+              ORDER BY minute`
+
+	rows, err := matchesDB.conn.QueryContext(ctx, query, matchID)
+	if err != nil {
+		return nil, ErrMatches.Wrap(err)
+	}
+
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var substitutions []matches.MatchSubstitution
+
+	for rows.Next() {
+		var substitution matches.MatchSubstitution
+		err = rows.Scan(&substitution.ID, &substitution.MatchID, &substitution.UserID,
+			&substitution.CardOutID, &substitution.CardInID, &substitution.Minute, &substitution.Reason)
+		if err != nil {
+			return nil, ErrMatches.Wrap(err)
+		}
+
+		substitutions = append(substitutions, substitution)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, ErrMatches.Wrap(err)
+	}
+
+	return substitutions, ErrMatches.Wrap(err)
+}
@@ -17,6 +17,7 @@ import (
 	"ultimatedivision/cards"
 	"ultimatedivision/clubs"
 	"ultimatedivision/divisions"
+	"ultimatedivision/finances"
 	"ultimatedivision/gameplay/matches"
 	"ultimatedivision/internal/mail"
 	"ultimatedivision/seasons"
@@ -31,6 +32,7 @@ type SeedDB struct {
 	cards     *cardsDB
 	matches   *matchesDB
 	divisions *divisionsDB
+	finances  *financesDB
 }
 
 // NewSeedDB is a constructor for seed db.
@@ -41,6 +43,7 @@ func NewSeedDB(conn *sql.DB) *SeedDB {
 		cards:     &cardsDB{conn: conn},
 		matches:   &matchesDB{conn: conn},
 		divisions: &divisionsDB{conn: conn},
+		finances:  &financesDB{conn: conn},
 	}
 }
 
@@ -224,10 +227,12 @@ func CreateClubs(ctx context.Context, conn *sql.DB) error {
 			Status:     clubs.StatusActive,
 			DivisionID: lastDivision.ID,
 			CreatedAt:  time.Now(),
+			Crest:      clubs.DefaultCrest,
+			Kit:        clubs.DefaultKit,
 		}
 
-		_, err = conn.ExecContext(ctx, "INSERT INTO clubs(id, owner_id, club_name, status, division_id, created_at)VALUES($1,$2,$3,$4,$5,$6)",
-			club.ID, club.OwnerID, club.Name, club.Status, club.DivisionID, club.CreatedAt)
+		_, err = conn.ExecContext(ctx, "INSERT INTO clubs(id, owner_id, club_name, status, division_id, created_at, crest_shape, crest_symbol, primary_color, secondary_color, badge_url)VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)",
+			club.ID, club.OwnerID, club.Name, club.Status, club.DivisionID, club.CreatedAt, club.Crest.Shape, club.Crest.Symbol, club.Kit.PrimaryColor, club.Kit.SecondaryColor, club.BadgeURL)
 
 		if err != nil {
 			return ErrClubs.Wrap(err)
@@ -247,15 +252,16 @@ func CreateSquads(ctx context.Context, conn *sql.DB) error {
 	for _, club := range allClubs {
 		squad := clubs.Squad{
 			ID:        uuid.New(),
-			Name:      "",
+			Name:      clubs.DefaultSquadName,
 			ClubID:    club.ID,
 			Formation: clubs.FourFourTwo,
 			Tactic:    clubs.Balanced,
 			CaptainID: uuid.Nil,
+			IsActive:  true,
 		}
 
-		_, err = conn.ExecContext(ctx, "INSERT INTO squads(id, squad_name, club_id, formation, tactic, captain_id)VALUES($1,$2,$3,$4,$5,$6)",
-			squad.ID, squad.Name, squad.ClubID, squad.Formation, squad.Tactic, squad.CaptainID)
+		_, err = conn.ExecContext(ctx, "INSERT INTO squads(id, squad_name, club_id, formation, tactic, captain_id, is_active)VALUES($1,$2,$3,$4,$5,$6,$7)",
+			squad.ID, squad.Name, squad.ClubID, squad.Formation, squad.Tactic, squad.CaptainID, squad.IsActive)
 
 		if err != nil {
 			return ErrClubs.Wrap(err)
@@ -283,7 +289,7 @@ func (seedDB *SeedDB) CreateSquadCards(ctx context.Context, conn *sql.DB, cardsC
 		}
 		for i := 0; i < 11; i++ {
 			probabilities := []int{lootboxesConfig.RegularBoxConfig.Wood, lootboxesConfig.RegularBoxConfig.Silver, lootboxesConfig.RegularBoxConfig.Gold, lootboxesConfig.RegularBoxConfig.Diamond}
-			card, err := cardsService.Create(ctx, club.OwnerID, probabilities, cards.TypeWon)
+			card, err := cardsService.Create(ctx, club.OwnerID, probabilities, cards.TypeWon, cards.Event{Cause: cards.CauseLootBox})
 			if err != nil {
 				return ErrClubs.Wrap(err)
 			}
@@ -359,7 +365,7 @@ func GetLastDivision(ctx context.Context, conn *sql.DB) (divisions.Division, err
 
 // ListClubs returns all clubs from the database.
 func ListClubs(ctx context.Context, conn *sql.DB) ([]clubs.Club, error) {
-	query := `SELECT id, owner_id, club_name, status, division_id, created_at
+	query := `SELECT id, owner_id, club_name, status, division_id, created_at, crest_shape, crest_symbol, primary_color, secondary_color, badge_url
 			  FROM clubs`
 
 	rows, err := conn.QueryContext(ctx, query)
@@ -374,7 +380,8 @@ func ListClubs(ctx context.Context, conn *sql.DB) ([]clubs.Club, error) {
 
 	for rows.Next() {
 		var club clubs.Club
-		err = rows.Scan(&club.ID, &club.OwnerID, &club.Name, &club.Status, &club.DivisionID, &club.CreatedAt)
+		err = rows.Scan(&club.ID, &club.OwnerID, &club.Name, &club.Status, &club.DivisionID, &club.CreatedAt,
+			&club.Crest.Shape, &club.Crest.Symbol, &club.Kit.PrimaryColor, &club.Kit.SecondaryColor, &club.BadgeURL)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				return allClubs, clubs.ErrNoClub.Wrap(err)
@@ -390,7 +397,7 @@ func ListClubs(ctx context.Context, conn *sql.DB) ([]clubs.Club, error) {
 
 // ListSquadByClubID returns squad by club id from the database.
 func ListSquadByClubID(ctx context.Context, conn *sql.DB, clubID uuid.UUID) (clubs.Squad, error) {
-	query := `SELECT id, squad_name, club_id, tactic, formation, captain_id
+	query := `SELECT id, squad_name, club_id, tactic, formation, captain_id, is_active
 			  FROM squads
 			  WHERE club_id = $1`
 
@@ -398,7 +405,7 @@ func ListSquadByClubID(ctx context.Context, conn *sql.DB, clubID uuid.UUID) (clu
 
 	var squad clubs.Squad
 
-	err := row.Scan(&squad.ID, &squad.Name, &squad.ClubID, &squad.Tactic, &squad.Formation, &squad.CaptainID)
+	err := row.Scan(&squad.ID, &squad.Name, &squad.ClubID, &squad.Tactic, &squad.Formation, &squad.CaptainID, &squad.IsActive)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return squad, clubs.ErrNoSquad.Wrap(err)
@@ -415,7 +422,8 @@ func (seedDB *SeedDB) CreateMatches(ctx context.Context, conn *sql.DB, matchesCo
 	usersService := users.NewService(seedDB.users)
 	cardsService := cards.NewService(seedDB.cards, cardsConfig)
 	clubsService := clubs.NewService(seedDB.clubs, usersService, cardsService, seedDB.divisions)
-	matchesService := matches.NewService(seedDB.matches, matchesConfig, clubsService, cardsService)
+	financesService := finances.NewService(seedDB.finances, clubsService, cardsService, finances.Config{})
+	matchesService := matches.NewService(seedDB.matches, matchesConfig, clubsService, cardsService, financesService)
 
 	type player struct {
 		userID   uuid.UUID
@@ -0,0 +1,259 @@
+// Copyright (C) 2022 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/cards"
+	"ultimatedivision/marketplace/trades"
+)
+
+// ensures that tradesDB implements trades.DB.
+var _ trades.DB = (*tradesDB)(nil)
+
+// ErrTrades indicates that there was an error in the database.
+var ErrTrades = errs.Class("trades repository error")
+
+// tradesDB provides access to trades db.
+//
+// architecture: Database
+type tradesDB struct {
+	conn *sql.DB
+}
+
+// allFieldsOfTrade is the list of the trade fields selected in the same order as they are scanned.
+const allFieldsOfTrade = `id, proposer_id, recipient_id, amount, status, counter_of_id, created_at, expires_at`
+
+// Create adds trade with its cards in the database.
+func (tradesDB *tradesDB) Create(ctx context.Context, trade trades.Trade) error {
+	tx, err := tradesDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrTrades.Wrap(err)
+	}
+
+	query := `INSERT INTO trades(` + allFieldsOfTrade + `)
+	          VALUES($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err = tx.ExecContext(ctx, query,
+		trade.ID, trade.ProposerID, trade.RecipientID, trade.Amount.Bytes(), trade.Status, trade.CounterOfID, trade.CreatedAt, trade.ExpiresAt)
+	if err != nil {
+		return ErrTrades.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	for offered, cardIDs := range map[bool][]uuid.UUID{true: trade.OfferedCardIDs, false: trade.RequestedCardIDs} {
+		for position, cardID := range cardIDs {
+			_, err = tx.ExecContext(ctx, `INSERT INTO trade_cards(trade_id, card_id, offered, position) VALUES($1, $2, $3, $4)`,
+				trade.ID, cardID, offered, position)
+			if err != nil {
+				return ErrTrades.Wrap(errs.Combine(err, tx.Rollback()))
+			}
+		}
+	}
+
+	return ErrTrades.Wrap(tx.Commit())
+}
+
+// Get returns trade by id from the database.
+func (tradesDB *tradesDB) Get(ctx context.Context, id uuid.UUID) (trades.Trade, error) {
+	query := `SELECT ` + allFieldsOfTrade + `
+	          FROM trades
+	          WHERE id = $1`
+
+	var (
+		trade  trades.Trade
+		amount []byte
+	)
+	err := tradesDB.conn.QueryRowContext(ctx, query, id).Scan(
+		&trade.ID, &trade.ProposerID, &trade.RecipientID, &amount, &trade.Status, &trade.CounterOfID, &trade.CreatedAt, &trade.ExpiresAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return trade, trades.ErrNoTrade.Wrap(err)
+	case err != nil:
+		return trade, ErrTrades.Wrap(err)
+	}
+	trade.Amount.SetBytes(amount)
+
+	list := []trades.Trade{trade}
+	if err = tradesDB.addCards(ctx, list); err != nil {
+		return trade, err
+	}
+
+	return list[0], nil
+}
+
+// ListByUserID returns trades proposed by the user or to the user from the database, newest first.
+func (tradesDB *tradesDB) ListByUserID(ctx context.Context, userID uuid.UUID) ([]trades.Trade, error) {
+	query := `SELECT ` + allFieldsOfTrade + `
+	          FROM trades
+	          WHERE proposer_id = $1 OR recipient_id = $1
+	          ORDER BY created_at DESC`
+
+	return tradesDB.list(ctx, query, userID)
+}
+
+// ListExpired returns pending trades which expire before or at the time from the database.
+func (tradesDB *tradesDB) ListExpired(ctx context.Context, now time.Time) ([]trades.Trade, error) {
+	query := `SELECT ` + allFieldsOfTrade + `
+	          FROM trades
+	          WHERE status = $1 AND expires_at <= $2`
+
+	return tradesDB.list(ctx, query, trades.StatusPending, now)
+}
+
+// list returns trades with their cards selected by the query.
+func (tradesDB *tradesDB) list(ctx context.Context, query string, args ...interface{}) (_ []trades.Trade, err error) {
+	rows, err := tradesDB.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, ErrTrades.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var list []trades.Trade
+	for rows.Next() {
+		var (
+			trade  trades.Trade
+			amount []byte
+		)
+		if err = rows.Scan(&trade.ID, &trade.ProposerID, &trade.RecipientID, &amount, &trade.Status, &trade.CounterOfID, &trade.CreatedAt, &trade.ExpiresAt); err != nil {
+			return nil, ErrTrades.Wrap(err)
+		}
+		trade.Amount.SetBytes(amount)
+
+		list = append(list, trade)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, ErrTrades.Wrap(err)
+	}
+
+	return list, tradesDB.addCards(ctx, list)
+}
+
+// addCards fills offered and requested cards of the trades.
+func (tradesDB *tradesDB) addCards(ctx context.Context, list []trades.Trade) (err error) {
+	if len(list) == 0 {
+		return nil
+	}
+
+	byID := make(map[uuid.UUID]*trades.Trade, len(list))
+	ids := make([]uuid.UUID, 0, len(list))
+	for i := range list {
+		byID[list[i].ID] = &list[i]
+		ids = append(ids, list[i].ID)
+	}
+
+	query := `SELECT trade_id, card_id, offered
+	          FROM trade_cards
+	          WHERE trade_id = ANY($1)
+	          ORDER BY trade_id, position`
+
+	rows, err := tradesDB.conn.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return ErrTrades.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	for rows.Next() {
+		var (
+			tradeID, cardID uuid.UUID
+			offered         bool
+		)
+		if err = rows.Scan(&tradeID, &cardID, &offered); err != nil {
+			return ErrTrades.Wrap(err)
+		}
+
+		trade := byID[tradeID]
+		if offered {
+			trade.OfferedCardIDs = append(trade.OfferedCardIDs, cardID)
+		} else {
+			trade.RequestedCardIDs = append(trade.RequestedCardIDs, cardID)
+		}
+	}
+
+	return ErrTrades.Wrap(rows.Err())
+}
+
+// UpdateStatus moves trade from one status to another in the database.
+func (tradesDB *tradesDB) UpdateStatus(ctx context.Context, id uuid.UUID, from, to trades.Status) error {
+	result, err := tradesDB.conn.ExecContext(ctx, "UPDATE trades SET status = $1 WHERE id = $2 AND status = $3", to, id, from)
+	if err != nil {
+		return ErrTrades.Wrap(err)
+	}
+
+	rowNum, err := result.RowsAffected()
+	if err != nil {
+		return ErrTrades.Wrap(err)
+	}
+	if rowNum == 0 {
+		return trades.ErrTradeConflict.New("trade is not %s", from)
+	}
+
+	return nil
+}
+
+// Accept applies all changes of the accepted trade in one transaction, only if the trade is still pending
+// and the cards still belong to their owners and are active.
+func (tradesDB *tradesDB) Accept(ctx context.Context, acceptance trades.Acceptance) error {
+	tx, err := tradesDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ErrTrades.Wrap(err)
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE trades SET status = $1 WHERE id = $2 AND status = $3 AND expires_at > $4",
+		trades.StatusAccepted, acceptance.TradeID, trades.StatusPending, time.Now().UTC())
+	if err != nil {
+		return ErrTrades.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+	rowNum, err := result.RowsAffected()
+	if err != nil {
+		return ErrTrades.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+	if rowNum == 0 {
+		return errs.Combine(trades.ErrTradeConflict.New("trade is not pending"), tx.Rollback())
+	}
+
+	cardIDs := make([]uuid.UUID, 0, len(acceptance.Swaps))
+	for _, swap := range acceptance.Swaps {
+		result, err = tx.ExecContext(ctx, "UPDATE cards SET user_id = $1 WHERE id = $2 AND user_id = $3 AND status = $4",
+			swap.ToID, swap.CardID, swap.FromID, cards.StatusActive)
+		if err != nil {
+			return ErrTrades.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+		if rowNum, err = result.RowsAffected(); err != nil {
+			return ErrTrades.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+		if rowNum == 0 {
+			return errs.Combine(trades.ErrInvalidTrade.New("card %s does not belong to user %s or is not active", swap.CardID, swap.FromID), tx.Rollback())
+		}
+
+		cardIDs = append(cardIDs, swap.CardID)
+	}
+
+	if err = removeCardsFromSquads(ctx, tx, cardIDs); err != nil {
+		return ErrTrades.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	if err = insertCardHistory(ctx, tx, acceptance.History...); err != nil {
+		return ErrTrades.Wrap(errs.Combine(err, tx.Rollback()))
+	}
+
+	if acceptance.Payment != nil {
+		if err = insertPayment(ctx, tx, *acceptance.Payment); err != nil {
+			return ErrTrades.Wrap(errs.Combine(err, tx.Rollback()))
+		}
+	}
+
+	return ErrTrades.Wrap(tx.Commit())
+}
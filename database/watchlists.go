@@ -0,0 +1,212 @@
+// Copyright (C) 2022 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/marketplace/watchlists"
+)
+
+// ensures that watchlistsDB implements watchlists.DB.
+var _ watchlists.DB = (*watchlistsDB)(nil)
+
+// ErrWatchlists indicates that there was an error in the database.
+var ErrWatchlists = errs.Class("watchlists repository error")
+
+// watchlistsDB provides access to watchlists db.
+//
+// architecture: Database
+type watchlistsDB struct {
+	conn *sql.DB
+}
+
+// CreateWatch adds watch of the lot in the database.
+func (watchlistsDB *watchlistsDB) CreateWatch(ctx context.Context, watch watchlists.Watch) error {
+	query := `INSERT INTO watchlists(user_id, lot_id, email, notified_price, ending_notified, created_at)
+	          VALUES($1, $2, $3, $4, $5, $6)
+	          ON CONFLICT (user_id, lot_id) DO UPDATE SET email = EXCLUDED.email`
+
+	_, err := watchlistsDB.conn.ExecContext(ctx, query,
+		watch.UserID, watch.LotID, watch.Email, watch.NotifiedPrice.Bytes(), watch.EndingNotified, watch.CreatedAt)
+	return ErrWatchlists.Wrap(err)
+}
+
+// ListWatches returns all watches from the database.
+func (watchlistsDB *watchlistsDB) ListWatches(ctx context.Context) ([]watchlists.Watch, error) {
+	query := `SELECT user_id, lot_id, email, notified_price, ending_notified, created_at
+	          FROM watchlists`
+
+	return watchlistsDB.listWatches(ctx, query)
+}
+
+// ListWatchesByUserID returns watches of the user from the database.
+func (watchlistsDB *watchlistsDB) ListWatchesByUserID(ctx context.Context, userID uuid.UUID) ([]watchlists.Watch, error) {
+	query := `SELECT user_id, lot_id, email, notified_price, ending_notified, created_at
+	          FROM watchlists
+	          WHERE user_id = $1
+	          ORDER BY created_at`
+
+	return watchlistsDB.listWatches(ctx, query, userID)
+}
+
+// listWatches returns watches selected by the query.
+func (watchlistsDB *watchlistsDB) listWatches(ctx context.Context, query string, args ...interface{}) (_ []watchlists.Watch, err error) {
+	rows, err := watchlistsDB.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, ErrWatchlists.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var watches []watchlists.Watch
+	for rows.Next() {
+		var (
+			watch         watchlists.Watch
+			notifiedPrice []byte
+		)
+		if err = rows.Scan(&watch.UserID, &watch.LotID, &watch.Email, &notifiedPrice, &watch.EndingNotified, &watch.CreatedAt); err != nil {
+			return nil, ErrWatchlists.Wrap(err)
+		}
+		watch.NotifiedPrice.SetBytes(notifiedPrice)
+
+		watches = append(watches, watch)
+	}
+
+	return watches, ErrWatchlists.Wrap(rows.Err())
+}
+
+// UpdateWatch updates notified state of the watch in the database.
+func (watchlistsDB *watchlistsDB) UpdateWatch(ctx context.Context, watch watchlists.Watch) error {
+	query := `UPDATE watchlists
+	          SET notified_price = $1, ending_notified = $2
+	          WHERE user_id = $3 AND lot_id = $4`
+
+	result, err := watchlistsDB.conn.ExecContext(ctx, query, watch.NotifiedPrice.Bytes(), watch.EndingNotified, watch.UserID, watch.LotID)
+	if err != nil {
+		return ErrWatchlists.Wrap(err)
+	}
+
+	rowNum, err := result.RowsAffected()
+	if rowNum == 0 {
+		return watchlists.ErrNoWatch.New("")
+	}
+
+	return ErrWatchlists.Wrap(err)
+}
+
+// DeleteWatch deletes watch of the lot by the user in the database.
+func (watchlistsDB *watchlistsDB) DeleteWatch(ctx context.Context, userID, lotID uuid.UUID) error {
+	result, err := watchlistsDB.conn.ExecContext(ctx, "DELETE FROM watchlists WHERE user_id = $1 AND lot_id = $2", userID, lotID)
+	if err != nil {
+		return ErrWatchlists.Wrap(err)
+	}
+
+	rowNum, err := result.RowsAffected()
+	if rowNum == 0 {
+		return watchlists.ErrNoWatch.New("")
+	}
+
+	return ErrWatchlists.Wrap(err)
+}
+
+// CreateSavedSearch adds saved search in the database.
+func (watchlistsDB *watchlistsDB) CreateSavedSearch(ctx context.Context, search watchlists.SavedSearch) error {
+	query, err := json.Marshal(search.Query)
+	if err != nil {
+		return ErrWatchlists.Wrap(err)
+	}
+
+	_, err = watchlistsDB.conn.ExecContext(ctx,
+		`INSERT INTO saved_searches(id, user_id, name, query, sale_mode, email, notified_at, created_at)
+		 VALUES($1, $2, $3, $4, $5, $6, $7, $8)`,
+		search.ID, search.UserID, search.Name, query, search.SaleMode, search.Email, search.NotifiedAt, search.CreatedAt)
+	return ErrWatchlists.Wrap(err)
+}
+
+// ListSavedSearches returns all saved searches from the database.
+func (watchlistsDB *watchlistsDB) ListSavedSearches(ctx context.Context) ([]watchlists.SavedSearch, error) {
+	query := `SELECT id, user_id, name, query, sale_mode, email, notified_at, created_at
+	          FROM saved_searches`
+
+	return watchlistsDB.listSavedSearches(ctx, query)
+}
+
+// ListSavedSearchesByUserID returns saved searches of the user from the database.
+func (watchlistsDB *watchlistsDB) ListSavedSearchesByUserID(ctx context.Context, userID uuid.UUID) ([]watchlists.SavedSearch, error) {
+	query := `SELECT id, user_id, name, query, sale_mode, email, notified_at, created_at
+	          FROM saved_searches
+	          WHERE user_id = $1
+	          ORDER BY created_at`
+
+	return watchlistsDB.listSavedSearches(ctx, query, userID)
+}
+
+// listSavedSearches returns saved searches selected by the query.
+func (watchlistsDB *watchlistsDB) listSavedSearches(ctx context.Context, query string, args ...interface{}) (_ []watchlists.SavedSearch, err error) {
+	rows, err := watchlistsDB.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, ErrWatchlists.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var searches []watchlists.SavedSearch
+	for rows.Next() {
+		var (
+			search      watchlists.SavedSearch
+			searchQuery []byte
+		)
+		if err = rows.Scan(
+			&search.ID, &search.UserID, &search.Name, &searchQuery, &search.SaleMode, &search.Email, &search.NotifiedAt, &search.CreatedAt,
+		); err != nil {
+			return nil, ErrWatchlists.Wrap(err)
+		}
+		if err = json.Unmarshal(searchQuery, &search.Query); err != nil {
+			return nil, ErrWatchlists.Wrap(err)
+		}
+
+		searches = append(searches, search)
+	}
+
+	return searches, ErrWatchlists.Wrap(rows.Err())
+}
+
+// UpdateSavedSearchNotifiedAt updates time of the last notification of the saved search in the database.
+func (watchlistsDB *watchlistsDB) UpdateSavedSearchNotifiedAt(ctx context.Context, id uuid.UUID, notifiedAt time.Time) error {
+	result, err := watchlistsDB.conn.ExecContext(ctx, "UPDATE saved_searches SET notified_at = $1 WHERE id = $2", notifiedAt, id)
+	if err != nil {
+		return ErrWatchlists.Wrap(err)
+	}
+
+	rowNum, err := result.RowsAffected()
+	if rowNum == 0 {
+		return watchlists.ErrNoSavedSearch.New("")
+	}
+
+	return ErrWatchlists.Wrap(err)
+}
+
+// DeleteSavedSearch deletes saved search of the user in the database.
+func (watchlistsDB *watchlistsDB) DeleteSavedSearch(ctx context.Context, userID, id uuid.UUID) error {
+	result, err := watchlistsDB.conn.ExecContext(ctx, "DELETE FROM saved_searches WHERE user_id = $1 AND id = $2", userID, id)
+	if err != nil {
+		return ErrWatchlists.Wrap(err)
+	}
+
+	rowNum, err := result.RowsAffected()
+	if rowNum == 0 {
+		return watchlists.ErrNoSavedSearch.New("")
+	}
+
+	return ErrWatchlists.Wrap(err)
+}
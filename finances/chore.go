@@ -0,0 +1,73 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package finances
+
+import (
+	"context"
+	"time"
+
+	"github.com/BoostyLabs/thelooper"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/clubs"
+)
+
+var (
+	// ChoreError represents finances chore error type.
+	ChoreError = errs.Class("finances chore error")
+)
+
+// Chore periodically charges active clubs for the upkeep of their cards.
+//
+// architecture: Chore.
+type Chore struct {
+	Loop     *thelooper.Loop
+	config   Config
+	finances *Service
+	clubs    *clubs.Service
+}
+
+// NewChore instantiates Chore.
+func NewChore(config Config, finances *Service, clubs *clubs.Service) *Chore {
+	return &Chore{
+		Loop:     thelooper.NewLoop(config.Upkeep.RenewalInterval),
+		config:   config,
+		finances: finances,
+		clubs:    clubs,
+	}
+}
+
+// Run runs the upkeep charging of the clubs.
+func (chore *Chore) Run(ctx context.Context) error {
+	return chore.Loop.Run(ctx, func(ctx context.Context) error {
+		if !chore.config.Upkeep.Enabled {
+			return nil
+		}
+
+		// the clubs are charged once for the period, so the restarts of the chore do not charge them twice.
+		period := time.Now().UTC().Truncate(chore.config.Upkeep.RenewalInterval)
+
+		allClubs, err := chore.clubs.List(ctx)
+		if err != nil {
+			return ChoreError.Wrap(err)
+		}
+
+		for _, club := range allClubs {
+			if club.Status != clubs.StatusActive {
+				continue
+			}
+
+			if err = chore.finances.ChargeUpkeep(ctx, club, period); err != nil {
+				return ChoreError.Wrap(err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Close closes the chore.
+func (chore *Chore) Close() {
+	chore.Loop.Close()
+}
@@ -0,0 +1,177 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package finances
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+)
+
+// ErrInsufficientFunds indicates that the available balance of the user is less than the required amount.
+var ErrInsufficientFunds = errs.Class("insufficient funds")
+
+// EscrowAccount returns account which holds funds of the user's bids on the lot. Held funds are moved out
+// of the club account, so the club balance is always the balance available for the new bids.
+func EscrowAccount(lotID, userID uuid.UUID) Account {
+	return Account("escrow:" + lotID.String() + ":" + userID.String())
+}
+
+// Escrow describes the funds of the user held on the lot and the account of the user they are held from
+// and returned to, so the escrow could be changed together with the other changes of the lot.
+type Escrow struct {
+	LotID  uuid.UUID
+	UserID uuid.UUID
+	// Account is the account the first funds of the escrow are held from. The account is recorded with the held
+	// funds, so the later holds, the releases and the payments use the recorded account even if the active club
+	// of the user is changed, Account is used only if nothing is recorded.
+	Account Account
+	// Amount is the amount up to which the funds are held, it is not used when the funds are returned.
+	Amount big.Int
+}
+
+// HoldTransaction returns transaction which raises the held funds up to the amount of the escrow,
+// false is returned if the held funds are already enough.
+func (escrow Escrow) HoldTransaction(held big.Int) (Transaction, bool) {
+	var missing big.Int
+	if missing.Sub(&escrow.Amount, &held); missing.Sign() <= 0 {
+		return Transaction{}, false
+	}
+
+	return NewTransfer(TypeEscrowHold, fmt.Sprintf("lot %s", escrow.LotID), escrow.Account, EscrowAccount(escrow.LotID, escrow.UserID), missing), true
+}
+
+// ReleaseTransaction returns transaction which returns all held funds to the account of the escrow,
+// false is returned if nothing is held.
+func (escrow Escrow) ReleaseTransaction(held big.Int) (Transaction, bool) {
+	if held.Sign() <= 0 {
+		return Transaction{}, false
+	}
+
+	return NewTransfer(TypeEscrowRelease, fmt.Sprintf("lot %s", escrow.LotID), EscrowAccount(escrow.LotID, escrow.UserID), escrow.Account, held), true
+}
+
+// NewHold returns escrow which holds funds of the user on the lot up to the amount from the active club of the user,
+// the funds are raised from the club the first funds of the escrow were held from.
+func (service *Service) NewHold(ctx context.Context, lotID, userID uuid.UUID, amount big.Int) (Escrow, error) {
+	account, err := service.userAccount(ctx, userID)
+	if err != nil {
+		return Escrow{}, err
+	}
+	if account == AccountTransfers {
+		return Escrow{}, ErrInsufficientFunds.New("user does not have active club to pay from")
+	}
+
+	return Escrow{LotID: lotID, UserID: userID, Account: account, Amount: amount}, nil
+}
+
+// NewRelease returns escrow which returns all funds of the user held on the lot to the account they were held from.
+func (service *Service) NewRelease(ctx context.Context, lotID, userID uuid.UUID) (Escrow, error) {
+	account, err := service.userAccount(ctx, userID)
+	if err != nil {
+		return Escrow{}, err
+	}
+
+	return Escrow{LotID: lotID, UserID: userID, Account: account}, nil
+}
+
+// Payment describes the payment of the price from the funds held by the buyer. The transactions are recorded
+// only if the held funds are enough for all of them, the rest of the held funds is returned to the buyer.
+type Payment struct {
+	Transactions []Transaction
+	// Escrow is the escrow of the buyer which pays the transactions and returns the rest of the held funds.
+	Escrow Escrow
+}
+
+// GetHeld returns amount of the user's funds held on the lot.
+func (service *Service) GetHeld(ctx context.Context, lotID, userID uuid.UUID) (big.Int, error) {
+	entries, err := service.finances.ListEntriesByAccount(ctx, EscrowAccount(lotID, userID))
+	if err != nil {
+		return big.Int{}, ErrFinances.Wrap(err)
+	}
+
+	return Balance(entries), nil
+}
+
+// Hold raises funds held from the club of the user on the lot up to the amount, only the missing part
+// is moved to the escrow, so it must not exceed the balance of the club.
+func (service *Service) Hold(ctx context.Context, lotID, userID uuid.UUID, amount big.Int) error {
+	escrow, err := service.NewHold(ctx, lotID, userID, amount)
+	if err != nil {
+		return err
+	}
+
+	return ErrFinances.Wrap(service.finances.Hold(ctx, escrow))
+}
+
+// Release returns all funds held on the lot to the account they were held from, it does nothing if nothing is held.
+func (service *Service) Release(ctx context.Context, lotID, userID uuid.UUID) error {
+	escrow, err := service.NewRelease(ctx, lotID, userID)
+	if err != nil {
+		return err
+	}
+
+	return ErrFinances.Wrap(service.finances.Release(ctx, escrow))
+}
+
+// Settle pays the price of the lot from the funds held by the buyer to the active club of the seller and the fees
+// of the sale to their recipients, the rest of the held funds are returned to the buyer.
+func (service *Service) Settle(ctx context.Context, lotID, sellerID, buyerID uuid.UUID, price big.Int, fees Fees) error {
+	payment, err := service.NewPayment(ctx, lotID, sellerID, buyerID, price, fees)
+	if err != nil {
+		return err
+	}
+
+	return ErrFinances.Wrap(service.finances.CreatePayment(ctx, payment))
+}
+
+// NewPayment returns payment of the price of the lot without recording it, so it could be recorded together with
+// the other changes of the lot. The seller gets the price without the fees, the price is paid from the funds held
+// by the buyer and the rest of them is returned to the buyer.
+func (service *Service) NewPayment(ctx context.Context, lotID, sellerID, buyerID uuid.UUID, price big.Int, fees Fees) (Payment, error) {
+	var proceeds big.Int
+	proceeds.Sub(&price, &fees.Commission)
+	if proceeds.Sub(&proceeds, &fees.Royalty); proceeds.Sign() < 0 || fees.Commission.Sign() < 0 || fees.Royalty.Sign() < 0 {
+		return Payment{}, ErrFinances.New("fees %s and %s do not fit the price %s", fees.Commission.String(), fees.Royalty.String(), price.String())
+	}
+
+	escrow, err := service.NewRelease(ctx, lotID, buyerID)
+	if err != nil {
+		return Payment{}, err
+	}
+
+	seller, err := service.userAccount(ctx, sellerID)
+	if err != nil {
+		return Payment{}, err
+	}
+
+	creator := AccountRoyalties
+	if fees.CreatorID != uuid.Nil {
+		if creator, err = service.userAccount(ctx, fees.CreatorID); err != nil {
+			return Payment{}, err
+		}
+	}
+
+	payment := Payment{Escrow: escrow}
+	description := fmt.Sprintf("lot %s", lotID)
+	for _, transfer := range []struct {
+		transactionType Type
+		to              Account
+		amount          big.Int
+	}{
+		{TypeTransfer, seller, proceeds},
+		{TypeCommission, AccountCommission, fees.Commission},
+		{TypeRoyalty, creator, fees.Royalty},
+	} {
+		if transfer.amount.Sign() > 0 {
+			payment.Transactions = append(payment.Transactions,
+				NewTransfer(transfer.transactionType, description, EscrowAccount(lotID, buyerID), transfer.to, transfer.amount))
+		}
+	}
+
+	return payment, nil
+}
@@ -0,0 +1,274 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package finances
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/cards"
+	"ultimatedivision/pkg/pagination"
+)
+
+// ErrUnbalancedTransaction indicates that debits and credits of the transaction are not equal.
+var ErrUnbalancedTransaction = errs.Class("unbalanced transaction")
+
+// ErrUpkeepCharged indicates that the club is already charged for the upkeep of the period.
+var ErrUpkeepCharged = errs.Class("upkeep is already charged for the period")
+
+// DB is exposing access to finances db.
+//
+// architecture: DB
+type DB interface {
+	// Create creates transaction with all its entries in the database.
+	Create(ctx context.Context, transaction Transaction) error
+	// ListEntriesByAccount returns all entries of the account from the database.
+	ListEntriesByAccount(ctx context.Context, account Account) ([]Entry, error)
+	// ListByAccount returns page of transactions which have entries of the account from the database.
+	ListByAccount(ctx context.Context, account Account, cursor pagination.Cursor) (TransactionsPage, error)
+	// CreateScouting records the payment for the scouting together with the scouting report and its history in one transaction.
+	// Returns ErrInsufficientFunds if the balance of the paying account becomes negative.
+	CreateScouting(ctx context.Context, scouting Scouting) error
+	// CreateUpkeep records the upkeep of the club for the period, returns ErrUpkeepCharged if the period is already charged.
+	CreateUpkeep(ctx context.Context, upkeep Upkeep) error
+	// Hold raises the funds held on the lot up to the amount of the escrow, returns ErrInsufficientFunds
+	// if the balance of the account of the escrow is not enough.
+	Hold(ctx context.Context, escrow Escrow) error
+	// Release returns all funds held on the lot to the account of the escrow.
+	Release(ctx context.Context, escrow Escrow) error
+	// CreatePayment records the transactions of the payment and returns the rest of the held funds in one transaction,
+	// returns ErrInsufficientFunds if the held funds are not enough for the payment.
+	CreatePayment(ctx context.Context, payment Payment) error
+	// CreateStorePurchase records the payment for the card bought in the store together with the order of the card
+	// in one transaction, returns cards.ErrNoCard if the card is already ordered.
+	CreateStorePurchase(ctx context.Context, purchase StorePurchase) error
+}
+
+// Config defines amounts of soft currency which clubs earn and spend.
+type Config struct {
+	MatchIncome struct {
+		Win  int64 `json:"win"`
+		Draw int64 `json:"draw"`
+		Loss int64 `json:"loss"`
+	} `json:"matchIncome"`
+	// SeasonPrizes are prizes by the place of the club in the division, the first one is for the winner.
+	SeasonPrizes []int64 `json:"seasonPrizes"`
+	Upkeep       struct {
+		Enabled         bool          `json:"enabled"`
+		RenewalInterval time.Duration `json:"renewalInterval"`
+		Wood            int64         `json:"wood"`
+		Silver          int64         `json:"silver"`
+		Gold            int64         `json:"gold"`
+		Diamond         int64         `json:"diamond"`
+	} `json:"upkeep"`
+	pagination.Cursor `json:"cursor"`
+}
+
+// Account defines ledger account. Each club has its own cash account, each bidder has escrow account per lot,
+// the rest of the accounts are the game accounts which are the source of the club income and the destination
+// of the club expenses.
+type Account string
+
+const (
+	// AccountMatchIncome is the source of the match income.
+	AccountMatchIncome Account = "match_income"
+	// AccountSeasonPrizes is the source of the season prizes.
+	AccountSeasonPrizes Account = "season_prizes"
+	// AccountTransfers is the counterparty of the card purchases and sales when the other side has no club.
+	AccountTransfers Account = "transfers"
+	// AccountTraining is the destination of the training costs.
+	AccountTraining Account = "training"
+	// AccountUpkeep is the destination of the cards upkeep.
+	AccountUpkeep Account = "upkeep"
+	// AccountCommission is the revenue account of the marketplace which receives commissions of the sales.
+	AccountCommission Account = "marketplace_commission"
+	// AccountRoyalties receives royalties of the sales when the creator of the item is unknown.
+	AccountRoyalties Account = "royalties"
+	// AccountStore is the revenue account of the store which receives payments for the bought cards.
+	AccountStore Account = "store"
+	// AccountOnChain is the source of the funds which users pay on-chain.
+	AccountOnChain Account = "on_chain"
+)
+
+// ClubAccount returns cash account of the club.
+func ClubAccount(clubID uuid.UUID) Account {
+	return Account("club:" + clubID.String())
+}
+
+// Direction defines side of the entry.
+type Direction string
+
+const (
+	// DirectionDebit indicates that the entry increases the balance of the account.
+	DirectionDebit Direction = "debit"
+	// DirectionCredit indicates that the entry decreases the balance of the account.
+	DirectionCredit Direction = "credit"
+)
+
+// Type defines the list of possible reasons of the transaction.
+type Type string
+
+const (
+	// TypeMatchIncome indicates that the club earned money for the played match.
+	TypeMatchIncome Type = "match_income"
+	// TypeSeasonPrize indicates that the club earned the prize for its place at the end of the season.
+	TypeSeasonPrize Type = "season_prize"
+	// TypeTransfer indicates that the card was sold by one club to another.
+	TypeTransfer Type = "transfer"
+	// TypeTraining indicates that the club paid for the development of its cards.
+	TypeTraining Type = "training"
+	// TypeUpkeep indicates that the club paid the weekly upkeep of its cards.
+	TypeUpkeep Type = "upkeep"
+	// TypeCommission indicates that the marketplace charged the commission of the sale.
+	TypeCommission Type = "commission"
+	// TypeRoyalty indicates that the creator of the item earned the royalty of its sale.
+	TypeRoyalty Type = "royalty"
+	// TypeEscrowHold indicates that the funds of the club were held for the bid on the lot.
+	TypeEscrowHold Type = "escrow_hold"
+	// TypeEscrowRelease indicates that the held funds were returned to the club when its bid was outbid or not needed anymore.
+	TypeEscrowRelease Type = "escrow_release"
+	// TypeStorePurchase indicates that the card was bought in the store.
+	TypeStorePurchase Type = "store_purchase"
+)
+
+// Transaction describes balanced set of entries, the sum of debits always equals the sum of credits.
+type Transaction struct {
+	ID          uuid.UUID `json:"id"`
+	Type        Type      `json:"type"`
+	Description string    `json:"description"`
+	Entries     []Entry   `json:"entries"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Entry describes single movement of the money on the account.
+type Entry struct {
+	TransactionID uuid.UUID `json:"transactionId"`
+	Account       Account   `json:"account"`
+	Direction     Direction `json:"direction"`
+	Amount        big.Int   `json:"amount"`
+}
+
+// NewTransfer returns transaction which moves amount from one account to another.
+func NewTransfer(transactionType Type, description string, from, to Account, amount big.Int) Transaction {
+	transaction := Transaction{
+		ID:          uuid.New(),
+		Type:        transactionType,
+		Description: description,
+		CreatedAt:   time.Now().UTC(),
+	}
+	transaction.Entries = []Entry{
+		{TransactionID: transaction.ID, Account: to, Direction: DirectionDebit, Amount: amount},
+		{TransactionID: transaction.ID, Account: from, Direction: DirectionCredit, Amount: amount},
+	}
+
+	return transaction
+}
+
+// Validate checks that transaction has positive entries and its debits equal its credits.
+func (transaction Transaction) Validate() error {
+	if len(transaction.Entries) < 2 {
+		return ErrUnbalancedTransaction.New("transaction must have at least two entries")
+	}
+
+	var debits, credits big.Int
+	for _, entry := range transaction.Entries {
+		if entry.Amount.Sign() <= 0 {
+			return ErrUnbalancedTransaction.New("amount of the entry must be positive")
+		}
+
+		switch entry.Direction {
+		case DirectionDebit:
+			debits.Add(&debits, &entry.Amount)
+		case DirectionCredit:
+			credits.Add(&credits, &entry.Amount)
+		default:
+			return ErrUnbalancedTransaction.New("invalid direction %q", entry.Direction)
+		}
+	}
+
+	if debits.Cmp(&credits) != 0 {
+		return ErrUnbalancedTransaction.New("debits %s are not equal to credits %s", debits.String(), credits.String())
+	}
+
+	return nil
+}
+
+// Balance returns balance of the account from its entries.
+func Balance(entries []Entry) big.Int {
+	var balance big.Int
+	for _, entry := range entries {
+		if entry.Direction == DirectionDebit {
+			balance.Add(&balance, &entry.Amount)
+			continue
+		}
+		balance.Sub(&balance, &entry.Amount)
+	}
+
+	return balance
+}
+
+// Scouting describes the scouting of the card paid by the club, the payment is recorded together with the report.
+type Scouting struct {
+	Report  cards.ScoutingReport
+	History cards.History
+	// Account pays for the scouting, Transactions are empty if the scouting is free.
+	Account      Account
+	Transactions []Transaction
+}
+
+// Upkeep describes the upkeep of the club for the period which starts at Period.
+type Upkeep struct {
+	ClubID      uuid.UUID
+	Period      time.Time
+	Transaction Transaction
+}
+
+// StorePurchase describes the card bought in the store, the payment is recorded together with the order of the card.
+type StorePurchase struct {
+	History cards.History
+	// Transactions are empty if the card is free.
+	Transactions []Transaction
+}
+
+// NewStorePurchaseTransaction returns transaction of the payment for the card bought in the store. The price is paid
+// on-chain, so it comes through the account of the buyer's club to the store without changing the balance of the club,
+// the club account is skipped if the buyer has no club.
+func NewStorePurchaseTransaction(description string, account Account, price big.Int) Transaction {
+	if account == AccountTransfers {
+		return NewTransfer(TypeStorePurchase, description, AccountOnChain, AccountStore, price)
+	}
+
+	transaction := NewTransfer(TypeStorePurchase, description, AccountOnChain, account, price)
+	transaction.Entries = append(transaction.Entries,
+		Entry{TransactionID: transaction.ID, Account: AccountStore, Direction: DirectionDebit, Amount: price},
+		Entry{TransactionID: transaction.ID, Account: account, Direction: DirectionCredit, Amount: price},
+	)
+
+	return transaction
+}
+
+// Fees describes parts of the price of the sale which are paid to the marketplace and to the creator of the item
+// instead of the seller. Royalty is paid to the game account if the creator is unknown.
+type Fees struct {
+	Commission big.Int   `json:"commission"`
+	Royalty    big.Int   `json:"royalty"`
+	CreatorID  uuid.UUID `json:"creatorId"`
+}
+
+// TransactionsPage holds transactions page entity which is used to show listed page of transactions.
+type TransactionsPage struct {
+	Transactions []Transaction   `json:"transactions"`
+	Page         pagination.Page `json:"page"`
+}
+
+// Statement describes balance of the club and its transactions.
+type Statement struct {
+	ClubID       uuid.UUID        `json:"clubId"`
+	Balance      big.Int          `json:"balance"`
+	Transactions TransactionsPage `json:"transactions"`
+}
@@ -0,0 +1,366 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package finances_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ultimatedivision"
+	"ultimatedivision/cards"
+	"ultimatedivision/clubs"
+	"ultimatedivision/database/dbtesting"
+	"ultimatedivision/divisions"
+	"ultimatedivision/finances"
+	"ultimatedivision/pkg/pagination"
+	"ultimatedivision/users"
+)
+
+func TestFinances(t *testing.T) {
+	clubID := uuid.New()
+	clubAccount := finances.ClubAccount(clubID)
+
+	matchIncome := finances.NewTransfer(finances.TypeMatchIncome, "match", finances.AccountMatchIncome, clubAccount, *big.NewInt(100))
+	matchIncome.CreatedAt = time.Now().UTC().Add(-time.Minute)
+	scouting := finances.NewTransfer(finances.TypeTraining, "scouting", clubAccount, finances.AccountTraining, *big.NewInt(30))
+
+	cursor := pagination.Cursor{
+		Limit: 10,
+		Page:  1,
+	}
+
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db ultimatedivision.DB) {
+		repositoryFinances := db.Finances()
+
+		t.Run("list entries sql no rows", func(t *testing.T) {
+			entries, err := repositoryFinances.ListEntriesByAccount(ctx, clubAccount)
+			require.NoError(t, err)
+			assert.Equal(t, 0, len(entries))
+		})
+
+		t.Run("create", func(t *testing.T) {
+			err := repositoryFinances.Create(ctx, matchIncome)
+			require.NoError(t, err)
+
+			err = repositoryFinances.Create(ctx, scouting)
+			require.NoError(t, err)
+		})
+
+		t.Run("list entries", func(t *testing.T) {
+			entries, err := repositoryFinances.ListEntriesByAccount(ctx, clubAccount)
+			require.NoError(t, err)
+			assert.Equal(t, 2, len(entries))
+
+			balance := finances.Balance(entries)
+			assert.Equal(t, "70", balance.String())
+		})
+
+		t.Run("list by account", func(t *testing.T) {
+			page, err := repositoryFinances.ListByAccount(ctx, clubAccount, cursor)
+			require.NoError(t, err)
+			assert.Equal(t, 2, page.Page.TotalCount)
+			require.Equal(t, 2, len(page.Transactions))
+
+			compareTransactions(t, page.Transactions[0], scouting)
+			compareTransactions(t, page.Transactions[1], matchIncome)
+
+			page, err = repositoryFinances.ListByAccount(ctx, finances.AccountTraining, cursor)
+			require.NoError(t, err)
+			assert.Equal(t, 1, page.Page.TotalCount)
+			require.Equal(t, 1, len(page.Transactions))
+			compareTransactions(t, page.Transactions[0], scouting)
+		})
+	})
+}
+
+func TestEscrow(t *testing.T) {
+	testUser := users.User{
+		ID:           uuid.New(),
+		Email:        "escrow@example.com",
+		PasswordHash: []byte{1},
+		NickName:     "escrow",
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	division := divisions.Division{
+		ID:             uuid.New(),
+		Name:           10,
+		PassingPercent: 10,
+		CreatedAt:      time.Now().UTC(),
+	}
+
+	testClub := clubs.Club{
+		ID:         uuid.New(),
+		OwnerID:    testUser.ID,
+		Name:       testUser.NickName,
+		Status:     clubs.StatusActive,
+		DivisionID: division.ID,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	testSquad := clubs.Squad{
+		ID:        uuid.New(),
+		Name:      "escrow squad",
+		ClubID:    testClub.ID,
+		Tactic:    clubs.Balanced,
+		Formation: clubs.FourFourTwo,
+		IsActive:  true,
+	}
+
+	lotID := uuid.New()
+	sellerID := uuid.New()
+
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db ultimatedivision.DB) {
+		cardsService := cards.NewService(db.Cards(), cards.Config{})
+		clubsService := clubs.NewService(db.Clubs(), users.NewService(db.Users()), cardsService, db.Divisions())
+		financesService := finances.NewService(db.Finances(), clubsService, cardsService, finances.Config{})
+
+		require.NoError(t, db.Users().Create(ctx, testUser))
+		require.NoError(t, db.Divisions().Create(ctx, division))
+		_, err := db.Clubs().Create(ctx, testClub)
+		require.NoError(t, err)
+		_, err = db.Clubs().CreateSquad(ctx, testSquad)
+		require.NoError(t, err)
+
+		income := finances.NewTransfer(finances.TypeMatchIncome, "match", finances.AccountMatchIncome, finances.ClubAccount(testClub.ID), *big.NewInt(1000))
+		require.NoError(t, financesService.Record(ctx, income))
+
+		assertFunds := func(t *testing.T, balance, held string) {
+			clubBalance, err := financesService.GetBalance(ctx, testClub.ID)
+			require.NoError(t, err)
+			assert.Equal(t, balance, clubBalance.String())
+
+			heldAmount, err := financesService.GetHeld(ctx, lotID, testUser.ID)
+			require.NoError(t, err)
+			assert.Equal(t, held, heldAmount.String())
+		}
+
+		t.Run("hold", func(t *testing.T) {
+			require.NoError(t, financesService.Hold(ctx, lotID, testUser.ID, *big.NewInt(600)))
+			assertFunds(t, "400", "600")
+		})
+
+		t.Run("hold more than balance", func(t *testing.T) {
+			err := financesService.Hold(ctx, lotID, testUser.ID, *big.NewInt(1500))
+			require.Error(t, err)
+			assert.True(t, finances.ErrInsufficientFunds.Has(err))
+			assertFunds(t, "400", "600")
+		})
+
+		t.Run("hold without club", func(t *testing.T) {
+			err := financesService.Hold(ctx, lotID, uuid.New(), *big.NewInt(1))
+			require.Error(t, err)
+			assert.True(t, finances.ErrInsufficientFunds.Has(err))
+		})
+
+		t.Run("raise hold", func(t *testing.T) {
+			require.NoError(t, financesService.Hold(ctx, lotID, testUser.ID, *big.NewInt(800)))
+			assertFunds(t, "200", "800")
+
+			require.NoError(t, financesService.Hold(ctx, lotID, testUser.ID, *big.NewInt(500)))
+			assertFunds(t, "200", "800")
+		})
+
+		t.Run("settle more than held", func(t *testing.T) {
+			err := financesService.Settle(ctx, lotID, sellerID, testUser.ID, *big.NewInt(900), finances.Fees{})
+			require.Error(t, err)
+			assert.True(t, finances.ErrInsufficientFunds.Has(err))
+			assertFunds(t, "200", "800")
+		})
+
+		t.Run("settle", func(t *testing.T) {
+			require.NoError(t, financesService.Settle(ctx, lotID, sellerID, testUser.ID, *big.NewInt(700), finances.Fees{}))
+			assertFunds(t, "300", "0")
+
+			entries, err := db.Finances().ListEntriesByAccount(ctx, finances.AccountTransfers)
+			require.NoError(t, err)
+			balance := finances.Balance(entries)
+			assert.Equal(t, "700", balance.String())
+		})
+
+		t.Run("release", func(t *testing.T) {
+			require.NoError(t, financesService.Hold(ctx, lotID, testUser.ID, *big.NewInt(300)))
+			assertFunds(t, "0", "300")
+
+			require.NoError(t, financesService.Release(ctx, lotID, testUser.ID))
+			assertFunds(t, "300", "0")
+
+			require.NoError(t, financesService.Release(ctx, lotID, testUser.ID))
+			assertFunds(t, "300", "0")
+		})
+
+		t.Run("ledger", func(t *testing.T) {
+			page, err := db.Finances().ListByAccount(ctx, finances.EscrowAccount(lotID, testUser.ID), pagination.Cursor{Limit: 10, Page: 1})
+			require.NoError(t, err)
+			assert.Equal(t, 6, page.Page.TotalCount)
+		})
+
+		t.Run("settle with fees", func(t *testing.T) {
+			require.NoError(t, financesService.Hold(ctx, lotID, testUser.ID, *big.NewInt(300)))
+			assertFunds(t, "0", "300")
+
+			fees := finances.Fees{Commission: *big.NewInt(20), Royalty: *big.NewInt(10)}
+			require.NoError(t, financesService.Settle(ctx, lotID, sellerID, testUser.ID, *big.NewInt(200), fees))
+			assertFunds(t, "100", "0")
+
+			for account, expected := range map[finances.Account]string{
+				finances.AccountTransfers:  "870",
+				finances.AccountCommission: "20",
+				finances.AccountRoyalties:  "10",
+			} {
+				entries, err := db.Finances().ListEntriesByAccount(ctx, account)
+				require.NoError(t, err)
+				balance := finances.Balance(entries)
+				assert.Equal(t, expected, balance.String(), account)
+			}
+		})
+
+		t.Run("settle fees more than price", func(t *testing.T) {
+			fees := finances.Fees{Commission: *big.NewInt(60), Royalty: *big.NewInt(50)}
+			err := financesService.Settle(ctx, lotID, sellerID, testUser.ID, *big.NewInt(100), fees)
+			require.Error(t, err)
+			assertFunds(t, "100", "0")
+		})
+
+		t.Run("settle without held funds", func(t *testing.T) {
+			err := financesService.Settle(ctx, lotID, sellerID, testUser.ID, *big.NewInt(50), finances.Fees{})
+			require.Error(t, err)
+			assert.True(t, finances.ErrInsufficientFunds.Has(err))
+			assertFunds(t, "100", "0")
+		})
+
+		t.Run("charge scouting", func(t *testing.T) {
+			card := cards.Card{ID: uuid.New(), PlayerName: "scouted", UserID: sellerID, Potential: 80}
+			require.NoError(t, db.Cards().Create(ctx, card))
+
+			report := cards.ScoutingReport{CardID: card.ID, MinPotential: 60, MaxPotential: 90, ScoutsCount: 1, UpdatedAt: time.Now().UTC()}
+			history := cards.NewHistory(card, cards.HistoryKindScouted, "", "60-90", cards.Event{Cause: cards.CauseScouting, Price: *big.NewInt(60)})
+			require.NoError(t, financesService.ChargeScouting(ctx, testUser.ID, report, history))
+			assertFunds(t, "40", "0")
+
+			report.ScoutsCount++
+			history = cards.NewHistory(card, cards.HistoryKindScouted, "60-90", "70-85", cards.Event{Cause: cards.CauseScouting, Price: *big.NewInt(60)})
+			err := financesService.ChargeScouting(ctx, testUser.ID, report, history)
+			require.Error(t, err)
+			assert.True(t, finances.ErrInsufficientFunds.Has(err))
+			assertFunds(t, "40", "0")
+
+			reportFromDB, err := db.Cards().GetScoutingReport(ctx, card.ID)
+			require.NoError(t, err)
+			assert.Equal(t, 1, reportFromDB.ScoutsCount)
+		})
+
+		t.Run("charge upkeep once for the period", func(t *testing.T) {
+			period := time.Now().UTC().Truncate(time.Hour)
+			upkeep := finances.Upkeep{
+				ClubID:      testClub.ID,
+				Period:      period,
+				Transaction: finances.NewTransfer(finances.TypeUpkeep, "upkeep", finances.ClubAccount(testClub.ID), finances.AccountUpkeep, *big.NewInt(10)),
+			}
+			require.NoError(t, db.Finances().CreateUpkeep(ctx, upkeep))
+			assertFunds(t, "30", "0")
+
+			upkeep.Transaction = finances.NewTransfer(finances.TypeUpkeep, "upkeep", finances.ClubAccount(testClub.ID), finances.AccountUpkeep, *big.NewInt(10))
+			err := db.Finances().CreateUpkeep(ctx, upkeep)
+			require.Error(t, err)
+			assert.True(t, finances.ErrUpkeepCharged.Has(err))
+			assertFunds(t, "30", "0")
+		})
+
+		t.Run("buy in store", func(t *testing.T) {
+			card := cards.Card{ID: uuid.New(), PlayerName: "store", Type: cards.TypeUnordered}
+			require.NoError(t, db.Cards().Create(ctx, card))
+
+			require.NoError(t, financesService.BuyInStore(ctx, testUser.ID, card, *big.NewInt(500)))
+			assertFunds(t, "30", "0")
+
+			entries, err := db.Finances().ListEntriesByAccount(ctx, finances.AccountStore)
+			require.NoError(t, err)
+			balance := finances.Balance(entries)
+			assert.Equal(t, "500", balance.String())
+
+			page, err := db.Finances().ListByAccount(ctx, finances.ClubAccount(testClub.ID), pagination.Cursor{Limit: 1, Page: 1})
+			require.NoError(t, err)
+			require.Equal(t, 1, len(page.Transactions))
+			assert.Equal(t, finances.TypeStorePurchase, page.Transactions[0].Type)
+
+			cardFromDB, err := db.Cards().Get(ctx, card.ID)
+			require.NoError(t, err)
+			assert.Equal(t, cards.TypeOrdered, cardFromDB.Type)
+
+			err = financesService.BuyInStore(ctx, uuid.New(), card, *big.NewInt(500))
+			require.Error(t, err)
+			assert.True(t, cards.ErrNoCard.Has(err))
+		})
+
+		t.Run("release to the club the funds were held from", func(t *testing.T) {
+			otherClub := clubs.Club{
+				ID:         uuid.New(),
+				OwnerID:    testUser.ID,
+				Name:       "other club",
+				Status:     clubs.StatusInactive,
+				DivisionID: division.ID,
+				CreatedAt:  time.Now().UTC(),
+			}
+			_, err := db.Clubs().Create(ctx, otherClub)
+			require.NoError(t, err)
+			_, err = db.Clubs().CreateSquad(ctx, clubs.Squad{ID: uuid.New(), ClubID: otherClub.ID, Tactic: clubs.Balanced, Formation: clubs.FourFourTwo, IsActive: true})
+			require.NoError(t, err)
+			income := finances.NewTransfer(finances.TypeMatchIncome, "match", finances.AccountMatchIncome, finances.ClubAccount(otherClub.ID), *big.NewInt(100))
+			require.NoError(t, financesService.Record(ctx, income))
+
+			require.NoError(t, financesService.Hold(ctx, lotID, testUser.ID, *big.NewInt(20)))
+			assertFunds(t, "10", "20")
+
+			testClub.Status, otherClub.Status = clubs.StatusInactive, clubs.StatusActive
+			require.NoError(t, db.Clubs().UpdateStatuses(ctx, []clubs.Club{testClub, otherClub}))
+
+			require.NoError(t, financesService.Hold(ctx, lotID, testUser.ID, *big.NewInt(30)))
+			assertFunds(t, "0", "30")
+
+			require.NoError(t, financesService.Release(ctx, lotID, testUser.ID))
+			assertFunds(t, "30", "0")
+
+			otherBalance, err := financesService.GetBalance(ctx, otherClub.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "100", otherBalance.String())
+		})
+	})
+}
+
+func TestValidate(t *testing.T) {
+	transaction := finances.NewTransfer(finances.TypeTransfer, "card", finances.AccountTransfers, finances.ClubAccount(uuid.New()), *big.NewInt(10))
+	require.NoError(t, transaction.Validate())
+
+	transaction.Entries[0].Amount = *big.NewInt(11)
+	err := transaction.Validate()
+	require.Error(t, err)
+	assert.Equal(t, true, finances.ErrUnbalancedTransaction.Has(err))
+
+	zero := finances.NewTransfer(finances.TypeTransfer, "card", finances.AccountTransfers, finances.ClubAccount(uuid.New()), big.Int{})
+	err = zero.Validate()
+	require.Error(t, err)
+	assert.Equal(t, true, finances.ErrUnbalancedTransaction.Has(err))
+
+	single := finances.Transaction{Entries: transaction.Entries[:1]}
+	err = single.Validate()
+	require.Error(t, err)
+	assert.Equal(t, true, finances.ErrUnbalancedTransaction.Has(err))
+}
+
+func compareTransactions(t *testing.T, transactionDB finances.Transaction, transactionTest finances.Transaction) {
+	assert.Equal(t, transactionDB.ID, transactionTest.ID)
+	assert.Equal(t, transactionDB.Type, transactionTest.Type)
+	assert.Equal(t, transactionDB.Description, transactionTest.Description)
+	assert.WithinDuration(t, transactionDB.CreatedAt, transactionTest.CreatedAt, 1*time.Second)
+	balance := finances.Balance(transactionDB.Entries)
+	assert.Equal(t, "0", balance.String())
+	assert.Equal(t, len(transactionDB.Entries), len(transactionTest.Entries))
+}
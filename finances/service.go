@@ -0,0 +1,274 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package finances
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/cards"
+	"ultimatedivision/clubs"
+	"ultimatedivision/pkg/pagination"
+)
+
+// ErrFinances indicates that there was an error in the service.
+var ErrFinances = errs.Class("finances service error")
+
+// Service is handling club finances related logic.
+//
+// architecture: Service
+type Service struct {
+	finances DB
+	clubs    *clubs.Service
+	cards    *cards.Service
+	config   Config
+}
+
+// NewService is a constructor for finances service.
+func NewService(finances DB, clubs *clubs.Service, cards *cards.Service, config Config) *Service {
+	return &Service{
+		finances: finances,
+		clubs:    clubs,
+		cards:    cards,
+		config:   config,
+	}
+}
+
+// Record validates and records the transaction in the ledger.
+func (service *Service) Record(ctx context.Context, transaction Transaction) error {
+	if err := transaction.Validate(); err != nil {
+		return err
+	}
+
+	return ErrFinances.Wrap(service.finances.Create(ctx, transaction))
+}
+
+// GetBalance returns balance of the club.
+func (service *Service) GetBalance(ctx context.Context, clubID uuid.UUID) (big.Int, error) {
+	entries, err := service.finances.ListEntriesByAccount(ctx, ClubAccount(clubID))
+	if err != nil {
+		return big.Int{}, ErrFinances.Wrap(err)
+	}
+
+	return Balance(entries), nil
+}
+
+// GetAccountBalance returns balance of the account.
+func (service *Service) GetAccountBalance(ctx context.Context, account Account) (big.Int, error) {
+	entries, err := service.finances.ListEntriesByAccount(ctx, account)
+	if err != nil {
+		return big.Int{}, ErrFinances.Wrap(err)
+	}
+
+	return Balance(entries), nil
+}
+
+// GetStatement returns balance and page of transactions of the user's club.
+func (service *Service) GetStatement(ctx context.Context, userID, clubID uuid.UUID, cursor pagination.Cursor) (Statement, error) {
+	club, err := service.clubs.Get(ctx, clubID)
+	if err != nil {
+		return Statement{}, ErrFinances.Wrap(err)
+	}
+	if club.OwnerID != userID {
+		return Statement{}, clubs.ErrInvalidOperation.New("club does not belong to the user")
+	}
+
+	if cursor.Limit <= 0 {
+		cursor.Limit = service.config.Cursor.Limit
+	}
+	if cursor.Page <= 0 {
+		cursor.Page = service.config.Cursor.Page
+	}
+
+	balance, err := service.GetBalance(ctx, clubID)
+	if err != nil {
+		return Statement{}, err
+	}
+
+	transactions, err := service.finances.ListByAccount(ctx, ClubAccount(clubID), cursor)
+	if err != nil {
+		return Statement{}, ErrFinances.Wrap(err)
+	}
+
+	return Statement{
+		ClubID:       clubID,
+		Balance:      balance,
+		Transactions: transactions,
+	}, nil
+}
+
+// MatchIncome returns transactions which pay the club which owns the squad for the played match according
+// to its result, transactions are empty if the result brings no income.
+func (service *Service) MatchIncome(ctx context.Context, matchID, squadID uuid.UUID, goals, opponentGoals int) ([]Transaction, error) {
+	var income int64
+	switch {
+	case goals > opponentGoals:
+		income = service.config.MatchIncome.Win
+	case goals < opponentGoals:
+		income = service.config.MatchIncome.Loss
+	default:
+		income = service.config.MatchIncome.Draw
+	}
+	if income <= 0 {
+		return nil, nil
+	}
+
+	squad, err := service.clubs.GetSquad(ctx, squadID)
+	if err != nil {
+		return nil, ErrFinances.Wrap(err)
+	}
+
+	transaction := NewTransfer(TypeMatchIncome, fmt.Sprintf("match %s, %d:%d", matchID, goals, opponentGoals),
+		AccountMatchIncome, ClubAccount(squad.ClubID), *big.NewInt(income))
+	return []Transaction{transaction}, nil
+}
+
+// AddSeasonPrize pays the club the prize for its place in the division at the end of the season, places start from 1.
+func (service *Service) AddSeasonPrize(ctx context.Context, clubID uuid.UUID, seasonID, place int) error {
+	if place < 1 || place > len(service.config.SeasonPrizes) || service.config.SeasonPrizes[place-1] <= 0 {
+		return nil
+	}
+
+	transaction := NewTransfer(TypeSeasonPrize, fmt.Sprintf("season %d, place %d", seasonID, place),
+		AccountSeasonPrizes, ClubAccount(clubID), *big.NewInt(service.config.SeasonPrizes[place-1]))
+	return service.Record(ctx, transaction)
+}
+
+// AddTransfer moves the price of the card from the active club of the buyer to the active club of the seller.
+// If one of the users has no active club, the game transfers account is used instead.
+func (service *Service) AddTransfer(ctx context.Context, cardID, sellerID, buyerID uuid.UUID, price big.Int) error {
+	if price.Sign() <= 0 {
+		return nil
+	}
+
+	seller, err := service.userAccount(ctx, sellerID)
+	if err != nil {
+		return err
+	}
+
+	buyer, err := service.userAccount(ctx, buyerID)
+	if err != nil {
+		return err
+	}
+
+	transaction := NewTransfer(TypeTransfer, fmt.Sprintf("card %s", cardID), buyer, seller, price)
+	return service.Record(ctx, transaction)
+}
+
+// ChargeScouting charges the active club of the user for the scouting of the card with the price from the history
+// of the scouting and saves the scouting report, the report is not saved if the club could not pay for it.
+func (service *Service) ChargeScouting(ctx context.Context, userID uuid.UUID, report cards.ScoutingReport, history cards.History) error {
+	account, err := service.userAccount(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	scouting := Scouting{Report: report, History: history, Account: account}
+	if history.Price.Sign() > 0 {
+		if account == AccountTransfers {
+			return ErrInsufficientFunds.New("user does not have active club to pay from")
+		}
+
+		transaction := NewTransfer(TypeTraining, fmt.Sprintf("scouting of card %s", report.CardID), account, AccountTraining, history.Price)
+		scouting.Transactions = append(scouting.Transactions, transaction)
+	}
+
+	return ErrFinances.Wrap(service.finances.CreateScouting(ctx, scouting))
+}
+
+// ChargeUpkeep charges the club for the upkeep of the active cards of its owner according to their quality
+// for the period which starts at the given time, the club is charged only once for the period.
+func (service *Service) ChargeUpkeep(ctx context.Context, club clubs.Club, period time.Time) error {
+	upkeepByQuality := map[cards.Quality]int64{
+		cards.QualityWood:    service.config.Upkeep.Wood,
+		cards.QualitySilver:  service.config.Upkeep.Silver,
+		cards.QualityGold:    service.config.Upkeep.Gold,
+		cards.QualityDiamond: service.config.Upkeep.Diamond,
+	}
+
+	var (
+		upkeep int64
+		count  int
+	)
+	cursor := pagination.Cursor{Page: 1}
+	for {
+		page, err := service.cards.ListByUserID(ctx, club.OwnerID, cursor)
+		if err != nil {
+			return ErrFinances.Wrap(err)
+		}
+
+		for _, card := range page.Cards {
+			if card.Status == cards.StatusActive {
+				upkeep += upkeepByQuality[card.Quality]
+				count++
+			}
+		}
+
+		if page.Page.NextToken == "" {
+			break
+		}
+		cursor.Token = page.Page.NextToken
+	}
+
+	if upkeep <= 0 {
+		return nil
+	}
+
+	upkeepOfPeriod := Upkeep{
+		ClubID: club.ID,
+		Period: period,
+		Transaction: NewTransfer(TypeUpkeep, fmt.Sprintf("upkeep of %d cards from %s", count, period.Format(time.RFC3339)),
+			ClubAccount(club.ID), AccountUpkeep, *big.NewInt(upkeep)),
+	}
+	if err := service.finances.CreateUpkeep(ctx, upkeepOfPeriod); err != nil {
+		if ErrUpkeepCharged.Has(err) {
+			return nil
+		}
+		return ErrFinances.Wrap(err)
+	}
+
+	return nil
+}
+
+// BuyInStore records the purchase of the card in the store by the user for the price together with the order of the card.
+// Returns cards.ErrNoCard if the card is already ordered by someone else.
+func (service *Service) BuyInStore(ctx context.Context, userID uuid.UUID, card cards.Card, price big.Int) error {
+	event := cards.Event{
+		Cause:  cards.CauseStore,
+		UserID: userID,
+		Price:  price,
+	}
+	purchase := StorePurchase{
+		History: cards.NewHistory(card, cards.HistoryKindType, string(card.Type), string(cards.TypeOrdered), event),
+	}
+
+	if price.Sign() > 0 {
+		account, err := service.userAccount(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		purchase.Transactions = append(purchase.Transactions, NewStorePurchaseTransaction(fmt.Sprintf("card %s", card.ID), account, price))
+	}
+
+	return ErrFinances.Wrap(service.finances.CreateStorePurchase(ctx, purchase))
+}
+
+// userAccount returns account of the active club of the user or the transfers account if the user has no club.
+func (service *Service) userAccount(ctx context.Context, userID uuid.UUID) (Account, error) {
+	squad, err := service.clubs.GetActiveSquadByUserID(ctx, userID)
+	if err != nil {
+		if clubs.ErrNoClub.Has(err) || clubs.ErrNoSquad.Has(err) {
+			return AccountTransfers, nil
+		}
+		return "", ErrFinances.Wrap(err)
+	}
+
+	return ClubAccount(squad.ClubID), nil
+}
@@ -39,6 +39,8 @@ const (
 	ActionDribbling Action = "dribbling"
 	// ActionFeints defines action when player show feints.
 	ActionFeints Action = "feints"
+	// ActionSubstitution defines replacing of the player by the substitute from the bench.
+	ActionSubstitution Action = "substitution"
 )
 
 // Config contains config values related to game.
@@ -46,6 +48,8 @@ type Config struct {
 	LeftSide  LeftSide  `json:"leftSide"`
 	RightSide RightSide `json:"rightSide"`
 	Rounds    int       `json:"rounds"`
+
+	MaxSubstitutions int `json:"maxSubstitutions"`
 }
 
 // LeftSide contains config values of the left side team positions.
@@ -99,6 +103,8 @@ type MatchRepresentation struct {
 	User2ClubInformation   clubs.Club            `json:"user2ClubInformation"`
 	User1SquadInformation  clubs.Squad           `json:"user1SquadInformation"`
 	User2SquadInformation  clubs.Squad           `json:"user2SquadInformation"`
+	User1BadgeURL          string                `json:"user1BadgeUrl"`
+	User2BadgeURL          string                `json:"user2BadgeUrl"`
 	Rounds                 int                   `json:"rounds"`
 	UserSide               int                   `json:"userSide"`
 }
@@ -51,4 +51,12 @@ type CardIDWithPosition struct {
 type CardIDsWithPositionWithBallPosition struct {
 	CardIDsWithPosition []CardIDWithPosition `json:"cardIdsWithPosition"`
 	BallPosition        int                  `json:"ballPosition"`
+	Substitutions       []CardSubstitution   `json:"substitutions"`
+}
+
+// CardSubstitution defines card of the team which was replaced by the substitute.
+type CardSubstitution struct {
+	CardOutID uuid.UUID `json:"cardOutId"`
+	CardInID  uuid.UUID `json:"cardInId"`
+	Team      string    `json:"team"`
 }
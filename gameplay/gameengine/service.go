@@ -711,13 +711,116 @@ func (service *Service) GameInformation(ctx context.Context, player1SquadID, pla
 		User2ClubInformation:   clubPlayer2,
 		User1SquadInformation:  squadPlayer1,
 		User2SquadInformation:  squadPlayer2,
+		User1BadgeURL:          clubPlayer1.BadgeURL,
+		User2BadgeURL:          clubPlayer2.BadgeURL,
 		Rounds:                 service.config.Rounds,
 	}, nil
 }
 
+// Substitute replaces the card in the field by the substitute from the bench of the same team.
+// Team of the cardIDWithPosition is the team of the player who makes the substitution, so only the cards
+// of the player's lineup and bench could be substituted.
+func (service *Service) Substitute(ctx context.Context, matchID uuid.UUID, cardIDWithPosition CardIDWithPosition, substituteID uuid.UUID) (ActionResult, error) {
+	gameInfoJSON, err := service.games.Get(ctx, matchID)
+	if err != nil {
+		return ActionResult{}, ErrGameEngine.Wrap(err)
+	}
+
+	var game Game
+	game.MatchID = matchID
+
+	err = json.Unmarshal([]byte(gameInfoJSON), &game.GameInfo)
+	if err != nil {
+		return ActionResult{}, ErrGameEngine.Wrap(err)
+	}
+
+	index := -1
+	for i, card := range game.GameInfo.CardIDsWithPosition {
+		if card.CardID == substituteID {
+			return ActionResult{}, ErrGameEngine.New("substitute is already in the field")
+		}
+		if card.CardID == cardIDWithPosition.CardID {
+			index = i
+		}
+	}
+	if index < 0 {
+		return ActionResult{}, ErrGameEngine.New("card is not in the field")
+	}
+	team := game.GameInfo.CardIDsWithPosition[index].Team
+	if team != cardIDWithPosition.Team {
+		return ActionResult{}, ErrGameEngine.New("card is not in the lineup of the player")
+	}
+
+	var teamSubstitutions int
+	for _, substitution := range game.GameInfo.Substitutions {
+		if substitution.CardOutID == substituteID {
+			return ActionResult{}, ErrGameEngine.New("substitute has already left the field")
+		}
+		if substitution.Team == team {
+			teamSubstitutions++
+		}
+	}
+	if teamSubstitutions >= service.config.MaxSubstitutions {
+		return ActionResult{}, ErrGameEngine.New("limit of substitutions is reached")
+	}
+
+	match, err := service.matches.Get(ctx, matchID)
+	if err != nil {
+		return ActionResult{}, ErrGameEngine.Wrap(err)
+	}
+
+	squadID := match.Squad1ID
+	if team == Player2 {
+		squadID = match.Squad2ID
+	}
+
+	substitutes, err := service.clubs.ListSubstitutes(ctx, squadID)
+	if err != nil {
+		return ActionResult{}, ErrGameEngine.Wrap(err)
+	}
+
+	var isOnTheBench bool
+	for _, substitute := range substitutes {
+		if substitute.CardID == substituteID {
+			isOnTheBench = true
+			break
+		}
+	}
+	if !isOnTheBench {
+		return ActionResult{}, ErrGameEngine.New("card is not on the bench")
+	}
+
+	// substitute takes the position of the replaced card.
+	game.GameInfo.CardIDsWithPosition[index].CardID = substituteID
+	game.GameInfo.Substitutions = append(game.GameInfo.Substitutions, CardSubstitution{
+		CardOutID: cardIDWithPosition.CardID,
+		CardInID:  substituteID,
+		Team:      team,
+	})
+
+	newGameInfoJSON, err := json.Marshal(game.GameInfo)
+	if err != nil {
+		return ActionResult{}, ErrGameEngine.Wrap(err)
+	}
+
+	err = service.games.Update(ctx, matchID, string(newGameInfoJSON))
+	if err != nil {
+		return ActionResult{}, ErrGameEngine.Wrap(err)
+	}
+
+	return ActionResult{
+		CardIDWithPosition: game.GameInfo.CardIDsWithPosition[index],
+		BallPosition:       game.GameInfo.BallPosition,
+		CardAvailableAction: CardAvailableAction{
+			Action: ActionSubstitution,
+			CardID: substituteID,
+		},
+	}, nil
+}
+
 // GameLogicByAction returns game logic by action.
 func (service *Service) GameLogicByAction(ctx context.Context, matchID uuid.UUID, cardIDWithPosition CardIDWithPosition, action Action,
-	newPositions []int, finalPosition int, hasBall bool) (ActionResult, error) {
+	newPositions []int, finalPosition int, hasBall bool, substituteID uuid.UUID) (ActionResult, error) {
 	youTeam, opponentTeam, err := service.TeamsList(ctx, matchID, cardIDWithPosition.CardID)
 	if err != nil {
 		return ActionResult{}, ErrGameEngine.Wrap(err)
@@ -732,6 +835,8 @@ func (service *Service) GameLogicByAction(ctx context.Context, matchID uuid.UUID
 		return service.Move(ctx, matchID, cardIDWithPosition, newPositions, finalPosition, hasBall, youTeam, opponentTeam)
 	case ActionPass:
 		return service.GivePass(ctx, newPositions, cardIDWithPosition, finalPosition, youTeam, opponentTeamStats)
+	case ActionSubstitution:
+		return service.Substitute(ctx, matchID, cardIDWithPosition, substituteID)
 	}
 
 	return ActionResult{}, nil
@@ -761,58 +866,72 @@ func (service *Service) ifCardHasBall(ctx context.Context, matchID uuid.UUID, ca
 }
 
 func (service *Service) squadPositionToFieldPositionLeftSide(squadPosition clubs.Position) int {
-	switch squadPosition {
-	case clubs.GK:
-		return service.config.LeftSide.Goalkeeper
-	case clubs.LB:
-		return service.config.LeftSide.LeftBack
-	case clubs.RB:
-		return service.config.LeftSide.RightBack
-	case clubs.LM:
-		return service.config.LeftSide.LeftMid
-	case clubs.RM:
-		return service.config.LeftSide.RightMid
-	case clubs.LCD:
-		return service.config.LeftSide.CenterBackLeft
-	case clubs.RCD:
-		return service.config.LeftSide.CenterBackRight
-	case clubs.LCM:
-		return service.config.LeftSide.CenterMidLeft
-	case clubs.RCM:
-		return service.config.LeftSide.CenterMidRight
-	case clubs.LST:
-		return service.config.LeftSide.ForwardLeft
-	case clubs.RST:
-		return service.config.LeftSide.ForwardRight
-	}
-
-	return 0
+	return squadPositionToFieldPosition(service.config.LeftSide.Positions, -fieldHeight, squadPosition)
 }
 
 func (service *Service) squadPositionToFieldPositionRightSide(squadPosition clubs.Position) int {
+	return squadPositionToFieldPosition(service.config.RightSide.Positions, fieldHeight, squadPosition)
+}
+
+// fieldHeight defines the number of cells in one column of the field.
+const fieldHeight = 7
+
+// squadPositionToFieldPosition returns cell of the field for the squad position. Positions which are not configured,
+// e.g. from custom formations, are placed relative to the configured ones, forward is the step towards the opponent's goal.
+func squadPositionToFieldPosition(positions Positions, forward int, squadPosition clubs.Position) int {
+	centerBack := (positions.CenterBackLeft + positions.CenterBackRight) / 2
+	centerMid := (positions.CenterMidLeft + positions.CenterMidRight) / 2
+
 	switch squadPosition {
 	case clubs.GK:
-		return service.config.RightSide.Goalkeeper
+		return positions.Goalkeeper
 	case clubs.LB:
-		return service.config.RightSide.LeftBack
+		return positions.LeftBack
 	case clubs.RB:
-		return service.config.RightSide.RightBack
+		return positions.RightBack
 	case clubs.LM:
-		return service.config.RightSide.LeftMid
+		return positions.LeftMid
 	case clubs.RM:
-		return service.config.RightSide.RightMid
+		return positions.RightMid
 	case clubs.LCD:
-		return service.config.RightSide.CenterBackLeft
+		return positions.CenterBackLeft
 	case clubs.RCD:
-		return service.config.RightSide.CenterBackRight
+		return positions.CenterBackRight
 	case clubs.LCM:
-		return service.config.RightSide.CenterMidLeft
+		return positions.CenterMidLeft
 	case clubs.RCM:
-		return service.config.RightSide.CenterMidRight
+		return positions.CenterMidRight
 	case clubs.LST:
-		return service.config.RightSide.ForwardLeft
+		return positions.ForwardLeft
 	case clubs.RST:
-		return service.config.RightSide.ForwardRight
+		return positions.ForwardRight
+	case clubs.CCD:
+		return centerBack
+	case clubs.LWB:
+		return positions.LeftBack + forward
+	case clubs.RWB:
+		return positions.RightBack + forward
+	case clubs.LCDM:
+		return positions.CenterBackLeft + forward
+	case clubs.CCDM:
+		return centerBack + forward
+	case clubs.RCDM:
+		return positions.CenterBackRight + forward
+	case clubs.CCM:
+		return centerMid
+	case clubs.LCAM:
+		return positions.LeftMid + forward
+	case clubs.CCAM:
+		return centerMid + forward
+	case clubs.RCAM:
+		return positions.RightMid + forward
+	case clubs.LW:
+		return positions.LeftMid + 2*forward
+	case clubs.CST:
+		return centerMid + 2*forward
+	case clubs.RW:
+		return positions.RightMid + 2*forward
 	}
+
 	return 0
 }
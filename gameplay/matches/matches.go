@@ -11,6 +11,7 @@ import (
 
 	"ultimatedivision/cards"
 	"ultimatedivision/clubs"
+	"ultimatedivision/finances"
 	"ultimatedivision/pkg/pagination"
 	"ultimatedivision/udts/currencywaitlist"
 )
@@ -32,6 +33,9 @@ type DB interface {
 	ListSquadMatches(ctx context.Context, seasonID int) ([]Match, error)
 	// UpdateMatch updates the number of points that users received for a played match.
 	UpdateMatch(ctx context.Context, match Match) error
+	// RankMatch updates the number of points that users received for a played match and records the income
+	// of the clubs for the match in one transaction.
+	RankMatch(ctx context.Context, match Match, income []finances.Transaction) error
 	// Delete deletes match from the database.
 	Delete(ctx context.Context, id uuid.UUID) error
 	// AddGoals adds new goal in the match.
@@ -40,6 +44,10 @@ type DB interface {
 	ListMatchGoals(ctx context.Context, matchID uuid.UUID) ([]MatchGoals, error)
 	// GetMatchResult returns goals of each user in the match from db.
 	GetMatchResult(ctx context.Context, matchID uuid.UUID) ([]MatchResult, error)
+	// AddSubstitutions adds substitutions made in the match.
+	AddSubstitutions(ctx context.Context, substitutions []MatchSubstitution) error
+	// ListMatchSubstitutions returns all substitutions made in the match from the database.
+	ListMatchSubstitutions(ctx context.Context, matchID uuid.UUID) ([]MatchSubstitution, error)
 }
 
 // Config defines configuration for matches.
@@ -105,6 +113,19 @@ type Config struct {
 		RB  int `json:"rb"`
 	} `json:"goalProbabilityByPosition"`
 
+	Substitutions struct {
+		Max               int `json:"max"`
+		FatigueMinute     int `json:"fatigueMinute"`
+		FatigueStamina    int `json:"fatigueStamina"`
+		InjuryProbability int `json:"injuryProbability"`
+		LosingMinute      int `json:"losingMinute"`
+	} `json:"substitutions"`
+
+	Tactics struct {
+		// Bonus defines max percent of the effectiveness which each tactical setting adds to the squad or takes from it.
+		Bonus int `json:"bonus"`
+	} `json:"tactics"`
+
 	pagination.Cursor `json:"pagination"`
 
 	NumberOfPointsForWin    int `json:"numberOfPointsForWin"`
@@ -133,12 +154,42 @@ type MatchGoals struct {
 	Minute  int       `json:"minute"`
 }
 
+// MatchSubstitution defines card of the user's squad which was replaced by the substitute in which minute.
+type MatchSubstitution struct {
+	ID        uuid.UUID          `json:"id"`
+	MatchID   uuid.UUID          `json:"matchId"`
+	UserID    uuid.UUID          `json:"userId"`
+	CardOutID uuid.UUID          `json:"cardOutId"`
+	CardInID  uuid.UUID          `json:"cardInId"`
+	Minute    int                `json:"minute"`
+	Reason    SubstitutionReason `json:"reason"`
+}
+
+// SubstitutionReason defines the list of possible reasons of the substitution.
+type SubstitutionReason string
+
+const (
+	// SubstitutionReasonFatigue indicates that the card was replaced because it is tired.
+	SubstitutionReasonFatigue SubstitutionReason = "fatigue"
+	// SubstitutionReasonInjury indicates that the card was replaced because it is injured.
+	SubstitutionReasonInjury SubstitutionReason = "injury"
+	// SubstitutionReasonScore indicates that the card was replaced because the squad is losing.
+	SubstitutionReasonScore SubstitutionReason = "score"
+	// SubstitutionReasonManual indicates that the card was replaced by the user.
+	SubstitutionReasonManual SubstitutionReason = "manual"
+)
+
+// MatchDuration defines duration of the match in minutes.
+const MatchDuration = 90
+
 // MatchResult defines quantity goals of each user in the match
 // and which cards of user's squad scored in which minute.
 type MatchResult struct {
-	UserID        uuid.UUID    `json:"userId"`
-	QuantityGoals int          `json:"quantityGoals"`
-	Goalscorers   []Goalscorer `json:"goals"`
+	UserID        uuid.UUID           `json:"userId"`
+	BadgeURL      string              `json:"badgeUrl"`
+	QuantityGoals int                 `json:"quantityGoals"`
+	Goalscorers   []Goalscorer        `json:"goals"`
+	Substitutions []MatchSubstitution `json:"substitutions"`
 }
 
 // GameResult entity describes values which send to user after game.
@@ -5,6 +5,7 @@ package matches_test
 
 import (
 	"context"
+	"math/big"
 	"testing"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"ultimatedivision/clubs"
 	"ultimatedivision/database/dbtesting"
 	"ultimatedivision/divisions"
+	"ultimatedivision/finances"
 	"ultimatedivision/gameplay/matches"
 	"ultimatedivision/pkg/pagination"
 	"ultimatedivision/seasons"
@@ -53,6 +55,11 @@ func TestMatches(t *testing.T) {
 		UserID: testUser1.ID,
 	}
 
+	testCard2 := cards.Card{
+		ID:     uuid.New(),
+		UserID: testUser1.ID,
+	}
+
 	division1 := divisions.Division{
 		ID:             uuid.New(),
 		Name:           10,
@@ -135,6 +142,16 @@ func TestMatches(t *testing.T) {
 		Minute:  41,
 	}
 
+	testMatchSubstitution := matches.MatchSubstitution{
+		ID:        uuid.New(),
+		MatchID:   testMatch.ID,
+		UserID:    testUser1.ID,
+		CardOutID: testCard.ID,
+		CardInID:  testCard2.ID,
+		Minute:    61,
+		Reason:    matches.SubstitutionReasonFatigue,
+	}
+
 	testResult := []matches.MatchResult{{
 		UserID:        testUser1.ID,
 		QuantityGoals: 2,
@@ -220,6 +237,20 @@ func TestMatches(t *testing.T) {
 			compareMatchResults(t, matchResult, testResult)
 		})
 
+		t.Run("Add substitution in the match", func(t *testing.T) {
+			err := repositoryCards.Create(ctx, testCard2)
+			require.NoError(t, err)
+
+			err = repositoryMatches.AddSubstitutions(ctx, []matches.MatchSubstitution{testMatchSubstitution})
+			require.NoError(t, err)
+		})
+
+		t.Run("List match substitutions", func(t *testing.T) {
+			substitutionsDB, err := repositoryMatches.ListMatchSubstitutions(ctx, testMatch.ID)
+			require.NoError(t, err)
+			compareMatchSubstitutions(t, substitutionsDB, []matches.MatchSubstitution{testMatchSubstitution})
+		})
+
 		t.Run("update sql no rows", func(t *testing.T) {
 			testMatchUpdated.ID = uuid.New()
 			err := repositoryMatches.UpdateMatch(ctx, testMatchUpdated)
@@ -233,6 +264,28 @@ func TestMatches(t *testing.T) {
 			require.NoError(t, err)
 		})
 
+		t.Run("rank sql no rows", func(t *testing.T) {
+			income := finances.NewTransfer(finances.TypeMatchIncome, "match", finances.AccountMatchIncome, finances.ClubAccount(testClub1.ID), *big.NewInt(100))
+			err := repositoryMatches.RankMatch(ctx, matches.Match{ID: uuid.New()}, []finances.Transaction{income})
+			require.Error(t, err)
+			assert.Equal(t, true, matches.ErrNoMatch.Has(err))
+
+			entries, err := db.Finances().ListEntriesByAccount(ctx, finances.AccountMatchIncome)
+			require.NoError(t, err)
+			assert.Equal(t, 0, len(entries))
+		})
+
+		t.Run("rank", func(t *testing.T) {
+			income := finances.NewTransfer(finances.TypeMatchIncome, "match", finances.AccountMatchIncome, finances.ClubAccount(testClub1.ID), *big.NewInt(100))
+			err := repositoryMatches.RankMatch(ctx, testMatchUpdated, []finances.Transaction{income})
+			require.NoError(t, err)
+
+			entries, err := db.Finances().ListEntriesByAccount(ctx, finances.ClubAccount(testClub1.ID))
+			require.NoError(t, err)
+			balance := finances.Balance(entries)
+			assert.Equal(t, "100", balance.String())
+		})
+
 		t.Run("delete sql no rows", func(t *testing.T) {
 			err := repositoryMatches.Delete(ctx, uuid.New())
 			require.Error(t, err)
@@ -292,6 +345,20 @@ func compareMatchGoals(t *testing.T, matchGoalsDB, matchGoalsTest []matches.Matc
 	}
 }
 
+func compareMatchSubstitutions(t *testing.T, substitutionsDB, substitutionsTest []matches.MatchSubstitution) {
+	assert.Equal(t, len(substitutionsDB), len(substitutionsTest))
+
+	for i := 0; i < len(substitutionsDB); i++ {
+		assert.Equal(t, substitutionsDB[i].ID, substitutionsTest[i].ID)
+		assert.Equal(t, substitutionsDB[i].MatchID, substitutionsTest[i].MatchID)
+		assert.Equal(t, substitutionsDB[i].UserID, substitutionsTest[i].UserID)
+		assert.Equal(t, substitutionsDB[i].CardOutID, substitutionsTest[i].CardOutID)
+		assert.Equal(t, substitutionsDB[i].CardInID, substitutionsTest[i].CardInID)
+		assert.Equal(t, substitutionsDB[i].Minute, substitutionsTest[i].Minute)
+		assert.Equal(t, substitutionsDB[i].Reason, substitutionsTest[i].Reason)
+	}
+}
+
 func TestMatchService(t *testing.T) {
 	testUser1 := users.User{
 		ID:           uuid.New(),
@@ -387,7 +454,8 @@ func TestMatchService(t *testing.T) {
 		cardsService := cards.NewService(repositoryCards, cards.Config{})
 		usersService := users.NewService(repositoryUsers)
 		clubsService := clubs.NewService(repositoryClubs, usersService, cardsService, repositoryDivisions)
-		matchesService := matches.NewService(repositoryMatches, matches.Config{}, clubsService, cardsService)
+		financesService := finances.NewService(db.Finances(), clubsService, cardsService, finances.Config{})
+		matchesService := matches.NewService(repositoryMatches, matches.Config{}, clubsService, cardsService, financesService)
 
 		var matchID uuid.UUID
 
@@ -13,6 +13,7 @@ import (
 
 	"ultimatedivision/cards"
 	"ultimatedivision/clubs"
+	"ultimatedivision/finances"
 	"ultimatedivision/pkg/pagination"
 	rand2 "ultimatedivision/pkg/rand"
 )
@@ -24,19 +25,21 @@ var ErrMatches = errs.Class("matches service error")
 //
 // architecture: Service
 type Service struct {
-	matches DB
-	config  Config
-	clubs   *clubs.Service
-	cards   *cards.Service
+	matches  DB
+	config   Config
+	clubs    *clubs.Service
+	cards    *cards.Service
+	finances *finances.Service
 }
 
 // NewService is a constructor for matches service.
-func NewService(matches DB, config Config, clubs *clubs.Service, cards *cards.Service) *Service {
+func NewService(matches DB, config Config, clubs *clubs.Service, cards *cards.Service, finances *finances.Service) *Service {
 	return &Service{
-		matches: matches,
-		config:  config,
-		clubs:   clubs,
-		cards:   cards,
+		matches:  matches,
+		config:   config,
+		clubs:    clubs,
+		cards:    cards,
+		finances: finances,
 	}
 }
 
@@ -83,23 +86,49 @@ func (service *Service) Play(ctx context.Context, match Match, squadCards1 []clu
 		clubs.RB:   service.config.GoalProbabilityByPosition.RB,
 	}
 
+	team1, err := service.newTeam(ctx, match.User1ID, match.Squad1ID, squadCards1)
+	if err != nil {
+		return ErrMatches.Wrap(err)
+	}
+
+	team2, err := service.newTeam(ctx, match.User2ID, match.Squad2ID, squadCards2)
+	if err != nil {
+		return ErrMatches.Wrap(err)
+	}
+
 	rand.Seed(time.Now().UTC().UnixNano())
 
 	goals := make([]MatchGoals, 0, 10)
+	var substitutions []MatchSubstitution
 
 	for i := 0; i < len(periods); i += 2 {
+		for _, teams := range [][2]*team{{team1, team2}, {team2, team1}} {
+			substitution, ok, err := service.substitute(ctx, match.ID, teams[0], teams[1].goals, periods[i+periodBegin])
+			if err != nil {
+				return ErrMatches.Wrap(err)
+			}
+			if ok {
+				substitutions = append(substitutions, substitution)
+			}
+		}
+
 		randNumber := rand.Intn(100) + 1
 		if randNumber > goalProbability {
 			continue
 		}
 
 		minute := rand2.Minute(periods[i+periodBegin], periods[i+periodEnd])
-		userID, cardID, err := service.chooseSquad(ctx, goalProbabilityByPosition,
-			squadPowerAccuracy, match.User1ID, match.User2ID, squadCards1, squadCards2)
+		userID, cardID, err := service.chooseSquad(ctx, goalProbabilityByPosition, squadPowerAccuracy, team1, team2)
 		if err != nil {
 			return ErrMatches.Wrap(err)
 		}
 
+		if userID == match.User1ID {
+			team1.goals++
+		} else {
+			team2.goals++
+		}
+
 		goals = append(goals, MatchGoals{
 			ID:      uuid.New(),
 			MatchID: match.ID,
@@ -109,16 +138,43 @@ func (service *Service) Play(ctx context.Context, match Match, squadCards1 []clu
 		})
 	}
 
-	err := service.matches.AddGoals(ctx, goals)
+	err = service.matches.AddGoals(ctx, goals)
+	if err != nil {
+		return ErrMatches.Wrap(err)
+	}
+
+	err = service.matches.AddSubstitutions(ctx, substitutions)
 	if err != nil {
 		return ErrMatches.Wrap(err)
 	}
 
+	for _, squadCards := range [][]clubs.SquadCard{squadCards1, squadCards2} {
+		if err = service.clubs.RecordPartnerships(ctx, squadCards); err != nil {
+			return ErrMatches.Wrap(err)
+		}
+	}
+
 	err = service.RankMatch(ctx, match, goals)
 
 	return ErrMatches.Wrap(err)
 }
 
+// RecordPartnerships records that cards of the current lineups of both squads have played the match together.
+func (service *Service) RecordPartnerships(ctx context.Context, match Match) error {
+	for _, squadID := range []uuid.UUID{match.Squad1ID, match.Squad2ID} {
+		squadCards, err := service.clubs.ListLineup(ctx, squadID)
+		if err != nil {
+			return ErrMatches.Wrap(err)
+		}
+
+		if err = service.clubs.RecordPartnerships(ctx, squadCards); err != nil {
+			return ErrMatches.Wrap(err)
+		}
+	}
+
+	return nil
+}
+
 // AddGoals added goals to match result.
 func (service *Service) AddGoals(ctx context.Context, match Match, matchGoals []MatchGoals) error {
 	err := service.matches.AddGoals(ctx, matchGoals)
@@ -134,8 +190,9 @@ func (service *Service) AddGoals(ctx context.Context, match Match, matchGoals []
 	return nil
 }
 
-// choseGoalscorer returns id of cards which scored goal.
-func chooseGoalscorer(squadCards []clubs.SquadCard, goalByPosition map[clubs.Position]int) uuid.UUID {
+// choseGoalscorer returns id of cards which scored goal, cards which get forward have double chance to score
+// and cards which stay back score only if there is nobody else in the chosen line.
+func chooseGoalscorer(squadCards []clubs.SquadCard, goalByPosition map[clubs.Position]int, instructions map[uuid.UUID]clubs.Instruction) uuid.UUID {
 	rand.Seed(time.Now().UTC().UnixNano())
 	var cardsByPosition []uuid.UUID
 	randNumber := rand.Intn(100) + 1
@@ -199,24 +256,51 @@ func chooseGoalscorer(squadCards []clubs.SquadCard, goalByPosition map[clubs.Pos
 		return uuid.Nil
 	}
 
+	var candidates []uuid.UUID
+	for _, cardID := range cardsByPosition {
+		switch instructions[cardID] {
+		case clubs.InstructionStayBack:
+			continue
+		case clubs.InstructionGetForward:
+			candidates = append(candidates, cardID)
+		}
+		candidates = append(candidates, cardID)
+	}
+	if len(candidates) > 0 {
+		cardsByPosition = candidates
+	}
+
 	randIndex := rand.Intn(len(cardsByPosition))
 	goalscorer := cardsByPosition[randIndex]
 
 	return goalscorer
 }
 
-// chooseSquad returns the squad which is stronger in the period.
-func (service *Service) chooseSquad(ctx context.Context, goalByPosition map[clubs.Position]int, squadPowerAccuracy int, user1 uuid.UUID, user2 uuid.UUID, squadCards1, squadCards2 []clubs.SquadCard) (uuid.UUID, uuid.UUID, error) {
-	squad1Effectiveness, err := service.clubs.CalculateEffectivenessOfSquad(ctx, squadCards1)
+// chooseSquad returns the squad which is stronger in the period, effectiveness of the squads depends on their tactics.
+func (service *Service) chooseSquad(ctx context.Context, goalByPosition map[clubs.Position]int, squadPowerAccuracy int, team1, team2 *team) (uuid.UUID, uuid.UUID, error) {
+	squad1Effectiveness, err := service.clubs.CalculateEffectivenessOfSquad(ctx, team1.squadCards)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ErrMatches.Wrap(err)
+	}
+
+	squad2Effectiveness, err := service.clubs.CalculateEffectivenessOfSquad(ctx, team2.squadCards)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ErrMatches.Wrap(err)
+	}
+
+	modifier1, err := service.tacticalModifier(ctx, team1, team2)
 	if err != nil {
 		return uuid.Nil, uuid.Nil, ErrMatches.Wrap(err)
 	}
 
-	squad2Effectiveness, err := service.clubs.CalculateEffectivenessOfSquad(ctx, squadCards2)
+	modifier2, err := service.tacticalModifier(ctx, team2, team1)
 	if err != nil {
 		return uuid.Nil, uuid.Nil, ErrMatches.Wrap(err)
 	}
 
+	squad1Effectiveness *= modifier1
+	squad2Effectiveness *= modifier2
+
 	randAccuracy1 := float64(rand.Intn(2*squadPowerAccuracy+1)-squadPowerAccuracy) / 100
 	randAccuracy2 := float64(rand.Intn(2*squadPowerAccuracy+1)-squadPowerAccuracy) / 100
 
@@ -224,20 +308,20 @@ func (service *Service) chooseSquad(ctx context.Context, goalByPosition map[club
 	squad2Effectiveness += squad1Effectiveness * randAccuracy2
 
 	if squad1Effectiveness > squad2Effectiveness {
-		return user1, chooseGoalscorer(squadCards1, goalByPosition), nil
+		return team1.userID, chooseGoalscorer(team1.squadCards, goalByPosition, team1.playerInstructions), nil
 	}
 
-	return user2, chooseGoalscorer(squadCards2, goalByPosition), nil
+	return team2.userID, chooseGoalscorer(team2.squadCards, goalByPosition, team2.playerInstructions), nil
 }
 
 // Create creates new match.
 func (service *Service) Create(ctx context.Context, squad1ID uuid.UUID, squad2ID uuid.UUID, user1ID, user2ID uuid.UUID, seasonID int) (uuid.UUID, error) {
-	squadCards1, err := service.clubs.ListSquadCardIDs(ctx, squad1ID)
+	squadCards1, err := service.clubs.ListLineup(ctx, squad1ID)
 	if err != nil {
 		return uuid.Nil, ErrMatches.Wrap(err)
 	}
 
-	squadCards2, err := service.clubs.ListSquadCardIDs(ctx, squad2ID)
+	squadCards2, err := service.clubs.ListLineup(ctx, squad2ID)
 	if err != nil {
 		return uuid.Nil, ErrMatches.Wrap(err)
 	}
@@ -313,6 +397,16 @@ func (service *Service) ListMatchGoals(ctx context.Context, matchID uuid.UUID) (
 
 // GetGameResult returns goals of each user in the match.
 func (service *Service) GetGameResult(ctx context.Context, matchID uuid.UUID) (GameResult, error) {
+	match, err := service.matches.Get(ctx, matchID)
+	if err != nil {
+		return GameResult{}, ErrMatches.Wrap(err)
+	}
+
+	badgeURLs, err := service.badgeURLs(ctx, match)
+	if err != nil {
+		return GameResult{}, ErrMatches.Wrap(err)
+	}
+
 	matchResults, err := service.matches.GetMatchResult(ctx, matchID)
 	if err != nil {
 		return GameResult{}, ErrMatches.Wrap(err)
@@ -323,6 +417,11 @@ func (service *Service) GetGameResult(ctx context.Context, matchID uuid.UUID) (G
 		return GameResult{}, ErrMatches.Wrap(err)
 	}
 
+	substitutions, err := service.ListMatchSubstitutions(ctx, matchID)
+	if err != nil {
+		return GameResult{}, ErrMatches.Wrap(err)
+	}
+
 	gameResult := GameResult{
 		MatchResults: matchResults,
 	}
@@ -342,16 +441,13 @@ func (service *Service) GetGameResult(ctx context.Context, matchID uuid.UUID) (G
 					})
 				}
 			}
+			gameResult.MatchResults[k].Substitutions = userSubstitutions(substitutions, result.UserID)
+			gameResult.MatchResults[k].BadgeURL = badgeURLs[result.UserID]
 		}
 
 		return gameResult, nil
 	}
 
-	match, err := service.matches.Get(ctx, matchID)
-	if err != nil {
-		return gameResult, ErrMatches.Wrap(err)
-	}
-
 	var newGameResult GameResult
 	newGameResult.MatchResults = append(newGameResult.MatchResults, MatchResult{UserID: match.User1ID})
 	newGameResult.MatchResults = append(newGameResult.MatchResults, MatchResult{UserID: match.User2ID})
@@ -376,11 +472,39 @@ func (service *Service) GetGameResult(ctx context.Context, matchID uuid.UUID) (G
 				})
 			}
 		}
+		newGameResult.MatchResults[k].Substitutions = userSubstitutions(substitutions, result.UserID)
+		newGameResult.MatchResults[k].BadgeURL = badgeURLs[result.UserID]
 	}
 
 	return newGameResult, nil
 }
 
+// badgeURLs returns badge urls of the clubs which played the match by user ids.
+func (service *Service) badgeURLs(ctx context.Context, match Match) (map[uuid.UUID]string, error) {
+	badgeURLs := make(map[uuid.UUID]string, 2)
+	for userID, squadID := range map[uuid.UUID]uuid.UUID{match.User1ID: match.Squad1ID, match.User2ID: match.Squad2ID} {
+		badgeURL, err := service.clubs.GetBadgeURLBySquadID(ctx, squadID)
+		if err != nil {
+			return nil, ErrMatches.Wrap(err)
+		}
+		badgeURLs[userID] = badgeURL
+	}
+
+	return badgeURLs, nil
+}
+
+// userSubstitutions returns substitutions made by the user.
+func userSubstitutions(substitutions []MatchSubstitution, userID uuid.UUID) []MatchSubstitution {
+	var result []MatchSubstitution
+	for _, substitution := range substitutions {
+		if substitution.UserID == userID {
+			result = append(result, substitution)
+		}
+	}
+
+	return result
+}
+
 // ListSquadMatches returns all club matches in season.
 func (service *Service) ListSquadMatches(ctx context.Context, seasonID int) ([]Match, error) {
 	allMatches, err := service.matches.ListSquadMatches(ctx, seasonID)
@@ -414,7 +538,17 @@ func (service *Service) RankMatch(ctx context.Context, match Match, matchGoals [
 		match.User2Points = service.config.NumberOfPointsForDraw
 	}
 
-	return ErrMatches.Wrap(service.matches.UpdateMatch(ctx, match))
+	income, err := service.finances.MatchIncome(ctx, match.ID, match.Squad1ID, user1Goals, user2Goals)
+	if err != nil {
+		return ErrMatches.Wrap(err)
+	}
+
+	income2, err := service.finances.MatchIncome(ctx, match.ID, match.Squad2ID, user2Goals, user1Goals)
+	if err != nil {
+		return ErrMatches.Wrap(err)
+	}
+
+	return ErrMatches.Wrap(service.matches.RankMatch(ctx, match, append(income, income2...)))
 }
 
 // GetStatistic returns statistic of club in season.
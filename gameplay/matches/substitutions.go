@@ -0,0 +1,188 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package matches
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/google/uuid"
+
+	"ultimatedivision/cards"
+	"ultimatedivision/clubs"
+)
+
+// team describes state of the user's squad during the simulated match.
+type team struct {
+	userID        uuid.UUID
+	squadCards    []clubs.SquadCard
+	bench         []uuid.UUID
+	cameOn        map[uuid.UUID]bool
+	cards         map[uuid.UUID]cards.Card
+	substitutions int
+	goals         int
+	instructions  clubs.TeamInstructions
+	// playerInstructions are instructions of the cards of the starting lineup, substitutes inherit them.
+	playerInstructions map[uuid.UUID]clubs.Instruction
+}
+
+// newTeam is a constructor for team which copies squad cards, so substitutions do not change the starting lineup.
+func (service *Service) newTeam(ctx context.Context, userID, squadID uuid.UUID, squadCards []clubs.SquadCard) (*team, error) {
+	substitutes, err := service.clubs.ListSubstitutes(ctx, squadID)
+	if err != nil {
+		return nil, ErrMatches.Wrap(err)
+	}
+
+	squad, err := service.clubs.GetSquad(ctx, squadID)
+	if err != nil {
+		return nil, ErrMatches.Wrap(err)
+	}
+
+	instructions, err := service.clubs.ListPlayerInstructions(ctx, squadID)
+	if err != nil {
+		return nil, ErrMatches.Wrap(err)
+	}
+
+	playerInstructions := make(map[uuid.UUID]clubs.Instruction, len(instructions))
+	for _, instruction := range instructions {
+		playerInstructions[instruction.CardID] = instruction.Instruction
+	}
+
+	bench := make([]uuid.UUID, 0, len(substitutes))
+	for _, substitute := range substitutes {
+		bench = append(bench, substitute.CardID)
+	}
+
+	return &team{
+		userID:             userID,
+		squadCards:         append([]clubs.SquadCard(nil), squadCards...),
+		bench:              bench,
+		cameOn:             make(map[uuid.UUID]bool),
+		cards:              make(map[uuid.UUID]cards.Card),
+		instructions:       squad.Instructions,
+		playerInstructions: playerInstructions,
+	}, nil
+}
+
+// substitute replaces the card of the team by the next substitute from the bench if the limit of substitutions allows it
+// and there is a reason: injury of the random card, fatigue of the card with the lowest stamina or the losing score.
+func (service *Service) substitute(ctx context.Context, matchID uuid.UUID, team *team, opponentGoals, minute int) (MatchSubstitution, bool, error) {
+	if team.substitutions >= service.config.Substitutions.Max || len(team.bench) == 0 {
+		return MatchSubstitution{}, false, nil
+	}
+
+	index, reason, err := service.chooseSubstituted(ctx, team, opponentGoals, minute)
+	if err != nil || index < 0 {
+		return MatchSubstitution{}, false, err
+	}
+
+	substitution := MatchSubstitution{
+		ID:        uuid.New(),
+		MatchID:   matchID,
+		UserID:    team.userID,
+		CardOutID: team.squadCards[index].CardID,
+		CardInID:  team.bench[0],
+		Minute:    minute,
+		Reason:    reason,
+	}
+
+	// substitute takes the position and the instruction of the replaced card.
+	team.playerInstructions[team.bench[0]] = team.playerInstructions[team.squadCards[index].CardID]
+	team.squadCards[index].CardID = team.bench[0]
+	team.cameOn[team.bench[0]] = true
+	team.bench = team.bench[1:]
+	team.substitutions++
+
+	return substitution, true, nil
+}
+
+// chooseSubstituted returns index of the squad card which should be replaced and the reason, or -1 if there is no need in substitution.
+func (service *Service) chooseSubstituted(ctx context.Context, team *team, opponentGoals, minute int) (int, SubstitutionReason, error) {
+	config := service.config.Substitutions
+
+	var onField []int
+	for index, squadCard := range team.squadCards {
+		if squadCard.CardID != uuid.Nil {
+			onField = append(onField, index)
+		}
+	}
+	if len(onField) == 0 {
+		return -1, "", nil
+	}
+
+	if rand.Intn(100)+1 <= config.InjuryProbability {
+		return onField[rand.Intn(len(onField))], SubstitutionReasonInjury, nil
+	}
+
+	if minute >= config.FatigueMinute {
+		tired, lowestStamina := -1, config.FatigueStamina
+		for _, index := range onField {
+			squadCard := team.squadCards[index]
+			if squadCard.Position == clubs.GK || team.cameOn[squadCard.CardID] {
+				continue
+			}
+
+			card, err := service.teamCard(ctx, team, squadCard.CardID)
+			if err != nil {
+				return -1, "", ErrMatches.Wrap(err)
+			}
+
+			if card.Stamina < lowestStamina {
+				tired, lowestStamina = index, card.Stamina
+			}
+		}
+
+		if tired >= 0 {
+			return tired, SubstitutionReasonFatigue, nil
+		}
+	}
+
+	if minute >= config.LosingMinute && team.goals < opponentGoals {
+		// the defender is replaced to strengthen the attack.
+		for _, index := range onField {
+			squadCard := team.squadCards[index]
+			if isDefender(squadCard.Position) && !team.cameOn[squadCard.CardID] {
+				return index, SubstitutionReasonScore, nil
+			}
+		}
+	}
+
+	return -1, "", nil
+}
+
+// teamCard returns card of the team, cards are cached for the whole match.
+func (service *Service) teamCard(ctx context.Context, team *team, cardID uuid.UUID) (cards.Card, error) {
+	if card, ok := team.cards[cardID]; ok {
+		return card, nil
+	}
+
+	card, err := service.cards.Get(ctx, cardID)
+	if err != nil {
+		return cards.Card{}, err
+	}
+	team.cards[cardID] = card
+
+	return card, nil
+}
+
+// isDefender checks if the position belongs to the defence line.
+func isDefender(position clubs.Position) bool {
+	switch position {
+	case clubs.LB, clubs.RB, clubs.LWB, clubs.RWB, clubs.CCD, clubs.LCD, clubs.RCD:
+		return true
+	default:
+		return false
+	}
+}
+
+// AddSubstitutions adds substitutions made in the match.
+func (service *Service) AddSubstitutions(ctx context.Context, substitutions []MatchSubstitution) error {
+	return ErrMatches.Wrap(service.matches.AddSubstitutions(ctx, substitutions))
+}
+
+// ListMatchSubstitutions returns all substitutions made in the match.
+func (service *Service) ListMatchSubstitutions(ctx context.Context, matchID uuid.UUID) ([]MatchSubstitution, error) {
+	substitutions, err := service.matches.ListMatchSubstitutions(ctx, matchID)
+	return substitutions, ErrMatches.Wrap(err)
+}
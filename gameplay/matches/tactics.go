@@ -0,0 +1,149 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package matches
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"ultimatedivision/cards"
+	"ultimatedivision/clubs"
+)
+
+// averageSkill defines the middle value of the card skills.
+const averageSkill = 50
+
+// isWide checks if the position is on the flank.
+func isWide(position clubs.Position) bool {
+	switch position {
+	case clubs.LB, clubs.RB, clubs.LWB, clubs.RWB, clubs.LM, clubs.RM, clubs.LW, clubs.RW:
+		return true
+	default:
+		return false
+	}
+}
+
+// isCentralMidfielder checks if the position is in the centre of the midfield.
+func isCentralMidfielder(position clubs.Position) bool {
+	return position.Line() == clubs.LineMidfield && !isWide(position)
+}
+
+// isAttacker checks if the position belongs to the attack line.
+func isAttacker(position clubs.Position) bool {
+	return position.Line() == clubs.LineAttack
+}
+
+// isOutfield checks if the position is not goalkeeper.
+func isOutfield(position clubs.Position) bool {
+	return position != clubs.GK
+}
+
+// averageSkillOf returns average skill of the cards of the team in the positions, or average skill if there are no such cards.
+func (service *Service) averageSkillOf(ctx context.Context, team *team, inPosition func(clubs.Position) bool, skill func(cards.Card) int) (float64, error) {
+	var sum, count int
+	for _, squadCard := range team.squadCards {
+		if squadCard.CardID == uuid.Nil || !inPosition(squadCard.Position) {
+			continue
+		}
+
+		card, err := service.teamCard(ctx, team, squadCard.CardID)
+		if err != nil {
+			return 0, ErrMatches.Wrap(err)
+		}
+
+		sum += skill(card)
+		count++
+	}
+
+	if count == 0 {
+		return averageSkill, nil
+	}
+
+	return float64(sum) / float64(count), nil
+}
+
+// clampFactor limits the factor of the tactical setting to the range of -1 to 1.
+func clampFactor(factor float64) float64 {
+	switch {
+	case factor > 1:
+		return 1
+	case factor < -1:
+		return -1
+	default:
+		return factor
+	}
+}
+
+// tacticalModifier returns multiplier of the effectiveness of the team, which depends on how well its tactical
+// settings suit its cards and the opponent: high pressing needs stamina, fast tempo needs passing, wide play needs
+// crosses, narrow play needs dribbling, and a high defensive line relies on the offside trap against the pace
+// of the opponent attackers, while a deep line is good only against fast attackers.
+func (service *Service) tacticalModifier(ctx context.Context, own, opponent *team) (float64, error) {
+	if service.config.Tactics.Bonus == 0 {
+		return 1, nil
+	}
+
+	var factors float64
+
+	switch own.instructions.Pressing {
+	case clubs.IntensityHigh:
+		stamina, err := service.averageSkillOf(ctx, own, isOutfield, func(card cards.Card) int { return card.Stamina })
+		if err != nil {
+			return 0, err
+		}
+		factors += clampFactor((stamina - averageSkill) / averageSkill)
+	case clubs.IntensityLow:
+		aggression, err := service.averageSkillOf(ctx, opponent, isOutfield, func(card cards.Card) int { return card.Aggression })
+		if err != nil {
+			return 0, err
+		}
+		factors -= clampFactor((aggression - averageSkill) / averageSkill)
+	}
+
+	if own.instructions.Tempo == clubs.IntensityHigh {
+		passing, err := service.averageSkillOf(ctx, own, isOutfield, func(card cards.Card) int { return card.ShortPassing })
+		if err != nil {
+			return 0, err
+		}
+		factors += clampFactor((passing - averageSkill) / averageSkill)
+	}
+
+	switch own.instructions.Width {
+	case clubs.IntensityHigh:
+		crosses, err := service.averageSkillOf(ctx, own, isWide, func(card cards.Card) int { return card.Crosses })
+		if err != nil {
+			return 0, err
+		}
+		factors += clampFactor((crosses - averageSkill) / averageSkill)
+	case clubs.IntensityLow:
+		dribbling, err := service.averageSkillOf(ctx, own, isCentralMidfielder, func(card cards.Card) int { return card.Dribbling })
+		if err != nil {
+			return 0, err
+		}
+		factors += clampFactor((dribbling - averageSkill) / averageSkill)
+	}
+
+	if own.instructions.DefensiveLine != clubs.IntensityMedium {
+		pace, err := service.averageSkillOf(ctx, opponent, isAttacker, func(card cards.Card) int {
+			return (card.RunningSpeed + card.Acceleration) / 2
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		switch own.instructions.DefensiveLine {
+		case clubs.IntensityHigh:
+			offsideTrap, err := service.averageSkillOf(ctx, own, isDefender, func(card cards.Card) int { return card.OffsideTrap })
+			if err != nil {
+				return 0, err
+			}
+			factors += clampFactor((offsideTrap - pace) / averageSkill)
+		case clubs.IntensityLow:
+			factors += clampFactor((pace - averageSkill) / averageSkill)
+		}
+	}
+
+	return 1 + factors*float64(service.config.Tactics.Bonus)/100, nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/zeebo/errs"
 
+	"ultimatedivision/clubs"
 	"ultimatedivision/console/connections"
 	"ultimatedivision/gameplay/gameengine"
 	"ultimatedivision/gameplay/matches"
@@ -34,16 +35,18 @@ type Service struct {
 	gameEngine  *gameengine.Service
 	queue       *queue.Chore
 	matches     *matches.Service
+	clubs       *clubs.Service
 }
 
 // NewService is a constructor for matchmaking service.
-func NewService(players DB, connections *connections.Service, gameEngine *gameengine.Service, queue *queue.Chore, matches *matches.Service) *Service {
+func NewService(players DB, connections *connections.Service, gameEngine *gameengine.Service, queue *queue.Chore, matches *matches.Service, clubs *clubs.Service) *Service {
 	return &Service{
 		players:     players,
 		connections: connections,
 		gameEngine:  gameEngine,
 		queue:       queue,
 		matches:     matches,
+		clubs:       clubs,
 	}
 }
 
@@ -82,6 +85,18 @@ func (service *Service) Create(ctx context.Context, userID uuid.UUID) error {
 	fmt.Println("action1 ------>>>", req.Action)
 
 	if req.Action == queue.ActionStartSearch {
+		report, err := service.clubs.ValidateSquad(ctx, userID, req.SquadID)
+		if err != nil {
+			return ErrMatchmaking.Wrap(err)
+		}
+		if !report.IsValid {
+			resp := queue.Response{
+				Status:  http.StatusBadRequest,
+				Message: report,
+			}
+			return ErrMatchmaking.Wrap(conn.WriteJSON(resp))
+		}
+
 		if err = service.players.Create(player); err != nil {
 			return ErrMatchmaking.Wrap(err)
 		}
@@ -289,6 +304,7 @@ func (service *Service) MatchPlayer(ctx context.Context, player *Player) (*Match
 				HasBall       bool      `json:"hasBall"`
 				NewPositions  []int     `json:"newPositions"`
 				FinalPosition int       `json:"finalPosition"`
+				SubstituteID  uuid.UUID `json:"substituteId"`
 			}
 
 			type gameRequest struct {
@@ -298,6 +314,7 @@ func (service *Service) MatchPlayer(ctx context.Context, player *Player) (*Match
 
 			startGameInformation.Rounds = 4
 			var gameResults []matches.MatchGoals
+			var gameSubstitutions []matches.MatchSubstitution
 			for i := 1; i <= startGameInformation.Rounds; i++ {
 				fmt.Println("Round:", i)
 
@@ -321,11 +338,24 @@ func (service *Service) MatchPlayer(ctx context.Context, player *Player) (*Match
 					gameengine.CardIDWithPosition{
 						CardID:   matchData.CardID,
 						Position: matchData.Position,
-					}, gameengine.Action(matchData.Action), matchData.NewPositions, matchData.FinalPosition, matchData.HasBall)
+						Team:     gameengine.Player1,
+					}, gameengine.Action(matchData.Action), matchData.NewPositions, matchData.FinalPosition, matchData.HasBall, matchData.SubstituteID)
 				if err != nil {
 					return nil, ErrMatchmaking.Wrap(err)
 				}
 
+				if gameengine.Action(matchData.Action) == gameengine.ActionSubstitution {
+					gameSubstitutions = append(gameSubstitutions, matches.MatchSubstitution{
+						ID:        uuid.New(),
+						MatchID:   startGameInformation.MatchID,
+						UserID:    match.Player1.UserID,
+						CardOutID: matchData.CardID,
+						CardInID:  matchData.SubstituteID,
+						Minute:    i * matches.MatchDuration / startGameInformation.Rounds,
+						Reason:    matches.SubstitutionReasonManual,
+					})
+				}
+
 				cardAvailableAction.Message = "match action"
 				cardAvailableAction.Team = "player 1"
 				fmt.Println("cardAvailableAction player 1:", cardAvailableAction)
@@ -343,6 +373,7 @@ func (service *Service) MatchPlayer(ctx context.Context, player *Player) (*Match
 				}
 				fmt.Println("req player 2:", req)
 
+				matchData = Match{}
 				err = json.Unmarshal([]byte(req.Match), &matchData)
 				if err != nil {
 					fmt.Println(err)
@@ -352,11 +383,24 @@ func (service *Service) MatchPlayer(ctx context.Context, player *Player) (*Match
 				cardAvailableAction, err = service.gameEngine.GameLogicByAction(ctx, startGameInformation.MatchID, gameengine.CardIDWithPosition{
 					CardID:   matchData.CardID,
 					Position: matchData.Position,
-				}, gameengine.Action(matchData.Action), matchData.NewPositions, matchData.FinalPosition, matchData.HasBall)
+					Team:     gameengine.Player2,
+				}, gameengine.Action(matchData.Action), matchData.NewPositions, matchData.FinalPosition, matchData.HasBall, matchData.SubstituteID)
 				if err != nil {
 					return nil, ErrMatchmaking.Wrap(err)
 				}
 
+				if gameengine.Action(matchData.Action) == gameengine.ActionSubstitution {
+					gameSubstitutions = append(gameSubstitutions, matches.MatchSubstitution{
+						ID:        uuid.New(),
+						MatchID:   startGameInformation.MatchID,
+						UserID:    match.Player2.UserID,
+						CardOutID: matchData.CardID,
+						CardInID:  matchData.SubstituteID,
+						Minute:    i * matches.MatchDuration / startGameInformation.Rounds,
+						Reason:    matches.SubstitutionReasonManual,
+					})
+				}
+
 				cardAvailableAction.Message = "match action"
 				cardAvailableAction.Team = "player 2"
 				fmt.Println("cardAvailableAction player 2:", cardAvailableAction)
@@ -387,6 +431,17 @@ func (service *Service) MatchPlayer(ctx context.Context, player *Player) (*Match
 				}
 			}
 
+			if gameSubstitutions != nil {
+				err = service.matches.AddSubstitutions(ctx, gameSubstitutions)
+				if err != nil {
+					return nil, ErrMatchmaking.Wrap(err)
+				}
+			}
+
+			if err = service.matches.RecordPartnerships(ctx, matchInfo); err != nil {
+				return nil, ErrMatchmaking.Wrap(err)
+			}
+
 			var value = new(big.Int)
 			value.SetString(service.queue.Config.DrawValue, 10)
 
@@ -275,23 +275,16 @@ func DivideClients(clients []Client) [][]Client {
 
 // Play method contains all the logic for playing matches.
 func (chore *Chore) Play(ctx context.Context, firstClient, secondClient Client) error {
-	squadCardsFirstClient, err := chore.service.clubs.ListSquadCards(ctx, firstClient.SquadID)
-	if err != nil {
-		return ChoreError.Wrap(err)
-	}
-	if len(squadCardsFirstClient) != clubs.SquadSize {
-		if err := firstClient.WriteJSON(http.StatusInternalServerError, "squad is not full"); err != nil {
+	for _, client := range []Client{firstClient, secondClient} {
+		report, err := chore.service.ValidateSquad(ctx, client)
+		if err != nil {
 			return ChoreError.Wrap(err)
 		}
-	}
-
-	squadCardsSecondClient, err := chore.service.clubs.ListSquadCards(ctx, secondClient.SquadID)
-	if err != nil {
-		return ChoreError.Wrap(err)
-	}
-	if len(squadCardsSecondClient) != clubs.SquadSize {
-		if err := secondClient.WriteJSON(http.StatusInternalServerError, "squad is not full"); err != nil {
-			return ChoreError.Wrap(err)
+		if !report.IsValid {
+			if err := client.WriteJSON(http.StatusBadRequest, report); err != nil {
+				return ChoreError.Wrap(err)
+			}
+			return ChoreError.New("squad is not valid")
 		}
 	}
 
@@ -42,16 +42,35 @@ func (service *Service) Create(ctx context.Context, client Client) error {
 		return ErrQueue.Wrap(err)
 	}
 
+	// client plays with the active squad of the club, unless another lineup preset is picked.
+	if client.SquadID == uuid.Nil {
+		squad, err := service.clubs.GetActiveSquadByUserID(ctx, client.UserID)
+		if err != nil {
+			return ErrQueue.Wrap(err)
+		}
+		client.SquadID = squad.ID
+	}
+
 	squad, err := service.clubs.GetSquad(ctx, client.SquadID)
 	if err != nil {
 		return ErrQueue.Wrap(err)
 	}
 
-	_, err = service.clubs.Get(ctx, squad.ClubID)
+	club, err := service.clubs.Get(ctx, squad.ClubID)
 	if err != nil {
 		return ErrQueue.Wrap(err)
 	}
 
+	if club.OwnerID != client.UserID {
+		return ErrQueue.New("squad does not belong to user")
+	}
+
+	if !squad.IsActive {
+		if err = service.clubs.SetActiveSquad(ctx, client.UserID, club.ID, squad.ID); err != nil {
+			return ErrQueue.Wrap(err)
+		}
+	}
+
 	// TODO: add division ID to client.
 
 	err = service.queues.Delete(client.UserID)
@@ -63,6 +82,12 @@ func (service *Service) Create(ctx context.Context, client Client) error {
 	return ErrQueue.Wrap(err)
 }
 
+// ValidateSquad returns health check report of the squad with which the client is going to play.
+func (service *Service) ValidateSquad(ctx context.Context, client Client) (clubs.SquadReport, error) {
+	report, err := service.clubs.ValidateSquad(ctx, client.UserID, client.SquadID)
+	return report, ErrQueue.Wrap(err)
+}
+
 // Get returns client from database.
 func (service *Service) Get(userID uuid.UUID) (Client, error) {
 	queue, err := service.queues.Get(userID)
@@ -6,21 +6,33 @@ package bids
 import (
 	"context"
 	"math/big"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/zeebo/errs"
+
+	"ultimatedivision/finances"
 )
 
 // ErrNoBid indicates that bid does not exist.
 var ErrNoBid = errs.Class("bid does not exist")
 
+// ErrNoProxyBid indicates that proxy bid does not exist.
+var ErrNoProxyBid = errs.Class("proxy bid does not exist")
+
+// ErrBidConflict indicates that the lot was changed by another bid or purchase while the bid was resolved.
+var ErrBidConflict = errs.Class("bid conflict")
+
 // DB is exposing access to bids db.
 //
 // architecture: DB
 type DB interface {
 	// Create creates bid for lot in the database.
 	Create(ctx context.Context, bid Bid) error
+	// Place applies all changes of the placement in one transaction, returns ErrBidConflict if the lot is not active
+	// anymore or its current bid or maximums of the users differ from the ones the placement was resolved from.
+	Place(ctx context.Context, placement Placement) error
 	// GetCurrentBidByLotID returns current bid by lot id from the database.
 	GetCurrentBidByLotID(ctx context.Context, lotID uuid.UUID) (Bid, error)
 	// ListByLotID returns bids by lot id from the database.
@@ -29,27 +41,175 @@ type DB interface {
 	ListByUserID(ctx context.Context, userID uuid.UUID) ([]Bid, error)
 	// GetUserBidsAmountByLotID returns amount of user last bet on certain lot form the database.
 	GetUserBidsAmountByLotID(ctx context.Context, userID, lotID uuid.UUID) ([]big.Int, error)
-	// DeleteByLotID deletes bids by lot id in the database.
+	// DeleteByLotID deletes bids with proxy bids by lot id in the database.
 	DeleteByLotID(ctx context.Context, lotID uuid.UUID) error
+	// Withdraw deletes maximum amount of the user on the lot and returns the funds held for it in one transaction.
+	Withdraw(ctx context.Context, release finances.Escrow) error
+	// SetProxyBid creates or replaces maximum amount of the user on the lot in the database.
+	SetProxyBid(ctx context.Context, proxyBid ProxyBid) error
+	// GetProxyBid returns maximum amount of the user on the lot from the database.
+	GetProxyBid(ctx context.Context, lotID, userID uuid.UUID) (ProxyBid, error)
+	// ListProxyBidsByLotID returns maximum amounts of the users on the lot from the database.
+	ListProxyBidsByLotID(ctx context.Context, lotID uuid.UUID) ([]ProxyBid, error)
 }
 
 // Bid describes bids placed on a specific lot.
+// Automatic bids are placed by the system on behalf of the user up to the maximum amount of the user.
 type Bid struct {
 	ID        uuid.UUID `json:"id"`
 	LotID     uuid.UUID `json:"lotId"`
 	UserID    uuid.UUID `json:"userId"`
 	UserName  string    `json:"userName"`
 	Amount    big.Int   `json:"amount"`
+	Automatic bool      `json:"automatic"`
+	CreatedAt time.Time `json:"createdAt"`
+	// MaxAmount is the hidden maximum amount the user is ready to pay, it is set only when the bid is placed
+	// and is never stored with the bid.
+	MaxAmount big.Int `json:"-"`
+}
+
+// ProxyBid describes the hidden maximum amount up to which the system bids on the lot on behalf of the user.
+type ProxyBid struct {
+	LotID     uuid.UUID `json:"lotId"`
+	UserID    uuid.UUID `json:"userId"`
+	MaxAmount big.Int   `json:"maxAmount"`
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+// Placement describes changes of the auction lot made by the bid. The lot is locked while they are applied,
+// so the concurrent bids are applied one by one and each of them only to the state of the lot it was resolved from.
+type Placement struct {
+	LotID uuid.UUID
+	// CurrentBidID is the current bid of the lot the placement was resolved from, it is nil if there were no bids.
+	CurrentBidID uuid.UUID
+	// ProxyBids are the maximums of the users the placement was resolved from.
+	ProxyBids []ProxyBid
+	// ProxyBid is the new maximum of the bidder, it is nil if the maximum is not raised.
+	ProxyBid *ProxyBid
+	// Bids are the bids which raise the price, the last one is the bid of the new shopper of the lot.
+	Bids    []Bid
+	EndTime time.Time
+	// Hold holds the maximum of the bidder, so the bidder could not bid more than the balance of the club.
+	Hold finances.Escrow
+	// Releases return the funds of the outbid users, their maximums are deleted with them.
+	Releases []finances.Escrow
+}
+
 // Config defines configuration for bids.
 type Config struct {
 	ExpiredLotRenewalInterval time.Duration `json:"expiredLotRenewalInterval"`
+	SoftClose                 SoftClose     `json:"softClose"`
+	// MinIncrement is the least amount by which the bid must exceed the current one, one if it is not set.
+	MinIncrement int64 `json:"minIncrement"`
+}
+
+// Increment returns the least amount by which the bid must exceed the current one.
+func (config Config) Increment() big.Int {
+	if config.MinIncrement <= 0 {
+		return *big.NewInt(1)
+	}
+
+	return *big.NewInt(config.MinIncrement)
+}
+
+// SoftClose defines anti-sniping rules of the auction: any bid placed in the window before the end time
+// extends the end time, but not further than max extension after the end of the lot period.
+// Soft close is disabled if window is zero.
+type SoftClose struct {
+	Window       time.Duration `json:"window"`
+	Extension    time.Duration `json:"extension"`
+	MaxExtension time.Duration `json:"maxExtension"`
+}
+
+// ExtendedEndTime returns end time of the lot after the bid placed at the moment,
+// endTime is the current end time and periodEnd is the end of the lot period.
+func (softClose SoftClose) ExtendedEndTime(now, endTime, periodEnd time.Time) time.Time {
+	if softClose.Window <= 0 || endTime.Sub(now) > softClose.Window {
+		return endTime
+	}
+
+	extended := endTime.Add(softClose.Extension)
+	if limit := periodEnd.Add(softClose.MaxExtension); extended.After(limit) {
+		extended = limit
+	}
+	if extended.Before(endTime) {
+		return endTime
+	}
+
+	return extended
 }
 
 // Compare compares two bids.
 func (b Bid) Compare(bidToCompare Bid) bool {
 	return b.ID == bidToCompare.ID && b.LotID == bidToCompare.LotID && b.UserID == bidToCompare.UserID &&
-		b.UserName == bidToCompare.UserName && b.Amount.Cmp(&bidToCompare.Amount) == 0 && b.CreatedAt == bidToCompare.CreatedAt
+		b.UserName == bidToCompare.UserName && b.Amount.Cmp(&bidToCompare.Amount) == 0 && b.Automatic == bidToCompare.Automatic &&
+		b.CreatedAt == bidToCompare.CreatedAt
+}
+
+// Contender describes the user competing for the lot with the maximum amount the user is ready to pay.
+// Since is the moment the maximum was reached, the earlier contender wins the tie.
+// Amount is the amount the contender bids explicitly, the bid placed for the contender is not less than it.
+type Contender struct {
+	UserID    uuid.UUID
+	MaxAmount big.Int
+	Amount    big.Int
+	Since     time.Time
+}
+
+// Resolve resolves the competition of the contenders for the lot led by the leader at the price, minimum is the least
+// amount of the bid which outbids the leader. The contender with the highest maximum wins and pays the increment above
+// the maximum of the runner-up, but not more than its own maximum. It returns the winner and the bids which raise
+// the price: the last bid of the outbid runner-up and the bid of the winner, no bids are returned if the leader keeps
+// the lot at the same price. The returned bids have only user, amount and automatic flag set.
+func Resolve(contenders []Contender, leaderID uuid.UUID, price, minimum, increment big.Int) (Contender, []Bid) {
+	if len(contenders) == 0 {
+		return Contender{}, nil
+	}
+
+	sorted := make([]Contender, len(contenders))
+	copy(sorted, contenders)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if cmp := sorted[i].MaxAmount.Cmp(&sorted[j].MaxAmount); cmp != 0 {
+			return cmp > 0
+		}
+		return sorted[i].Since.Before(sorted[j].Since)
+	})
+
+	winner := sorted[0]
+	var winnerPrice big.Int
+	if len(sorted) > 1 {
+		winnerPrice.Add(&sorted[1].MaxAmount, &increment)
+		if winnerPrice.Cmp(&winner.MaxAmount) > 0 {
+			winnerPrice.Set(&winner.MaxAmount)
+		}
+	}
+	if winner.UserID != leaderID && winnerPrice.Cmp(&minimum) < 0 {
+		winnerPrice.Set(&minimum)
+	}
+	if winnerPrice.Cmp(&winner.Amount) < 0 {
+		winnerPrice.Set(&winner.Amount)
+	}
+	if winner.UserID == leaderID && winnerPrice.Cmp(&price) <= 0 {
+		return winner, nil
+	}
+
+	var placed []Bid
+	if len(sorted) > 1 {
+		runnerUp := sorted[1]
+		if runnerUp.MaxAmount.Cmp(&minimum) >= 0 && runnerUp.MaxAmount.Cmp(&winnerPrice) < 0 {
+			placed = append(placed, Bid{
+				UserID:    runnerUp.UserID,
+				Amount:    runnerUp.MaxAmount,
+				Automatic: runnerUp.MaxAmount.Cmp(&runnerUp.Amount) != 0,
+			})
+		}
+	}
+
+	placed = append(placed, Bid{
+		UserID:    winner.UserID,
+		Amount:    winnerPrice,
+		Automatic: winnerPrice.Cmp(&winner.Amount) != 0,
+	})
+
+	return winner, placed
 }
@@ -5,7 +5,10 @@ package bids_test
 
 import (
 	"context"
+	"fmt"
 	"math/big"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,10 +16,15 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/zeebo/errs"
 
 	"ultimatedivision"
 	"ultimatedivision/cards"
+	"ultimatedivision/cards/nfts"
+	"ultimatedivision/clubs"
 	"ultimatedivision/database/dbtesting"
+	"ultimatedivision/divisions"
+	"ultimatedivision/finances"
 	"ultimatedivision/marketplace"
 	"ultimatedivision/marketplace/bids"
 	"ultimatedivision/users"
@@ -180,6 +188,7 @@ func TestBids(t *testing.T) {
 	}
 
 	lot1 := marketplace.Lot{
+		ID:           uuid.New(),
 		CardID:       card1.ID,
 		Type:         marketplace.TypeCard,
 		UserID:       uuid.New(),
@@ -194,6 +203,7 @@ func TestBids(t *testing.T) {
 	}
 
 	_ = marketplace.Lot{
+		ID:           uuid.New(),
 		CardID:       card2.ID,
 		Type:         marketplace.TypeCard,
 		UserID:       uuid.New(),
@@ -207,7 +217,7 @@ func TestBids(t *testing.T) {
 
 	bid1 := bids.Bid{
 		ID:        uuid.New(),
-		LotID:     lot1.CardID,
+		LotID:     lot1.ID,
 		UserID:    user1.ID,
 		Amount:    *big.NewInt(2100000000000000000),
 		CreatedAt: time.Now().UTC().Add(5 * time.Minute).Round(time.Second),
@@ -215,7 +225,7 @@ func TestBids(t *testing.T) {
 
 	bid2 := bids.Bid{
 		ID:        uuid.New(),
-		LotID:     lot1.CardID,
+		LotID:     lot1.ID,
 		UserID:    user2.ID,
 		Amount:    *big.NewInt(2200000000000000000),
 		CreatedAt: time.Now().UTC().Add(7 * time.Minute).Round(time.Second),
@@ -223,7 +233,7 @@ func TestBids(t *testing.T) {
 
 	bid3 := bids.Bid{
 		ID:        uuid.New(),
-		LotID:     lot1.CardID,
+		LotID:     lot1.ID,
 		UserID:    user1.ID,
 		Amount:    *big.NewInt(2100000000000000000),
 		CreatedAt: time.Now().Add(5 * time.Minute).UTC(),
@@ -258,7 +268,7 @@ func TestBids(t *testing.T) {
 		})
 
 		t.Run("ListByLotID", func(t *testing.T) {
-			bids, err := bidsRepository.ListByLotID(ctx, lot1.CardID)
+			bids, err := bidsRepository.ListByLotID(ctx, lot1.ID)
 			require.NoError(t, err)
 			require.Equal(t, len(bids), 2)
 			compareBids(t, bids[0], bid1)
@@ -282,12 +292,12 @@ func TestBids(t *testing.T) {
 		})
 
 		t.Run("DeleteByLotID", func(t *testing.T) {
-			err := bidsRepository.DeleteByLotID(ctx, lot1.CardID)
+			err := bidsRepository.DeleteByLotID(ctx, lot1.ID)
 			require.NoError(t, err)
 		})
 
 		t.Run("Negative DeleteByLotID", func(t *testing.T) {
-			err := bidsRepository.DeleteByLotID(ctx, lot1.CardID)
+			err := bidsRepository.DeleteByLotID(ctx, lot1.ID)
 			require.Error(t, err)
 
 			assert.True(t, bids.ErrNoBid.Has(err))
@@ -295,10 +305,448 @@ func TestBids(t *testing.T) {
 	})
 }
 
+func TestSoftClose(t *testing.T) {
+	softClose := bids.SoftClose{Window: 5 * time.Minute, Extension: 2 * time.Minute, MaxExtension: 3 * time.Minute}
+	now := time.Now().UTC()
+
+	t.Run("bid before window", func(t *testing.T) {
+		endTime := now.Add(10 * time.Minute)
+		assert.Equal(t, endTime, softClose.ExtendedEndTime(now, endTime, endTime))
+	})
+
+	t.Run("bid in window", func(t *testing.T) {
+		endTime := now.Add(time.Minute)
+		assert.Equal(t, endTime.Add(2*time.Minute), softClose.ExtendedEndTime(now, endTime, endTime))
+	})
+
+	t.Run("extension is capped", func(t *testing.T) {
+		periodEnd := now.Add(-time.Minute)
+		endTime := now.Add(time.Minute)
+		assert.Equal(t, periodEnd.Add(3*time.Minute), softClose.ExtendedEndTime(now, endTime, periodEnd))
+
+		endTime = periodEnd.Add(3 * time.Minute)
+		assert.Equal(t, endTime, softClose.ExtendedEndTime(now, endTime, periodEnd))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		endTime := now.Add(time.Second)
+		assert.Equal(t, endTime, bids.SoftClose{}.ExtendedEndTime(now, endTime, endTime))
+	})
+}
+
+func TestResolve(t *testing.T) {
+	increment := *big.NewInt(10)
+	now := time.Now().UTC()
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+
+	contender := func(userID uuid.UUID, maxAmount, amount int64, since time.Time) bids.Contender {
+		return bids.Contender{UserID: userID, MaxAmount: *big.NewInt(maxAmount), Amount: *big.NewInt(amount), Since: since}
+	}
+	assertBids := func(t *testing.T, expected []bids.Bid, placed []bids.Bid) {
+		require.Len(t, placed, len(expected))
+		for i := range expected {
+			assert.Equal(t, expected[i].UserID, placed[i].UserID)
+			assert.Equal(t, expected[i].Amount.String(), placed[i].Amount.String())
+			assert.Equal(t, expected[i].Automatic, placed[i].Automatic)
+		}
+	}
+
+	t.Run("first proxy bid is placed at start price", func(t *testing.T) {
+		winner, placed := bids.Resolve([]bids.Contender{contender(first, 1000, 0, now)}, uuid.Nil, big.Int{}, *big.NewInt(500), increment)
+		assert.Equal(t, first, winner.UserID)
+		assertBids(t, []bids.Bid{{UserID: first, Amount: *big.NewInt(500), Automatic: true}}, placed)
+	})
+
+	t.Run("fixed bid keeps its amount", func(t *testing.T) {
+		contenders := []bids.Contender{contender(first, 500, 0, now), contender(second, 700, 700, now.Add(time.Second))}
+		winner, placed := bids.Resolve(contenders, first, *big.NewInt(500), *big.NewInt(510), increment)
+		assert.Equal(t, second, winner.UserID)
+		assertBids(t, []bids.Bid{{UserID: second, Amount: *big.NewInt(700)}}, placed)
+	})
+
+	t.Run("leader outbids fixed bid automatically", func(t *testing.T) {
+		contenders := []bids.Contender{contender(first, 1000, 0, now), contender(second, 700, 700, now.Add(time.Second))}
+		winner, placed := bids.Resolve(contenders, first, *big.NewInt(500), *big.NewInt(510), increment)
+		assert.Equal(t, first, winner.UserID)
+		assertBids(t, []bids.Bid{{UserID: second, Amount: *big.NewInt(700)}, {UserID: first, Amount: *big.NewInt(710), Automatic: true}}, placed)
+	})
+
+	t.Run("higher proxy takes the lead", func(t *testing.T) {
+		contenders := []bids.Contender{contender(first, 1000, 0, now), contender(second, 700, 700, now), contender(third, 1500, 0, now.Add(time.Second))}
+		winner, placed := bids.Resolve(contenders, first, *big.NewInt(710), *big.NewInt(720), increment)
+		assert.Equal(t, third, winner.UserID)
+		assertBids(t, []bids.Bid{{UserID: first, Amount: *big.NewInt(1000), Automatic: true}, {UserID: third, Amount: *big.NewInt(1010), Automatic: true}}, placed)
+	})
+
+	t.Run("winner does not pay more than its maximum", func(t *testing.T) {
+		contenders := []bids.Contender{contender(first, 1000, 0, now), contender(third, 1005, 0, now.Add(time.Second))}
+		winner, placed := bids.Resolve(contenders, first, *big.NewInt(710), *big.NewInt(720), increment)
+		assert.Equal(t, third, winner.UserID)
+		assertBids(t, []bids.Bid{{UserID: first, Amount: *big.NewInt(1000), Automatic: true}, {UserID: third, Amount: *big.NewInt(1005), Automatic: true}}, placed)
+	})
+
+	t.Run("earlier maximum wins the tie", func(t *testing.T) {
+		contenders := []bids.Contender{contender(first, 1000, 0, now.Add(time.Second)), contender(third, 1000, 0, now)}
+		winner, placed := bids.Resolve(contenders, third, *big.NewInt(710), *big.NewInt(720), increment)
+		assert.Equal(t, third, winner.UserID)
+		assertBids(t, []bids.Bid{{UserID: third, Amount: *big.NewInt(1000), Automatic: true}}, placed)
+	})
+
+	t.Run("leader raises its maximum", func(t *testing.T) {
+		contenders := []bids.Contender{contender(first, 2000, 0, now.Add(time.Second)), contender(second, 700, 700, now)}
+		winner, placed := bids.Resolve(contenders, first, *big.NewInt(710), *big.NewInt(720), increment)
+		assert.Equal(t, first, winner.UserID)
+		assert.Empty(t, placed)
+	})
+}
+
+func TestCreateProxy(t *testing.T) {
+	const biddersCount = 3
+
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db ultimatedivision.DB) {
+		usersService := users.NewService(db.Users())
+		cardsService := cards.NewService(db.Cards(), cards.Config{})
+		clubsService := clubs.NewService(db.Clubs(), usersService, cardsService, db.Divisions())
+		financesService := finances.NewService(db.Finances(), clubsService, cardsService, finances.Config{})
+		nftsService := nfts.NewService(nfts.Config{}, db.NFTs())
+		marketplaceService := marketplace.NewService(marketplace.Config{}, db.Marketplace(), usersService, cardsService, nftsService, financesService)
+		bidsService := bids.NewService(bids.Config{MinIncrement: 10}, db.Bids(), marketplaceService, cardsService, clubsService, nftsService, usersService, financesService)
+
+		seller := users.User{ID: uuid.New(), Email: "seller@example.com", PasswordHash: []byte{0}, NickName: "seller", CreatedAt: time.Now().UTC()}
+		require.NoError(t, db.Users().Create(ctx, seller))
+
+		division := divisions.Division{ID: uuid.New(), Name: 10, PassingPercent: 10, CreatedAt: time.Now().UTC()}
+		require.NoError(t, db.Divisions().Create(ctx, division))
+
+		bidders := make([]users.User, biddersCount)
+		for i := range bidders {
+			bidders[i] = users.User{
+				ID:           uuid.New(),
+				Email:        fmt.Sprintf("bidder%d@example.com", i),
+				PasswordHash: []byte{0},
+				NickName:     fmt.Sprintf("bidder%d", i),
+				CreatedAt:    time.Now().UTC(),
+			}
+			require.NoError(t, db.Users().Create(ctx, bidders[i]))
+
+			club := clubs.Club{
+				ID:         uuid.New(),
+				OwnerID:    bidders[i].ID,
+				Name:       bidders[i].NickName,
+				Status:     clubs.StatusActive,
+				DivisionID: division.ID,
+				CreatedAt:  time.Now().UTC(),
+			}
+			_, err := db.Clubs().Create(ctx, club)
+			require.NoError(t, err)
+
+			_, err = db.Clubs().CreateSquad(ctx, clubs.Squad{ID: uuid.New(), ClubID: club.ID, Tactic: clubs.Balanced, Formation: clubs.FourFourTwo, IsActive: true})
+			require.NoError(t, err)
+
+			income := finances.NewTransfer(finances.TypeMatchIncome, "match", finances.AccountMatchIncome, finances.ClubAccount(club.ID), *big.NewInt(10000))
+			require.NoError(t, financesService.Record(ctx, income))
+		}
+
+		card := cards.Card{
+			ID:           uuid.New(),
+			PlayerName:   "Proxied",
+			Quality:      cards.QualityWood,
+			DominantFoot: "left",
+			Status:       cards.StatusActive,
+			Type:         cards.TypeWon,
+			UserID:       seller.ID,
+		}
+		require.NoError(t, db.Cards().Create(ctx, card))
+
+		now := time.Now().UTC()
+		lot := marketplace.Lot{
+			ID:         uuid.New(),
+			CardID:     card.ID,
+			Type:       marketplace.TypeCard,
+			SaleMode:   marketplace.SaleModeAuction,
+			UserID:     seller.ID,
+			Status:     marketplace.StatusActive,
+			StartPrice: *big.NewInt(500),
+			StartTime:  now,
+			EndTime:    now.Add(time.Hour),
+			Period:     marketplace.MinPeriod,
+		}
+		require.NoError(t, db.Marketplace().CreateLot(ctx, lot))
+
+		assertLot := func(t *testing.T, shopperID uuid.UUID, price int64) {
+			lotFromDB, err := db.Marketplace().GetLotByID(ctx, lot.ID)
+			require.NoError(t, err)
+			assert.Equal(t, shopperID, lotFromDB.ShopperID)
+			assert.Equal(t, big.NewInt(price).String(), lotFromDB.CurrentPrice.String())
+		}
+		assertHeld := func(t *testing.T, held ...int64) {
+			for i, bidder := range bidders {
+				amount, err := financesService.GetHeld(ctx, lot.ID, bidder.ID)
+				require.NoError(t, err)
+				assert.Equal(t, big.NewInt(held[i]).String(), amount.String())
+			}
+		}
+
+		t.Run("proxy bid", func(t *testing.T) {
+			err := bidsService.Create(ctx, bids.Bid{LotID: lot.ID, UserID: bidders[0].ID, MaxAmount: *big.NewInt(1000)})
+			require.NoError(t, err)
+
+			assertLot(t, bidders[0].ID, 500)
+			assertHeld(t, 1000, 0, 0)
+		})
+
+		t.Run("fixed bid is outbid automatically", func(t *testing.T) {
+			err := bidsService.Create(ctx, bids.Bid{LotID: lot.ID, UserID: bidders[1].ID, Amount: *big.NewInt(700)})
+			require.NoError(t, err)
+
+			assertLot(t, bidders[0].ID, 710)
+			assertHeld(t, 1000, 0, 0)
+		})
+
+		t.Run("higher proxy bid takes the lead", func(t *testing.T) {
+			err := bidsService.Create(ctx, bids.Bid{LotID: lot.ID, UserID: bidders[2].ID, MaxAmount: *big.NewInt(1500)})
+			require.NoError(t, err)
+
+			assertLot(t, bidders[2].ID, 1010)
+			assertHeld(t, 0, 0, 1500)
+		})
+
+		t.Run("earlier proxy bid wins the tie", func(t *testing.T) {
+			err := bidsService.Create(ctx, bids.Bid{LotID: lot.ID, UserID: bidders[0].ID, MaxAmount: *big.NewInt(1500)})
+			require.NoError(t, err)
+
+			assertLot(t, bidders[2].ID, 1500)
+			assertHeld(t, 0, 0, 1500)
+		})
+
+		t.Run("small bid", func(t *testing.T) {
+			err := bidsService.Create(ctx, bids.Bid{LotID: lot.ID, UserID: bidders[1].ID, Amount: *big.NewInt(1505)})
+			require.Error(t, err)
+			assert.True(t, errs.Is(err, bids.ErrSmallAmountOfBid))
+		})
+
+		t.Run("history", func(t *testing.T) {
+			history, err := bidsService.ListByLotID(ctx, lot.ID)
+			require.NoError(t, err)
+
+			expected := []struct {
+				bidder    int
+				amount    int64
+				automatic bool
+			}{{0, 500, true}, {1, 700, false}, {0, 710, true}, {0, 1000, true}, {2, 1010, true}, {2, 1500, true}}
+			require.Len(t, history, len(expected))
+			for i, bid := range history {
+				assert.Equal(t, bidders[expected[i].bidder].ID, bid.UserID)
+				assert.Equal(t, bidders[expected[i].bidder].NickName, bid.UserName)
+				assert.Equal(t, big.NewInt(expected[i].amount).String(), bid.Amount.String())
+				assert.Equal(t, expected[i].automatic, bid.Automatic)
+				assert.Equal(t, 0, bid.MaxAmount.Sign())
+			}
+		})
+
+		t.Run("max amount is visible to the owner", func(t *testing.T) {
+			proxyBid, err := bidsService.GetProxyBid(ctx, lot.ID, bidders[2].ID)
+			require.NoError(t, err)
+			assert.Equal(t, "1500", proxyBid.MaxAmount.String())
+
+			_, err = bidsService.GetProxyBid(ctx, lot.ID, bidders[1].ID)
+			require.Error(t, err)
+			assert.True(t, bids.ErrNoProxyBid.Has(err))
+		})
+
+		t.Run("max amount of the outbid bidder is deleted", func(t *testing.T) {
+			_, err := bidsService.GetProxyBid(ctx, lot.ID, bidders[0].ID)
+			require.Error(t, err)
+			assert.True(t, bids.ErrNoProxyBid.Has(err))
+		})
+
+		t.Run("placement resolved from another state", func(t *testing.T) {
+			err := db.Bids().Place(ctx, bids.Placement{LotID: lot.ID, EndTime: lot.EndTime})
+			require.Error(t, err)
+			assert.True(t, bids.ErrBidConflict.Has(err))
+
+			assertLot(t, bidders[2].ID, 1500)
+		})
+
+		t.Run("withdraw", func(t *testing.T) {
+			release, err := financesService.NewRelease(ctx, lot.ID, bidders[2].ID)
+			require.NoError(t, err)
+			require.NoError(t, db.Bids().Withdraw(ctx, release))
+
+			_, err = bidsService.GetProxyBid(ctx, lot.ID, bidders[2].ID)
+			require.Error(t, err)
+			assert.True(t, bids.ErrNoProxyBid.Has(err))
+			assertHeld(t, 0, 0, 0)
+		})
+	})
+}
+
+func TestCreateNearDeadline(t *testing.T) {
+	const biddersCount = 8
+	softClose := bids.SoftClose{Window: 5 * time.Minute, Extension: 2 * time.Minute, MaxExtension: 5 * time.Minute}
+
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db ultimatedivision.DB) {
+		usersService := users.NewService(db.Users())
+		cardsService := cards.NewService(db.Cards(), cards.Config{})
+		clubsService := clubs.NewService(db.Clubs(), usersService, cardsService, db.Divisions())
+		financesService := finances.NewService(db.Finances(), clubsService, cardsService, finances.Config{})
+		nftsService := nfts.NewService(nfts.Config{}, db.NFTs())
+		marketplaceService := marketplace.NewService(marketplace.Config{}, db.Marketplace(), usersService, cardsService, nftsService, financesService)
+		bidsService := bids.NewService(bids.Config{SoftClose: softClose}, db.Bids(), marketplaceService, cardsService, clubsService, nftsService, usersService, financesService)
+
+		seller := users.User{ID: uuid.New(), Email: "seller@example.com", PasswordHash: []byte{0}, NickName: "seller", CreatedAt: time.Now().UTC()}
+		require.NoError(t, db.Users().Create(ctx, seller))
+
+		bidders := make([]users.User, biddersCount)
+		for i := range bidders {
+			bidders[i] = users.User{
+				ID:           uuid.New(),
+				Email:        fmt.Sprintf("bidder%d@example.com", i),
+				PasswordHash: []byte{0},
+				NickName:     fmt.Sprintf("bidder%d", i),
+				CreatedAt:    time.Now().UTC(),
+			}
+			require.NoError(t, db.Users().Create(ctx, bidders[i]))
+		}
+
+		// every bidder has the active club with enough funds for any of the bids.
+		division := divisions.Division{ID: uuid.New(), Name: 10, PassingPercent: 10, CreatedAt: time.Now().UTC()}
+		require.NoError(t, db.Divisions().Create(ctx, division))
+
+		clubIDs := make([]uuid.UUID, biddersCount)
+		for i, bidder := range bidders {
+			club := clubs.Club{
+				ID:         uuid.New(),
+				OwnerID:    bidder.ID,
+				Name:       bidder.NickName,
+				Status:     clubs.StatusActive,
+				DivisionID: division.ID,
+				CreatedAt:  time.Now().UTC(),
+			}
+			_, err := db.Clubs().Create(ctx, club)
+			require.NoError(t, err)
+
+			_, err = db.Clubs().CreateSquad(ctx, clubs.Squad{ID: uuid.New(), ClubID: club.ID, Tactic: clubs.Balanced, Formation: clubs.FourFourTwo, IsActive: true})
+			require.NoError(t, err)
+
+			income := finances.NewTransfer(finances.TypeMatchIncome, "match", finances.AccountMatchIncome, finances.ClubAccount(club.ID), *big.NewInt(10000))
+			require.NoError(t, financesService.Record(ctx, income))
+			clubIDs[i] = club.ID
+		}
+
+		card := cards.Card{
+			ID:           uuid.New(),
+			PlayerName:   "Sniped",
+			Quality:      cards.QualityWood,
+			DominantFoot: "left",
+			Status:       cards.StatusActive,
+			Type:         cards.TypeWon,
+			UserID:       seller.ID,
+		}
+		require.NoError(t, db.Cards().Create(ctx, card))
+
+		// the lot period is almost over, so every bid is placed in the soft close window.
+		now := time.Now().UTC()
+		lot := marketplace.Lot{
+			ID:         uuid.New(),
+			CardID:     card.ID,
+			Type:       marketplace.TypeCard,
+			SaleMode:   marketplace.SaleModeAuction,
+			UserID:     seller.ID,
+			Status:     marketplace.StatusActive,
+			StartPrice: *big.NewInt(500),
+			StartTime:  now.Add(-59 * time.Minute),
+			EndTime:    now.Add(time.Minute),
+			Period:     marketplace.MinPeriod,
+		}
+		require.NoError(t, db.Marketplace().CreateLot(ctx, lot))
+
+		var group sync.WaitGroup
+		for i := range bidders {
+			group.Add(1)
+			go func(i int) {
+				defer group.Done()
+				err := bidsService.Create(ctx, bids.Bid{
+					LotID:  lot.ID,
+					UserID: bidders[i].ID,
+					Amount: *big.NewInt(int64(1000 + 100*i)),
+				})
+				if err != nil {
+					assert.True(t, errs.Is(err, bids.ErrSmallAmountOfBid), err)
+				}
+			}(i)
+		}
+		group.Wait()
+
+		placedBids, err := db.Bids().ListByLotID(ctx, lot.ID)
+		require.NoError(t, err)
+		require.NotEmpty(t, placedBids)
+
+		// accepted bids are strictly increasing in order of placing.
+		sort.Slice(placedBids, func(i, j int) bool {
+			return placedBids[i].CreatedAt.Before(placedBids[j].CreatedAt)
+		})
+		for i := 1; i < len(placedBids); i++ {
+			assert.Equal(t, 1, placedBids[i].Amount.Cmp(&placedBids[i-1].Amount))
+		}
+
+		lastBid := placedBids[len(placedBids)-1]
+		assert.Equal(t, "1700", lastBid.Amount.String())
+		assert.Equal(t, bidders[biddersCount-1].ID, lastBid.UserID)
+
+		lotFromDB, err := db.Marketplace().GetLotByID(ctx, lot.ID)
+		require.NoError(t, err)
+		assert.Equal(t, lastBid.Amount.String(), lotFromDB.CurrentPrice.String())
+		assert.Equal(t, lastBid.UserID, lotFromDB.ShopperID)
+
+		// every accepted bid extends the end time, but not further than the cap.
+		expectedEndTime := lot.EndTime.Add(time.Duration(len(placedBids)) * softClose.Extension)
+		if limit := lot.StartTime.Add(time.Hour + softClose.MaxExtension); expectedEndTime.After(limit) {
+			expectedEndTime = limit
+		}
+		assert.WithinDuration(t, expectedEndTime, lotFromDB.EndTime, time.Second)
+
+		// only the funds of the highest bid stay held, the funds of the outbid bidders are released.
+		for i, bidder := range bidders {
+			held, err := financesService.GetHeld(ctx, lot.ID, bidder.ID)
+			require.NoError(t, err)
+			balance, err := financesService.GetBalance(ctx, clubIDs[i])
+			require.NoError(t, err)
+
+			if bidder.ID == lastBid.UserID {
+				assert.Equal(t, lastBid.Amount.String(), held.String())
+				assert.Equal(t, "8300", balance.String())
+				continue
+			}
+			assert.Equal(t, "0", held.String())
+			assert.Equal(t, "10000", balance.String())
+		}
+
+		t.Run("bid more than balance", func(t *testing.T) {
+			err := bidsService.Create(ctx, bids.Bid{LotID: lot.ID, UserID: bidders[0].ID, Amount: *big.NewInt(20000)})
+			require.Error(t, err)
+			assert.True(t, finances.ErrInsufficientFunds.Has(err))
+
+			lotFromDB, err := db.Marketplace().GetLotByID(ctx, lot.ID)
+			require.NoError(t, err)
+			assert.Equal(t, lastBid.Amount.String(), lotFromDB.CurrentPrice.String())
+		})
+
+		t.Run("bid after end time", func(t *testing.T) {
+			require.NoError(t, db.Marketplace().UpdateEndTimeLot(ctx, lot.ID, time.Now().UTC().Add(-time.Second)))
+
+			err := bidsService.Create(ctx, bids.Bid{LotID: lot.ID, UserID: bidders[0].ID, Amount: *big.NewInt(5000)})
+			require.Error(t, err)
+			assert.False(t, errs.Is(err, bids.ErrSmallAmountOfBid))
+		})
+	})
+}
+
 func compareBids(t *testing.T, bid1, bid2 bids.Bid) {
 	assert.Equal(t, bid1.ID, bid2.ID)
 	assert.Equal(t, bid1.UserID, bid2.UserID)
 	assert.Equal(t, bid1.LotID, bid2.LotID)
 	assert.Equal(t, bid1.Amount, bid2.Amount)
+	assert.Equal(t, bid1.Automatic, bid2.Automatic)
 	assert.WithinDuration(t, bid1.CreatedAt, bid2.CreatedAt, 1*time.Second)
 }
@@ -17,6 +17,7 @@ import (
 	"ultimatedivision/cards"
 	"ultimatedivision/cards/nfts"
 	"ultimatedivision/clubs"
+	"ultimatedivision/finances"
 	"ultimatedivision/marketplace"
 	"ultimatedivision/users"
 )
@@ -32,28 +33,51 @@ var (
 //
 // architecture: Service
 type Service struct {
+	config      Config
 	bids        DB
 	marketplace *marketplace.Service
 	cards       *cards.Service
 	clubs       *clubs.Service
 	nfts        *nfts.Service
 	users       *users.Service
+	finances    *finances.Service
 }
 
 // NewService is constructor for Service.
-func NewService(bids DB, marketplace *marketplace.Service, cards *cards.Service, clubs *clubs.Service, nfts *nfts.Service, users *users.Service) *Service {
+func NewService(config Config, bids DB, marketplace *marketplace.Service, cards *cards.Service, clubs *clubs.Service, nfts *nfts.Service, users *users.Service, finances *finances.Service) *Service {
 	return &Service{
+		config:      config,
 		bids:        bids,
 		marketplace: marketplace,
 		cards:       cards,
 		clubs:       clubs,
 		nfts:        nfts,
 		users:       users,
+		finances:    finances,
 	}
 }
 
-// Create creates bid for lot in the database.
+// placeAttempts is the number of times the bid is resolved, the bid is resolved again
+// if the lot is changed by another bid while the bid is resolved.
+const placeAttempts = 10
+
+// Create places bid for lot, the bid with the max amount sets the hidden maximum up to which the system bids
+// on behalf of the user whenever the user is outbid. The competing maximums are resolved instantly: the highest
+// wins, the earlier wins the tie, and the winner becomes the shopper at the increment above the runner-up.
+// The maximum of the bidder is held from the funds of the bidder and the funds of the outbid bidders are released.
+// The bids placed in the soft close window extend the end time of the lot.
 func (service *Service) Create(ctx context.Context, bid Bid) error {
+	for attempt := 1; ; attempt++ {
+		err := service.place(ctx, bid)
+		if !ErrBidConflict.Has(err) || attempt == placeAttempts {
+			return err
+		}
+	}
+}
+
+// place resolves the bid against the current state of the lot and applies the changes
+// if the state is not changed meanwhile.
+func (service *Service) place(ctx context.Context, bid Bid) error {
 	lot, err := service.marketplace.GetLotByID(ctx, bid.LotID)
 	if err != nil {
 		return ErrBids.Wrap(err)
@@ -62,41 +86,114 @@ func (service *Service) Create(ctx context.Context, bid Bid) error {
 		return ErrBids.Wrap(err)
 	}
 
-	currentBid, err := service.GetCurrentBidByLotID(ctx, lot.CardID)
+	now := time.Now().UTC()
+	if lot.Status != marketplace.StatusActive || !now.Before(lot.EndTime) {
+		return ErrBids.New("lot is not active")
+	}
+	if lot.SaleMode != marketplace.SaleModeAuction {
+		return ErrBids.New("bids could be placed only at auction")
+	}
+
+	currentBid, err := service.GetCurrentBidByLotID(ctx, lot.ID)
 	if err != nil && !ErrNoBid.Has(err) {
 		return ErrBids.Wrap(err)
 	}
-	if ErrNoBid.Has(err) {
-		if bid.Amount.Cmp(&bid.Amount) < 0 {
-			return ErrSmallAmountOfBid
-		}
+
+	increment := service.config.Increment()
+	var minimum big.Int
+	if minimum.Set(&lot.StartPrice); !ErrNoBid.Has(err) {
+		minimum.Add(&currentBid.Amount, &increment)
 	}
 
-	if currentBid.Amount.Cmp(&bid.Amount) >= 0 {
+	maxAmount := bid.MaxAmount
+	if maxAmount.Cmp(&bid.Amount) < 0 {
+		maxAmount = bid.Amount
+	}
+	if maxAmount.Sign() <= 0 || maxAmount.Cmp(&minimum) < 0 || (bid.Amount.Sign() != 0 && bid.Amount.Cmp(&minimum) < 0) {
 		return ErrSmallAmountOfBid
 	}
 
-	// TODO: check if user have this amount of the money.
-	_, err = service.GetBidsAmountOnSpecificLot(ctx, bid.UserID, bid.LotID)
+	proxyBids, err := service.bids.ListProxyBidsByLotID(ctx, lot.ID)
 	if err != nil {
 		return ErrBids.Wrap(err)
 	}
 
-	bid = Bid{
-		ID:        uuid.New(),
-		LotID:     bid.LotID,
-		UserID:    bid.UserID,
-		Amount:    bid.Amount,
-		CreatedAt: time.Now().UTC(),
+	placement := Placement{
+		LotID:        lot.ID,
+		CurrentBidID: currentBid.ID,
+		ProxyBids:    proxyBids,
+		EndTime:      lot.EndTime,
 	}
-	if err = service.bids.Create(ctx, bid); err != nil {
-		return ErrBids.Wrap(err)
+
+	contenders := make(map[uuid.UUID]*Contender)
+	compete := func(userID uuid.UUID, maxAmount big.Int, since time.Time) {
+		contender, ok := contenders[userID]
+		if !ok {
+			contenders[userID] = &Contender{UserID: userID, MaxAmount: maxAmount, Since: since}
+			return
+		}
+		if cmp := maxAmount.Cmp(&contender.MaxAmount); cmp > 0 || (cmp == 0 && since.Before(contender.Since)) {
+			contender.MaxAmount, contender.Since = maxAmount, since
+		}
+	}
+	raised := bid.MaxAmount.Sign() > 0
+	for _, proxyBid := range proxyBids {
+		compete(proxyBid.UserID, proxyBid.MaxAmount, proxyBid.CreatedAt)
+		if proxyBid.UserID == bid.UserID && proxyBid.MaxAmount.Cmp(&maxAmount) >= 0 {
+			raised = false
+		}
+	}
+	if currentBid.UserID != uuid.Nil {
+		compete(currentBid.UserID, currentBid.Amount, currentBid.CreatedAt)
 	}
+	compete(bid.UserID, maxAmount, now)
+	contenders[bid.UserID].Amount = bid.Amount
 
-	if err = service.marketplace.UpdateCurrentPriceLot(ctx, bid.LotID, bid.Amount); err != nil {
+	if placement.Hold, err = service.finances.NewHold(ctx, lot.ID, bid.UserID, maxAmount); err != nil {
 		return ErrBids.Wrap(err)
 	}
-	return nil
+
+	list := make([]Contender, 0, len(contenders))
+	for _, contender := range contenders {
+		list = append(list, *contender)
+	}
+	winner, placedBids := Resolve(list, currentBid.UserID, currentBid.Amount, minimum, increment)
+
+	// the maximum of the outbid bidder is not stored, its funds are released right away.
+	if raised && winner.UserID == bid.UserID {
+		placement.ProxyBid = &ProxyBid{
+			LotID:     lot.ID,
+			UserID:    bid.UserID,
+			MaxAmount: maxAmount,
+			CreatedAt: now,
+		}
+	}
+
+	// only the funds and the maximum of the winner stay, its maximum was held when it took the lead.
+	for _, userID := range []uuid.UUID{bid.UserID, currentBid.UserID} {
+		if userID == uuid.Nil || userID == winner.UserID {
+			continue
+		}
+		release, err := service.finances.NewRelease(ctx, lot.ID, userID)
+		if err != nil {
+			return ErrBids.Wrap(err)
+		}
+		placement.Releases = append(placement.Releases, release)
+	}
+
+	for _, placedBid := range placedBids {
+		placedBid.ID = uuid.New()
+		placedBid.LotID = lot.ID
+		placedBid.CreatedAt = now
+		placement.Bids = append(placement.Bids, placedBid)
+	}
+
+	if len(placedBids) != 0 {
+		periodEnd := lot.StartTime.Add(time.Duration(lot.Period) * time.Hour)
+		placement.EndTime = service.config.SoftClose.ExtendedEndTime(now, lot.EndTime, periodEnd)
+	}
+
+	return ErrBids.Wrap(service.bids.Place(ctx, placement))
 }
 
 // GetMakeOfferData returns make offer data by card id from DB.
@@ -106,18 +203,31 @@ func (service *Service) GetMakeOfferData(ctx context.Context, cardID, userID uui
 		return nfts.MakeOffer{}, ErrBids.Wrap(err)
 	}
 
-	if err := service.marketplace.UpdateShopperIDLot(ctx, cardID, userID); err != nil {
-		log.Error(fmt.Sprintf("could not update update shopper id by card id equal %v in db", cardID), ErrBids.Wrap(err))
+	lot, err := service.marketplace.GetActiveLotByCardID(ctx, cardID)
+	if err != nil {
+		log.Error(fmt.Sprintf("could not get lot by card id equal %v from db", cardID), ErrBids.Wrap(err))
 	}
-	if err = service.marketplace.Delete(ctx, cardID); err != nil {
-		log.Error(fmt.Sprintf("could not delete lot by card id equal %v in db", cardID), ErrBids.Wrap(err))
+
+	// the offer is paid on chain, so the funds held for the bids are not needed anymore
+	// and the system must not bid on behalf of the shopper with the returned funds.
+	if lot.ShopperID != uuid.Nil {
+		if err = service.withdraw(ctx, lot.ID, lot.ShopperID); err != nil {
+			log.Error(fmt.Sprintf("could not release funds held by user id equal %v in db", lot.ShopperID), ErrBids.Wrap(err))
+		}
 	}
-	if err = service.cards.UpdateStatus(ctx, cardID, cards.StatusActive); err != nil {
+
+	if err := service.marketplace.UpdateShopperIDLot(ctx, lot.ID, userID); err != nil {
+		log.Error(fmt.Sprintf("could not update update shopper id by lot id equal %v in db", lot.ID), ErrBids.Wrap(err))
+	}
+	if err = service.marketplace.Delete(ctx, lot.ID); err != nil {
+		log.Error(fmt.Sprintf("could not delete lot by id equal %v in db", lot.ID), ErrBids.Wrap(err))
+	}
+	if err = service.cards.UpdateStatus(ctx, cardID, cards.StatusActive, cards.Event{Cause: cards.CauseMarketplace}); err != nil {
 		log.Error(fmt.Sprintf("could not update card status by card id equal %v in db", cardID), ErrBids.Wrap(err))
 	}
 
-	if err = service.bids.DeleteByLotID(ctx, cardID); err != nil {
-		log.Error(fmt.Sprintf("could not delete bids by card id equal %v in db", cardID), ErrBids.Wrap(err))
+	if err = service.bids.DeleteByLotID(ctx, lot.ID); err != nil {
+		log.Error(fmt.Sprintf("could not delete bids by lot id equal %v in db", lot.ID), ErrBids.Wrap(err))
 	}
 
 	squadID, err := service.clubs.GetSquadIDByCardID(ctx, cardID)
@@ -136,7 +246,12 @@ func (service *Service) GetMakeOfferData(ctx context.Context, cardID, userID uui
 		log.Error(fmt.Sprintf("could not get user by user id equal %v from db", userID), ErrBids.Wrap(err))
 	}
 
-	if err = service.cards.UpdateUserID(ctx, cardID, userID); err != nil {
+	event := cards.Event{
+		Cause:          cards.CauseMarketplace,
+		CounterpartyID: lot.UserID,
+		Price:          lot.CurrentPrice,
+	}
+	if err = service.cards.UpdateUserID(ctx, cardID, userID, event); err != nil {
 		log.Error(fmt.Sprintf("could not get update user id of the card lot id equal %v in db", cardID), ErrBids.Wrap(err))
 	}
 
@@ -163,6 +278,22 @@ func (service *Service) GetMakeOfferData(ctx context.Context, cardID, userID uui
 	return tokenIDWithContractAddress, ErrBids.Wrap(err)
 }
 
+// withdraw deletes maximum amount of the user on the lot and returns the funds held for it.
+func (service *Service) withdraw(ctx context.Context, lotID, userID uuid.UUID) error {
+	release, err := service.finances.NewRelease(ctx, lotID, userID)
+	if err != nil {
+		return err
+	}
+
+	return service.bids.Withdraw(ctx, release)
+}
+
+// GetProxyBid returns maximum amount of the user on the lot, the maximums are visible only to their owners.
+func (service *Service) GetProxyBid(ctx context.Context, lotID, userID uuid.UUID) (ProxyBid, error) {
+	proxyBid, err := service.bids.GetProxyBid(ctx, lotID, userID)
+	return proxyBid, ErrBids.Wrap(err)
+}
+
 // GetCurrentBidByLotID returns current bid by lot id from the database.
 func (service *Service) GetCurrentBidByLotID(ctx context.Context, lotID uuid.UUID) (Bid, error) {
 	currentAmount, err := service.bids.GetCurrentBidByLotID(ctx, lotID)
@@ -0,0 +1,98 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package marketplace
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/google/uuid"
+
+	"ultimatedivision/cards"
+	"ultimatedivision/finances"
+)
+
+// MaxBasisPoints defines basis points of the whole price, one basis point is 0.01% of the price.
+const MaxBasisPoints = 10000
+
+// FeeRates defines parts of the price in basis points which are paid to the marketplace and to the creator of the item.
+type FeeRates struct {
+	Commission int64 `json:"commission"`
+	Royalty    int64 `json:"royalty"`
+}
+
+// Validate checks that fee rates are not negative and do not exceed the price together.
+func (rates FeeRates) Validate() error {
+	if rates.Commission < 0 || rates.Royalty < 0 || rates.Commission+rates.Royalty > MaxBasisPoints {
+		return ErrMarketplace.New("fee rates %d and %d are not correct", rates.Commission, rates.Royalty)
+	}
+
+	return nil
+}
+
+// Apply returns fees of the sale at the price, fees are rounded down.
+func (rates FeeRates) Apply(price big.Int) finances.Fees {
+	var fees finances.Fees
+	fees.Commission.Mul(&price, big.NewInt(rates.Commission))
+	fees.Commission.Quo(&fees.Commission, big.NewInt(MaxBasisPoints))
+	fees.Royalty.Mul(&price, big.NewInt(rates.Royalty))
+	fees.Royalty.Quo(&fees.Royalty, big.NewInt(MaxBasisPoints))
+
+	return fees
+}
+
+// Fees defines fee rates of the lots by their type, rates of the card quality override the rates of the card lots.
+type Fees struct {
+	ByType    map[Type]FeeRates          `json:"byType"`
+	ByQuality map[cards.Quality]FeeRates `json:"byQuality"`
+}
+
+// Rates returns fee rates of the lot with the item of the quality, quality is empty for the items which are not cards.
+func (fees Fees) Rates(lotType Type, quality cards.Quality) FeeRates {
+	if rates, ok := fees.ByQuality[quality]; ok && lotType == TypeCard {
+		return rates
+	}
+
+	return fees.ByType[lotType]
+}
+
+// GetFees returns fee rates of the marketplace.
+func (service *Service) GetFees() Fees {
+	return service.config.Fees
+}
+
+// LotFees returns fees of the sale of the lot item at the price. Royalty is paid to the user who got the card
+// when it was created.
+func (service *Service) LotFees(ctx context.Context, lotType Type, itemID uuid.UUID, price big.Int) (finances.Fees, error) {
+	var quality cards.Quality
+	if lotType == TypeCard {
+		card, err := service.cards.Get(ctx, itemID)
+		if err != nil {
+			return finances.Fees{}, ErrMarketplace.Wrap(err)
+		}
+		quality = card.Quality
+	}
+
+	rates := service.config.Fees.Rates(lotType, quality)
+	if err := rates.Validate(); err != nil {
+		return finances.Fees{}, err
+	}
+
+	fees := rates.Apply(price)
+	if fees.Royalty.Sign() > 0 && lotType == TypeCard {
+		history, err := service.cards.ListHistoryByCardID(ctx, itemID)
+		if err != nil {
+			return finances.Fees{}, ErrMarketplace.Wrap(err)
+		}
+
+		for _, record := range history {
+			if record.Kind == cards.HistoryKindCreated {
+				fees.CreatorID = record.UserID
+				break
+			}
+		}
+	}
+
+	return fees, nil
+}
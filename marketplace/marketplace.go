@@ -12,6 +12,8 @@ import (
 	"github.com/zeebo/errs"
 
 	"ultimatedivision/cards"
+	"ultimatedivision/cards/nfts"
+	"ultimatedivision/finances"
 	"ultimatedivision/pkg/pagination"
 	"ultimatedivision/users"
 )
@@ -19,23 +21,47 @@ import (
 // ErrNoLot indicated that lot does not exist.
 var ErrNoLot = errs.Class("lot does not exist")
 
+// ErrInvalidBundle indicates that the cards could not be sold together in one lot.
+var ErrInvalidBundle = errs.Class("invalid bundle")
+
+// ErrSettlementConflict indicates that the lot is not in the expected settlement state, it was moved by another process.
+var ErrSettlementConflict = errs.Class("lot settlement state conflict")
+
+// ErrLotNotAvailable indicates that the lot could not be bought by the user, it is not active anymore,
+// it is led by another shopper or the user is its seller.
+var ErrLotNotAvailable = errs.Class("lot is not available")
+
+// ErrCardNotAvailable indicates that the card could not be put on sale, it is already on sale, retired
+// or it is not owned by the seller anymore.
+var ErrCardNotAvailable = errs.Class("card is not available")
+
 // DB is exposing access to lots db.
 //
 // architecture: DB
 type DB interface {
-	// CreateLot add lot in the data base.
-	CreateLot(ctx context.Context, lot Lot) error
+	// CreateLot adds lot in the data base and puts its cards on sale with the records of the card history.
+	CreateLot(ctx context.Context, lot Lot, history ...cards.History) error
 	// GetLotByID returns lot by id from the data base.
 	GetLotByID(ctx context.Context, id uuid.UUID) (Lot, error)
+	// GetActiveLotByCardID returns active lot which holds the card from the data base.
+	GetActiveLotByCardID(ctx context.Context, cardID uuid.UUID) (Lot, error)
+	// ListLotCards returns cards of the lots by lot id from the data base in the order they were added to the lots.
+	ListLotCards(ctx context.Context, lotIDs []uuid.UUID) (map[uuid.UUID][]cards.Card, error)
 	// GetLotEndTimeByID returns lot end time by id from data base.
 	GetLotEndTimeByID(ctx context.Context, id uuid.UUID) (time.Time, error)
 	// GetCurrentPriceByCardID returns current price by card id from the data base.
 	GetCurrentPriceByCardID(ctx context.Context, cardID uuid.UUID) (big.Int, error)
-	// ListActiveLots returns active lots from the data base.
-	ListActiveLots(ctx context.Context, cursor pagination.Cursor) (Page, error)
-	// ListActiveLotsByCardID returns active lots from the data base by card id.
-	ListActiveLotsByCardID(ctx context.Context, cardIds []uuid.UUID, cursor pagination.Cursor) (Page, error)
-	// ListExpiredLot returns lots where end time lower than or equal to time now UTC from the data base.
+	// ListActiveLots returns active lots of the sale mode from the data base, lots of all modes are returned if mode is empty.
+	ListActiveLots(ctx context.Context, saleMode SaleMode, cursor pagination.Cursor) (Page, error)
+	// ListActiveLotsByCardID returns active lots of the sale mode which hold any of the cards from the data base.
+	ListActiveLotsByCardID(ctx context.Context, cardIds []uuid.UUID, saleMode SaleMode, cursor pagination.Cursor) (Page, error)
+	// ListActiveLotsWithQuery returns active lots of the sale mode which cards match the query from the data base.
+	ListActiveLotsWithQuery(ctx context.Context, query cards.Query, saleMode SaleMode, cursor pagination.Cursor) (Page, error)
+	// ListActiveLotsWithQuerySince returns active lots of the sale mode which cards match the query and which were
+	// started after the time from the data base.
+	ListActiveLotsWithQuerySince(ctx context.Context, query cards.Query, saleMode SaleMode, since time.Time, cursor pagination.Cursor) (Page, error)
+	// ListExpiredLot returns lots where end time lower than or equal to time now UTC and the bought lots which are not
+	// settled yet from the data base.
 	ListExpiredLot(ctx context.Context) ([]Lot, error)
 	// UpdateShopperIDLot updates shopper id of lot in the database.
 	UpdateShopperIDLot(ctx context.Context, id, shopperID uuid.UUID) error
@@ -45,24 +71,53 @@ type DB interface {
 	UpdateCurrentPriceLot(ctx context.Context, id uuid.UUID, currentPrice big.Int) error
 	// UpdateEndTimeLot updates end time of lot in the database.
 	UpdateEndTimeLot(ctx context.Context, id uuid.UUID, endTime time.Time) error
+	// UpdateSettlementLot moves lot from one settlement state to another in the database.
+	UpdateSettlementLot(ctx context.Context, id uuid.UUID, from, to SettlementState, finalListingHash string) error
+	// BuyLot claims the active lot for the shopper together with the funds of the purchase in one transaction
+	// and moves lot to the pending final listing state, returns ErrLotNotAvailable if the lot is already claimed.
+	BuyLot(ctx context.Context, purchase Purchase) error
+	// SettleLot applies all changes of the settlement in one transaction and moves lot to the db settled state.
+	SettleLot(ctx context.Context, settlement Settlement) error
+	// ListSettledLots returns lots which are settled in the database.
+	ListSettledLots(ctx context.Context) ([]Lot, error)
 	// Delete deletes lot in the database.
-	Delete(ctx context.Context, cardID uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// CreateSale adds sale in the database.
+	CreateSale(ctx context.Context, sale Sale) error
+	// ListSales returns page of sales which match the filter from the database ordered by time.
+	ListSales(ctx context.Context, filter SalesFilter, cursor pagination.Cursor) (SalesPage, error)
+	// ListPriceStats returns price stats of the sales which match the filter by qualities and rating bands from the database.
+	ListPriceStats(ctx context.Context, filter SalesFilter) ([]PriceStats, error)
+	// ListVolumes returns volumes of the sales which match the filter by the intervals from the database.
+	ListVolumes(ctx context.Context, filter SalesFilter, interval time.Duration) ([]Volume, error)
 }
 
 // Lot describes lot entity.
 type Lot struct {
-	CardID       uuid.UUID  `json:"cardId"`
-	Type         Type       `json:"type"`
-	UserID       uuid.UUID  `json:"userId"`
-	ShopperID    uuid.UUID  `json:"shopperId"`
-	Status       Status     `json:"status"`
-	StartPrice   big.Int    `json:"startPrice"`
-	MaxPrice     big.Int    `json:"maxPrice"`
-	CurrentPrice big.Int    `json:"currentPrice"`
-	StartTime    time.Time  `json:"startTime"`
-	EndTime      time.Time  `json:"endTime"`
-	Period       Period     `json:"period"`
-	Card         cards.Card `json:"card"`
+	ID uuid.UUID `json:"id"`
+	// CardID is the first card of the lot, it is the card which represents the lot in the lists.
+	CardID       uuid.UUID `json:"cardId"`
+	Type         Type      `json:"type"`
+	SaleMode     SaleMode  `json:"saleMode"`
+	UserID       uuid.UUID `json:"userId"`
+	ShopperID    uuid.UUID `json:"shopperId"`
+	Status       Status    `json:"status"`
+	StartPrice   big.Int   `json:"startPrice"`
+	MaxPrice     big.Int   `json:"maxPrice"`
+	FloorPrice   big.Int   `json:"floorPrice"`
+	CurrentPrice big.Int   `json:"currentPrice"`
+	StartTime    time.Time `json:"startTime"`
+	EndTime      time.Time `json:"endTime"`
+	Period       Period    `json:"period"`
+	// Settlement is the step of the settlement of the expired lot, it is empty while the lot is not expired.
+	Settlement SettlementState `json:"settlement"`
+	// FinalListingHash is the hash of the deploy which finished the listing of the lot on chain.
+	FinalListingHash string     `json:"finalListingHash"`
+	Card             cards.Card `json:"card"`
+	// Cards are all cards of the lot, the bundle is sold with all of them at once.
+	Cards []cards.Card `json:"cards"`
+	// Scouting is estimated range of the card potential, it is nil if the card was never scouted.
+	Scouting *cards.ScoutingReport `json:"scouting,omitempty"`
 }
 
 // Type defines the list of possible lot types.
@@ -71,8 +126,121 @@ type Type string
 const (
 	// TypeCard indicates that lot type is card.
 	TypeCard Type = "card"
+	// TypeBundle indicates that lot is a bundle of several cards.
+	TypeBundle Type = "bundle"
+)
+
+// MaxBundleCards defines the max number of the cards in the bundle lot.
+const MaxBundleCards = 10
+
+// CardIDs returns ids of all cards of the lot, the lot without loaded cards holds only its first card.
+func (lot Lot) CardIDs() []uuid.UUID {
+	if len(lot.Cards) == 0 {
+		return []uuid.UUID{lot.CardID}
+	}
+
+	ids := make([]uuid.UUID, len(lot.Cards))
+	for i, card := range lot.Cards {
+		ids[i] = card.ID
+	}
+	return ids
+}
+
+// SaleMode defines the list of possible ways the lot is sold.
+type SaleMode string
+
+const (
+	// SaleModeAuction indicates that the lot is sold to the highest bidder at the end time,
+	// or instantly at the max price if it is set.
+	SaleModeAuction SaleMode = "auction"
+	// SaleModeFixedPrice indicates that the lot is sold instantly at the start price to the first buyer.
+	SaleModeFixedPrice SaleMode = "fixedPrice"
+	// SaleModeDutch indicates that the price of the lot decays from the start price to the floor price
+	// over the period and the lot is sold instantly at the current price to the first buyer.
+	SaleModeDutch SaleMode = "dutch"
+)
+
+// IsValid checks if sale mode is valid.
+func (saleMode SaleMode) IsValid() bool {
+	return saleMode == SaleModeAuction || saleMode == SaleModeFixedPrice || saleMode == SaleModeDutch
+}
+
+// BuyNowPrice returns the price for which the lot could be bought instantly at the moment,
+// false is returned if the lot could not be bought instantly.
+func (lot Lot) BuyNowPrice(now time.Time) (big.Int, bool) {
+	switch lot.SaleMode {
+	case SaleModeFixedPrice:
+		return lot.StartPrice, true
+	case SaleModeDutch:
+		if !now.After(lot.StartTime) {
+			return lot.StartPrice, true
+		}
+		if !now.Before(lot.EndTime) {
+			return lot.FloorPrice, true
+		}
+
+		// price = start - (start - floor) * elapsed / duration.
+		var decay big.Int
+		decay.Sub(&lot.StartPrice, &lot.FloorPrice)
+		decay.Mul(&decay, big.NewInt(int64(now.Sub(lot.StartTime))))
+		decay.Quo(&decay, big.NewInt(int64(lot.EndTime.Sub(lot.StartTime))))
+
+		var price big.Int
+		price.Sub(&lot.StartPrice, &decay)
+		return price, true
+	default:
+		return lot.MaxPrice, lot.MaxPrice.BitLen() != 0
+	}
+}
+
+// SettlementState defines the list of possible steps of the settlement of the expired lot.
+// Each step is retried until it succeeds, so the lot is moved to the next state only after the step is done.
+type SettlementState string
+
+const (
+	// SettlementNone indicates that the settlement of the lot is not started.
+	SettlementNone SettlementState = ""
+	// SettlementPendingFinalListing indicates that the lot is expired and waits for the final listing on chain.
+	SettlementPendingFinalListing SettlementState = "pendingFinalListing"
+	// SettlementOnChainConfirmed indicates that the final listing of the lot is accepted on chain,
+	// and the lot waits for the settlement in the database.
+	SettlementOnChainConfirmed SettlementState = "onChainConfirmed"
+	// SettlementDBSettled indicates that the status of the lot, the owner of the item and the payment
+	// were changed in the database in one transaction.
+	SettlementDBSettled SettlementState = "dbSettled"
 )
 
+// Settlement describes changes of the database which settle the expired lot.
+type Settlement struct {
+	LotID   uuid.UUID
+	CardIDs []uuid.UUID
+	// Status is the final status of the lot, the cards are removed from the squads of the seller if they are sold
+	// or bought now.
+	Status  Status
+	OwnerID uuid.UUID
+	History []cards.History
+	// NFTs are the nfts of the minted cards with the wallet of the owner.
+	NFTs []nfts.NFT
+	// Payment pays the price of the sold lot from the funds held by the shopper, it is nil if nothing is paid.
+	Payment *finances.Payment
+	// Sale is the record of the sale for the price history, it is nil if the card is not sold
+	// and for the bundles, which price does not tell the price of the single card.
+	Sale *Sale
+}
+
+// Purchase describes the instant purchase of the lot, the lot is claimed only if it is still active and led
+// by the previous shopper, the price is held from the funds of the shopper and the funds of the previous shopper
+// are returned. The cards and the funds are settled afterwards like the cards and the funds of the expired lot.
+type Purchase struct {
+	LotID             uuid.UUID
+	ShopperID         uuid.UUID
+	PreviousShopperID uuid.UUID
+	Price             big.Int
+	Hold              finances.Escrow
+	// Release returns the funds of the previous shopper, it is nil if there is no other shopper.
+	Release *finances.Escrow
+}
+
 // Status defines the list of possible lot statuses.
 type Status string
 
@@ -116,40 +284,52 @@ type Config struct {
 
 	ContractOwnerPrivateKey string `json:"contractOwnerPrivateKey"`
 	ContractOwnerPublicKey  string `json:"contractOwnerPublicKey"`
+
+	Fees Fees `json:"fees"`
+
+	// AnalyticsPeriod defines how old sales are used to suggest prices, all sales are used if it is not set.
+	AnalyticsPeriod time.Duration `json:"analyticsPeriod"`
 }
 
 // CreateLot entity that contains the values required to create the lot.
+// The lot with several cards in CardIDs is the bundle, CardID is used if CardIDs is empty.
 type CreateLot struct {
-	CardID     uuid.UUID `json:"cardId"`
-	Type       Type      `json:"type"`
-	UserID     uuid.UUID `json:"userId"`
-	StartPrice big.Int   `json:"startPrice"`
-	MaxPrice   big.Int   `json:"maxPrice"`
-	Period     Period    `json:"period"`
+	CardID     uuid.UUID   `json:"cardId"`
+	CardIDs    []uuid.UUID `json:"cardIds"`
+	Type       Type        `json:"type"`
+	SaleMode   SaleMode    `json:"saleMode"`
+	UserID     uuid.UUID   `json:"userId"`
+	StartPrice big.Int     `json:"startPrice"`
+	MaxPrice   big.Int     `json:"maxPrice"`
+	FloorPrice big.Int     `json:"floorPrice"`
+	Period     Period      `json:"period"`
 }
 
-// BetLot entity that contains the values required to place bet the lot.
-type BetLot struct {
-	CardID    uuid.UUID `json:"cardId"`
-	UserID    uuid.UUID `json:"userId"`
-	BetAmount big.Int   `json:"betAmount"`
-}
-
-// WinLot entity that contains the values required to win the lot.
-type WinLot struct {
-	CardID    uuid.UUID `json:"cardId"`
-	Type      Type      `json:"type"`
-	UserID    uuid.UUID `json:"userId"`
-	ShopperID uuid.UUID `json:"shopperID"`
-	Status    Status    `json:"status"`
-	Amount    big.Int   `json:"amount"`
+// BuyLot entity that contains the values required to buy the lot instantly.
+type BuyLot struct {
+	LotID  uuid.UUID `json:"lotId"`
+	UserID uuid.UUID `json:"userId"`
 }
 
 // ValidateCreateLot check is empty fields of create lot entity.
 func (createLot CreateLot) ValidateCreateLot() error {
-	if createLot.CardID.String() == "" {
+	cardIDs := createLot.ItemIDs()
+	if len(cardIDs) == 1 && cardIDs[0] == uuid.Nil {
 		return ErrMarketplace.New("item id is empty")
 	}
+	if len(cardIDs) > MaxBundleCards {
+		return ErrInvalidBundle.New("bundle could hold at most %d cards", MaxBundleCards)
+	}
+	added := make(map[uuid.UUID]bool, len(cardIDs))
+	for _, cardID := range cardIDs {
+		if cardID == uuid.Nil {
+			return ErrInvalidBundle.New("card id is empty")
+		}
+		if added[cardID] {
+			return ErrInvalidBundle.New("card %s is added to the bundle twice", cardID)
+		}
+		added[cardID] = true
+	}
 
 	if createLot.StartPrice.BitLen() == 0 {
 		return ErrMarketplace.New("start price is empty")
@@ -159,26 +339,76 @@ func (createLot CreateLot) ValidateCreateLot() error {
 		return ErrMarketplace.New("period is empty")
 	}
 
-	return nil
-}
-
-// ValidateBetLot check is empty fields of bet lot entity.
-func (betLot BetLot) ValidateBetLot() error {
-	if betLot.CardID.String() == "" {
-		return ErrMarketplace.New("lot id is empty")
+	if createLot.Period < MinPeriod || createLot.Period > MaxPeriod {
+		return ErrMarketplace.New("period exceed the range from %d to %d hours", MinPeriod, MaxPeriod)
 	}
 
-	if betLot.BetAmount.BitLen() == 0 {
-		return ErrMarketplace.New("bet amount is empty")
+	switch createLot.SaleMode {
+	case SaleModeAuction, "":
+		if createLot.FloorPrice.BitLen() != 0 {
+			return ErrMarketplace.New("auction could not have floor price")
+		}
+		if createLot.MaxPrice.BitLen() != 0 && createLot.MaxPrice.Cmp(&createLot.StartPrice) == -1 {
+			return ErrMarketplace.New("max price less start price")
+		}
+	case SaleModeFixedPrice:
+		if createLot.MaxPrice.BitLen() != 0 || createLot.FloorPrice.BitLen() != 0 {
+			return ErrMarketplace.New("fixed price lot could have only start price")
+		}
+	case SaleModeDutch:
+		if createLot.MaxPrice.BitLen() != 0 {
+			return ErrMarketplace.New("dutch auction could not have max price")
+		}
+		if createLot.FloorPrice.BitLen() == 0 {
+			return ErrMarketplace.New("floor price is empty")
+		}
+		if createLot.FloorPrice.Cmp(&createLot.StartPrice) != -1 {
+			return ErrMarketplace.New("floor price must be less than start price")
+		}
+	default:
+		return ErrMarketplace.New("sale mode is not correct")
 	}
 
 	return nil
 }
 
+// ItemIDs returns ids of the cards of the lot.
+func (createLot CreateLot) ItemIDs() []uuid.UUID {
+	if len(createLot.CardIDs) == 0 {
+		return []uuid.UUID{createLot.CardID}
+	}
+
+	return createLot.CardIDs
+}
+
 // ResponseCreateLot entity describes the values required to response for create lot in admin.
 type ResponseCreateLot struct {
 	Cards cards.Page
 	Users []users.User
+	// Fees are fee rates which are charged from the price of the sale, they are shown before the lot is created.
+	Fees Fees
+	// SuggestedPrices are median prices of the similar cards by card id.
+	SuggestedPrices map[uuid.UUID]*big.Int
+}
+
+// ResponseEconomy entity describes the values required to response for the economy health view in admin.
+type ResponseEconomy struct {
+	Stats   []PriceStats
+	Volumes []Volume
+	Revenue Revenue
+}
+
+// Revenue entity describes the funds received by the marketplace from the sales.
+type Revenue struct {
+	Commission big.Int `json:"commission"`
+	Royalties  big.Int `json:"royalties"`
+}
+
+// ResponseGetLot entity describes the values required to response for get lot in admin.
+type ResponseGetLot struct {
+	Lot Lot
+	// Fees are fees of the sale at the current price of the lot.
+	Fees finances.Fees
 }
 
 // ResponsePlaceBetLot entity describes the values required to response for place bet lot in admin.
@@ -15,9 +15,13 @@ import (
 
 	"ultimatedivision"
 	"ultimatedivision/cards"
+	"ultimatedivision/clubs"
 	"ultimatedivision/database/dbtesting"
+	"ultimatedivision/divisions"
+	"ultimatedivision/finances"
 	"ultimatedivision/marketplace"
 	"ultimatedivision/pkg/pagination"
+	"ultimatedivision/pkg/sqlsearchoperators"
 	"ultimatedivision/users"
 )
 
@@ -92,7 +96,7 @@ func TestMarketplace(t *testing.T) {
 		Weight:           73.3,
 		DominantFoot:     "right",
 		IsTattoo:         true,
-		Status:           cards.StatusSale,
+		Status:           cards.StatusActive,
 		UserID:           uuid.New(),
 		Tactics:          2,
 		Positioning:      2,
@@ -146,8 +150,10 @@ func TestMarketplace(t *testing.T) {
 	}
 
 	lot1 := marketplace.Lot{
+		ID:           uuid.New(),
 		CardID:       card1.ID,
 		Type:         marketplace.TypeCard,
+		SaleMode:     marketplace.SaleModeAuction,
 		UserID:       uuid.New(),
 		ShopperID:    uuid.New(),
 		Status:       marketplace.StatusSoldBuynow,
@@ -160,11 +166,14 @@ func TestMarketplace(t *testing.T) {
 	}
 
 	lot2 := marketplace.Lot{
+		ID:           uuid.New(),
 		CardID:       uuid.New(),
 		Type:         marketplace.TypeCard,
+		SaleMode:     marketplace.SaleModeDutch,
 		UserID:       uuid.New(),
 		Status:       marketplace.StatusActive,
 		StartPrice:   *big.NewInt(500000000000000),
+		FloorPrice:   *big.NewInt(100000000000000),
 		CurrentPrice: *big.NewInt(2500000000000000),
 		StartTime:    time.Now().UTC(),
 		EndTime:      time.Now().AddDate(0, 0, 1).UTC(),
@@ -224,13 +233,28 @@ func TestMarketplace(t *testing.T) {
 			err = repositoryMarketplace.CreateLot(ctx, lot1)
 			require.NoError(t, err)
 
-			lotFromDB, err := repositoryMarketplace.GetLotByID(ctx, lot1.CardID)
+			lotFromDB, err := repositoryMarketplace.GetLotByID(ctx, lot1.ID)
 			require.NoError(t, err)
 			compareLot(t, lot1, lotFromDB)
+
+			cardFromDB, err := repositoryCards.Get(ctx, card1.ID)
+			require.NoError(t, err)
+			assert.Equal(t, cards.StatusSale, cardFromDB.Status)
+		})
+
+		t.Run("create lot with card on sale", func(t *testing.T) {
+			lot := lot1
+			lot.ID = uuid.New()
+			err := repositoryMarketplace.CreateLot(ctx, lot)
+			require.Error(t, err)
+			assert.True(t, marketplace.ErrCardNotAvailable.Has(err))
+
+			_, err = repositoryMarketplace.GetLotByID(ctx, lot.ID)
+			assert.True(t, marketplace.ErrNoLot.Has(err))
 		})
 
 		t.Run("get lot end time", func(t *testing.T) {
-			endTime, err := repositoryMarketplace.GetLotEndTimeByID(ctx, lot1.CardID)
+			endTime, err := repositoryMarketplace.GetLotEndTimeByID(ctx, lot1.ID)
 			require.NoError(t, err)
 			assert.WithinDuration(t, lot1.EndTime, endTime, 1*time.Second)
 		})
@@ -248,30 +272,58 @@ func TestMarketplace(t *testing.T) {
 			err = repositoryMarketplace.CreateLot(ctx, lot2)
 			require.NoError(t, err)
 
-			activeLots, err := repositoryMarketplace.ListActiveLots(ctx, cursor1)
+			activeLots, err := repositoryMarketplace.ListActiveLots(ctx, "", cursor1)
 			assert.NoError(t, err)
 			assert.Equal(t, len(activeLots.Lots), 1)
 			compareLot(t, lot2, activeLots.Lots[0])
 		})
 
+		t.Run("list active by sale mode", func(t *testing.T) {
+			activeLots, err := repositoryMarketplace.ListActiveLots(ctx, marketplace.SaleModeDutch, cursor1)
+			require.NoError(t, err)
+			assert.Equal(t, 1, len(activeLots.Lots))
+			assert.Equal(t, 1, activeLots.Page.TotalCount)
+			compareLot(t, lot2, activeLots.Lots[0])
+
+			activeLots, err = repositoryMarketplace.ListActiveLots(ctx, marketplace.SaleModeFixedPrice, cursor1)
+			require.NoError(t, err)
+			assert.Equal(t, 0, len(activeLots.Lots))
+			assert.Equal(t, 0, activeLots.Page.TotalCount)
+		})
+
 		t.Run("list active by card id", func(t *testing.T) {
 			var cardsIds []uuid.UUID
 			cardsIds = append(cardsIds, card1.ID)
 			cardsIds = append(cardsIds, card2.ID)
 
-			activeLots, err := repositoryMarketplace.ListActiveLotsByCardID(ctx, cardsIds, cursor1)
+			activeLots, err := repositoryMarketplace.ListActiveLotsByCardID(ctx, cardsIds, "", cursor1)
 			assert.NoError(t, err)
 			assert.Equal(t, len(activeLots.Lots), 1)
 			compareLot(t, lot2, activeLots.Lots[0])
 		})
 
+		t.Run("list active with query", func(t *testing.T) {
+			query := cards.Query{
+				Where: &cards.Condition{
+					Filter: &cards.Filters{Name: cards.FilterPrice, Value: "1000000000000000,5000000000000000", SearchOperator: sqlsearchoperators.BETWEEN},
+				},
+				Sort: []cards.Sort{{Name: cards.FilterPrice, Order: cards.OrderDesc}},
+			}
+			require.NoError(t, query.Validate())
+
+			activeLots, err := repositoryMarketplace.ListActiveLotsWithQuery(ctx, query, "", cursor1)
+			require.NoError(t, err)
+			assert.Equal(t, len(activeLots.Lots), 1)
+			compareLot(t, lot2, activeLots.Lots[0])
+		})
+
 		t.Run("list expired lot", func(t *testing.T) {
 			lot1.EndTime = time.Now().UTC()
-			err := repositoryMarketplace.UpdateEndTimeLot(ctx, lot1.CardID, lot1.EndTime)
+			err := repositoryMarketplace.UpdateEndTimeLot(ctx, lot1.ID, lot1.EndTime)
 			require.NoError(t, err)
 
 			lot1.Status = marketplace.StatusActive
-			err = repositoryMarketplace.UpdateStatusLot(ctx, lot1.CardID, marketplace.StatusActive)
+			err = repositoryMarketplace.UpdateStatusLot(ctx, lot1.ID, marketplace.StatusActive)
 			require.NoError(t, err)
 
 			activeLots, err := repositoryMarketplace.ListExpiredLot(ctx)
@@ -288,10 +340,10 @@ func TestMarketplace(t *testing.T) {
 
 		t.Run("update shopperID of lot", func(t *testing.T) {
 			lot1.ShopperID = uuid.New()
-			err := repositoryMarketplace.UpdateShopperIDLot(ctx, lot1.CardID, lot1.ShopperID)
+			err := repositoryMarketplace.UpdateShopperIDLot(ctx, lot1.ID, lot1.ShopperID)
 			require.NoError(t, err)
 
-			lotFromDB, err := repositoryMarketplace.GetLotByID(ctx, lot1.CardID)
+			lotFromDB, err := repositoryMarketplace.GetLotByID(ctx, lot1.ID)
 			require.NoError(t, err)
 			compareLot(t, lot1, lotFromDB)
 		})
@@ -304,10 +356,10 @@ func TestMarketplace(t *testing.T) {
 
 		t.Run("update status of lot", func(t *testing.T) {
 			lot1.Status = marketplace.StatusExpired
-			err := repositoryMarketplace.UpdateStatusLot(ctx, lot1.CardID, marketplace.StatusExpired)
+			err := repositoryMarketplace.UpdateStatusLot(ctx, lot1.ID, marketplace.StatusExpired)
 			require.NoError(t, err)
 
-			lotFromDB, err := repositoryMarketplace.GetLotByID(ctx, lot1.CardID)
+			lotFromDB, err := repositoryMarketplace.GetLotByID(ctx, lot1.ID)
 			require.NoError(t, err)
 			compareLot(t, lot1, lotFromDB)
 		})
@@ -320,10 +372,10 @@ func TestMarketplace(t *testing.T) {
 
 		t.Run("update current price of lot", func(t *testing.T) {
 			lot1.CurrentPrice = *big.NewInt(2500000000000000)
-			err := repositoryMarketplace.UpdateCurrentPriceLot(ctx, lot1.CardID, *big.NewInt(2500000000000000))
+			err := repositoryMarketplace.UpdateCurrentPriceLot(ctx, lot1.ID, *big.NewInt(2500000000000000))
 			require.NoError(t, err)
 
-			lotFromDB, err := repositoryMarketplace.GetLotByID(ctx, lot1.CardID)
+			lotFromDB, err := repositoryMarketplace.GetLotByID(ctx, lot1.ID)
 			require.NoError(t, err)
 			compareLot(t, lot1, lotFromDB)
 		})
@@ -342,27 +394,467 @@ func TestMarketplace(t *testing.T) {
 
 		t.Run("update end time of lot", func(t *testing.T) {
 			lot1.EndTime = time.Now().UTC().Add(time.Hour)
-			err := repositoryMarketplace.UpdateEndTimeLot(ctx, lot1.CardID, lot1.EndTime)
+			err := repositoryMarketplace.UpdateEndTimeLot(ctx, lot1.ID, lot1.EndTime)
+			require.NoError(t, err)
+
+			lotFromDB, err := repositoryMarketplace.GetLotByID(ctx, lot1.ID)
+			require.NoError(t, err)
+			compareLot(t, lot1, lotFromDB)
+		})
+
+		t.Run("update settlement of lot conflict", func(t *testing.T) {
+			err := repositoryMarketplace.UpdateSettlementLot(ctx, lot1.ID, marketplace.SettlementPendingFinalListing, marketplace.SettlementOnChainConfirmed, "")
+			require.Error(t, err)
+			assert.True(t, marketplace.ErrSettlementConflict.Has(err))
+		})
+
+		t.Run("settle lot not confirmed on chain", func(t *testing.T) {
+			err := repositoryMarketplace.SettleLot(ctx, marketplace.Settlement{LotID: lot1.ID, CardIDs: []uuid.UUID{card1.ID}, Status: marketplace.StatusSold, OwnerID: user2.ID})
+			require.Error(t, err)
+			assert.True(t, marketplace.ErrSettlementConflict.Has(err))
+
+			cardFromDB, err := repositoryCards.Get(ctx, card1.ID)
+			require.NoError(t, err)
+			assert.Equal(t, user1.ID, cardFromDB.UserID)
+		})
+
+		t.Run("update settlement of lot", func(t *testing.T) {
+			err := repositoryMarketplace.UpdateSettlementLot(ctx, lot1.ID, marketplace.SettlementNone, marketplace.SettlementPendingFinalListing, "")
 			require.NoError(t, err)
 
-			lotFromDB, err := repositoryMarketplace.GetLotByID(ctx, lot1.CardID)
+			// the hash of the sent deploy is kept while the deploy is pending.
+			err = repositoryMarketplace.UpdateSettlementLot(ctx, lot1.ID, marketplace.SettlementPendingFinalListing, marketplace.SettlementPendingFinalListing, "hash")
+			require.NoError(t, err)
+			lotFromDB, err := repositoryMarketplace.GetLotByID(ctx, lot1.ID)
+			require.NoError(t, err)
+			assert.Equal(t, marketplace.SettlementPendingFinalListing, lotFromDB.Settlement)
+			assert.Equal(t, "hash", lotFromDB.FinalListingHash)
+
+			err = repositoryMarketplace.UpdateSettlementLot(ctx, lot1.ID, marketplace.SettlementPendingFinalListing, marketplace.SettlementOnChainConfirmed, "hash")
+			require.NoError(t, err)
+			lot1.Settlement = marketplace.SettlementOnChainConfirmed
+			lot1.FinalListingHash = "hash"
+
+			lotFromDB, err = repositoryMarketplace.GetLotByID(ctx, lot1.ID)
 			require.NoError(t, err)
 			compareLot(t, lot1, lotFromDB)
 		})
 
+		t.Run("settle lot", func(t *testing.T) {
+			// the sold card is the captain of the squad of the seller and has the instruction.
+			division := divisions.Division{ID: uuid.New(), Name: 10, PassingPercent: 10, CreatedAt: time.Now().UTC()}
+			require.NoError(t, db.Divisions().Create(ctx, division))
+			club := clubs.Club{ID: uuid.New(), OwnerID: user1.ID, Name: user1.NickName, Status: clubs.StatusActive, DivisionID: division.ID, CreatedAt: time.Now().UTC()}
+			_, err := db.Clubs().Create(ctx, club)
+			require.NoError(t, err)
+			squadID, err := db.Clubs().CreateSquad(ctx, clubs.Squad{ID: uuid.New(), ClubID: club.ID, Tactic: clubs.Balanced, Formation: clubs.FourFourTwo, IsActive: true})
+			require.NoError(t, err)
+			require.NoError(t, db.Clubs().UpdateTacticCaptain(ctx, clubs.Squad{ID: squadID, Tactic: clubs.Balanced, CaptainID: card1.ID}))
+			require.NoError(t, db.Clubs().UpsertPlayerInstruction(ctx, clubs.PlayerInstruction{SquadID: squadID, CardID: card1.ID, Instruction: clubs.InstructionStayBack}))
+
+			card1.UserID = user2.ID
+			payment := finances.NewTransfer(finances.TypeTransfer, "lot", finances.AccountTransfers, finances.AccountMatchIncome, lot1.CurrentPrice)
+			settlement := marketplace.Settlement{
+				LotID:   lot1.ID,
+				CardIDs: []uuid.UUID{card1.ID},
+				Status:  marketplace.StatusSold,
+				OwnerID: user2.ID,
+				History: []cards.History{
+					cards.NewHistory(card1, cards.HistoryKindOwnership, user1.ID.String(), user2.ID.String(), cards.Event{Cause: cards.CauseMarketplace}),
+				},
+				Payment: &finances.Payment{
+					Transactions: []finances.Transaction{payment},
+					Escrow:       finances.Escrow{LotID: lot1.ID, UserID: user2.ID, Account: finances.AccountTransfers},
+				},
+			}
+			sale := marketplace.NewSale(card1, lot1.CurrentPrice, time.Now().UTC())
+			settlement.Sale = &sale
+			err = repositoryMarketplace.SettleLot(ctx, settlement)
+			require.NoError(t, err)
+			lot1.Status = marketplace.StatusSold
+			lot1.Settlement = marketplace.SettlementDBSettled
+
+			lotFromDB, err := repositoryMarketplace.GetLotByID(ctx, lot1.ID)
+			require.NoError(t, err)
+			compareLot(t, lot1, lotFromDB)
+
+			cardFromDB, err := repositoryCards.Get(ctx, card1.ID)
+			require.NoError(t, err)
+			assert.Equal(t, user2.ID, cardFromDB.UserID)
+			assert.Equal(t, cards.StatusActive, cardFromDB.Status)
+
+			history, err := repositoryCards.ListHistoryByCardID(ctx, card1.ID)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(history))
+			assert.Equal(t, settlement.History[0].ID, history[0].ID)
+
+			entries, err := db.Finances().ListEntriesByAccount(ctx, finances.AccountMatchIncome)
+			require.NoError(t, err)
+			balance := finances.Balance(entries)
+			assert.Equal(t, lot1.CurrentPrice.String(), balance.String())
+
+			settledLots, err := repositoryMarketplace.ListSettledLots(ctx)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(settledLots))
+			compareLot(t, lot1, settledLots[0])
+
+			captainID, err := db.Clubs().GetCaptainID(ctx, squadID)
+			require.NoError(t, err)
+			assert.Equal(t, uuid.Nil, captainID)
+
+			instructions, err := db.Clubs().ListPlayerInstructions(ctx, squadID)
+			require.NoError(t, err)
+			assert.Empty(t, instructions)
+		})
+
+		t.Run("list sales", func(t *testing.T) {
+			sale := marketplace.NewSale(card2, *big.NewInt(500), time.Now().UTC())
+			err := repositoryMarketplace.CreateSale(ctx, sale)
+			require.NoError(t, err)
+
+			cursor := pagination.Cursor{Limit: 10, Page: 1}
+			salesPage, err := repositoryMarketplace.ListSales(ctx, marketplace.SalesFilter{}, cursor)
+			require.NoError(t, err)
+			require.Equal(t, 2, len(salesPage.Sales))
+			assert.Equal(t, 2, salesPage.Page.TotalCount)
+			assert.Equal(t, card1.ID, salesPage.Sales[0].CardID)
+			assert.Equal(t, lot1.CurrentPrice.String(), salesPage.Sales[0].Price.String())
+			compareSale(t, sale, salesPage.Sales[1])
+
+			salesPage, err = repositoryMarketplace.ListSales(ctx, marketplace.SalesFilter{}, pagination.Cursor{Limit: 1, Page: 2})
+			require.NoError(t, err)
+			require.Equal(t, 1, len(salesPage.Sales))
+			assert.Equal(t, 2, salesPage.Page.PageCount)
+			compareSale(t, sale, salesPage.Sales[0])
+
+			band := marketplace.NewRatingBand(card2.Rating())
+			salesPage, err = repositoryMarketplace.ListSales(ctx, marketplace.SalesFilter{Quality: card2.Quality, Band: &band}, cursor)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(salesPage.Sales))
+			compareSale(t, sale, salesPage.Sales[0])
+
+			salesPage, err = repositoryMarketplace.ListSales(ctx, marketplace.SalesFilter{Since: time.Now().UTC().Add(time.Hour)}, cursor)
+			require.NoError(t, err)
+			assert.Equal(t, 0, len(salesPage.Sales))
+		})
+
+		t.Run("settle lot twice", func(t *testing.T) {
+			err := repositoryMarketplace.SettleLot(ctx, marketplace.Settlement{LotID: lot1.ID, CardIDs: []uuid.UUID{card1.ID}, Status: marketplace.StatusExpired, OwnerID: user1.ID})
+			require.Error(t, err)
+			assert.True(t, marketplace.ErrSettlementConflict.Has(err))
+		})
+
+		t.Run("bundle", func(t *testing.T) {
+			card3, card4 := card1, card1
+			card3.ID, card4.ID = uuid.New(), uuid.New()
+			card3.UserID, card4.UserID = user1.ID, user1.ID
+			require.NoError(t, repositoryCards.Create(ctx, card3))
+			require.NoError(t, repositoryCards.Create(ctx, card4))
+
+			bundle := lot2
+			bundle.ID = uuid.New()
+			bundle.CardID = card3.ID
+			bundle.Type = marketplace.TypeBundle
+			bundle.UserID = user1.ID
+			bundle.Cards = []cards.Card{card3, card4}
+			require.NoError(t, repositoryMarketplace.CreateLot(ctx, bundle))
+
+			lotCards, err := repositoryMarketplace.ListLotCards(ctx, []uuid.UUID{bundle.ID, lot1.ID})
+			require.NoError(t, err)
+			require.Equal(t, 2, len(lotCards[bundle.ID]))
+			assert.Equal(t, card3.ID, lotCards[bundle.ID][0].ID)
+			assert.Equal(t, card4.ID, lotCards[bundle.ID][1].ID)
+			require.Equal(t, 1, len(lotCards[lot1.ID]))
+			assert.Equal(t, card1.ID, lotCards[lot1.ID][0].ID)
+
+			lotFromDB, err := repositoryMarketplace.GetActiveLotByCardID(ctx, card4.ID)
+			require.NoError(t, err)
+			compareLot(t, bundle, lotFromDB)
+
+			_, err = repositoryMarketplace.GetActiveLotByCardID(ctx, card1.ID)
+			assert.True(t, marketplace.ErrNoLot.Has(err))
+
+			activeLots, err := repositoryMarketplace.ListActiveLotsByCardID(ctx, []uuid.UUID{card4.ID}, "", cursor1)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(activeLots.Lots))
+			compareLot(t, bundle, activeLots.Lots[0])
+
+			err = repositoryMarketplace.UpdateSettlementLot(ctx, bundle.ID, marketplace.SettlementNone, marketplace.SettlementOnChainConfirmed, "")
+			require.NoError(t, err)
+
+			settlement := marketplace.Settlement{
+				LotID:   bundle.ID,
+				CardIDs: []uuid.UUID{card3.ID, card4.ID},
+				Status:  marketplace.StatusSold,
+				OwnerID: user2.ID,
+			}
+			require.NoError(t, repositoryMarketplace.SettleLot(ctx, settlement))
+
+			for _, cardID := range settlement.CardIDs {
+				cardFromDB, err := repositoryCards.Get(ctx, cardID)
+				require.NoError(t, err)
+				assert.Equal(t, user2.ID, cardFromDB.UserID)
+				assert.Equal(t, cards.StatusActive, cardFromDB.Status)
+			}
+		})
+
+		t.Run("buy lot", func(t *testing.T) {
+			card5 := card1
+			card5.ID = uuid.New()
+			card5.UserID = user1.ID
+			require.NoError(t, repositoryCards.Create(ctx, card5))
+
+			lot := lot2
+			lot.ID = uuid.New()
+			lot.CardID = card5.ID
+			lot.SaleMode = marketplace.SaleModeFixedPrice
+			lot.UserID = user1.ID
+			lot.Cards = []cards.Card{card5}
+			require.NoError(t, repositoryMarketplace.CreateLot(ctx, lot))
+
+			account := finances.ClubAccount(uuid.New())
+			funding := finances.NewTransfer(finances.TypeTransfer, "funding", finances.AccountTransfers, account, *big.NewInt(1000))
+			require.NoError(t, db.Finances().Create(ctx, funding))
+
+			purchase := marketplace.Purchase{
+				LotID:     lot.ID,
+				ShopperID: user2.ID,
+				Price:     *big.NewInt(600),
+				Hold:      finances.Escrow{LotID: lot.ID, UserID: user2.ID, Account: account, Amount: *big.NewInt(600)},
+			}
+			require.NoError(t, repositoryMarketplace.BuyLot(ctx, purchase))
+
+			err := repositoryMarketplace.BuyLot(ctx, purchase)
+			require.Error(t, err)
+			assert.True(t, marketplace.ErrLotNotAvailable.Has(err))
+
+			lot.Status = marketplace.StatusSoldBuynow
+			lot.ShopperID = user2.ID
+			lot.CurrentPrice = purchase.Price
+			lot.Settlement = marketplace.SettlementPendingFinalListing
+			lotFromDB, err := repositoryMarketplace.GetLotByID(ctx, lot.ID)
+			require.NoError(t, err)
+			compareLot(t, lot, lotFromDB)
+
+			expiredLots, err := repositoryMarketplace.ListExpiredLot(ctx)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(expiredLots))
+			compareLot(t, lot, expiredLots[0])
+
+			entries, err := db.Finances().ListEntriesByAccount(ctx, finances.EscrowAccount(lot.ID, user2.ID))
+			require.NoError(t, err)
+			held := finances.Balance(entries)
+			assert.Equal(t, "600", held.String())
+
+			entries, err = db.Finances().ListEntriesByAccount(ctx, account)
+			require.NoError(t, err)
+			balance := finances.Balance(entries)
+			assert.Equal(t, "400", balance.String())
+		})
 	})
 }
 
+func TestBuyNowPrice(t *testing.T) {
+	startTime := time.Now().UTC()
+	lot := marketplace.Lot{
+		StartPrice: *big.NewInt(1000),
+		FloorPrice: *big.NewInt(200),
+		StartTime:  startTime,
+		EndTime:    startTime.Add(10 * time.Hour),
+	}
+
+	t.Run("auction without max price", func(t *testing.T) {
+		lot.SaleMode = marketplace.SaleModeAuction
+		_, ok := lot.BuyNowPrice(startTime)
+		assert.False(t, ok)
+	})
+
+	t.Run("fixed price", func(t *testing.T) {
+		lot.SaleMode = marketplace.SaleModeFixedPrice
+		price, ok := lot.BuyNowPrice(startTime.Add(5 * time.Hour))
+		require.True(t, ok)
+		assert.Equal(t, "1000", price.String())
+	})
+
+	t.Run("dutch", func(t *testing.T) {
+		lot.SaleMode = marketplace.SaleModeDutch
+		for elapsed, expected := range map[time.Duration]string{
+			-time.Hour:       "1000",
+			0:                "1000",
+			5 * time.Hour:    "600",
+			9 * time.Hour:    "280",
+			10 * time.Hour:   "200",
+			1000 * time.Hour: "200",
+		} {
+			price, ok := lot.BuyNowPrice(startTime.Add(elapsed))
+			require.True(t, ok)
+			assert.Equal(t, expected, price.String(), elapsed)
+		}
+	})
+}
+
+func TestFeeRates(t *testing.T) {
+	rates := marketplace.FeeRates{Commission: 250, Royalty: 125}
+	require.NoError(t, rates.Validate())
+
+	fees := rates.Apply(*big.NewInt(1999))
+	assert.Equal(t, "49", fees.Commission.String())
+	assert.Equal(t, "24", fees.Royalty.String())
+
+	for _, invalid := range []marketplace.FeeRates{
+		{Commission: -1},
+		{Royalty: -1},
+		{Commission: marketplace.MaxBasisPoints, Royalty: 1},
+	} {
+		assert.Error(t, invalid.Validate(), invalid)
+	}
+}
+
+func TestFeesRates(t *testing.T) {
+	fees := marketplace.Fees{
+		ByType: map[marketplace.Type]marketplace.FeeRates{
+			marketplace.TypeCard: {Commission: 250, Royalty: 100},
+		},
+		ByQuality: map[cards.Quality]marketplace.FeeRates{
+			cards.QualityDiamond: {Commission: 150, Royalty: 300},
+		},
+	}
+
+	assert.Equal(t, marketplace.FeeRates{Commission: 250, Royalty: 100}, fees.Rates(marketplace.TypeCard, cards.QualityWood))
+	assert.Equal(t, marketplace.FeeRates{Commission: 150, Royalty: 300}, fees.Rates(marketplace.TypeCard, cards.QualityDiamond))
+	assert.Equal(t, marketplace.FeeRates{}, fees.Rates(marketplace.Type("box"), ""))
+}
+
+func TestNewRatingBand(t *testing.T) {
+	for rating, expected := range map[float64]marketplace.RatingBand{
+		0:     0,
+		9.99:  0,
+		10:    10,
+		57.5:  50,
+		99.9:  90,
+		100.0: 100,
+	} {
+		assert.Equal(t, expected, marketplace.NewRatingBand(rating), rating)
+	}
+}
+
+func TestSalesAnalytics(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db ultimatedivision.DB) {
+		repositoryMarketplace := db.Marketplace()
+		day := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+
+		for _, price := range []int64{70, 10, 100, 40, 20, 90, 30, 60, 50, 80} {
+			sale := marketplace.Sale{ID: uuid.New(), CardID: uuid.New(), Quality: cards.QualityGold, RatingBand: 50, Price: *big.NewInt(price), SoldAt: day.Add(100 * time.Hour)}
+			require.NoError(t, repositoryMarketplace.CreateSale(ctx, sale))
+		}
+		for i, price := range []int64{30, 10, 20} {
+			soldAt := []time.Duration{50 * time.Hour, time.Hour, 23 * time.Hour}[i]
+			sale := marketplace.Sale{ID: uuid.New(), CardID: uuid.New(), Quality: cards.QualityWood, Price: *big.NewInt(price), SoldAt: day.Add(soldAt)}
+			require.NoError(t, repositoryMarketplace.CreateSale(ctx, sale))
+		}
+
+		t.Run("list price stats", func(t *testing.T) {
+			stats, err := repositoryMarketplace.ListPriceStats(ctx, marketplace.SalesFilter{})
+			require.NoError(t, err)
+			require.Equal(t, 2, len(stats))
+			assert.Equal(t, cards.QualityWood, stats[0].Quality)
+			assert.Equal(t, 3, stats[0].Count)
+
+			stat := stats[1]
+			assert.Equal(t, cards.QualityGold, stat.Quality)
+			assert.Equal(t, marketplace.RatingBand(50), stat.RatingBand)
+			assert.Equal(t, 10, stat.Count)
+			assert.Equal(t, "10", stat.Min.String())
+			assert.Equal(t, "30", stat.P25.String())
+			assert.Equal(t, "50", stat.Median.String())
+			assert.Equal(t, "80", stat.P75.String())
+			assert.Equal(t, "90", stat.P90.String())
+			assert.Equal(t, "100", stat.Max.String())
+
+			stats, err = repositoryMarketplace.ListPriceStats(ctx, marketplace.SalesFilter{Quality: cards.QualityDiamond})
+			require.NoError(t, err)
+			assert.Equal(t, 0, len(stats))
+		})
+
+		t.Run("list volumes", func(t *testing.T) {
+			volumes, err := repositoryMarketplace.ListVolumes(ctx, marketplace.SalesFilter{Quality: cards.QualityWood}, 24*time.Hour)
+			require.NoError(t, err)
+			require.Equal(t, 2, len(volumes))
+			assert.Equal(t, day, volumes[0].From)
+			assert.Equal(t, 2, volumes[0].Count)
+			assert.Equal(t, "30", volumes[0].Amount.String())
+			assert.Equal(t, day.Add(48*time.Hour), volumes[1].From)
+			assert.Equal(t, 1, volumes[1].Count)
+			assert.Equal(t, "30", volumes[1].Amount.String())
+		})
+	})
+}
+
+func TestValidateCreateLot(t *testing.T) {
+	valid := []marketplace.CreateLot{
+		{CardID: uuid.New(), StartPrice: *big.NewInt(100), MaxPrice: *big.NewInt(200), Period: marketplace.MinPeriod},
+		{CardID: uuid.New(), SaleMode: marketplace.SaleModeFixedPrice, StartPrice: *big.NewInt(100), Period: marketplace.MaxPeriod},
+		{CardID: uuid.New(), SaleMode: marketplace.SaleModeDutch, StartPrice: *big.NewInt(100), FloorPrice: *big.NewInt(10), Period: 24},
+		{CardIDs: []uuid.UUID{uuid.New(), uuid.New()}, StartPrice: *big.NewInt(100), Period: 1},
+	}
+	for _, createLot := range valid {
+		assert.NoError(t, createLot.ValidateCreateLot(), createLot.SaleMode)
+	}
+
+	invalid := []marketplace.CreateLot{
+		{CardID: uuid.New(), StartPrice: *big.NewInt(100), Period: marketplace.MaxPeriod + 1},
+		{CardID: uuid.New(), StartPrice: *big.NewInt(100), MaxPrice: *big.NewInt(50), Period: 1},
+		{CardID: uuid.New(), StartPrice: *big.NewInt(100), FloorPrice: *big.NewInt(50), Period: 1},
+		{CardID: uuid.New(), SaleMode: marketplace.SaleModeFixedPrice, StartPrice: *big.NewInt(100), MaxPrice: *big.NewInt(200), Period: 1},
+		{CardID: uuid.New(), SaleMode: marketplace.SaleModeDutch, StartPrice: *big.NewInt(100), Period: 1},
+		{CardID: uuid.New(), SaleMode: marketplace.SaleModeDutch, StartPrice: *big.NewInt(100), FloorPrice: *big.NewInt(100), Period: 1},
+		{CardID: uuid.New(), SaleMode: "english", StartPrice: *big.NewInt(100), Period: 1},
+		{StartPrice: *big.NewInt(100), Period: 1},
+	}
+	for _, createLot := range invalid {
+		assert.Error(t, createLot.ValidateCreateLot(), createLot)
+	}
+
+	cardID := uuid.New()
+	bundles := [][]uuid.UUID{
+		{cardID, cardID},
+		{cardID, uuid.Nil},
+		make([]uuid.UUID, marketplace.MaxBundleCards+1),
+	}
+	for _, cardIDs := range bundles {
+		createLot := marketplace.CreateLot{CardIDs: cardIDs, StartPrice: *big.NewInt(100), Period: 1}
+		assert.True(t, marketplace.ErrInvalidBundle.Has(createLot.ValidateCreateLot()), cardIDs)
+	}
+}
+
 func compareLot(t *testing.T, lot1, lot2 marketplace.Lot) {
+	assert.Equal(t, lot1.ID, lot2.ID)
 	assert.Equal(t, lot1.CardID, lot2.CardID)
 	assert.Equal(t, lot1.Type, lot2.Type)
+	assert.Equal(t, lot1.SaleMode, lot2.SaleMode)
 	assert.Equal(t, lot1.UserID, lot2.UserID)
 	assert.Equal(t, lot1.ShopperID, lot2.ShopperID)
 	assert.Equal(t, lot1.Status, lot2.Status)
 	assert.Equal(t, lot1.StartPrice, lot2.StartPrice)
 	assert.Equal(t, lot1.MaxPrice, lot2.MaxPrice)
+	assert.Equal(t, lot1.FloorPrice.String(), lot2.FloorPrice.String())
 	assert.Equal(t, lot1.CurrentPrice, lot2.CurrentPrice)
 	assert.WithinDuration(t, lot1.StartTime, lot2.StartTime, 1*time.Second)
 	assert.WithinDuration(t, lot1.EndTime, lot2.EndTime, 1*time.Second)
 	assert.Equal(t, lot1.Period, lot2.Period)
+	assert.Equal(t, lot1.Settlement, lot2.Settlement)
+	assert.Equal(t, lot1.FinalListingHash, lot2.FinalListingHash)
+}
+
+func compareSale(t *testing.T, sale1, sale2 marketplace.Sale) {
+	assert.Equal(t, sale1.ID, sale2.ID)
+	assert.Equal(t, sale1.CardID, sale2.CardID)
+	assert.Equal(t, sale1.Quality, sale2.Quality)
+	assert.InDelta(t, sale1.Rating, sale2.Rating, 0.01)
+	assert.Equal(t, sale1.RatingBand, sale2.RatingBand)
+	assert.Equal(t, sale1.Tactics, sale2.Tactics)
+	assert.Equal(t, sale1.Goalkeeping, sale2.Goalkeeping)
+	assert.Equal(t, sale1.Age, sale2.Age)
+	assert.Equal(t, sale1.Price.String(), sale2.Price.String())
+	assert.WithinDuration(t, sale1.SoldAt, sale2.SoldAt, 1*time.Second)
 }
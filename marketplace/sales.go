@@ -0,0 +1,202 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package marketplace
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/cards"
+	"ultimatedivision/finances"
+	"ultimatedivision/pkg/pagination"
+)
+
+// ErrNoSale indicates that there are no sales to analyze.
+var ErrNoSale = errs.Class("sale does not exist")
+
+// ErrInvalidInterval indicates that interval of the volumes is not valid.
+var ErrInvalidInterval = errs.Class("invalid interval")
+
+// RatingBandWidth defines the width of the rating band, sales are analyzed by the bands of the card ratings.
+const RatingBandWidth = 10
+
+// RatingBand defines the lowest rating of the band of the card ratings.
+type RatingBand int
+
+// NewRatingBand returns the band of the rating.
+func NewRatingBand(rating float64) RatingBand {
+	return RatingBand(int(rating) / RatingBandWidth * RatingBandWidth)
+}
+
+// Sale describes sold card entity, it holds the values of the card at the moment of the sale.
+type Sale struct {
+	ID          uuid.UUID     `json:"id"`
+	CardID      uuid.UUID     `json:"cardId"`
+	Quality     cards.Quality `json:"quality"`
+	Rating      float64       `json:"rating"`
+	RatingBand  RatingBand    `json:"ratingBand"`
+	Tactics     int           `json:"tactics"`
+	Physique    int           `json:"physique"`
+	Technique   int           `json:"technique"`
+	Offence     int           `json:"offence"`
+	Defence     int           `json:"defence"`
+	Goalkeeping int           `json:"goalkeeping"`
+	Age         int           `json:"age"`
+	Price       big.Int       `json:"price"`
+	SoldAt      time.Time     `json:"soldAt"`
+}
+
+// NewSale returns sale of the card at the price.
+func NewSale(card cards.Card, price big.Int, soldAt time.Time) Sale {
+	rating := card.Rating()
+
+	return Sale{
+		ID:          uuid.New(),
+		CardID:      card.ID,
+		Quality:     card.Quality,
+		Rating:      rating,
+		RatingBand:  NewRatingBand(rating),
+		Tactics:     card.Tactics,
+		Physique:    card.Physique,
+		Technique:   card.Technique,
+		Offence:     card.Offence,
+		Defence:     card.Defence,
+		Goalkeeping: card.Goalkeeping,
+		Age:         card.Age,
+		Price:       price,
+		SoldAt:      soldAt,
+	}
+}
+
+// SalesFilter defines sales to analyze, empty quality and nil band match sales of all qualities and bands.
+type SalesFilter struct {
+	Quality cards.Quality
+	Band    *RatingBand
+	Since   time.Time
+}
+
+// PriceStats describes prices of the sales of the cards of the quality and rating band.
+type PriceStats struct {
+	Quality    cards.Quality `json:"quality"`
+	RatingBand RatingBand    `json:"ratingBand"`
+	Count      int           `json:"count"`
+	Min        big.Int       `json:"min"`
+	P25        big.Int       `json:"p25"`
+	Median     big.Int       `json:"median"`
+	P75        big.Int       `json:"p75"`
+	P90        big.Int       `json:"p90"`
+	Max        big.Int       `json:"max"`
+}
+
+// Volume describes sales made during the interval which starts from the time,
+// intervals are counted from the unix epoch.
+type Volume struct {
+	From   time.Time `json:"from"`
+	Count  int       `json:"count"`
+	Amount big.Int   `json:"amount"`
+}
+
+// SalesPage holds sales page entity which is used to show listed page of sales.
+type SalesPage struct {
+	Sales []Sale          `json:"sales"`
+	Page  pagination.Page `json:"page"`
+}
+
+// ListSales returns page of sales which match the filter.
+func (service *Service) ListSales(ctx context.Context, filter SalesFilter, cursor pagination.Cursor) (SalesPage, error) {
+	if cursor.Limit <= 0 {
+		cursor.Limit = service.config.Cursor.Limit
+	}
+	if cursor.Page <= 0 {
+		cursor.Page = service.config.Cursor.Page
+	}
+
+	salesPage, err := service.marketplace.ListSales(ctx, filter, cursor)
+	return salesPage, ErrMarketplace.Wrap(err)
+}
+
+// ListPriceStats returns price stats of the sales which match the filter by qualities and rating bands.
+func (service *Service) ListPriceStats(ctx context.Context, filter SalesFilter) ([]PriceStats, error) {
+	stats, err := service.marketplace.ListPriceStats(ctx, filter)
+	return stats, ErrMarketplace.Wrap(err)
+}
+
+// ListVolumes returns volumes of the sales which match the filter by the intervals.
+func (service *Service) ListVolumes(ctx context.Context, filter SalesFilter, interval time.Duration) ([]Volume, error) {
+	if interval <= 0 {
+		return nil, ErrInvalidInterval.New("interval must be positive")
+	}
+
+	volumes, err := service.marketplace.ListVolumes(ctx, filter, interval)
+	return volumes, ErrMarketplace.Wrap(err)
+}
+
+// GetRevenue returns the commissions and royalties received by the marketplace.
+func (service *Service) GetRevenue(ctx context.Context) (Revenue, error) {
+	commission, err := service.finances.GetAccountBalance(ctx, finances.AccountCommission)
+	if err != nil {
+		return Revenue{}, ErrMarketplace.Wrap(err)
+	}
+
+	royalties, err := service.finances.GetAccountBalance(ctx, finances.AccountRoyalties)
+	if err != nil {
+		return Revenue{}, ErrMarketplace.Wrap(err)
+	}
+
+	return Revenue{Commission: commission, Royalties: royalties}, nil
+}
+
+// SuggestedPrice returns the median price of the cards of the same quality and rating band as the card
+// sold during the analytics period.
+func (service *Service) SuggestedPrice(ctx context.Context, cardID uuid.UUID) (big.Int, error) {
+	card, err := service.cards.Get(ctx, cardID)
+	if err != nil {
+		return big.Int{}, ErrMarketplace.Wrap(err)
+	}
+
+	band := NewRatingBand(card.Rating())
+	stats, err := service.ListPriceStats(ctx, SalesFilter{Quality: card.Quality, Band: &band, Since: service.analyticsSince()})
+	if err != nil {
+		return big.Int{}, err
+	}
+	if len(stats) == 0 {
+		return big.Int{}, ErrNoSale.New("there are no sales of %s cards with rating %d", card.Quality, band)
+	}
+
+	return stats[0].Median, nil
+}
+
+// SuggestedPrices returns suggested prices of the cards, cards without similar sales are skipped.
+func (service *Service) SuggestedPrices(ctx context.Context, cardsList []cards.Card) (map[uuid.UUID]*big.Int, error) {
+	stats, err := service.ListPriceStats(ctx, SalesFilter{Since: service.analyticsSince()})
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[uuid.UUID]*big.Int)
+	for _, card := range cardsList {
+		band := NewRatingBand(card.Rating())
+		for i := range stats {
+			if stats[i].Quality == card.Quality && stats[i].RatingBand == band {
+				prices[card.ID] = &stats[i].Median
+				break
+			}
+		}
+	}
+
+	return prices, nil
+}
+
+// analyticsSince returns the start of the analytics period, all sales are analyzed if the period is not set.
+func (service *Service) analyticsSince() time.Time {
+	if service.config.AnalyticsPeriod <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().UTC().Add(-service.config.AnalyticsPeriod)
+}
@@ -16,6 +16,7 @@ import (
 
 	"ultimatedivision/cards"
 	"ultimatedivision/cards/nfts"
+	"ultimatedivision/finances"
 	"ultimatedivision/pkg/pagination"
 	"ultimatedivision/users"
 )
@@ -32,74 +33,176 @@ type Service struct {
 	users       *users.Service
 	cards       *cards.Service
 	nfts        *nfts.Service
+	finances    *finances.Service
 }
 
 // NewService is a constructor for marketplace service.
-func NewService(config Config, marketplace DB, users *users.Service, cards *cards.Service, nfts *nfts.Service) *Service {
+func NewService(config Config, marketplace DB, users *users.Service, cards *cards.Service, nfts *nfts.Service, finances *finances.Service) *Service {
 	return &Service{
 		config:      config,
 		marketplace: marketplace,
 		users:       users,
 		cards:       cards,
 		nfts:        nfts,
+		finances:    finances,
 	}
 }
 
-// CreateLot add lot in DB.
+// CreateLot add lot in DB. The lot with several cards is the bundle, all its cards are put on sale,
+// so they could not be used in the squads and sold in other lots until the lot is settled.
 func (service *Service) CreateLot(ctx context.Context, createLot CreateLot) error {
-	// TODO: add transaction.
-	card, err := service.cards.Get(ctx, createLot.CardID)
-	if err == nil {
+	if _, err := service.users.Get(ctx, createLot.UserID); err != nil {
+		return ErrMarketplace.Wrap(err)
+	}
+
+	if createLot.SaleMode == "" {
+		createLot.SaleMode = SaleModeAuction
+	}
+
+	if err := createLot.ValidateCreateLot(); err != nil {
+		return err
+	}
+
+	cardIDs := createLot.ItemIDs()
+	lotCards := make([]cards.Card, 0, len(cardIDs))
+	for _, cardID := range cardIDs {
+		card, err := service.cards.Get(ctx, cardID)
+		if err != nil {
+			// TODO: check other items.
+			if cards.ErrNoCard.Has(err) {
+				return ErrMarketplace.New("not found item by id")
+			}
+			return ErrMarketplace.Wrap(err)
+		}
+
 		if card.UserID != createLot.UserID {
-			return ErrMarketplace.New("it is not the user's card")
+			return ErrCardNotAvailable.New("it is not the user's card")
 		}
 
 		if card.Status == cards.StatusSale {
-			return ErrMarketplace.New("the card is already on sale")
+			return ErrCardNotAvailable.New("the card is already on sale")
 		}
 
-		if err := service.cards.UpdateStatus(ctx, createLot.CardID, cards.StatusSale); err != nil {
-			return ErrMarketplace.Wrap(err)
+		if card.Status == cards.StatusRetired {
+			return ErrCardNotAvailable.New("the card is retired")
 		}
 
-		createLot.Type = TypeCard
-	}
-	// TODO: check other items.
-	if createLot.Type == "" {
-		return ErrMarketplace.New("not found item by id")
+		lotCards = append(lotCards, card)
 	}
 
-	if _, err := service.users.Get(ctx, createLot.UserID); err != nil {
-		return ErrMarketplace.Wrap(err)
-	}
+	createLot.Type = TypeCard
+	if len(lotCards) > 1 {
+		createLot.Type = TypeBundle
 
-	if createLot.MaxPrice.BitLen() != 0 && createLot.MaxPrice.Cmp(&createLot.StartPrice) == -1 {
-		return ErrMarketplace.New("max price less start price")
+		// the final listing on chain holds a single token, so minted cards are sold only one by one.
+		for _, card := range lotCards {
+			minted, err := service.nfts.IsMinted(ctx, card.ID)
+			if err != nil {
+				return ErrMarketplace.Wrap(err)
+			}
+			if minted != 0 {
+				return ErrInvalidBundle.New("minted card %s could not be sold in the bundle", card.ID)
+			}
+		}
 	}
 
-	if createLot.Period < MinPeriod && createLot.Period < MaxPeriod {
-		return ErrMarketplace.New("period exceed the range from 1 to 120 hours")
+	history := make([]cards.History, 0, len(lotCards))
+	for _, card := range lotCards {
+		history = append(history,
+			cards.NewHistory(card, cards.HistoryKindStatus, strconv.Itoa(int(card.Status)), strconv.Itoa(int(cards.StatusSale)), cards.Event{Cause: cards.CauseMarketplace}))
 	}
 
+	startTime := time.Now().UTC()
 	lot := Lot{
-		CardID:     card.ID,
+		ID:         uuid.New(),
+		CardID:     lotCards[0].ID,
 		Type:       createLot.Type,
+		SaleMode:   createLot.SaleMode,
 		UserID:     createLot.UserID,
 		Status:     StatusActive,
 		StartPrice: createLot.StartPrice,
 		MaxPrice:   createLot.MaxPrice,
-		StartTime:  time.Now().UTC(),
-		EndTime:    time.Now().UTC().Add(time.Duration(createLot.Period) * time.Hour),
+		FloorPrice: createLot.FloorPrice,
+		StartTime:  startTime,
+		EndTime:    startTime.Add(time.Duration(createLot.Period) * time.Hour),
 		Period:     createLot.Period,
+		Cards:      lotCards,
 	}
 
-	return ErrMarketplace.Wrap(service.marketplace.CreateLot(ctx, lot))
+	return ErrMarketplace.Wrap(service.marketplace.CreateLot(ctx, lot, history...))
 }
 
 // GetLotByID returns lot by id from DB.
 func (service *Service) GetLotByID(ctx context.Context, id uuid.UUID) (Lot, error) {
 	lot, err := service.marketplace.GetLotByID(ctx, id)
-	return lot, ErrMarketplace.Wrap(err)
+	if err != nil {
+		return lot, ErrMarketplace.Wrap(err)
+	}
+
+	lots := []Lot{lot}
+	if err = service.addLotCards(ctx, lots); err != nil {
+		return lots[0], ErrMarketplace.Wrap(err)
+	}
+	err = service.addScoutingReports(ctx, lots)
+	return lots[0], ErrMarketplace.Wrap(err)
+}
+
+// GetActiveLotByCardID returns active lot which holds the card from DB.
+func (service *Service) GetActiveLotByCardID(ctx context.Context, cardID uuid.UUID) (Lot, error) {
+	lot, err := service.marketplace.GetActiveLotByCardID(ctx, cardID)
+	if err != nil {
+		return lot, ErrMarketplace.Wrap(err)
+	}
+
+	return service.GetLotByID(ctx, lot.ID)
+}
+
+// addLotCards adds all cards to the lots, so the contents of the bundles are shown.
+func (service *Service) addLotCards(ctx context.Context, lots []Lot) error {
+	if len(lots) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(lots))
+	for i := range lots {
+		ids[i] = lots[i].ID
+	}
+
+	lotCards, err := service.marketplace.ListLotCards(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range lots {
+		lots[i].Cards = lotCards[lots[i].ID]
+	}
+
+	return nil
+}
+
+// addScoutingReports adds scouting reports to the lots which cards were scouted.
+func (service *Service) addScoutingReports(ctx context.Context, lots []Lot) error {
+	cardIDs := make([]uuid.UUID, 0, len(lots))
+	for _, lot := range lots {
+		cardIDs = append(cardIDs, lot.CardID)
+	}
+
+	reports, err := service.cards.ListScoutingReports(ctx, cardIDs)
+	if err != nil {
+		return err
+	}
+
+	reportsByCardID := make(map[uuid.UUID]cards.ScoutingReport, len(reports))
+	for _, report := range reports {
+		reportsByCardID[report.CardID] = report
+	}
+	for i := range lots {
+		if report, ok := reportsByCardID[lots[i].CardID]; ok {
+			lots[i].Scouting = &report
+		}
+	}
+
+	return nil
 }
 
 // GetLotEndTimeByID returns lot end time by id from DB.
@@ -194,16 +297,28 @@ func (service *Service) GetMakeOfferByCardID(ctx context.Context, cardID uuid.UU
 	}, ErrMarketplace.Wrap(err)
 }
 
-// ListActiveLots returns active lots from DB.
-func (service *Service) ListActiveLots(ctx context.Context, cursor pagination.Cursor) (Page, error) {
+// ListActiveLots returns active lots of the sale mode from DB, lots of all modes are returned if mode is empty.
+func (service *Service) ListActiveLots(ctx context.Context, saleMode SaleMode, cursor pagination.Cursor) (Page, error) {
+	if saleMode != "" && !saleMode.IsValid() {
+		return Page{}, ErrMarketplace.New("sale mode is not correct")
+	}
+
 	if cursor.Limit <= 0 {
 		cursor.Limit = service.config.Cursor.Limit
 	}
 	if cursor.Page <= 0 {
 		cursor.Page = service.config.Cursor.Page
 	}
-	lotsPage, err := service.marketplace.ListActiveLots(ctx, cursor)
-	return lotsPage, ErrMarketplace.Wrap(err)
+	lotsPage, err := service.marketplace.ListActiveLots(ctx, saleMode, cursor)
+	if err != nil {
+		return lotsPage, ErrMarketplace.Wrap(err)
+	}
+
+	if err = service.addLotCards(ctx, lotsPage.Lots); err != nil {
+		return lotsPage, ErrMarketplace.Wrap(err)
+	}
+
+	return lotsPage, ErrMarketplace.Wrap(service.addScoutingReports(ctx, lotsPage.Lots))
 }
 
 // ListExpiredLots returns all expired lots form the database.
@@ -212,20 +327,20 @@ func (service *Service) ListExpiredLots(ctx context.Context) ([]Lot, error) {
 	return lots, ErrMarketplace.Wrap(err)
 }
 
-// ListActiveLotsWithFilters returns active lots from DB, taking the necessary filters.
-func (service *Service) ListActiveLotsWithFilters(ctx context.Context, filters []cards.Filters, cursor pagination.Cursor) (Page, error) {
-	var lotsPage Page
-	for _, v := range filters {
-		err := v.Validate()
-		if err != nil {
-			return lotsPage, ErrMarketplace.Wrap(err)
-		}
-	}
+// ListActiveLotsWithFilters returns active lots of the sale mode from DB, taking the necessary filters.
+func (service *Service) ListActiveLotsWithFilters(ctx context.Context, filters []cards.Filters, saleMode SaleMode, cursor pagination.Cursor) (Page, error) {
+	return service.ListActiveLotsWithQuery(ctx, cards.NewQueryFromFilters(filters), saleMode, cursor)
+}
 
-	cardIDs, err := service.cards.ListCardIDsWithFiltersWhereActiveLot(ctx, filters)
-	if err != nil {
+// ListActiveLotsWithQuery returns active lots of the sale mode from DB which cards match the query.
+func (service *Service) ListActiveLotsWithQuery(ctx context.Context, query cards.Query, saleMode SaleMode, cursor pagination.Cursor) (Page, error) {
+	var lotsPage Page
+	if err := query.Validate(); err != nil {
 		return lotsPage, ErrMarketplace.Wrap(err)
 	}
+	if saleMode != "" && !saleMode.IsValid() {
+		return lotsPage, ErrMarketplace.New("sale mode is not correct")
+	}
 
 	if cursor.Limit <= 0 {
 		cursor.Limit = service.config.Cursor.Limit
@@ -233,14 +348,46 @@ func (service *Service) ListActiveLotsWithFilters(ctx context.Context, filters [
 	if cursor.Page <= 0 {
 		cursor.Page = service.config.Cursor.Page
 	}
-	lotsPage, err = service.marketplace.ListActiveLotsByCardID(ctx, cardIDs, cursor)
-	return lotsPage, ErrMarketplace.Wrap(err)
+	lotsPage, err := service.marketplace.ListActiveLotsWithQuery(ctx, query, saleMode, cursor)
+	if err != nil {
+		return lotsPage, ErrMarketplace.Wrap(err)
+	}
+
+	if err = service.addLotCards(ctx, lotsPage.Lots); err != nil {
+		return lotsPage, ErrMarketplace.Wrap(err)
+	}
+
+	return lotsPage, ErrMarketplace.Wrap(service.addScoutingReports(ctx, lotsPage.Lots))
+}
+
+// ListNewLotsWithQuery returns the first page of the active lots of the sale mode which cards match the query
+// and which were started after the time.
+func (service *Service) ListNewLotsWithQuery(ctx context.Context, query cards.Query, saleMode SaleMode, since time.Time, limit int) ([]Lot, error) {
+	if err := query.Validate(); err != nil {
+		return nil, ErrMarketplace.Wrap(err)
+	}
+	if saleMode != "" && !saleMode.IsValid() {
+		return nil, ErrMarketplace.New("sale mode is not correct")
+	}
+
+	if limit <= 0 {
+		limit = service.config.Cursor.Limit
+	}
+	lotsPage, err := service.marketplace.ListActiveLotsWithQuerySince(ctx, query, saleMode, since, pagination.Cursor{Limit: limit, Page: 1})
+	if err != nil {
+		return nil, ErrMarketplace.Wrap(err)
+	}
+
+	return lotsPage.Lots, ErrMarketplace.Wrap(service.addLotCards(ctx, lotsPage.Lots))
 }
 
-// ListActiveLotsByPlayerName returns active lots from DB by player name card.
-func (service *Service) ListActiveLotsByPlayerName(ctx context.Context, filter cards.Filters, cursor pagination.Cursor) (Page, error) {
+// ListActiveLotsByPlayerName returns active lots of the sale mode from DB by player name card.
+func (service *Service) ListActiveLotsByPlayerName(ctx context.Context, filter cards.Filters, saleMode SaleMode, cursor pagination.Cursor) (Page, error) {
 	var lotsPage Page
 	strings.ToValidUTF8(filter.Value, "")
+	if saleMode != "" && !saleMode.IsValid() {
+		return lotsPage, ErrMarketplace.New("sale mode is not correct")
+	}
 
 	// TODO: add best check.
 	_, err := strconv.Atoi(filter.Value)
@@ -259,8 +406,16 @@ func (service *Service) ListActiveLotsByPlayerName(ctx context.Context, filter c
 	if cursor.Page <= 0 {
 		cursor.Page = service.config.Cursor.Page
 	}
-	lotsPage, err = service.marketplace.ListActiveLotsByCardID(ctx, cardIDs, cursor)
-	return lotsPage, ErrMarketplace.Wrap(err)
+	lotsPage, err = service.marketplace.ListActiveLotsByCardID(ctx, cardIDs, saleMode, cursor)
+	if err != nil {
+		return lotsPage, ErrMarketplace.Wrap(err)
+	}
+
+	if err = service.addLotCards(ctx, lotsPage.Lots); err != nil {
+		return lotsPage, ErrMarketplace.Wrap(err)
+	}
+
+	return lotsPage, ErrMarketplace.Wrap(service.addScoutingReports(ctx, lotsPage.Lots))
 }
 
 // ListExpiredLot returns not active lots from DB.
@@ -269,92 +424,62 @@ func (service *Service) ListExpiredLot(ctx context.Context) ([]Lot, error) {
 	return lots, ErrMarketplace.Wrap(err)
 }
 
-// PlaceBetLot checks the amount of money and makes a bet.
-func (service *Service) PlaceBetLot(ctx context.Context, betLot BetLot) error {
-	if _, err := service.users.Get(ctx, betLot.UserID); err != nil {
-		return ErrMarketplace.Wrap(err)
+// BuyLot buys the lot instantly at its current buy now price: fixed price lots at the start price,
+// dutch auctions at the decayed price and auctions at the max price.
+func (service *Service) BuyLot(ctx context.Context, buyLot BuyLot) (big.Int, error) {
+	if _, err := service.users.Get(ctx, buyLot.UserID); err != nil {
+		return big.Int{}, ErrMarketplace.Wrap(err)
 	}
-	// TODO: check if the user has the required amount of money.
 
-	lot, err := service.GetLotByID(ctx, betLot.CardID)
+	lot, err := service.GetLotByID(ctx, buyLot.LotID)
 	if err != nil {
-		return ErrMarketplace.Wrap(err)
+		return big.Int{}, ErrMarketplace.Wrap(err)
 	}
-	if lot.Status == StatusSold || lot.Status == StatusSoldBuynow {
-		return ErrMarketplace.New("the lot is already on sale")
+	if lot.Status != StatusActive {
+		return big.Int{}, ErrLotNotAvailable.New("the lot is not active")
 	}
-	if lot.Status == StatusExpired {
-		return ErrMarketplace.New("the lot is already expired")
-	}
-
-	if betLot.BetAmount.Cmp(&lot.StartPrice) == -1 || betLot.BetAmount.Cmp(&lot.CurrentPrice) == -1 || betLot.BetAmount.Cmp(&lot.CurrentPrice) == 0 {
-		return ErrMarketplace.New("not enough money")
+	if lot.UserID == buyLot.UserID {
+		return big.Int{}, ErrLotNotAvailable.New("the lot could not be bought by its seller")
 	}
 
-	/** TODO: the transaction may be required for all operations,
-	  so that an error in the middle does not lead to an unwanted result in the database. **/
-
-	// TODO: update status to `hold` for new user's money.
-	// TODO: unhold old user's money if exist.
-
-	if err := service.UpdateShopperIDLot(ctx, betLot.CardID, betLot.UserID); err != nil {
-		return ErrMarketplace.Wrap(err)
+	now := time.Now().UTC()
+	if !now.Before(lot.EndTime) {
+		return big.Int{}, ErrLotNotAvailable.New("the lot is already expired")
 	}
 
-	if (betLot.BetAmount.Cmp(&lot.MaxPrice) == 1 || betLot.BetAmount.Cmp(&lot.MaxPrice) == 0) && lot.MaxPrice.BitLen() != 0 {
-		if err = service.UpdateCurrentPriceLot(ctx, betLot.CardID, lot.MaxPrice); err != nil {
-			return ErrMarketplace.Wrap(err)
-		}
-
-		winLot := WinLot{
-			CardID:    betLot.CardID,
-			Type:      TypeCard,
-			UserID:    lot.UserID,
-			ShopperID: betLot.UserID,
-			Status:    StatusSoldBuynow,
-			Amount:    lot.MaxPrice,
-		}
-
-		if err = service.WinLot(ctx, winLot); err != nil {
-			return ErrMarketplace.Wrap(err)
-		}
-
-	} else {
-		if err = service.UpdateCurrentPriceLot(ctx, betLot.CardID, betLot.BetAmount); err != nil {
-			return ErrMarketplace.Wrap(err)
-		}
-		if lot.EndTime.Sub(time.Now().UTC()) < time.Minute {
-			if err = service.UpdateEndTimeLot(ctx, betLot.CardID, time.Now().UTC().Add(time.Minute)); err != nil {
-				return ErrMarketplace.Wrap(err)
-			}
-		}
+	price, ok := lot.BuyNowPrice(now)
+	if !ok {
+		return big.Int{}, ErrLotNotAvailable.New("the lot could not be bought now")
 	}
 
-	return nil
+	return price, ErrMarketplace.Wrap(service.buy(ctx, lot, buyLot.UserID, price))
 }
 
-// WinLot changes owner of the item and transfers money.
-func (service *Service) WinLot(ctx context.Context, winLot WinLot) error {
-	if err := service.UpdateStatusLot(ctx, winLot.CardID, winLot.Status); err != nil {
-		return ErrMarketplace.Wrap(err)
+// buy claims the lot for the shopper at the price, holds the price from the funds of the shopper and returns
+// the funds of the previous shopper in one transaction. The claimed lot is settled by the chore,
+// so the cards and the funds move through the same settlement states as the ones of the expired lots.
+func (service *Service) buy(ctx context.Context, lot Lot, shopperID uuid.UUID, price big.Int) error {
+	hold, err := service.finances.NewHold(ctx, lot.ID, shopperID, price)
+	if err != nil {
+		return err
 	}
 
-	// TODO: transfer money to the old cardholder from new user. If userID == shopperID not transfer mb.
-
-	if winLot.Type == TypeCard {
-		if err := service.cards.UpdateStatus(ctx, winLot.CardID, cards.StatusActive); err != nil {
-			return ErrMarketplace.Wrap(err)
-		}
-
-		if winLot.UserID != winLot.ShopperID {
-			if err := service.cards.UpdateUserID(ctx, winLot.CardID, winLot.ShopperID); err != nil {
-				return ErrMarketplace.Wrap(err)
-			}
+	purchase := Purchase{
+		LotID:             lot.ID,
+		ShopperID:         shopperID,
+		PreviousShopperID: lot.ShopperID,
+		Price:             price,
+		Hold:              hold,
+	}
+	if lot.ShopperID != uuid.Nil && lot.ShopperID != shopperID {
+		release, err := service.finances.NewRelease(ctx, lot.ID, lot.ShopperID)
+		if err != nil {
+			return err
 		}
+		purchase.Release = &release
 	}
-	// TODO: check other items.
 
-	return nil
+	return service.marketplace.BuyLot(ctx, purchase)
 }
 
 // UpdateShopperIDLot updates shopper id of lot.
@@ -378,6 +503,6 @@ func (service *Service) UpdateEndTimeLot(ctx context.Context, id uuid.UUID, endT
 }
 
 // Delete deletes lot in the database.
-func (service *Service) Delete(ctx context.Context, cardID uuid.UUID) error {
-	return ErrMarketplace.Wrap(service.marketplace.Delete(ctx, cardID))
+func (service *Service) Delete(ctx context.Context, id uuid.UUID) error {
+	return ErrMarketplace.Wrap(service.marketplace.Delete(ctx, id))
 }
@@ -0,0 +1,242 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package marketplace
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	casper_ed25519 "github.com/casper-ecosystem/casper-golang-sdk/keypair/ed25519"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/cards"
+	"ultimatedivision/cards/nfts"
+	"ultimatedivision/internal/contract/casper"
+	contract "ultimatedivision/pkg/contractcasper"
+	"ultimatedivision/users"
+)
+
+// SettleLot moves the expired or bought lot through the settlement states up to the db settled one. Each step is done
+// at most once, the failed step returns the error and is retried by the next call from the state where it stopped.
+// The lot waits in the pending final listing state until the deploy of the final listing is executed on chain.
+func (service *Service) SettleLot(ctx context.Context, lot Lot) error {
+	switch lot.Settlement {
+	case SettlementNone:
+		err := service.marketplace.UpdateSettlementLot(ctx, lot.ID, SettlementNone, SettlementPendingFinalListing, "")
+		if err != nil {
+			return ErrMarketplace.Wrap(err)
+		}
+		lot.Settlement = SettlementPendingFinalListing
+		fallthrough
+	case SettlementPendingFinalListing:
+		confirmed, err := service.confirmFinalListing(ctx, lot)
+		if err != nil || !confirmed {
+			return ErrMarketplace.Wrap(err)
+		}
+		lot.Settlement = SettlementOnChainConfirmed
+		fallthrough
+	case SettlementOnChainConfirmed:
+		settlement, err := service.settlement(ctx, lot)
+		if err != nil {
+			return ErrMarketplace.Wrap(err)
+		}
+
+		return ErrMarketplace.Wrap(service.marketplace.SettleLot(ctx, settlement))
+	default:
+		return nil
+	}
+}
+
+// ListSettledLots returns lots which are settled in the database.
+func (service *Service) ListSettledLots(ctx context.Context) ([]Lot, error) {
+	lots, err := service.marketplace.ListSettledLots(ctx)
+	return lots, ErrMarketplace.Wrap(err)
+}
+
+// confirmFinalListing finishes the listing of the lot on chain and moves the lot to the on chain confirmed state once
+// the deploy is executed, false is returned while the deploy is pending. The hash of the deploy is stored as soon as
+// it is sent, so the next calls wait for the same deploy instead of sending a new one, the failed deploy is sent again.
+func (service *Service) confirmFinalListing(ctx context.Context, lot Lot) (bool, error) {
+	hash := lot.FinalListingHash
+	if hash == "" {
+		var err error
+		if hash, err = service.finalListing(ctx, lot); err != nil {
+			return false, err
+		}
+
+		if hash != "" {
+			err = service.marketplace.UpdateSettlementLot(ctx, lot.ID, SettlementPendingFinalListing, SettlementPendingFinalListing, hash)
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if hash != "" {
+		status, err := contract.New(service.config.RPCNodeAddress).GetDeployStatus(hash)
+		if err != nil {
+			return false, err
+		}
+
+		switch status {
+		case contract.DeployStatusPending:
+			return false, nil
+		case contract.DeployStatusFailure:
+			err = service.marketplace.UpdateSettlementLot(ctx, lot.ID, SettlementPendingFinalListing, SettlementPendingFinalListing, "")
+			return false, errs.Combine(ErrMarketplace.New("final listing deploy %s failed", hash), err)
+		}
+	}
+
+	if err := service.marketplace.UpdateSettlementLot(ctx, lot.ID, SettlementPendingFinalListing, SettlementOnChainConfirmed, hash); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// finalListing finishes the listing of the lot on chain and returns the hash of the deploy,
+// lots of the cards which are not minted and bundles, which hold only such cards, have nothing to finish.
+func (service *Service) finalListing(ctx context.Context, lot Lot) (string, error) {
+	if lot.Type == TypeBundle {
+		return "", nil
+	}
+
+	tokenID, err := service.nfts.GetNFTTokenIDbyCardID(ctx, lot.CardID)
+	if err != nil {
+		if nfts.ErrNoNFT.Has(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	privateAccountKeyBytes, err := hex.DecodeString(service.config.ContractOwnerPrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	publicAccountKeyBytes, err := hex.DecodeString(service.config.ContractOwnerPublicKey)
+	if err != nil {
+		return "", err
+	}
+
+	pair := casper_ed25519.ParseKeyPair(publicAccountKeyBytes, privateAccountKeyBytes)
+
+	casperClient := contract.New(service.config.RPCNodeAddress)
+	transfer := casper.NewTransfer(casperClient, func(b []byte) ([]byte, error) {
+		casperSignature := pair.Sign(b)
+		return casperSignature.SignatureData, nil
+	})
+
+	return transfer.FinalListing(ctx, casper.FinalListingRequest{
+		PublicKey:          pair.PublicKey(),
+		ChainName:          "casper-test",
+		StandardPayment:    10000000000,
+		MarketContractHash: service.config.MarketContractAddress,
+		NFTContractHash:    fmt.Sprintf("%s%s", service.config.NFTContractPrefix, service.config.NFTContractAddress),
+		TokenID:            tokenID.String(),
+	})
+}
+
+// settlement returns changes of the database which settle the lot confirmed on chain. The bought lot and the lot
+// with the bet are sold to its shopper, who pays the current price, otherwise it is expired and the cards
+// are returned to the seller.
+func (service *Service) settlement(ctx context.Context, lot Lot) (Settlement, error) {
+	lots := []Lot{lot}
+	if err := service.addLotCards(ctx, lots); err != nil {
+		return Settlement{}, err
+	}
+	lot = lots[0]
+
+	settlement := Settlement{
+		LotID:   lot.ID,
+		CardIDs: lot.CardIDs(),
+		Status:  StatusExpired,
+		OwnerID: lot.UserID,
+	}
+
+	event := cards.Event{Cause: cards.CauseMarketplace}
+	if lot.Status == StatusSoldBuynow || lot.CurrentPrice.BitLen() != 0 {
+		settlement.Status = StatusSold
+		if lot.Status == StatusSoldBuynow {
+			settlement.Status = StatusSoldBuynow
+		}
+		settlement.OwnerID = lot.ShopperID
+		event.CounterpartyID = lot.ShopperID
+		event.Price = lot.CurrentPrice
+	}
+
+	var (
+		owner     *users.User
+		firstCard cards.Card
+	)
+	for i, cardID := range settlement.CardIDs {
+		card, err := service.cards.Get(ctx, cardID)
+		if err != nil {
+			return Settlement{}, err
+		}
+		if i == 0 {
+			firstCard = card
+		}
+
+		cardEvent := event
+		settlement.History = append(settlement.History,
+			cards.NewHistory(card, cards.HistoryKindStatus, strconv.Itoa(int(card.Status)), strconv.Itoa(int(cards.StatusActive)), cardEvent))
+
+		if settlement.OwnerID == card.UserID {
+			continue
+		}
+
+		previousUserID := card.UserID
+		card.UserID = settlement.OwnerID
+		cardEvent.CounterpartyID = previousUserID
+		settlement.History = append(settlement.History,
+			cards.NewHistory(card, cards.HistoryKindOwnership, previousUserID.String(), card.UserID.String(), cardEvent))
+
+		nft, err := service.nfts.GetNFTByCardID(ctx, cardID)
+		switch {
+		case err == nil:
+			if owner == nil {
+				user, err := service.users.Get(ctx, settlement.OwnerID)
+				if err != nil {
+					return Settlement{}, err
+				}
+				owner = &user
+			}
+
+			if owner.WalletType == users.WalletTypeCasper {
+				nft.WalletAddress = common.HexToAddress(owner.CasperWallet)
+			} else {
+				nft.WalletAddress = owner.Wallet
+			}
+			settlement.NFTs = append(settlement.NFTs, nft)
+		case !nfts.ErrNoNFT.Has(err):
+			return Settlement{}, err
+		}
+	}
+
+	if settlement.Status != StatusExpired && lot.UserID != lot.ShopperID {
+		fees, err := service.LotFees(ctx, lot.Type, lot.CardID, lot.CurrentPrice)
+		if err != nil {
+			return Settlement{}, err
+		}
+
+		payment, err := service.finances.NewPayment(ctx, lot.ID, lot.UserID, lot.ShopperID, lot.CurrentPrice, fees)
+		if err != nil {
+			return Settlement{}, err
+		}
+		settlement.Payment = &payment
+
+		// the price of the bundle does not tell the price of its cards, so only single cards get to the price history.
+		if lot.Type == TypeCard {
+			sale := NewSale(firstCard, lot.CurrentPrice, time.Now().UTC())
+			settlement.Sale = &sale
+		}
+	}
+
+	return settlement, nil
+}
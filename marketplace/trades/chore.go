@@ -0,0 +1,52 @@
+// Copyright (C) 2022 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package trades
+
+import (
+	"context"
+
+	"github.com/BoostyLabs/thelooper"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/internal/logger"
+)
+
+var (
+	// ChoreError represents trades chore error type.
+	ChoreError = errs.Class("trades chore error")
+)
+
+// Chore expires trades which were not answered in time.
+//
+// architecture: Chore
+type Chore struct {
+	log    logger.Logger
+	loop   *thelooper.Loop
+	trades *Service
+}
+
+// NewChore instantiates Chore.
+func NewChore(log logger.Logger, config Config, trades *Service) *Chore {
+	return &Chore{
+		log:    log,
+		loop:   thelooper.NewLoop(config.ExpirationInterval),
+		trades: trades,
+	}
+}
+
+// Run starts the chore for expiring trades.
+func (chore *Chore) Run(ctx context.Context) error {
+	return chore.loop.Run(ctx, func(ctx context.Context) error {
+		if err := chore.trades.ExpireTrades(ctx); err != nil {
+			chore.log.Error("could not expire trades", ChoreError.Wrap(err))
+		}
+
+		return nil
+	})
+}
+
+// Close closes the chore for expiring trades.
+func (chore *Chore) Close() {
+	chore.loop.Close()
+}
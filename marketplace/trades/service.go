@@ -0,0 +1,269 @@
+// Copyright (C) 2022 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package trades
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/cards"
+	"ultimatedivision/finances"
+)
+
+// ErrTrades indicates that there was an error in the service.
+var ErrTrades = errs.Class("trades service error")
+
+// Service is handling trades related logic.
+//
+// architecture: Service
+type Service struct {
+	config   Config
+	trades   DB
+	cards    *cards.Service
+	finances *finances.Service
+}
+
+// NewService is a constructor for trades service.
+func NewService(config Config, trades DB, cards *cards.Service, finances *finances.Service) *Service {
+	return &Service{
+		config:   config,
+		trades:   trades,
+		cards:    cards,
+		finances: finances,
+	}
+}
+
+// Propose creates pending trade, the amount offered by the proposer is held from the active club until the trade is answered.
+func (service *Service) Propose(ctx context.Context, trade Trade) (Trade, error) {
+	if err := trade.Validate(service.config.MaxCards); err != nil {
+		return Trade{}, ErrTrades.Wrap(err)
+	}
+	if err := service.checkCards(ctx, trade); err != nil {
+		return Trade{}, ErrTrades.Wrap(err)
+	}
+
+	trade.ID = uuid.New()
+	trade.Status = StatusPending
+	trade.CreatedAt = time.Now().UTC()
+	trade.ExpiresAt = trade.CreatedAt.Add(service.config.Expiration)
+
+	if trade.Amount.Sign() > 0 {
+		if err := service.finances.Hold(ctx, trade.ID, trade.ProposerID, trade.Amount); err != nil {
+			return Trade{}, ErrTrades.Wrap(err)
+		}
+	}
+
+	if err := service.trades.Create(ctx, trade); err != nil {
+		return Trade{}, ErrTrades.Wrap(errs.Combine(err, service.release(ctx, trade)))
+	}
+
+	return trade, nil
+}
+
+// Get returns trade by id if the user takes part in it.
+func (service *Service) Get(ctx context.Context, userID, id uuid.UUID) (Trade, error) {
+	trade, err := service.trades.Get(ctx, id)
+	if err != nil {
+		return Trade{}, ErrTrades.Wrap(err)
+	}
+	if trade.ProposerID != userID && trade.RecipientID != userID {
+		return Trade{}, ErrNoTrade.New("")
+	}
+
+	return trade, nil
+}
+
+// List returns trades proposed by the user or to the user.
+func (service *Service) List(ctx context.Context, userID uuid.UUID) ([]Trade, error) {
+	trades, err := service.trades.ListByUserID(ctx, userID)
+	return trades, ErrTrades.Wrap(err)
+}
+
+// Accept swaps ownership of the cards of the trade, pays the offered amount to the recipient and removes
+// the cards from the squads of their previous owners. The swapped cards are written to their history.
+// All changes are applied in one transaction, so the trade is accepted only once and only if its cards
+// were not sold or traded meanwhile.
+func (service *Service) Accept(ctx context.Context, userID, id uuid.UUID) error {
+	trade, err := service.pendingTrade(ctx, id)
+	if err != nil {
+		return ErrTrades.Wrap(err)
+	}
+	if trade.RecipientID != userID {
+		return ErrNoTrade.New("")
+	}
+
+	// cards could be sold or traded after the trade was proposed.
+	if err = service.checkCards(ctx, trade); err != nil {
+		return ErrTrades.Wrap(err)
+	}
+
+	acceptance := Acceptance{TradeID: trade.ID}
+	for _, swap := range []struct {
+		cardIDs      []uuid.UUID
+		fromID, toID uuid.UUID
+	}{
+		{cardIDs: trade.OfferedCardIDs, fromID: trade.ProposerID, toID: trade.RecipientID},
+		{cardIDs: trade.RequestedCardIDs, fromID: trade.RecipientID, toID: trade.ProposerID},
+	} {
+		for _, cardID := range swap.cardIDs {
+			card, err := service.cards.Get(ctx, cardID)
+			if err != nil {
+				return ErrTrades.Wrap(err)
+			}
+
+			card.UserID = swap.toID
+			event := cards.Event{
+				Cause:          cards.CauseTrade,
+				CounterpartyID: swap.fromID,
+			}
+			acceptance.Swaps = append(acceptance.Swaps, Swap{CardID: cardID, FromID: swap.fromID, ToID: swap.toID})
+			acceptance.History = append(acceptance.History,
+				cards.NewHistory(card, cards.HistoryKindOwnership, swap.fromID.String(), swap.toID.String(), event))
+		}
+	}
+
+	if trade.Amount.Sign() > 0 {
+		payment, err := service.finances.NewPayment(ctx, trade.ID, trade.RecipientID, trade.ProposerID, trade.Amount, finances.Fees{})
+		if err != nil {
+			return ErrTrades.Wrap(err)
+		}
+		acceptance.Payment = &payment
+	}
+
+	return ErrTrades.Wrap(service.trades.Accept(ctx, acceptance))
+}
+
+// Reject rejects the trade proposed to the user and returns the held amount to the proposer.
+func (service *Service) Reject(ctx context.Context, userID, id uuid.UUID) error {
+	return ErrTrades.Wrap(service.close(ctx, id, StatusRejected, func(trade Trade) bool {
+		return trade.RecipientID == userID
+	}))
+}
+
+// Cancel cancels the trade proposed by the user and returns the held amount to the user.
+func (service *Service) Cancel(ctx context.Context, userID, id uuid.UUID) error {
+	return ErrTrades.Wrap(service.close(ctx, id, StatusCancelled, func(trade Trade) bool {
+		return trade.ProposerID == userID
+	}))
+}
+
+// Counter answers the trade proposed to the user with the counter offer to its proposer, the counter offer
+// is proposed by the user, so the amount of the counter offer is paid by the user.
+func (service *Service) Counter(ctx context.Context, userID, id uuid.UUID, counter Trade) (Trade, error) {
+	trade, err := service.pendingTrade(ctx, id)
+	if err != nil {
+		return Trade{}, ErrTrades.Wrap(err)
+	}
+	if trade.RecipientID != userID {
+		return Trade{}, ErrNoTrade.New("")
+	}
+
+	counter.ProposerID = userID
+	counter.RecipientID = trade.ProposerID
+	counter.CounterOfID = trade.ID
+	if counter, err = service.Propose(ctx, counter); err != nil {
+		return Trade{}, err
+	}
+
+	if err = service.close(ctx, id, StatusCountered, func(Trade) bool { return true }); err != nil {
+		// the trade was answered meanwhile, so the counter offer is not needed.
+		return Trade{}, ErrTrades.Wrap(errs.Combine(err, service.close(ctx, counter.ID, StatusCancelled, func(Trade) bool { return true })))
+	}
+
+	return counter, nil
+}
+
+// ExpireTrades expires pending trades which were not answered in time and returns the held amounts to their proposers.
+func (service *Service) ExpireTrades(ctx context.Context) error {
+	trades, err := service.trades.ListExpired(ctx, time.Now().UTC())
+	if err != nil {
+		return ErrTrades.Wrap(err)
+	}
+
+	var errlist errs.Group
+	for _, trade := range trades {
+		if err = service.trades.UpdateStatus(ctx, trade.ID, StatusPending, StatusExpired); err != nil {
+			// the trade was answered meanwhile.
+			if !ErrTradeConflict.Has(err) {
+				errlist.Add(err)
+			}
+			continue
+		}
+
+		errlist.Add(service.release(ctx, trade))
+	}
+
+	return ErrTrades.Wrap(errlist.Err())
+}
+
+// pendingTrade returns trade which could be answered at the moment.
+func (service *Service) pendingTrade(ctx context.Context, id uuid.UUID) (Trade, error) {
+	trade, err := service.trades.Get(ctx, id)
+	if err != nil {
+		return Trade{}, err
+	}
+	if trade.Status != StatusPending {
+		return Trade{}, ErrTradeConflict.New("trade is %s", trade.Status)
+	}
+	if trade.IsExpired(time.Now().UTC()) {
+		return Trade{}, ErrTradeConflict.New("trade is expired")
+	}
+
+	return trade, nil
+}
+
+// close moves pending trade to the final status if the user is allowed to do it and returns the held amount to the proposer.
+func (service *Service) close(ctx context.Context, id uuid.UUID, status Status, isAllowed func(Trade) bool) error {
+	trade, err := service.trades.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !isAllowed(trade) {
+		return ErrNoTrade.New("")
+	}
+
+	if err = service.trades.UpdateStatus(ctx, trade.ID, StatusPending, status); err != nil {
+		return err
+	}
+
+	return service.release(ctx, trade)
+}
+
+// release returns the amount held for the trade to the proposer.
+func (service *Service) release(ctx context.Context, trade Trade) error {
+	if trade.Amount.Sign() <= 0 {
+		return nil
+	}
+
+	return service.finances.Release(ctx, trade.ID, trade.ProposerID)
+}
+
+// checkCards checks that the cards of both sides belong to their users and are not on sale.
+func (service *Service) checkCards(ctx context.Context, trade Trade) error {
+	for userID, cardIDs := range map[uuid.UUID][]uuid.UUID{
+		trade.ProposerID:  trade.OfferedCardIDs,
+		trade.RecipientID: trade.RequestedCardIDs,
+	} {
+		for _, cardID := range cardIDs {
+			card, err := service.cards.Get(ctx, cardID)
+			if err != nil {
+				if cards.ErrNoCard.Has(err) {
+					return ErrInvalidTrade.New("card %s does not exist", cardID)
+				}
+				return err
+			}
+			if card.UserID != userID {
+				return ErrInvalidTrade.New("card %s does not belong to user %s", cardID, userID)
+			}
+			if card.Status != cards.StatusActive {
+				return ErrInvalidTrade.New("card %s is not active", cardID)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright (C) 2022 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package trades
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/cards"
+	"ultimatedivision/finances"
+)
+
+// ErrNoTrade indicates that trade does not exist.
+var ErrNoTrade = errs.Class("trade does not exist")
+
+// ErrInvalidTrade indicates that trade offer is not correct.
+var ErrInvalidTrade = errs.Class("invalid trade")
+
+// ErrTradeConflict indicates that trade is not pending anymore, so it could not be answered.
+var ErrTradeConflict = errs.Class("trade conflict")
+
+// DB is exposing access to trades db.
+//
+// architecture: DB
+type DB interface {
+	// Create adds trade with its cards in the database.
+	Create(ctx context.Context, trade Trade) error
+	// Get returns trade by id from the database.
+	Get(ctx context.Context, id uuid.UUID) (Trade, error)
+	// ListByUserID returns trades proposed by the user or to the user from the database, newest first.
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]Trade, error)
+	// ListExpired returns pending trades which expire before or at the time from the database.
+	ListExpired(ctx context.Context, now time.Time) ([]Trade, error)
+	// UpdateStatus moves trade from one status to another in the database.
+	UpdateStatus(ctx context.Context, id uuid.UUID, from, to Status) error
+	// Accept applies all changes of the accepted trade in one transaction, only if the trade is still pending
+	// and the cards still belong to their owners and are active.
+	Accept(ctx context.Context, acceptance Acceptance) error
+}
+
+// Config defines configuration for trades.
+type Config struct {
+	ExpirationInterval time.Duration `json:"expirationInterval"`
+	// Expiration defines how long the trade offer waits for the answer of the recipient.
+	Expiration time.Duration `json:"expiration"`
+	// MaxCards defines max number of the cards on each side of the trade.
+	MaxCards int `json:"maxCards"`
+}
+
+// Status defines the list of possible trade statuses.
+type Status string
+
+const (
+	// StatusPending indicates that trade waits for the answer of the recipient.
+	StatusPending Status = "pending"
+	// StatusAccepted indicates that trade was accepted and the cards were swapped.
+	StatusAccepted Status = "accepted"
+	// StatusRejected indicates that trade was rejected by the recipient.
+	StatusRejected Status = "rejected"
+	// StatusCountered indicates that the recipient answered the trade with the counter offer.
+	StatusCountered Status = "countered"
+	// StatusCancelled indicates that trade was cancelled by the proposer.
+	StatusCancelled Status = "cancelled"
+	// StatusExpired indicates that the recipient did not answer the trade in time.
+	StatusExpired Status = "expired"
+)
+
+// Trade describes offer to swap the cards of the proposer with the cards of the recipient.
+type Trade struct {
+	ID          uuid.UUID `json:"id"`
+	ProposerID  uuid.UUID `json:"proposerId"`
+	RecipientID uuid.UUID `json:"recipientId"`
+	// OfferedCardIDs are the cards of the proposer which are given to the recipient.
+	OfferedCardIDs []uuid.UUID `json:"offeredCardIds"`
+	// RequestedCardIDs are the cards of the recipient which are given to the proposer.
+	RequestedCardIDs []uuid.UUID `json:"requestedCardIds"`
+	// Amount is the currency paid by the proposer in addition to the offered cards, it is held until the trade is answered.
+	Amount big.Int `json:"amount"`
+	Status Status  `json:"status"`
+	// CounterOfID is the trade which is answered by this counter offer.
+	CounterOfID uuid.UUID `json:"counterOfId"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// Validate checks that trade swaps at least one card of the recipient for the cards or the currency of the proposer.
+func (trade Trade) Validate(maxCards int) error {
+	if trade.ProposerID == trade.RecipientID {
+		return ErrInvalidTrade.New("trade with yourself")
+	}
+	if len(trade.RequestedCardIDs) == 0 {
+		return ErrInvalidTrade.New("requested cards are empty")
+	}
+	if len(trade.OfferedCardIDs) == 0 && trade.Amount.Sign() <= 0 {
+		return ErrInvalidTrade.New("offered cards and amount are empty")
+	}
+	if trade.Amount.Sign() < 0 {
+		return ErrInvalidTrade.New("amount is negative")
+	}
+	if len(trade.OfferedCardIDs) > maxCards || len(trade.RequestedCardIDs) > maxCards {
+		return ErrInvalidTrade.New("trade could hold at most %d cards on each side", maxCards)
+	}
+
+	added := make(map[uuid.UUID]bool, len(trade.OfferedCardIDs)+len(trade.RequestedCardIDs))
+	for _, cardID := range append(append([]uuid.UUID{}, trade.OfferedCardIDs...), trade.RequestedCardIDs...) {
+		if cardID == uuid.Nil {
+			return ErrInvalidTrade.New("card id is empty")
+		}
+		if added[cardID] {
+			return ErrInvalidTrade.New("card %s is added to the trade twice", cardID)
+		}
+		added[cardID] = true
+	}
+
+	return nil
+}
+
+// Acceptance describes all changes of the accepted trade, they are applied in one transaction.
+type Acceptance struct {
+	TradeID uuid.UUID
+	Swaps   []Swap
+	History []cards.History
+	// Payment pays the amount held from the proposer to the recipient, it is nil if nothing is offered.
+	Payment *finances.Payment
+}
+
+// Swap describes the card given by its owner to the new owner.
+type Swap struct {
+	CardID uuid.UUID
+	FromID uuid.UUID
+	ToID   uuid.UUID
+}
+
+// IsExpired returns true if the recipient could not answer the trade at the moment anymore.
+func (trade Trade) IsExpired(now time.Time) bool {
+	return !now.Before(trade.ExpiresAt)
+}
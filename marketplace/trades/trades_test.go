@@ -0,0 +1,297 @@
+// Copyright (C) 2022 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package trades_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ultimatedivision"
+	"ultimatedivision/cards"
+	"ultimatedivision/clubs"
+	"ultimatedivision/database/dbtesting"
+	"ultimatedivision/divisions"
+	"ultimatedivision/finances"
+	"ultimatedivision/marketplace/trades"
+	"ultimatedivision/users"
+)
+
+func TestTradeValidate(t *testing.T) {
+	proposerID, recipientID, cardID := uuid.New(), uuid.New(), uuid.New()
+
+	valid := []trades.Trade{
+		{ProposerID: proposerID, RecipientID: recipientID, OfferedCardIDs: []uuid.UUID{uuid.New()}, RequestedCardIDs: []uuid.UUID{uuid.New()}},
+		{ProposerID: proposerID, RecipientID: recipientID, RequestedCardIDs: []uuid.UUID{uuid.New()}, Amount: *big.NewInt(100)},
+	}
+	for _, trade := range valid {
+		assert.NoError(t, trade.Validate(2), trade)
+	}
+
+	invalid := []trades.Trade{
+		{ProposerID: proposerID, RecipientID: proposerID, OfferedCardIDs: []uuid.UUID{uuid.New()}, RequestedCardIDs: []uuid.UUID{uuid.New()}},
+		{ProposerID: proposerID, RecipientID: recipientID, OfferedCardIDs: []uuid.UUID{uuid.New()}},
+		{ProposerID: proposerID, RecipientID: recipientID, RequestedCardIDs: []uuid.UUID{uuid.New()}},
+		{ProposerID: proposerID, RecipientID: recipientID, OfferedCardIDs: []uuid.UUID{uuid.New()}, RequestedCardIDs: []uuid.UUID{uuid.New()}, Amount: *big.NewInt(-1)},
+		{ProposerID: proposerID, RecipientID: recipientID, OfferedCardIDs: []uuid.UUID{cardID}, RequestedCardIDs: []uuid.UUID{cardID}},
+		{ProposerID: proposerID, RecipientID: recipientID, OfferedCardIDs: []uuid.UUID{uuid.Nil}, RequestedCardIDs: []uuid.UUID{uuid.New()}},
+		{ProposerID: proposerID, RecipientID: recipientID, OfferedCardIDs: []uuid.UUID{uuid.New()}, RequestedCardIDs: []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}},
+	}
+	for _, trade := range invalid {
+		assert.True(t, trades.ErrInvalidTrade.Has(trade.Validate(2)), trade)
+	}
+}
+
+func TestTrades(t *testing.T) {
+	proposer := users.User{ID: uuid.New(), Email: "proposer@example.com", PasswordHash: []byte{0}, NickName: "proposer", CreatedAt: time.Now().UTC()}
+	recipient := users.User{ID: uuid.New(), Email: "recipient@example.com", PasswordHash: []byte{0}, NickName: "recipient", CreatedAt: time.Now().UTC()}
+
+	offered := cards.Card{ID: uuid.New(), PlayerName: "offered", Quality: cards.QualityWood, DominantFoot: "left", Status: cards.StatusActive, Type: cards.TypeWon, UserID: proposer.ID}
+	requested := cards.Card{ID: uuid.New(), PlayerName: "requested", Quality: cards.QualityGold, DominantFoot: "right", Status: cards.StatusActive, Type: cards.TypeWon, UserID: recipient.ID}
+
+	trade := trades.Trade{
+		ID:               uuid.New(),
+		ProposerID:       proposer.ID,
+		RecipientID:      recipient.ID,
+		OfferedCardIDs:   []uuid.UUID{offered.ID},
+		RequestedCardIDs: []uuid.UUID{requested.ID},
+		Amount:           *big.NewInt(100),
+		Status:           trades.StatusPending,
+		CreatedAt:        time.Now().UTC().Round(time.Second),
+		ExpiresAt:        time.Now().UTC().Add(time.Hour).Round(time.Second),
+	}
+
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db ultimatedivision.DB) {
+		repositoryTrades := db.Trades()
+
+		require.NoError(t, db.Users().Create(ctx, proposer))
+		require.NoError(t, db.Users().Create(ctx, recipient))
+		require.NoError(t, db.Cards().Create(ctx, offered))
+		require.NoError(t, db.Cards().Create(ctx, requested))
+
+		t.Run("get sql no rows", func(t *testing.T) {
+			_, err := repositoryTrades.Get(ctx, uuid.New())
+			require.Error(t, err)
+			assert.True(t, trades.ErrNoTrade.Has(err))
+		})
+
+		t.Run("create and get", func(t *testing.T) {
+			require.NoError(t, repositoryTrades.Create(ctx, trade))
+
+			tradeFromDB, err := repositoryTrades.Get(ctx, trade.ID)
+			require.NoError(t, err)
+			compareTrades(t, trade, tradeFromDB)
+		})
+
+		t.Run("list by user id", func(t *testing.T) {
+			for _, userID := range []uuid.UUID{proposer.ID, recipient.ID} {
+				list, err := repositoryTrades.ListByUserID(ctx, userID)
+				require.NoError(t, err)
+				require.Len(t, list, 1)
+				compareTrades(t, trade, list[0])
+			}
+
+			list, err := repositoryTrades.ListByUserID(ctx, uuid.New())
+			require.NoError(t, err)
+			assert.Empty(t, list)
+		})
+
+		t.Run("list expired", func(t *testing.T) {
+			list, err := repositoryTrades.ListExpired(ctx, time.Now().UTC())
+			require.NoError(t, err)
+			assert.Empty(t, list)
+
+			list, err = repositoryTrades.ListExpired(ctx, trade.ExpiresAt)
+			require.NoError(t, err)
+			require.Len(t, list, 1)
+			compareTrades(t, trade, list[0])
+		})
+
+		t.Run("update status", func(t *testing.T) {
+			require.NoError(t, repositoryTrades.UpdateStatus(ctx, trade.ID, trades.StatusPending, trades.StatusRejected))
+
+			err := repositoryTrades.UpdateStatus(ctx, trade.ID, trades.StatusPending, trades.StatusAccepted)
+			require.Error(t, err)
+			assert.True(t, trades.ErrTradeConflict.Has(err))
+
+			tradeFromDB, err := repositoryTrades.Get(ctx, trade.ID)
+			require.NoError(t, err)
+			assert.Equal(t, trades.StatusRejected, tradeFromDB.Status)
+
+			list, err := repositoryTrades.ListExpired(ctx, trade.ExpiresAt)
+			require.NoError(t, err)
+			assert.Empty(t, list)
+		})
+	})
+}
+
+func TestAccept(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db ultimatedivision.DB) {
+		usersService := users.NewService(db.Users())
+		cardsService := cards.NewService(db.Cards(), cards.Config{})
+		clubsService := clubs.NewService(db.Clubs(), usersService, cardsService, db.Divisions())
+		financesService := finances.NewService(db.Finances(), clubsService, cardsService, finances.Config{})
+		tradesService := trades.NewService(trades.Config{Expiration: time.Hour, MaxCards: 5}, db.Trades(), cardsService, financesService)
+
+		division := divisions.Division{ID: uuid.New(), Name: 10, PassingPercent: 10, CreatedAt: time.Now().UTC()}
+		require.NoError(t, db.Divisions().Create(ctx, division))
+
+		// both users have the active club with funds.
+		var (
+			traders  [2]users.User
+			clubIDs  [2]uuid.UUID
+			cardIDs  [2]uuid.UUID
+			squadIDs [2]uuid.UUID
+		)
+		for i := range traders {
+			traders[i] = users.User{ID: uuid.New(), Email: uuid.NewString() + "@example.com", PasswordHash: []byte{0}, NickName: uuid.NewString(), CreatedAt: time.Now().UTC()}
+			require.NoError(t, db.Users().Create(ctx, traders[i]))
+
+			club := clubs.Club{ID: uuid.New(), OwnerID: traders[i].ID, Name: traders[i].NickName, Status: clubs.StatusActive, DivisionID: division.ID, CreatedAt: time.Now().UTC()}
+			_, err := db.Clubs().Create(ctx, club)
+			require.NoError(t, err)
+			clubIDs[i] = club.ID
+
+			squadIDs[i], err = db.Clubs().CreateSquad(ctx, clubs.Squad{ID: uuid.New(), ClubID: club.ID, Tactic: clubs.Balanced, Formation: clubs.FourFourTwo, IsActive: true})
+			require.NoError(t, err)
+
+			income := finances.NewTransfer(finances.TypeMatchIncome, "match", finances.AccountMatchIncome, finances.ClubAccount(club.ID), *big.NewInt(1000))
+			require.NoError(t, financesService.Record(ctx, income))
+
+			card := cards.Card{ID: uuid.New(), PlayerName: traders[i].NickName, Quality: cards.QualityWood, DominantFoot: "left", Status: cards.StatusActive, Type: cards.TypeWon, UserID: traders[i].ID}
+			require.NoError(t, db.Cards().Create(ctx, card))
+			cardIDs[i] = card.ID
+
+			require.NoError(t, db.Clubs().AddSquadCard(ctx, clubs.SquadCard{SquadID: squadIDs[i], CardID: card.ID, Position: clubs.GK}))
+			require.NoError(t, db.Clubs().UpdateTacticCaptain(ctx, clubs.Squad{ID: squadIDs[i], Tactic: clubs.Balanced, CaptainID: card.ID}))
+			require.NoError(t, db.Clubs().UpsertPlayerInstruction(ctx, clubs.PlayerInstruction{SquadID: squadIDs[i], CardID: card.ID, Instruction: clubs.InstructionStayBack}))
+		}
+
+		trade, err := tradesService.Propose(ctx, trades.Trade{
+			ProposerID:       traders[0].ID,
+			RecipientID:      traders[1].ID,
+			RequestedCardIDs: []uuid.UUID{cardIDs[1]},
+			Amount:           *big.NewInt(300),
+		})
+		require.NoError(t, err)
+		assertBalance(ctx, t, financesService, clubIDs[0], 700)
+
+		t.Run("answered only by recipient", func(t *testing.T) {
+			err := tradesService.Accept(ctx, traders[0].ID, trade.ID)
+			assert.True(t, trades.ErrNoTrade.Has(err))
+		})
+
+		counter, err := tradesService.Counter(ctx, traders[1].ID, trade.ID, trades.Trade{
+			OfferedCardIDs:   []uuid.UUID{cardIDs[1]},
+			RequestedCardIDs: []uuid.UUID{cardIDs[0]},
+			Amount:           *big.NewInt(100),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, trade.ID, counter.CounterOfID)
+		assert.Equal(t, traders[0].ID, counter.RecipientID)
+
+		// the amount of the countered trade is returned and the amount of the counter offer is held.
+		assertBalance(ctx, t, financesService, clubIDs[0], 1000)
+		assertBalance(ctx, t, financesService, clubIDs[1], 900)
+
+		err = tradesService.Accept(ctx, traders[1].ID, trade.ID)
+		assert.True(t, trades.ErrTradeConflict.Has(err))
+
+		require.NoError(t, tradesService.Accept(ctx, traders[0].ID, counter.ID))
+
+		for i, owner := range []users.User{traders[1], traders[0]} {
+			card, err := cardsService.Get(ctx, cardIDs[i])
+			require.NoError(t, err)
+			assert.Equal(t, owner.ID, card.UserID)
+
+			history, err := cardsService.ListHistoryByCardID(ctx, cardIDs[i])
+			require.NoError(t, err)
+			require.NotEmpty(t, history)
+			assert.Equal(t, cards.CauseTrade, history[len(history)-1].Cause)
+
+			_, err = clubsService.GetSquadIDByCardID(ctx, cardIDs[i])
+			assert.True(t, clubs.ErrNoSquad.Has(err))
+
+			// the traded cards are not the captains and have no instructions in the squads of their former owners.
+			captainID, err := db.Clubs().GetCaptainID(ctx, squadIDs[i])
+			require.NoError(t, err)
+			assert.Equal(t, uuid.Nil, captainID)
+
+			instructions, err := db.Clubs().ListPlayerInstructions(ctx, squadIDs[i])
+			require.NoError(t, err)
+			assert.Empty(t, instructions)
+		}
+		assertBalance(ctx, t, financesService, clubIDs[0], 1100)
+		assertBalance(ctx, t, financesService, clubIDs[1], 900)
+
+		tradeFromDB, err := tradesService.Get(ctx, traders[0].ID, counter.ID)
+		require.NoError(t, err)
+		assert.Equal(t, trades.StatusAccepted, tradeFromDB.Status)
+
+		t.Run("cards already traded", func(t *testing.T) {
+			_, err := tradesService.Propose(ctx, trades.Trade{
+				ProposerID:       traders[1].ID,
+				RecipientID:      traders[0].ID,
+				OfferedCardIDs:   []uuid.UUID{cardIDs[1]},
+				RequestedCardIDs: []uuid.UUID{cardIDs[0]},
+			})
+			assert.True(t, trades.ErrInvalidTrade.Has(err))
+		})
+
+		t.Run("card put on sale before acceptance", func(t *testing.T) {
+			trade, err := tradesService.Propose(ctx, trades.Trade{
+				ProposerID:       traders[0].ID,
+				RecipientID:      traders[1].ID,
+				RequestedCardIDs: []uuid.UUID{cardIDs[0]},
+				Amount:           *big.NewInt(100),
+			})
+			require.NoError(t, err)
+			require.NoError(t, db.Cards().UpdateStatus(ctx, cardIDs[0], cards.StatusSale))
+
+			payment, err := financesService.NewPayment(ctx, trade.ID, traders[1].ID, traders[0].ID, trade.Amount, finances.Fees{})
+			require.NoError(t, err)
+			err = db.Trades().Accept(ctx, trades.Acceptance{
+				TradeID: trade.ID,
+				Swaps:   []trades.Swap{{CardID: cardIDs[0], FromID: traders[1].ID, ToID: traders[0].ID}},
+				Payment: &payment,
+			})
+			assert.True(t, trades.ErrInvalidTrade.Has(err))
+
+			// nothing is changed, so the trade could still be answered.
+			tradeFromDB, err := tradesService.Get(ctx, traders[0].ID, trade.ID)
+			require.NoError(t, err)
+			assert.Equal(t, trades.StatusPending, tradeFromDB.Status)
+
+			card, err := cardsService.Get(ctx, cardIDs[0])
+			require.NoError(t, err)
+			assert.Equal(t, traders[1].ID, card.UserID)
+			assertBalance(ctx, t, financesService, clubIDs[0], 1000)
+			assertBalance(ctx, t, financesService, clubIDs[1], 900)
+		})
+	})
+}
+
+func assertBalance(ctx context.Context, t *testing.T, financesService *finances.Service, clubID uuid.UUID, expected int64) {
+	balance, err := financesService.GetBalance(ctx, clubID)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(expected).String(), balance.String())
+}
+
+func compareTrades(t *testing.T, expected, actual trades.Trade) {
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.ProposerID, actual.ProposerID)
+	assert.Equal(t, expected.RecipientID, actual.RecipientID)
+	assert.Equal(t, expected.OfferedCardIDs, actual.OfferedCardIDs)
+	assert.Equal(t, expected.RequestedCardIDs, actual.RequestedCardIDs)
+	assert.Equal(t, expected.Amount.String(), actual.Amount.String())
+	assert.Equal(t, expected.Status, actual.Status)
+	assert.Equal(t, expected.CounterOfID, actual.CounterOfID)
+	assert.True(t, expected.CreatedAt.Equal(actual.CreatedAt))
+	assert.True(t, expected.ExpiresAt.Equal(actual.ExpiresAt))
+}
@@ -0,0 +1,56 @@
+// Copyright (C) 2022 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package watchlists
+
+import (
+	"context"
+
+	"github.com/BoostyLabs/thelooper"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/internal/logger"
+)
+
+var (
+	// ChoreError represents watchlists chore error type.
+	ChoreError = errs.Class("watchlists chore error")
+)
+
+// Chore notifies users about the changes of the watched lots and the new lots which match their saved searches.
+//
+// architecture: Chore
+type Chore struct {
+	log        logger.Logger
+	loop       *thelooper.Loop
+	watchlists *Service
+}
+
+// NewChore instantiates Chore.
+func NewChore(log logger.Logger, config Config, watchlists *Service) *Chore {
+	return &Chore{
+		log:        log,
+		loop:       thelooper.NewLoop(config.NotificationInterval),
+		watchlists: watchlists,
+	}
+}
+
+// Run starts the chore for notifying watchers of the lots and owners of the saved searches.
+func (chore *Chore) Run(ctx context.Context) error {
+	return chore.loop.Run(ctx, func(ctx context.Context) error {
+		if err := chore.watchlists.NotifyWatchers(ctx); err != nil {
+			chore.log.Error("could not notify watchers of the lots", ChoreError.Wrap(err))
+		}
+
+		if err := chore.watchlists.NotifySearches(ctx); err != nil {
+			chore.log.Error("could not notify about new lots of the saved searches", ChoreError.Wrap(err))
+		}
+
+		return nil
+	})
+}
+
+// Close closes the chore for notifying about the lots.
+func (chore *Chore) Close() {
+	chore.loop.Close()
+}
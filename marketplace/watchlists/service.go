@@ -0,0 +1,206 @@
+// Copyright (C) 2022 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package watchlists
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/console/connections"
+	"ultimatedivision/console/emails"
+	"ultimatedivision/gameplay/queue"
+	"ultimatedivision/marketplace"
+	"ultimatedivision/users"
+)
+
+// ErrWatchlists indicates that there was an error in the service.
+var ErrWatchlists = errs.Class("watchlists service error")
+
+// Service is handling watchlists related logic.
+//
+// architecture: Service
+type Service struct {
+	config      Config
+	watchlists  DB
+	marketplace *marketplace.Service
+	users       *users.Service
+	connections *connections.Service
+	emails      *emails.Service
+}
+
+// NewService is a constructor for watchlists service.
+func NewService(config Config, watchlists DB, marketplace *marketplace.Service, users *users.Service, connections *connections.Service, emails *emails.Service) *Service {
+	return &Service{
+		config:      config,
+		watchlists:  watchlists,
+		marketplace: marketplace,
+		users:       users,
+		connections: connections,
+		emails:      emails,
+	}
+}
+
+// Watch adds active lot to the watchlist of the user, the user is notified only about the changes made after it.
+func (service *Service) Watch(ctx context.Context, userID, lotID uuid.UUID, email bool) error {
+	lot, err := service.marketplace.GetLotByID(ctx, lotID)
+	if err != nil {
+		return ErrWatchlists.Wrap(err)
+	}
+	if lot.Status != marketplace.StatusActive {
+		return ErrWatchlists.New("lot is not active")
+	}
+
+	watch := Watch{
+		UserID:        userID,
+		LotID:         lotID,
+		Email:         email,
+		NotifiedPrice: lot.CurrentPrice,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	return ErrWatchlists.Wrap(service.watchlists.CreateWatch(ctx, watch))
+}
+
+// Unwatch removes lot from the watchlist of the user.
+func (service *Service) Unwatch(ctx context.Context, userID, lotID uuid.UUID) error {
+	return ErrWatchlists.Wrap(service.watchlists.DeleteWatch(ctx, userID, lotID))
+}
+
+// ListWatches returns watchlist of the user.
+func (service *Service) ListWatches(ctx context.Context, userID uuid.UUID) ([]Watch, error) {
+	watches, err := service.watchlists.ListWatchesByUserID(ctx, userID)
+	return watches, ErrWatchlists.Wrap(err)
+}
+
+// SaveSearch saves search of the user, the user is notified only about the lots started after it.
+func (service *Service) SaveSearch(ctx context.Context, search SavedSearch) (SavedSearch, error) {
+	if err := search.Query.Validate(); err != nil {
+		return SavedSearch{}, ErrWatchlists.Wrap(err)
+	}
+	if search.SaleMode != "" && !search.SaleMode.IsValid() {
+		return SavedSearch{}, ErrWatchlists.New("sale mode is not correct")
+	}
+
+	search.ID = uuid.New()
+	search.CreatedAt = time.Now().UTC()
+	search.NotifiedAt = search.CreatedAt
+
+	return search, ErrWatchlists.Wrap(service.watchlists.CreateSavedSearch(ctx, search))
+}
+
+// DeleteSearch deletes saved search of the user.
+func (service *Service) DeleteSearch(ctx context.Context, userID, id uuid.UUID) error {
+	return ErrWatchlists.Wrap(service.watchlists.DeleteSavedSearch(ctx, userID, id))
+}
+
+// ListSearches returns saved searches of the user.
+func (service *Service) ListSearches(ctx context.Context, userID uuid.UUID) ([]SavedSearch, error) {
+	searches, err := service.watchlists.ListSavedSearchesByUserID(ctx, userID)
+	return searches, ErrWatchlists.Wrap(err)
+}
+
+// NotifyWatchers notifies watchers of the lots about the changes of the lots, watches of the lots which are not
+// active anymore are deleted.
+func (service *Service) NotifyWatchers(ctx context.Context) error {
+	watches, err := service.watchlists.ListWatches(ctx)
+	if err != nil {
+		return ErrWatchlists.Wrap(err)
+	}
+
+	var errlist errs.Group
+	lots := make(map[uuid.UUID]marketplace.Lot)
+	for _, watch := range watches {
+		lot, ok := lots[watch.LotID]
+		if !ok {
+			if lot, err = service.marketplace.GetLotByID(ctx, watch.LotID); err != nil && !marketplace.ErrNoLot.Has(err) {
+				errlist.Add(err)
+				continue
+			}
+			lots[watch.LotID] = lot
+		}
+
+		if lot.Status != marketplace.StatusActive {
+			errlist.Add(service.watchlists.DeleteWatch(ctx, watch.UserID, watch.LotID))
+			continue
+		}
+
+		notifications, updated := watch.Notifications(lot, time.Now().UTC(), service.config.EndingSoon)
+		if len(notifications) == 0 && updated.EndingNotified == watch.EndingNotified {
+			continue
+		}
+
+		if err = service.watchlists.UpdateWatch(ctx, updated); err != nil {
+			errlist.Add(err)
+			continue
+		}
+
+		for _, notification := range notifications {
+			errlist.Add(service.notify(ctx, watch.UserID, notification, watch.Email))
+		}
+	}
+
+	return ErrWatchlists.Wrap(errlist.Err())
+}
+
+// NotifySearches notifies users about the new lots which match their saved searches.
+func (service *Service) NotifySearches(ctx context.Context) error {
+	searches, err := service.watchlists.ListSavedSearches(ctx)
+	if err != nil {
+		return ErrWatchlists.Wrap(err)
+	}
+
+	var errlist errs.Group
+	for _, search := range searches {
+		lots, err := service.marketplace.ListNewLotsWithQuery(ctx, search.Query, search.SaleMode, search.NotifiedAt, service.config.SearchLimit)
+		if err != nil {
+			errlist.Add(err)
+			continue
+		}
+		if len(lots) == 0 {
+			continue
+		}
+
+		for _, lot := range lots {
+			if lot.UserID == search.UserID {
+				continue
+			}
+
+			notification := NewNotification(NotificationNewLot, lot)
+			notification.SearchID = search.ID
+			errlist.Add(service.notify(ctx, search.UserID, notification, search.Email))
+		}
+
+		// lots are listed in the order they were started, so the lots which did not fit the limit
+		// are notified by the next check.
+		errlist.Add(service.watchlists.UpdateSavedSearchNotifiedAt(ctx, search.ID, lots[len(lots)-1].StartTime))
+	}
+
+	return ErrWatchlists.Wrap(errlist.Err())
+}
+
+// notify sends notification to the websocket connection of the user if the user is connected
+// and by email if it is requested.
+func (service *Service) notify(ctx context.Context, userID uuid.UUID, notification Notification, email bool) error {
+	var errlist errs.Group
+
+	err := service.connections.WriteJSON(userID, queue.Response{Status: http.StatusOK, Message: notification})
+	if err != nil && !connections.ErrNoConnection.Has(err) {
+		errlist.Add(err)
+	}
+
+	if email {
+		user, err := service.users.Get(ctx, userID)
+		if err != nil {
+			errlist.Add(err)
+		} else {
+			errlist.Add(service.emails.SendMarketplaceNotification(user.Email, notification.Subject(), notification.Text()))
+		}
+	}
+
+	return errlist.Err()
+}
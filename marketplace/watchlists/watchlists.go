@@ -0,0 +1,161 @@
+// Copyright (C) 2022 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package watchlists
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+
+	"ultimatedivision/cards"
+	"ultimatedivision/marketplace"
+)
+
+// ErrNoWatch indicates that watch does not exist.
+var ErrNoWatch = errs.Class("watch does not exist")
+
+// ErrNoSavedSearch indicates that saved search does not exist.
+var ErrNoSavedSearch = errs.Class("saved search does not exist")
+
+// DB is exposing access to watchlists db.
+//
+// architecture: DB
+type DB interface {
+	// CreateWatch adds watch of the lot in the database.
+	CreateWatch(ctx context.Context, watch Watch) error
+	// ListWatches returns all watches from the database.
+	ListWatches(ctx context.Context) ([]Watch, error)
+	// ListWatchesByUserID returns watches of the user from the database.
+	ListWatchesByUserID(ctx context.Context, userID uuid.UUID) ([]Watch, error)
+	// UpdateWatch updates notified state of the watch in the database.
+	UpdateWatch(ctx context.Context, watch Watch) error
+	// DeleteWatch deletes watch of the lot by the user in the database.
+	DeleteWatch(ctx context.Context, userID, lotID uuid.UUID) error
+	// CreateSavedSearch adds saved search in the database.
+	CreateSavedSearch(ctx context.Context, search SavedSearch) error
+	// ListSavedSearches returns all saved searches from the database.
+	ListSavedSearches(ctx context.Context) ([]SavedSearch, error)
+	// ListSavedSearchesByUserID returns saved searches of the user from the database.
+	ListSavedSearchesByUserID(ctx context.Context, userID uuid.UUID) ([]SavedSearch, error)
+	// UpdateSavedSearchNotifiedAt updates time of the last notification of the saved search in the database.
+	UpdateSavedSearchNotifiedAt(ctx context.Context, id uuid.UUID, notifiedAt time.Time) error
+	// DeleteSavedSearch deletes saved search of the user in the database.
+	DeleteSavedSearch(ctx context.Context, userID, id uuid.UUID) error
+}
+
+// Config defines configuration for watchlists.
+type Config struct {
+	NotificationInterval time.Duration `json:"notificationInterval"`
+	// EndingSoon defines how long before the end of the lot its watchers are notified, it is disabled if zero.
+	EndingSoon time.Duration `json:"endingSoon"`
+	// SearchLimit defines max number of the new lots the saved search is notified about at once.
+	SearchLimit int `json:"searchLimit"`
+}
+
+// Watch describes lot watched by the user.
+type Watch struct {
+	UserID uuid.UUID `json:"userId"`
+	LotID  uuid.UUID `json:"lotId"`
+	// Email indicates that notifications are also sent by email.
+	Email bool `json:"email"`
+	// NotifiedPrice is the price of the lot the user was last notified about.
+	NotifiedPrice big.Int `json:"-"`
+	// EndingNotified indicates that the user was notified that the lot is about to end.
+	EndingNotified bool      `json:"-"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// Notifications returns notifications about changes of the watched lot at the moment and the watch updated,
+// so the same changes are not notified again. The end of the lot is notified again if soft close moved it further.
+func (watch Watch) Notifications(lot marketplace.Lot, now time.Time, endingSoon time.Duration) ([]Notification, Watch) {
+	var notifications []Notification
+
+	if lot.CurrentPrice.Cmp(&watch.NotifiedPrice) > 0 {
+		if lot.ShopperID != uuid.Nil && lot.ShopperID != watch.UserID {
+			notifications = append(notifications, NewNotification(NotificationOutbid, lot))
+		}
+		watch.NotifiedPrice.Set(&lot.CurrentPrice)
+	}
+
+	if endingSoon > 0 {
+		isEnding := lot.EndTime.Sub(now) <= endingSoon
+		if isEnding && !watch.EndingNotified {
+			notifications = append(notifications, NewNotification(NotificationEndingSoon, lot))
+		}
+		watch.EndingNotified = isEnding
+	}
+
+	return notifications, watch
+}
+
+// SavedSearch describes search of the lots saved by the user, the user is notified about new lots which match it.
+type SavedSearch struct {
+	ID       uuid.UUID            `json:"id"`
+	UserID   uuid.UUID            `json:"userId"`
+	Name     string               `json:"name"`
+	Query    cards.Query          `json:"query"`
+	SaleMode marketplace.SaleMode `json:"saleMode"`
+	// Email indicates that notifications are also sent by email.
+	Email bool `json:"email"`
+	// NotifiedAt is the start time of the last new lot the search was notified about.
+	NotifiedAt time.Time `json:"-"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// NotificationKind defines the list of possible kinds of the notifications.
+type NotificationKind string
+
+const (
+	// NotificationOutbid indicates that the watched lot got a higher bid of another user.
+	NotificationOutbid NotificationKind = "outbid"
+	// NotificationEndingSoon indicates that the watched lot is about to end.
+	NotificationEndingSoon NotificationKind = "endingSoon"
+	// NotificationNewLot indicates that the new lot matches the saved search.
+	NotificationNewLot NotificationKind = "newLot"
+)
+
+// Notification describes notification about the lot sent to the user.
+type Notification struct {
+	Kind     NotificationKind `json:"kind"`
+	LotID    uuid.UUID        `json:"lotId"`
+	SearchID uuid.UUID        `json:"searchId"`
+	Price    big.Int          `json:"price"`
+	EndTime  time.Time        `json:"endTime"`
+}
+
+// NewNotification returns notification of the kind about the lot.
+func NewNotification(kind NotificationKind, lot marketplace.Lot) Notification {
+	price := lot.CurrentPrice
+	if price.BitLen() == 0 {
+		price = lot.StartPrice
+	}
+
+	return Notification{
+		Kind:    kind,
+		LotID:   lot.ID,
+		Price:   price,
+		EndTime: lot.EndTime,
+	}
+}
+
+// Subject returns subject of the email with the notification.
+func (notification Notification) Subject() string {
+	switch notification.Kind {
+	case NotificationOutbid:
+		return "your watched lot was outbid"
+	case NotificationEndingSoon:
+		return "your watched lot is ending soon"
+	default:
+		return "new lot matches your search"
+	}
+}
+
+// Text returns text of the email with the notification.
+func (notification Notification) Text() string {
+	return fmt.Sprintf("lot %s, price %s, ends at %s", notification.LotID, notification.Price.String(), notification.EndTime.Format(time.RFC1123))
+}
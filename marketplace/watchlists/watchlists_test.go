@@ -0,0 +1,240 @@
+// Copyright (C) 2022 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package watchlists_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ultimatedivision"
+	"ultimatedivision/cards"
+	"ultimatedivision/database/dbtesting"
+	"ultimatedivision/marketplace"
+	"ultimatedivision/marketplace/watchlists"
+	"ultimatedivision/pkg/pagination"
+	"ultimatedivision/pkg/sqlsearchoperators"
+	"ultimatedivision/users"
+)
+
+func TestWatchNotifications(t *testing.T) {
+	now := time.Now().UTC()
+	watch := watchlists.Watch{UserID: uuid.New(), LotID: uuid.New(), NotifiedPrice: *big.NewInt(100)}
+	lot := marketplace.Lot{
+		ID:           watch.LotID,
+		ShopperID:    uuid.New(),
+		Status:       marketplace.StatusActive,
+		CurrentPrice: *big.NewInt(200),
+		EndTime:      now.Add(time.Hour),
+	}
+
+	t.Run("outbid", func(t *testing.T) {
+		notifications, updated := watch.Notifications(lot, now, 5*time.Minute)
+		require.Len(t, notifications, 1)
+		assert.Equal(t, watchlists.NotificationOutbid, notifications[0].Kind)
+		assert.Equal(t, 0, updated.NotifiedPrice.Cmp(big.NewInt(200)))
+
+		notifications, _ = updated.Notifications(lot, now, 5*time.Minute)
+		assert.Empty(t, notifications)
+	})
+
+	t.Run("own bid", func(t *testing.T) {
+		ownLot := lot
+		ownLot.ShopperID = watch.UserID
+
+		notifications, updated := watch.Notifications(ownLot, now, 5*time.Minute)
+		assert.Empty(t, notifications)
+		assert.Equal(t, 0, updated.NotifiedPrice.Cmp(big.NewInt(200)))
+	})
+
+	t.Run("ending soon once", func(t *testing.T) {
+		endingLot := lot
+		endingLot.CurrentPrice = watch.NotifiedPrice
+		endingLot.EndTime = now.Add(time.Minute)
+
+		notifications, updated := watch.Notifications(endingLot, now, 5*time.Minute)
+		require.Len(t, notifications, 1)
+		assert.Equal(t, watchlists.NotificationEndingSoon, notifications[0].Kind)
+		assert.True(t, updated.EndingNotified)
+
+		notifications, updated = updated.Notifications(endingLot, now, 5*time.Minute)
+		assert.Empty(t, notifications)
+
+		endingLot.EndTime = now.Add(time.Hour)
+		notifications, updated = updated.Notifications(endingLot, now, 5*time.Minute)
+		assert.Empty(t, notifications)
+		assert.False(t, updated.EndingNotified)
+
+		endingLot.EndTime = now.Add(time.Minute)
+		notifications, _ = updated.Notifications(endingLot, now, 5*time.Minute)
+		assert.Len(t, notifications, 1)
+	})
+
+	t.Run("ending soon disabled", func(t *testing.T) {
+		endingLot := lot
+		endingLot.CurrentPrice = watch.NotifiedPrice
+		endingLot.EndTime = now.Add(time.Minute)
+
+		notifications, _ := watch.Notifications(endingLot, now, 0)
+		assert.Empty(t, notifications)
+	})
+}
+
+func TestWatchlists(t *testing.T) {
+	user := users.User{
+		ID:           uuid.New(),
+		Email:        "watcher@gmail.com",
+		PasswordHash: []byte{0},
+		NickName:     "watcher",
+		FirstName:    "Watcher",
+		LastName:     "Watcher",
+		LastLogin:    time.Now().UTC(),
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	card := cards.Card{
+		ID:           uuid.New(),
+		PlayerName:   "Dmytro yak muk",
+		Quality:      cards.QualityGold,
+		DominantFoot: "left",
+		Status:       cards.StatusActive,
+		Type:         cards.TypeWon,
+		UserID:       uuid.New(),
+	}
+
+	lot := marketplace.Lot{
+		ID:           uuid.New(),
+		CardID:       card.ID,
+		Type:         marketplace.TypeCard,
+		SaleMode:     marketplace.SaleModeAuction,
+		UserID:       card.UserID,
+		Status:       marketplace.StatusActive,
+		StartPrice:   *big.NewInt(100),
+		CurrentPrice: *big.NewInt(100),
+		StartTime:    time.Now().UTC(),
+		EndTime:      time.Now().Add(time.Hour).UTC(),
+		Period:       marketplace.MinPeriod,
+	}
+
+	watch := watchlists.Watch{
+		UserID:        user.ID,
+		LotID:         lot.ID,
+		Email:         true,
+		NotifiedPrice: *big.NewInt(100),
+		CreatedAt:     time.Now().UTC().Round(time.Second),
+	}
+
+	search := watchlists.SavedSearch{
+		ID:     uuid.New(),
+		UserID: user.ID,
+		Name:   "gold cards",
+		Query: cards.Query{
+			Where: &cards.Condition{
+				Filter: &cards.Filters{Name: cards.FilterQuality, Value: string(cards.QualityGold), SearchOperator: sqlsearchoperators.EQ},
+			},
+		},
+		SaleMode:   marketplace.SaleModeAuction,
+		NotifiedAt: time.Now().UTC().Round(time.Second),
+		CreatedAt:  time.Now().UTC().Round(time.Second),
+	}
+
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db ultimatedivision.DB) {
+		repositoryWatchlists := db.Watchlists()
+
+		require.NoError(t, db.Users().Create(ctx, user))
+		require.NoError(t, db.Cards().Create(ctx, card))
+		require.NoError(t, db.Marketplace().CreateLot(ctx, lot))
+
+		t.Run("watch", func(t *testing.T) {
+			require.NoError(t, repositoryWatchlists.CreateWatch(ctx, watch))
+			// watching again only changes email.
+			again := watch
+			again.Email = false
+			again.NotifiedPrice = *big.NewInt(1)
+			require.NoError(t, repositoryWatchlists.CreateWatch(ctx, again))
+
+			watches, err := repositoryWatchlists.ListWatchesByUserID(ctx, user.ID)
+			require.NoError(t, err)
+			require.Len(t, watches, 1)
+			assert.False(t, watches[0].Email)
+			assert.Equal(t, 0, watches[0].NotifiedPrice.Cmp(big.NewInt(100)))
+
+			updated := watches[0]
+			updated.NotifiedPrice = *big.NewInt(200)
+			updated.EndingNotified = true
+			require.NoError(t, repositoryWatchlists.UpdateWatch(ctx, updated))
+
+			watches, err = repositoryWatchlists.ListWatches(ctx)
+			require.NoError(t, err)
+			require.Len(t, watches, 1)
+			assert.True(t, watches[0].EndingNotified)
+			assert.Equal(t, 0, watches[0].NotifiedPrice.Cmp(big.NewInt(200)))
+
+			require.NoError(t, repositoryWatchlists.DeleteWatch(ctx, user.ID, lot.ID))
+			err = repositoryWatchlists.DeleteWatch(ctx, user.ID, lot.ID)
+			require.True(t, watchlists.ErrNoWatch.Has(err))
+		})
+
+		t.Run("saved search", func(t *testing.T) {
+			require.NoError(t, repositoryWatchlists.CreateSavedSearch(ctx, search))
+
+			searches, err := repositoryWatchlists.ListSavedSearchesByUserID(ctx, user.ID)
+			require.NoError(t, err)
+			require.Len(t, searches, 1)
+			assert.Equal(t, search.Query, searches[0].Query)
+			assert.Equal(t, search.SaleMode, searches[0].SaleMode)
+
+			notifiedAt := time.Now().UTC().Add(time.Minute).Round(time.Second)
+			require.NoError(t, repositoryWatchlists.UpdateSavedSearchNotifiedAt(ctx, search.ID, notifiedAt))
+
+			searches, err = repositoryWatchlists.ListSavedSearches(ctx)
+			require.NoError(t, err)
+			require.Len(t, searches, 1)
+			assert.True(t, notifiedAt.Equal(searches[0].NotifiedAt))
+
+			require.NoError(t, repositoryWatchlists.DeleteSavedSearch(ctx, user.ID, search.ID))
+			err = repositoryWatchlists.DeleteSavedSearch(ctx, user.ID, search.ID)
+			require.True(t, watchlists.ErrNoSavedSearch.Has(err))
+		})
+
+		t.Run("new lots", func(t *testing.T) {
+			cursor := pagination.Cursor{Limit: 10, Page: 1}
+
+			page, err := db.Marketplace().ListActiveLotsWithQuerySince(ctx, search.Query, search.SaleMode, lot.StartTime.Add(-time.Minute), cursor)
+			require.NoError(t, err)
+			require.Len(t, page.Lots, 1)
+			assert.Equal(t, lot.ID, page.Lots[0].ID)
+
+			page, err = db.Marketplace().ListActiveLotsWithQuerySince(ctx, search.Query, search.SaleMode, lot.StartTime.Add(time.Minute), cursor)
+			require.NoError(t, err)
+			assert.Empty(t, page.Lots)
+
+			card2 := card
+			card2.ID = uuid.New()
+			card2.PlayerName = "Andriy yak muk"
+			require.NoError(t, db.Cards().Create(ctx, card2))
+			lot2 := lot
+			lot2.ID = uuid.New()
+			lot2.CardID = card2.ID
+			lot2.StartTime = lot.StartTime.Add(-30 * time.Second)
+			require.NoError(t, db.Marketplace().CreateLot(ctx, lot2))
+
+			// new lots are listed in the order they were started whatever the order of the cards is.
+			page, err = db.Marketplace().ListActiveLotsWithQuerySince(ctx, search.Query, search.SaleMode, lot.StartTime.Add(-time.Minute), pagination.Cursor{Limit: 1, Page: 1})
+			require.NoError(t, err)
+			require.Len(t, page.Lots, 1)
+			assert.Equal(t, lot2.ID, page.Lots[0].ID)
+
+			page, err = db.Marketplace().ListActiveLotsWithQuerySince(ctx, search.Query, search.SaleMode, lot2.StartTime, cursor)
+			require.NoError(t, err)
+			require.Len(t, page.Lots, 1)
+			assert.Equal(t, lot.ID, page.Lots[0].ID)
+		})
+	})
+}
@@ -19,11 +19,14 @@ import (
 	"ultimatedivision/cards/avatars"
 	"ultimatedivision/cards/nfts"
 	"ultimatedivision/cards/waitlist"
+	"ultimatedivision/cards/youthacademy"
 	"ultimatedivision/clubs"
+	"ultimatedivision/clubs/badges"
 	"ultimatedivision/console/connections"
 	"ultimatedivision/console/consoleserver"
 	"ultimatedivision/console/emails"
 	"ultimatedivision/divisions"
+	"ultimatedivision/finances"
 	"ultimatedivision/gameplay/gameengine"
 	"ultimatedivision/gameplay/matches"
 	"ultimatedivision/gameplay/matchmaking"
@@ -32,6 +35,8 @@ import (
 	"ultimatedivision/internal/metrics"
 	"ultimatedivision/marketplace"
 	"ultimatedivision/marketplace/bids"
+	"ultimatedivision/marketplace/trades"
+	"ultimatedivision/marketplace/watchlists"
 	"ultimatedivision/pkg/auth"
 	mail2 "ultimatedivision/pkg/mail"
 	"ultimatedivision/pkg/velas"
@@ -72,6 +77,9 @@ type DB interface {
 	// Clubs provides access to clubs db.
 	Clubs() clubs.DB
 
+	// Finances provides access to finances db.
+	Finances() finances.DB
+
 	// LootBoxes provides access to lootboxes db.
 	LootBoxes() lootboxes.DB
 
@@ -81,6 +89,12 @@ type DB interface {
 	// Bids provides access to bids db.
 	Bids() bids.DB
 
+	// Watchlists provides access to watchlists db.
+	Watchlists() watchlists.DB
+
+	// Trades provides access to trades db.
+	Trades() trades.DB
+
 	// Matches provides access to matches db.
 	Matches() matches.DB
 
@@ -145,6 +159,14 @@ type Config struct {
 		avatars.Config
 	} `json:"avatars"`
 
+	Badges struct {
+		badges.Config
+	} `json:"badges"`
+
+	Finances struct {
+		finances.Config
+	} `json:"finances"`
+
 	NFTs struct {
 		nfts.Config
 	} `json:"nfts"`
@@ -157,6 +179,14 @@ type Config struct {
 		bids.Config
 	} `json:"bids"`
 
+	Watchlists struct {
+		watchlists.Config
+	} `json:"watchlists"`
+
+	Trades struct {
+		trades.Config
+	} `json:"trades"`
+
 	LootBoxes struct {
 		Config lootboxes.Config `json:"lootBoxes"`
 	} `json:"lootBoxes"`
@@ -189,6 +219,10 @@ type Config struct {
 		store.Config
 	} `json:"store"`
 
+	YouthAcademy struct {
+		youthacademy.Config
+	} `json:"youthAcademy"`
+
 	Velas struct {
 		velas.Config
 	} `json:"velas"`
@@ -220,7 +254,8 @@ type Peer struct {
 
 	// exposes cards related logic.
 	Cards struct {
-		Service *cards.Service
+		Service      *cards.Service
+		YouthAcademy *youthacademy.Chore
 	}
 
 	// exposes avatars related logic.
@@ -245,6 +280,17 @@ type Peer struct {
 		Service *clubs.Service
 	}
 
+	// exposes badges related logic.
+	Badges struct {
+		Service *badges.Service
+	}
+
+	// exposes finances related logic.
+	Finances struct {
+		Service     *finances.Service
+		UpkeepChore *finances.Chore
+	}
+
 	// exposes lootboxes related logic.
 	LootBoxes struct {
 		Service *lootboxes.Service
@@ -262,6 +308,18 @@ type Peer struct {
 		BidsChore *bids.Chore
 	}
 
+	// exposes watchlists related logic.
+	Watchlists struct {
+		Service           *watchlists.Service
+		NotificationChore *watchlists.Chore
+	}
+
+	// exposes trades related logic.
+	Trades struct {
+		Service         *trades.Service
+		ExpirationChore *trades.Chore
+	}
+
 	// exposes matches related logic.
 	Matches struct {
 		Service *matches.Service
@@ -459,6 +517,28 @@ func New(logger logger.Logger, config Config, db DB) (peer *Peer, err error) {
 		)
 	}
 
+	{ // badges setup.
+		peer.Badges.Service = badges.NewService(
+			peer.Clubs.Service,
+			config.Badges.Config,
+		)
+	}
+
+	{ // finances setup.
+		peer.Finances.Service = finances.NewService(
+			peer.Database.Finances(),
+			peer.Clubs.Service,
+			peer.Cards.Service,
+			config.Finances.Config,
+		)
+
+		peer.Finances.UpkeepChore = finances.NewChore(
+			config.Finances.Config,
+			peer.Finances.Service,
+			peer.Clubs.Service,
+		)
+	}
+
 	{ // lootboxes setup.
 		peer.LootBoxes.Service = lootboxes.NewService(
 			peer.Log,
@@ -476,6 +556,7 @@ func New(logger logger.Logger, config Config, db DB) (peer *Peer, err error) {
 			peer.Users.Service,
 			peer.Cards.Service,
 			peer.NFTs.Service,
+			peer.Finances.Service,
 		)
 
 		peer.Marketplace.ExpirationLotChore = marketplace.NewChore(
@@ -497,6 +578,7 @@ func New(logger logger.Logger, config Config, db DB) (peer *Peer, err error) {
 			config.Matches.Config,
 			peer.Clubs.Service,
 			peer.Cards.Service,
+			peer.Finances.Service,
 		)
 	}
 
@@ -522,8 +604,10 @@ func New(logger logger.Logger, config Config, db DB) (peer *Peer, err error) {
 			peer.Divisions.Service,
 			peer.Matches.Service,
 			peer.Clubs.Service,
+			peer.Cards.Service,
 			peer.Users.Service,
 			peer.CurrencyWaitList.Service,
+			peer.Finances.Service,
 		)
 
 		peer.Seasons.ExpirationSeasons = seasons.NewChore(
@@ -558,6 +642,7 @@ func New(logger logger.Logger, config Config, db DB) (peer *Peer, err error) {
 			peer.Database.Store(),
 			peer.Cards.Service,
 			peer.WaitList.Service,
+			peer.Finances.Service,
 		)
 
 		peer.Store.StoreRenewal = store.NewChore(
@@ -568,28 +653,66 @@ func New(logger logger.Logger, config Config, db DB) (peer *Peer, err error) {
 		)
 	}
 
+	{ // youth academy setup.
+		peer.Cards.YouthAcademy = youthacademy.NewChore(
+			config.YouthAcademy.Config,
+			peer.Cards.Service,
+			peer.Clubs.Service,
+			peer.Avatars.Service,
+		)
+	}
+
 	{ // bids setup.
 		peer.Bids.Service = bids.NewService(
+			config.Bids.Config,
 			peer.Database.Bids(),
 			peer.Marketplace.Service,
 			peer.Cards.Service,
 			peer.Clubs.Service,
 			peer.NFTs.Service,
 			peer.Users.Service,
+			peer.Finances.Service,
 		)
 
 		peer.Bids.BidsChore = bids.NewChore(
 			logger,
 			config.Bids.Config,
 			peer.Bids.Service,
-			peer.Clubs.Service,
+			peer.Marketplace.Service,
+		)
+
+	}
+
+	{ // watchlists setup.
+		peer.Watchlists.Service = watchlists.NewService(
+			config.Watchlists.Config,
+			peer.Database.Watchlists(),
 			peer.Marketplace.Service,
 			peer.Users.Service,
+			peer.Connections.Service,
+			peer.Console.EmailService,
+		)
+
+		peer.Watchlists.NotificationChore = watchlists.NewChore(
+			logger,
+			config.Watchlists.Config,
+			peer.Watchlists.Service,
+		)
+	}
+
+	{ // trades setup.
+		peer.Trades.Service = trades.NewService(
+			config.Trades.Config,
+			peer.Database.Trades(),
 			peer.Cards.Service,
-			peer.NFTs.Service,
-			peer.WaitList.Service,
+			peer.Finances.Service,
 		)
 
+		peer.Trades.ExpirationChore = trades.NewChore(
+			logger,
+			config.Trades.Config,
+			peer.Trades.Service,
+		)
 	}
 
 	{ // game engine setup.
@@ -605,7 +728,7 @@ func New(logger logger.Logger, config Config, db DB) (peer *Peer, err error) {
 	}
 
 	{ // matchmaking setup.
-		peer.Matchmaking.Service = matchmaking.NewService(peer.Database.Players(), peer.Connections.Service, peer.GameEngine.Service, peer.Queue.PlaceChore, peer.Matches.Service)
+		peer.Matchmaking.Service = matchmaking.NewService(peer.Database.Players(), peer.Connections.Service, peer.GameEngine.Service, peer.Queue.PlaceChore, peer.Matches.Service, peer.Clubs.Service)
 	}
 
 	{ // admin setup.
@@ -625,6 +748,7 @@ func New(logger logger.Logger, config Config, db DB) (peer *Peer, err error) {
 			config.Cards.PercentageQualities,
 			peer.Avatars.Service,
 			peer.Marketplace.Service,
+			peer.Bids.Service,
 			peer.LootBoxes.Service,
 			peer.Clubs.Service,
 			peer.Queue.Service,
@@ -653,7 +777,11 @@ func New(logger logger.Logger, config Config, db DB) (peer *Peer, err error) {
 			peer.LootBoxes.Service,
 			peer.Marketplace.Service,
 			peer.Bids.Service,
+			peer.Watchlists.Service,
+			peer.Trades.Service,
 			peer.Clubs.Service,
+			peer.Badges.Service,
+			peer.Finances.Service,
 			peer.Users.Auth,
 			peer.Users.Service,
 			peer.Queue.Service,
@@ -694,6 +822,12 @@ func (peer *Peer) Run(ctx context.Context) error {
 	group.Go(func() error {
 		return ignoreCancel(peer.Bids.BidsChore.Run(ctx))
 	})
+	group.Go(func() error {
+		return ignoreCancel(peer.Watchlists.NotificationChore.Run(ctx))
+	})
+	group.Go(func() error {
+		return ignoreCancel(peer.Trades.ExpirationChore.Run(ctx))
+	})
 	group.Go(func() error {
 		return ignoreCancel(peer.WaitList.WaitListChore.RunCasperCheckMintEvent(ctx))
 	})
@@ -710,6 +844,12 @@ func (peer *Peer) Run(ctx context.Context) error {
 	group.Go(func() error {
 		return ignoreCancel(peer.Store.StoreRenewal.Run(ctx))
 	})
+	group.Go(func() error {
+		return ignoreCancel(peer.Cards.YouthAcademy.Run(ctx))
+	})
+	group.Go(func() error {
+		return ignoreCancel(peer.Finances.UpkeepChore.Run(ctx))
+	})
 
 	return group.Wait()
 }
@@ -724,6 +864,10 @@ func (peer *Peer) Close() error {
 	peer.Queue.PlaceChore.Close()
 	peer.Seasons.ExpirationSeasons.Close()
 	peer.Store.StoreRenewal.Close()
+	peer.Cards.YouthAcademy.Close()
+	peer.Finances.UpkeepChore.Close()
+	peer.Watchlists.NotificationChore.Close()
+	peer.Trades.ExpirationChore.Close()
 
 	return errlist.Err()
 }
@@ -0,0 +1,105 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package assignment
+
+import (
+	"math"
+)
+
+// Maximize solves the assignment problem with the hungarian algorithm and returns for each row the index of the column
+// assigned to it, so the sum of the weights of the assigned cells is maximal.
+// Each column is assigned at most once, rows which could not be assigned, when there are less columns than rows, get -1.
+func Maximize(weights [][]float64) []int {
+	rows := len(weights)
+	if rows == 0 {
+		return nil
+	}
+	columns := len(weights[0])
+
+	// the algorithm requires rows to be not more than columns, so the matrix is transposed otherwise.
+	if rows > columns {
+		transposed := make([][]float64, columns)
+		for j := range transposed {
+			transposed[j] = make([]float64, rows)
+			for i := 0; i < rows; i++ {
+				transposed[j][i] = weights[i][j]
+			}
+		}
+
+		result := make([]int, rows)
+		for i := range result {
+			result[i] = -1
+		}
+		for j, i := range Maximize(transposed) {
+			if i >= 0 {
+				result[i] = j
+			}
+		}
+
+		return result
+	}
+
+	cost := func(i, j int) float64 {
+		return -weights[i-1][j-1]
+	}
+
+	// potentials of rows and columns and matching of columns with rows, indexed from 1, 0 is a fictive row.
+	u := make([]float64, rows+1)
+	v := make([]float64, columns+1)
+	matching := make([]int, columns+1)
+	way := make([]int, columns+1)
+
+	for i := 1; i <= rows; i++ {
+		matching[0] = i
+		column := 0
+		minv := make([]float64, columns+1)
+		used := make([]bool, columns+1)
+		for j := range minv {
+			minv[j] = math.Inf(1)
+		}
+
+		for matching[column] != 0 {
+			used[column] = true
+			row, delta, next := matching[column], math.Inf(1), 0
+			for j := 1; j <= columns; j++ {
+				if used[j] {
+					continue
+				}
+
+				current := cost(row, j) - u[row] - v[j]
+				if current < minv[j] {
+					minv[j], way[j] = current, column
+				}
+				if minv[j] < delta {
+					delta, next = minv[j], j
+				}
+			}
+
+			for j := 0; j <= columns; j++ {
+				if used[j] {
+					u[matching[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			column = next
+		}
+
+		for column != 0 {
+			previous := way[column]
+			matching[column] = matching[previous]
+			column = previous
+		}
+	}
+
+	result := make([]int, rows)
+	for j := 1; j <= columns; j++ {
+		if matching[j] != 0 {
+			result[matching[j]-1] = j - 1
+		}
+	}
+
+	return result
+}
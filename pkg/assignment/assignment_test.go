@@ -0,0 +1,58 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package assignment
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMaximize(t *testing.T) {
+	type testpair struct {
+		weights [][]float64
+		res     []int
+	}
+
+	testcases := []testpair{
+		{
+			weights: nil,
+			res:     nil,
+		},
+		{
+			// greedy choice of the first row would give 10 + 1 instead of 9 + 8.
+			weights: [][]float64{
+				{10, 9},
+				{8, 1},
+			},
+			res: []int{1, 0},
+		},
+		{
+			weights: [][]float64{
+				{1, 5, 3, 2},
+				{4, 6, 1, 7},
+				{2, 8, 9, 3},
+			},
+			res: []int{1, 3, 2},
+		},
+		{
+			weights: [][]float64{
+				{1},
+				{5},
+				{3},
+			},
+			res: []int{-1, 0, -1},
+		},
+	}
+
+	for _, testcase := range testcases {
+		actualResult := Maximize(testcase.weights)
+		if !reflect.DeepEqual(actualResult, testcase.res) {
+			t.Error(
+				"For", testcase.weights,
+				"expected", testcase.res,
+				"got", actualResult,
+			)
+		}
+	}
+}
@@ -116,8 +116,22 @@ type Casper interface {
 	PutDeploy(deploy sdk.Deploy) (string, error)
 	// GetBlockNumberByHash returns block number by deploy hash.
 	GetBlockNumberByHash(hash string) (int, error)
+	// GetDeployStatus returns status of the execution of the deploy by its hash.
+	GetDeployStatus(hash string) (DeployStatus, error)
 }
 
+// DeployStatus defines the list of possible statuses of the execution of the deploy.
+type DeployStatus string
+
+const (
+	// DeployStatusPending indicates that the deploy is not executed yet.
+	DeployStatusPending DeployStatus = "pending"
+	// DeployStatusSuccess indicates that the deploy is executed successfully.
+	DeployStatusSuccess DeployStatus = "success"
+	// DeployStatusFailure indicates that the execution of the deploy failed.
+	DeployStatusFailure DeployStatus = "failure"
+)
+
 // StringNetworkAddress describes an address for some network.
 type StringNetworkAddress struct {
 	NetworkName string
@@ -216,6 +230,42 @@ func (r *rpcClient) GetBlockNumberByHash(hash string) (int, error) {
 	return blockResp.Header.Height, err
 }
 
+// jsonGetDeployRes describes result of info_get_deploy call, the deploy has no execution results until it is executed.
+type jsonGetDeployRes struct {
+	ExecutionResults []struct {
+		Result struct {
+			Success *json.RawMessage `json:"Success"`
+			Failure *json.RawMessage `json:"Failure"`
+		} `json:"result"`
+	} `json:"execution_results"`
+}
+
+// GetDeployStatus returns status of the execution of the deploy by its hash.
+func (r *rpcClient) GetDeployStatus(hash string) (DeployStatus, error) {
+	resp, err := r.rpcCall("info_get_deploy", map[string]interface{}{
+		"deploy_hash": hash,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var result jsonGetDeployRes
+	if err = json.Unmarshal(resp.Result, &result); err != nil {
+		return "", fmt.Errorf("failed to get deploy: %w", err)
+	}
+
+	for _, executionResult := range result.ExecutionResults {
+		if executionResult.Result.Failure != nil {
+			return DeployStatusFailure, nil
+		}
+		if executionResult.Result.Success != nil {
+			return DeployStatusSuccess, nil
+		}
+	}
+
+	return DeployStatusPending, nil
+}
+
 // JSONPutDeployRes describes result of put_deploy tx.
 type JSONPutDeployRes struct {
 	Hash string `json:"deploy_hash"`
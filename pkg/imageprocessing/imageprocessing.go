@@ -4,13 +4,16 @@
 package imageprocessing
 
 import (
+	"bytes"
 	"fmt"
 	"image"
+	"image/color"
 	"image/draw"
 	"image/png"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 
 	"github.com/fogleman/gg"
 	"github.com/zeebo/errs"
@@ -83,6 +86,42 @@ func Layering(layers []image.Image, width, height int) *image.RGBA {
 	return generalImage
 }
 
+// Colorize tints the layer with the colour in hex format without '#', keeping its transparency,
+// so the white layer gets exactly the colour.
+func Colorize(layer image.Image, hexColor string) (*image.NRGBA, error) {
+	value, err := strconv.ParseUint(hexColor, 16, 32)
+	if err != nil {
+		return nil, err
+	}
+	tint := [3]uint32{uint32(value>>16) & 0xff, uint32(value>>8) & 0xff, uint32(value) & 0xff}
+
+	bounds := layer.Bounds()
+	colorized := image.NewNRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			pixel := color.NRGBAModel.Convert(layer.At(x, y)).(color.NRGBA)
+			colorized.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(uint32(pixel.R) * tint[0] / 0xff),
+				G: uint8(uint32(pixel.G) * tint[1] / 0xff),
+				B: uint8(uint32(pixel.B) * tint[2] / 0xff),
+				A: pixel.A,
+			})
+		}
+	}
+
+	return colorized, nil
+}
+
+// EncodePNG encodes image to png.
+func EncodePNG(baseImage image.Image) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, baseImage); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
 // SaveImage saves image by path.
 func SaveImage(path, fullPath string, baseImage image.Image) error {
 	err := os.MkdirAll(path, os.ModePerm)
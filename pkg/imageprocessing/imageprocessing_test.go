@@ -0,0 +1,57 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package imageprocessing
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestColorize(t *testing.T) {
+	type testpair struct {
+		pixel    color.NRGBA
+		hexColor string
+		res      color.NRGBA
+	}
+
+	testcases := []testpair{
+		{
+			pixel:    color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+			hexColor: "1E90FF",
+			res:      color.NRGBA{R: 0x1E, G: 0x90, B: 0xFF, A: 255},
+		},
+		{
+			pixel:    color.NRGBA{R: 255, G: 255, B: 255, A: 128},
+			hexColor: "FFD700",
+			res:      color.NRGBA{R: 0xFF, G: 0xD7, B: 0x00, A: 128},
+		},
+		{
+			pixel:    color.NRGBA{R: 0, G: 0, B: 0, A: 0},
+			hexColor: "FFFFFF",
+			res:      color.NRGBA{R: 0, G: 0, B: 0, A: 0},
+		},
+	}
+
+	for _, test := range testcases {
+		layer := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+		layer.SetNRGBA(0, 0, test.pixel)
+
+		colorized, err := Colorize(layer, test.hexColor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res := colorized.NRGBAAt(0, 0); res != test.res {
+			t.Error(
+				"For", test.pixel, test.hexColor,
+				"expected", test.res,
+				"got", res,
+			)
+		}
+	}
+
+	if _, err := Colorize(image.NewNRGBA(image.Rect(0, 0, 1, 1)), "not a colour"); err == nil {
+		t.Error("expected error for invalid colour")
+	}
+}
@@ -3,17 +3,61 @@
 
 package pagination
 
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/zeebo/errs"
+)
+
+// ErrInvalidToken indicates that continuation token is malformed.
+var ErrInvalidToken = errs.Class("invalid continuation token")
+
 // Cursor holds cursor entity which is used to create listed page.
+// If Token is set, listing continues right after the item the token points to and Page is ignored.
 type Cursor struct {
-	Limit int `json:"limit"`
-	Page  int `json:"page"`
+	Limit int    `json:"limit"`
+	Page  int    `json:"page"`
+	Token string `json:"token"`
 }
 
 // Page holds page entity which is used to show listed page.
+// Keyset pages have only Limit and NextToken, NextToken is empty on the last page.
 type Page struct {
-	Offset      int `json:"offset"`
-	Limit       int `json:"limit"`
-	CurrentPage int `json:"currentPage"`
-	PageCount   int `json:"pageCount"`
-	TotalCount  int `json:"totalCount"`
+	Offset      int    `json:"offset"`
+	Limit       int    `json:"limit"`
+	CurrentPage int    `json:"currentPage"`
+	PageCount   int    `json:"pageCount"`
+	TotalCount  int    `json:"totalCount"`
+	NextToken   string `json:"nextToken,omitempty"`
+}
+
+// IsKeyset returns true if listing continues after the continuation token instead of using the page number.
+func (cursor Cursor) IsKeyset() bool {
+	return cursor.Token != ""
+}
+
+// EncodeToken returns opaque continuation token which holds sort key of the last listed item.
+func EncodeToken(key ...string) string {
+	// marshaling of the slice of strings never fails.
+	data, _ := json.Marshal(key)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeToken returns sort key of the last listed item, size is the expected number of the key parts.
+func DecodeToken(token string, size int) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidToken.Wrap(err)
+	}
+
+	var key []string
+	if err = json.Unmarshal(data, &key); err != nil {
+		return nil, ErrInvalidToken.Wrap(err)
+	}
+	if len(key) != size {
+		return nil, ErrInvalidToken.New("expected %d key parts, got %d", size, len(key))
+	}
+
+	return key, nil
 }
@@ -0,0 +1,40 @@
+// Copyright (C) 2021 Creditor Corp. Group.
+// See LICENSE for copying information.
+
+package pagination_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ultimatedivision/pkg/pagination"
+)
+
+func TestToken(t *testing.T) {
+	t.Run("encode decode", func(t *testing.T) {
+		token := pagination.EncodeToken("2021-10-19T10:00:00.123456Z", "1f2b3c4d-0000-4000-8000-000000000001")
+
+		key, err := pagination.DecodeToken(token, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2021-10-19T10:00:00.123456Z", "1f2b3c4d-0000-4000-8000-000000000001"}, key)
+	})
+
+	t.Run("wrong key size", func(t *testing.T) {
+		_, err := pagination.DecodeToken(pagination.EncodeToken("1"), 2)
+		require.Error(t, err)
+		assert.True(t, pagination.ErrInvalidToken.Has(err))
+	})
+
+	t.Run("malformed token", func(t *testing.T) {
+		_, err := pagination.DecodeToken("not a token", 1)
+		require.Error(t, err)
+		assert.True(t, pagination.ErrInvalidToken.Has(err))
+	})
+
+	t.Run("is keyset", func(t *testing.T) {
+		assert.False(t, pagination.Cursor{Limit: 10, Page: 2}.IsKeyset())
+		assert.True(t, pagination.Cursor{Limit: 10, Token: pagination.EncodeToken("1")}.IsKeyset())
+	})
+}
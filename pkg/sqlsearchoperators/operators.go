@@ -3,13 +3,20 @@
 
 package sqlsearchoperators
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 // SearchOperators entity to mapping a list of possible search operators for sql.
 var SearchOperators = map[string]SearchOperator{
-	"eq":  EQ,
-	"gt":  GT,
-	"lt":  LT,
-	"gte": GTE,
-	"lte": LTE,
+	"eq":      EQ,
+	"gt":      GT,
+	"lt":      LT,
+	"gte":     GTE,
+	"lte":     LTE,
+	"in":      IN,
+	"between": BETWEEN,
 }
 
 // SearchOperator defines the list of possible search operators for sql.
@@ -28,4 +35,37 @@ const (
 	LTE SearchOperator = "<="
 	// LIKE - like to value.
 	LIKE SearchOperator = "LIKE"
+	// IN - equal to one of the comma separated values.
+	IN SearchOperator = "IN"
+	// BETWEEN - between two comma separated values inclusive.
+	BETWEEN SearchOperator = "BETWEEN"
 )
+
+// IsValid checks that search operator is one of the known operators.
+func (operator SearchOperator) IsValid() bool {
+	switch operator {
+	case EQ, GT, LT, GTE, LTE, LIKE, IN, BETWEEN:
+		return true
+	}
+	return false
+}
+
+// UnmarshalJSON decodes search operator either from its key (e.g. "gte") or from its sql representation (e.g. ">=").
+func (operator *SearchOperator) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	if searchOperator, ok := SearchOperators[value]; ok {
+		*operator = searchOperator
+		return nil
+	}
+
+	if !SearchOperator(value).IsValid() {
+		return fmt.Errorf("invalid search operator - %s", value)
+	}
+
+	*operator = SearchOperator(value)
+	return nil
+}
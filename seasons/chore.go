@@ -44,6 +44,8 @@ func (chore *Chore) Run(ctx context.Context) (err error) {
 			return ChoreError.Wrap(err)
 		}
 
+		// the last season identifies the end of the seasons of all divisions, so the cards are aged once for them.
+		var lastSeasonID int
 		for _, season := range seasons {
 			if season.EndedAt.IsZero() {
 				err = chore.seasons.EndSeason(ctx, season.ID)
@@ -51,6 +53,16 @@ func (chore *Chore) Run(ctx context.Context) (err error) {
 					return ChoreError.Wrap(err)
 				}
 			}
+			if season.ID > lastSeasonID {
+				lastSeasonID = season.ID
+			}
+		}
+
+		if lastSeasonID != 0 {
+			err = chore.seasons.AgeCards(ctx, lastSeasonID)
+			if err != nil {
+				return ChoreError.Wrap(err)
+			}
 		}
 
 		err = chore.seasons.Create(ctx)
@@ -74,8 +74,30 @@ type Config struct {
 
 // SeasonStatistics returns statistics of clubs in season.
 type SeasonStatistics struct {
-	Division   divisions.Division  `json:"division"`
-	Statistics []matches.Statistic `json:"statistics"`
+	Division   divisions.Division `json:"division"`
+	Statistics []ClubStatistic    `json:"statistics"`
+}
+
+// ClubStatistic describes statistics of the club in season with badge of the club.
+type ClubStatistic struct {
+	matches.Statistic
+	BadgeURL string `json:"badgeUrl"`
+}
+
+// NewSeasonStatistics returns statistics of clubs of the division in season with badges of the clubs.
+func NewSeasonStatistics(division divisions.Division, statistics []matches.Statistic) SeasonStatistics {
+	seasonStatistics := SeasonStatistics{
+		Division:   division,
+		Statistics: make([]ClubStatistic, 0, len(statistics)),
+	}
+	for _, statistic := range statistics {
+		seasonStatistics.Statistics = append(seasonStatistics.Statistics, ClubStatistic{
+			Statistic: statistic,
+			BadgeURL:  statistic.Club.BadgeURL,
+		})
+	}
+
+	return seasonStatistics
 }
 
 // Reward entity describes values which send to user after season ends.
@@ -15,8 +15,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"ultimatedivision"
+	"ultimatedivision/clubs"
 	"ultimatedivision/database/dbtesting"
 	"ultimatedivision/divisions"
+	"ultimatedivision/gameplay/matches"
 	"ultimatedivision/seasons"
 	"ultimatedivision/users"
 )
@@ -162,6 +164,21 @@ func TestSeasons(t *testing.T) {
 	})
 }
 
+func TestNewSeasonStatistics(t *testing.T) {
+	division := divisions.Division{ID: uuid.New(), Name: 10}
+	statistics := []matches.Statistic{
+		{Club: clubs.Club{ID: uuid.New(), BadgeURL: "https://badges/1.png"}, Points: 6},
+		{Club: clubs.Club{ID: uuid.New()}, Points: 3},
+	}
+
+	seasonStatistics := seasons.NewSeasonStatistics(division, statistics)
+	assert.Equal(t, division, seasonStatistics.Division)
+	require.Equal(t, 2, len(seasonStatistics.Statistics))
+	assert.Equal(t, "https://badges/1.png", seasonStatistics.Statistics[0].BadgeURL)
+	assert.Equal(t, 6, seasonStatistics.Statistics[0].Points)
+	assert.Equal(t, "", seasonStatistics.Statistics[1].BadgeURL)
+}
+
 func compareSeasons(t *testing.T, season1, season2 seasons.Season) {
 	assert.Equal(t, season1.ID, season2.ID)
 	assert.Equal(t, season1.DivisionID, season2.DivisionID)
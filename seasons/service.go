@@ -15,8 +15,10 @@ import (
 	"github.com/google/uuid"
 	"github.com/zeebo/errs"
 
+	"ultimatedivision/cards"
 	"ultimatedivision/clubs"
 	"ultimatedivision/divisions"
+	"ultimatedivision/finances"
 	"ultimatedivision/gameplay/matches"
 	"ultimatedivision/udts/currencywaitlist"
 	"ultimatedivision/users"
@@ -34,20 +36,24 @@ type Service struct {
 	matches          *matches.Service
 	config           Config
 	clubs            *clubs.Service
+	cards            *cards.Service
 	users            *users.Service
 	currencywaitlist *currencywaitlist.Service
+	finances         *finances.Service
 }
 
 // NewService is a constructor for seasons service.
-func NewService(seasons DB, config Config, divisions *divisions.Service, matches *matches.Service, clubs *clubs.Service, users *users.Service, currencywaitlist *currencywaitlist.Service) *Service {
+func NewService(seasons DB, config Config, divisions *divisions.Service, matches *matches.Service, clubs *clubs.Service, cards *cards.Service, users *users.Service, currencywaitlist *currencywaitlist.Service, finances *finances.Service) *Service {
 	return &Service{
 		seasons:          seasons,
 		divisions:        divisions,
 		config:           config,
 		matches:          matches,
 		clubs:            clubs,
+		cards:            cards,
 		users:            users,
 		currencywaitlist: currencywaitlist,
+		finances:         finances,
 	}
 }
 
@@ -73,6 +79,13 @@ func (service *Service) Create(ctx context.Context) error {
 	return nil
 }
 
+// AgeCards makes all cards one season older at the end of the season, cards are aged only once in the season.
+// Retired cards are removed from the squads together with the aging.
+func (service *Service) AgeCards(ctx context.Context, seasonID int) error {
+	_, err := service.cards.AgeAll(ctx, seasonID)
+	return ErrSeasons.Wrap(err)
+}
+
 // CreateReward creates a rewards in the end of a season.
 func (service *Service) CreateReward(ctx context.Context, reward Reward) error {
 	return ErrSeasons.Wrap(service.seasons.CreateReward(ctx, reward))
@@ -276,6 +289,13 @@ func (service *Service) UpdateClubsToNewDivision(ctx context.Context) error {
 			return sortStatistics[i].Points < sortStatistics[j].Points
 		})
 
+		for i, statistic := range sortStatistics {
+			place := len(sortStatistics) - i
+			if err = service.finances.AddSeasonPrize(ctx, statistic.Club.ID, statistic.SeasonID, place); err != nil {
+				return ErrSeasons.Wrap(err)
+			}
+		}
+
 		if len(sortStatistics) > 0 {
 			topStatisticsClubs := sortStatistics[len(sortStatistics)-int(totalPassingClubs):]
 			lowStatisticsClubs := sortStatistics[:int(totalPassingClubs)]
@@ -10,6 +10,7 @@ import (
 
 	"ultimatedivision/cards"
 	"ultimatedivision/cards/waitlist"
+	"ultimatedivision/finances"
 	"ultimatedivision/pkg/rand"
 )
 
@@ -24,15 +25,17 @@ type Service struct {
 	store    DB
 	cards    *cards.Service
 	waitlist *waitlist.Service
+	finances *finances.Service
 }
 
 // NewService is a constructor for store service.
-func NewService(config Config, store DB, cards *cards.Service, waitlist *waitlist.Service) *Service {
+func NewService(config Config, store DB, cards *cards.Service, waitlist *waitlist.Service, finances *finances.Service) *Service {
 	return &Service{
 		config:   config,
 		store:    store,
 		cards:    cards,
 		waitlist: waitlist,
+		finances: finances,
 	}
 }
 
@@ -67,7 +70,7 @@ func (service *Service) Buy(ctx context.Context, createNFT waitlist.CreateNFT) (
 		return transaction, ErrStore.Wrap(err)
 	}
 
-	return transaction, ErrStore.Wrap(service.cards.UpdateType(ctx, createNFT.CardID, cards.TypeOrdered))
+	return transaction, ErrStore.Wrap(service.finances.BuyInStore(ctx, createNFT.UserID, cardsList[randNumberCard-1], setting.Price))
 }
 
 // Create creates setting of store in database.